@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/rules"
+
+	"github.com/spf13/cobra"
+)
+
+func actionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "action",
+		Short: "Manage actions",
+	}
+
+	cmd.AddCommand(actionUpdateCmd())
+	cmd.AddCommand(actionDetachCmd())
+	cmd.AddCommand(actionCloneCmd())
+	return cmd
+}
+
+func actionUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "update <id>",
+		Short:             "Update an action, or with --series the action and all later occurrences",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			update := database.ActionUpdate{
+				Name:           changedString(cmd, "name"),
+				Note:           changedString(cmd, "note"),
+				ProjectID:      changedUint(cmd, "project"),
+				DueDate:        changedString(cmd, "due"),
+				StatusID:       changedUint(cmd, "status"),
+				RepeatMode:     changedString(cmd, "repeat-mode"),
+				RepeatCount:    changedUint(cmd, "repeat-count"),
+				RepeatInterval: changedString(cmd, "repeat-interval"),
+				RepeatPattern:  changedString(cmd, "repeat-pattern"),
+				RepeatUntil:    changedString(cmd, "repeat-until"),
+				Flagged:        changedBool(cmd, "flagged"),
+				Location:       changedString(cmd, "location"),
+				Energy:         changedString(cmd, "energy"),
+				WaitingOn:      changedString(cmd, "waiting-on"),
+				FollowUp:       changedString(cmd, "follow-up"),
+				StartDate:      changedString(cmd, "start"),
+			}
+
+			if coordinates := changedString(cmd, "coordinates"); coordinates != nil {
+				parsed, err := database.ParseCoordinates(*coordinates)
+				if err != nil {
+					fmt.Printf("❌ %v\n", err)
+					return
+				}
+				update.Coordinates = &parsed
+			}
+
+			reminders := changedStrings(cmd, "remind")
+			if reminders != nil {
+				if err := database.ValidateReminders(*reminders); err != nil {
+					fmt.Printf("❌ %v\n", err)
+					return
+				}
+			}
+
+			// An empty username removes the assignee
+			if assignee := changedString(cmd, "assignee"); assignee != nil {
+				var assigneeID uint
+				if *assignee != "" {
+					user, ok := lookupUser(*assignee)
+					if !ok {
+						return
+					}
+					assigneeID = user.ID
+				}
+				update.AssigneeID = &assigneeID
+			}
+
+			var previous *database.Action
+			notifyAssignee, _ := cmd.Flags().GetBool("notify")
+			if notifyAssignee || rules.Enabled() {
+				var err error
+				previous, err = database.GetActionByID(database.GetDatabasePath(), actionID)
+				if err != nil {
+					fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+					return
+				}
+			}
+
+			if !checkWIPLimit(cmd, actionID, update) {
+				return
+			}
+
+			if reminders != nil {
+				if err := database.SetReminders(database.GetDatabasePath(), actionID, *reminders); err != nil {
+					fmt.Println(i18n.T("FailedToSetReminders", i18n.Data{"Error": err}))
+					return
+				}
+			}
+
+			series, _ := cmd.Flags().GetBool("series")
+			if series {
+				updated, err := database.UpdateActionSeries(database.GetDatabasePath(), actionID, update)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToUpdateActionSeries", i18n.Data{"Error": err}))
+					return
+				}
+				fmt.Println(i18n.T("UpdatedOccurrences", i18n.Data{"Count": updated, "ID": actionID}))
+				return
+			}
+
+			if err := database.UpdateAction(database.GetDatabasePath(), actionID, update); err != nil {
+				fmt.Println(i18n.T("FailedToUpdateAction", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("UpdatedAction", i18n.Data{"ID": actionID}))
+			applyRules(previous, actionID)
+			fireActionHook(hooks.ActionUpdated, actionID)
+
+			if notifyAssignee && previous != nil {
+				sendAssigneeNotification(previous, actionID)
+			}
+		},
+	}
+
+	cmd.Flags().String("name", "", "New action name")
+	cmd.Flags().String("note", "", "New note")
+	cmd.Flags().Uint("project", 0, "Project ID (0 removes the action from its project)")
+	cmd.Flags().String("due", "", "Due date (YYYY-MM-DD, empty clears the date)")
+	cmd.Flags().String("start", "", "Start date, before which the action is left out of active lists (YYYY-MM-DD, empty clears the date)")
+	cmd.Flags().Uint("status", 0, "Status ID")
+	cmd.Flags().String("repeat-mode", "", "Repeat mode: none, forever, count or until")
+	cmd.Flags().Uint("repeat-count", 0, "Number of remaining repetitions for the count mode")
+	cmd.Flags().String("repeat-interval", "", "Repeat interval: minute, hour, day, week, month or year")
+	cmd.Flags().String("repeat-pattern", "", "Weekly repeat pattern, e.g. mon,wed,fri")
+	cmd.Flags().String("repeat-until", "", "Last date for the until mode (YYYY-MM-DD)")
+	cmd.Flags().String("assignee", "", "Username to assign the action to (empty removes the assignee)")
+	cmd.Flags().Bool("flagged", false, "Flag the action for today (--flagged=false removes the flag)")
+	cmd.Flags().String("location", "", "Location, such as office or supermarket (empty removes the location and coordinates)")
+	cmd.Flags().String("coordinates", "", "Coordinates of the location as latitude,longitude, such as 52.37,4.89")
+	cmd.Flags().String("energy", "", "Energy level: low, medium or high (empty removes the level)")
+	cmd.Flags().String("waiting-on", "", "Person or system the action waits on (empty removes it and the follow-up date)")
+	cmd.Flags().String("follow-up", "", "Date to follow up on what the action waits on (YYYY-MM-DD, empty clears the date)")
+	cmd.Flags().StringSlice("remind", nil, "Reminders before the due date, such as -1d or -2h (repeatable, replaces the reminders, empty removes them)")
+	cmd.Flags().Bool("series", false, "Also apply the changes to all later occurrences")
+	cmd.Flags().Bool("notify", false, "Notify the assignee when assigned or when the due date changed")
+	cmd.RegisterFlagCompletionFunc("project", completeProjectIDs)
+	cmd.RegisterFlagCompletionFunc("assignee", completeUsernames)
+	return cmd
+}
+
+func actionDetachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "detach <id>",
+		Short:             "Detach a single occurrence from its repeat series",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			if err := database.DetachAction(database.GetDatabasePath(), actionID); err != nil {
+				fmt.Println(i18n.T("FailedToDetachAction", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("DetachedAction", i18n.Data{"ID": actionID}))
+		},
+	}
+}
+
+func actionCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "clone <id>",
+		Short:             "Copy an action as a new todo action in the same project",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			cloneID, err := database.CloneAction(database.GetDatabasePath(), actionID, cloneOptions(cmd))
+			if err != nil {
+				fmt.Println(i18n.T("FailedToCloneAction", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("ClonedAction", i18n.Data{"ID": actionID, "CloneID": cloneID}))
+		},
+	}
+
+	addCloneFlags(cmd)
+	return cmd
+}
+
+// addCloneFlags adds the flags read by cloneOptions
+func addCloneFlags(cmd *cobra.Command) {
+	cmd.Flags().String("name", "", "Name of the copy (default the original name with \" (copy)\")")
+	cmd.Flags().Bool("no-tags", false, "Do not copy the tags")
+	cmd.Flags().Bool("no-notes", false, "Do not copy the notes")
+	cmd.Flags().String("shift", "", "Move the due dates, such as 3d, 2w, 1m or -1d")
+}
+
+// cloneOptions reads the clone flags of a command
+func cloneOptions(cmd *cobra.Command) database.CloneOptions {
+	name, _ := cmd.Flags().GetString("name")
+	noTags, _ := cmd.Flags().GetBool("no-tags")
+	noNotes, _ := cmd.Flags().GetBool("no-notes")
+	shift, _ := cmd.Flags().GetString("shift")
+	return database.CloneOptions{Name: name, Actions: true, Tags: !noTags, Notes: !noNotes, Shift: shift}
+}
+
+// sendAssigneeNotification notifies the assignee of an action through the
+// configured channels when it was newly assigned or its due date changed
+func sendAssigneeNotification(previous *database.Action, actionID uint) {
+	action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+	if err != nil || action == nil || !action.AssigneeID.Valid {
+		return
+	}
+
+	dispatcher, ok := loadDispatcher()
+	if !ok {
+		return
+	}
+
+	switch {
+	case previous.AssigneeID != action.AssigneeID:
+		err = notify.SendAssigned(database.GetDatabasePath(), dispatcher, actionID)
+	case previous.DueDate != action.DueDate:
+		err = notify.SendDueChanged(database.GetDatabasePath(), dispatcher, actionID)
+	default:
+		return
+	}
+	if err != nil {
+		fmt.Println(i18n.T("FailedToNotifyAssignee", i18n.Data{"Error": err}))
+		return
+	}
+	fmt.Println(i18n.T("NotifiedAssignee", i18n.Data{"Name": action.AssigneeName.String}))
+}
+
+// checkWIPLimit warns when an update would exceed a work-in-progress limit
+// and asks whether to exceed it anyway. It returns whether to go ahead.
+func checkWIPLimit(cmd *cobra.Command, actionID uint, update database.ActionUpdate) bool {
+	err := database.CheckWIPLimit(database.GetDatabasePath(), actionID, update)
+	var limitErr *database.WIPLimitError
+	if errors.As(err, &limitErr) {
+		fmt.Println(i18n.T("WIPLimitReached", i18n.Data{"Error": err}))
+		return confirm(cmd, i18n.T("WIPLimitExceedPrompt"))
+	}
+	if err != nil {
+		fmt.Println(i18n.T("FailedToCheckWIPLimits", i18n.Data{"Error": err}))
+		return false
+	}
+	return true
+}
+
+// parseActionID parses an action ID argument, printing an error when it is invalid
+func parseActionID(arg string) (uint, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return 0, false
+	}
+
+	actionID, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil {
+		fmt.Println(i18n.T("InvalidActionID", i18n.Data{"ID": arg}))
+		return 0, false
+	}
+	return uint(actionID), true
+}
+
+// changedString returns the flag value if it was set on the command line
+func changedString(cmd *cobra.Command, name string) *string {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	value, _ := cmd.Flags().GetString(name)
+	return &value
+}
+
+// changedBool returns the flag value if it was set on the command line
+func changedBool(cmd *cobra.Command, name string) *bool {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	value, _ := cmd.Flags().GetBool(name)
+	return &value
+}
+
+// changedStrings returns the values of a repeatable flag if it was set on the
+// command line
+func changedStrings(cmd *cobra.Command, name string) *[]string {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	values, _ := cmd.Flags().GetStringSlice(name)
+	return &values
+}
+
+// changedUint returns the flag value if it was set on the command line
+func changedUint(cmd *cobra.Command, name string) *uint {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	value, _ := cmd.Flags().GetUint(name)
+	return &value
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/quickadd"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// addedRecord is the JSON form of a created action in command output
+type addedRecord struct {
+	ID      uint     `json:"id"`
+	Name    string   `json:"name"`
+	DueDate string   `json:"due_date,omitempty"`
+	Tags    []string `json:"tags"`
+}
+
+func addCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add [name...]",
+		Short: "Add an action, or one action per line of stdin with --stdin",
+		Long: `Add an action. The name may contain inline tokens:
+
+  #tag, @context    add a tag
+  !priority         set the priority, such as !high (stored as a priority: tag)
+  due:<date>        set the due date: YYYY-MM-DD, today, tomorrow or a day name such as fri
+  start:<date>      hide the action from active lists until the start date (same formats as due:)
+  every:<interval>  repeat forever: day, week, month, year or days such as mon,thu
+  remind:<offsets>  remind before the due date, such as remind:-1d or remind:-1d,-2h
+  +Project          add the action to a project
+
+Without a name, a quick-entry bar opens that previews how the line is parsed.
+With --stdin, every non-empty line of stdin is added as an action, all in a
+single transaction. Actions that already exist as an open action with the
+same name in the same project are refused unless --allow-duplicate is given:
+
+  printf 'Buy milk @errands #home due:fri\nCall mom\n' | projector add --stdin
+
+With --remote, or a remote URL in the config file, add, list, done and delete
+work on the actions of a projector API server instead of the local database,
+so several machines can share one server:
+
+  {"remote": {"url": "http://server:8080", "token": "..."}}
+
+Remote actions are added one by one rather than in a single transaction.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			fromStdin, _ := cmd.Flags().GetBool("stdin")
+			project, _ := cmd.Flags().GetString("project")
+			due, _ := cmd.Flags().GetString("due")
+			tags, _ := cmd.Flags().GetStringSlice("tag")
+			allowDuplicate, _ := cmd.Flags().GetBool("allow-duplicate")
+			location, _ := cmd.Flags().GetString("location")
+			energy, _ := cmd.Flags().GetString("energy")
+			reminders, _ := cmd.Flags().GetStringSlice("remind")
+
+			if fromStdin && len(args) > 0 {
+				fmt.Println(i18n.T("AddNameOrStdin"))
+				return
+			}
+			if err := database.ValidateReminders(reminders); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+
+			// Connect first, so the quick-entry bar shows the state of the remote
+			client, ok := remoteClient(cmd)
+			if !ok {
+				return
+			}
+
+			var lines []string
+			switch {
+			case len(args) > 0:
+				lines = []string{strings.Join(args, " ")}
+			case fromStdin:
+				scanner := bufio.NewScanner(os.Stdin)
+				for scanner.Scan() {
+					lines = append(lines, scanner.Text())
+				}
+				if err := scanner.Err(); err != nil {
+					fmt.Println(i18n.T("FailedToReadStdin", i18n.Data{"Error": err}))
+					return
+				}
+			default:
+				line, ok := quickEntry(client == nil)
+				if !ok {
+					return
+				}
+				lines = []string{line}
+			}
+
+			if client != nil {
+				runRemoteAdd(client, lines, project, due, location, energy, tags, reminders, allowDuplicate)
+				return
+			}
+			runAdd(lines, project, due, location, energy, tags, reminders, allowDuplicate)
+		},
+	}
+
+	cmd.Flags().Bool("stdin", false, "Read one action per line from stdin")
+	cmd.Flags().StringP("project", "p", "", "Add the actions without +Project to this project (name or ID)")
+	cmd.Flags().String("due", "", "Due date for actions without due: (same formats as due:)")
+	cmd.Flags().StringSliceP("tag", "t", nil, "Tag to add to every action (repeatable)")
+	cmd.Flags().String("location", "", "Location of every action, such as office or supermarket")
+	cmd.Flags().String("energy", "", "Energy level of every action: low, medium or high")
+	cmd.Flags().StringSlice("remind", nil, "Remind before the due date, such as -1d or -2h (repeatable)")
+	cmd.Flags().Bool("allow-duplicate", false, "Add actions even when an open action with the same name exists in the project")
+	addRemoteFlags(cmd)
+	cmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	cmd.RegisterFlagCompletionFunc("tag", completeTagNames)
+	return cmd
+}
+
+// runAdd parses the lines and creates an action for each non-empty one
+func runAdd(lines []string, project, due, location, energy string, tags, reminders []string, allowDuplicate bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	var projectID *uint
+	if project != "" {
+		found, ok := lookupProject(project)
+		if !ok {
+			return
+		}
+		projectID = &found.ID
+	}
+
+	now := time.Now()
+	if due != "" {
+		date, err := quickadd.ParseDate(due, now)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		due = date
+	}
+
+	var actions []database.NewAction
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry, err := quickadd.Parse(line, now)
+		if err != nil {
+			fmt.Println(i18n.T("LineError", i18n.Data{"Line": i + 1, "Error": err}))
+			return
+		}
+		if entry.DueDate == "" {
+			entry.DueDate = due
+		}
+
+		action, err := entry.Resolve(database.GetDatabasePath())
+		if err != nil {
+			fmt.Println(i18n.T("LineError", i18n.Data{"Line": i + 1, "Error": err}))
+			return
+		}
+		if action.ProjectID == nil {
+			action.ProjectID = projectID
+		}
+		action.Tags = mergeTags(action.Tags, tags)
+		action.Location = location
+		action.Energy = energy
+		if len(action.Reminders) == 0 {
+			action.Reminders = reminders
+		}
+
+		if !allowDuplicate && !checkDuplicate(i+1, action, actions) {
+			return
+		}
+		actions = append(actions, action)
+	}
+
+	if len(actions) == 0 {
+		fmt.Println(i18n.T("NoActionsToAdd"))
+		return
+	}
+
+	actionIDs, err := database.CreateActions(database.GetDatabasePath(), actions)
+	if err != nil {
+		fmt.Println(i18n.T("FailedToAddActions", i18n.Data{"Error": err}))
+		return
+	}
+
+	records := []addedRecord{}
+	for i, action := range actions {
+		records = append(records, addedRecord{ID: actionIDs[i], Name: action.Name, DueDate: action.DueDate, Tags: action.Tags})
+	}
+	printAdded(records)
+
+	for _, actionID := range actionIDs {
+		applyRules(nil, actionID)
+		fireActionHook(hooks.ActionCreated, actionID)
+	}
+}
+
+// printAdded prints the created actions
+func printAdded(records []addedRecord) {
+	table := output.Table{Headers: []string{"ID", "NAME", "DUE", "TAGS"}}
+	for i := range records {
+		if records[i].Tags == nil {
+			records[i].Tags = []string{}
+		}
+		record := records[i]
+		table.AddRow(fmt.Sprint(record.ID), record.Name, displayDate(record.DueDate), strings.Join(record.Tags, ","))
+	}
+
+	if err := output.Print(records, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+		return
+	}
+	if !output.IsJSON() {
+		fmt.Println(i18n.T("ActionsAdded", i18n.Data{"Count": len(records)}))
+	}
+}
+
+// checkDuplicate reports whether the action may be added, printing why not
+// when an open action or an earlier line has the same name and project
+func checkDuplicate(line int, action database.NewAction, earlier []database.NewAction) bool {
+	for _, other := range earlier {
+		if other.Name == action.Name && sameProject(other.ProjectID, action.ProjectID) {
+			fmt.Println(i18n.T("DuplicateOnEarlierLine", i18n.Data{"Line": line, "Name": action.Name}))
+			return false
+		}
+	}
+
+	existing, err := database.FindOpenDuplicate(database.GetDatabasePath(), action.Name, action.ProjectID)
+	if err != nil {
+		fmt.Println(i18n.T("FailedToCheckDuplicates", i18n.Data{"Error": err}))
+		return false
+	}
+	if existing != nil {
+		fmt.Println(i18n.T("DuplicateExists", i18n.Data{"Line": line, "Name": action.Name, "ID": existing.ID}))
+		return false
+	}
+	return true
+}
+
+// sameProject reports whether two optional project IDs are equal
+func sameProject(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// quickEntry opens the quick-entry bar and returns the entered line. With
+// local set the line is validated against the local database as it is
+// typed, an API server validates the line when it is sent. It returns false
+// when the entry is cancelled or stdin is not a terminal.
+func quickEntry(local bool) (string, bool) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Println(i18n.T("NoActionGiven"))
+		return "", false
+	}
+
+	var validate func(string) error
+	if local && database.DatabaseExists(database.GetDatabasePath()) {
+		validate = validateLine
+	}
+
+	line, err := ui.QuickEntry(i18n.T("QuickEntryTitle"), validate)
+	if err != nil {
+		fmt.Println(i18n.T("QuickEntryFailed", i18n.Data{"Error": err}))
+		return "", false
+	}
+	return line, line != ""
+}
+
+// validateLine checks the action of a line in quick-add syntax against the
+// validation rules, as CreateActions will
+func validateLine(line string) error {
+	entry, err := quickadd.Parse(line, time.Now())
+	if err != nil {
+		return err
+	}
+	action, err := entry.Resolve(database.GetDatabasePath())
+	if err != nil {
+		return err
+	}
+	statusID, err := database.DefaultStatusID(database.GetDatabasePath())
+	if err != nil {
+		return err
+	}
+
+	input := action.Input(statusID)
+	return database.ValidateAction(database.GetDatabasePath(), &input)
+}
+
+// mergeTags appends the extra tags that are not in the list yet
+func mergeTags(tags, extra []string) []string {
+	for _, tag := range extra {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
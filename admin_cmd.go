@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/remote"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultAdminURL is the API server admin commands talk to without --remote
+const defaultAdminURL = "http://localhost:8080"
+
+func adminCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Administer an API server",
+		Long:  "Administer an API server through its admin endpoints. Requires the token of an admin account once user accounts exist.",
+	}
+
+	cmd.PersistentFlags().String("remote", "", "URL of the API server (or PROJECTOR_REMOTE_URL, default "+defaultAdminURL+")")
+	cmd.PersistentFlags().String("token", "", "API token of an admin (or PROJECTOR_REMOTE_TOKEN)")
+
+	cmd.AddCommand(adminUsersCmd())
+	cmd.AddCommand(adminDisableCmd(true))
+	cmd.AddCommand(adminDisableCmd(false))
+	cmd.AddCommand(adminStatsCmd())
+	cmd.AddCommand(adminBackupCmd())
+	return cmd
+}
+
+func adminUsersCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "users",
+		Short: "List the user accounts of the server",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := adminClient(cmd)
+
+			users, err := client.ListUsers()
+			if err != nil {
+				fmt.Printf("❌ Error retrieving users: %v\n", err)
+				return
+			}
+
+			accounts := make([]database.User, 0, len(users))
+			for _, user := range users {
+				accounts = append(accounts, database.User(user))
+			}
+			printUsers(accounts)
+		},
+	}
+}
+
+// adminDisableCmd builds the disable command, or the enable command when disable is false
+func adminDisableCmd(disable bool) *cobra.Command {
+	use, short, done := "enable <username>", "Re-enable a disabled user account", "Enabled"
+	if disable {
+		use, short, done = "disable <username>", "Disable a user account and revoke its tokens", "Disabled"
+	}
+
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client := adminClient(cmd)
+
+			users, err := client.ListUsers()
+			if err != nil {
+				fmt.Printf("❌ Error retrieving users: %v\n", err)
+				return
+			}
+
+			for _, user := range users {
+				if user.Username != args[0] {
+					continue
+				}
+
+				if err := client.SetUserDisabled(user.ID, disable); err != nil {
+					fmt.Printf("❌ Failed to update user: %v\n", err)
+					return
+				}
+				fmt.Printf("✅ %s user %s\n", done, user.Username)
+				return
+			}
+
+			fmt.Printf("❌ User %s not found\n", args[0])
+		},
+	}
+}
+
+func adminStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show statistics of the server database",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := adminClient(cmd)
+
+			stats, err := client.GetStats()
+			if err != nil {
+				fmt.Printf("❌ Error retrieving stats: %v\n", err)
+				return
+			}
+
+			printStats(stats)
+		},
+	}
+}
+
+func adminBackupCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the server database on the server",
+		Run: func(cmd *cobra.Command, args []string) {
+			client := adminClient(cmd)
+
+			backupPath, err := client.Backup()
+			if err != nil {
+				fmt.Printf("❌ Backup failed: %v\n", err)
+				return
+			}
+			fmt.Printf("✅ Backup written to %s on the server\n", backupPath)
+		},
+	}
+}
+
+// adminClient creates a client for the server given by the --remote and
+// --token flags or their environment variables
+func adminClient(cmd *cobra.Command) *remote.Client {
+	remoteURL, _ := cmd.Flags().GetString("remote")
+	if remoteURL == "" {
+		remoteURL = os.Getenv("PROJECTOR_REMOTE_URL")
+	}
+	if remoteURL == "" {
+		remoteURL = defaultAdminURL
+	}
+
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("PROJECTOR_REMOTE_TOKEN")
+	}
+
+	return remote.NewClient(remoteURL, token)
+}
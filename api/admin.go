@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// requireAdmin wraps an authenticated handler so only admins can use it. In
+// single-user mode there are no accounts and everyone is an admin.
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if user := currentUser(r); user != nil && !user.IsAdmin {
+			http.Error(w, "Forbidden: requires the admin role", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleAdminUsers lists all user accounts
+func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	users, err := database.GetAllUsers(s.dbPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving users: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"count":   len(users),
+		"users":   newUsers(r, users),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAdminUserByID disables, enables or changes the admin role of a user
+func (s *Server) handleAdminUserByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "PATCH" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract ID from URL path
+	path := r.URL.Path
+	if len(path) < 17 { // "/api/admin/users/" is 17 characters
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := strconv.ParseUint(path[17:], 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := database.GetUserByID(s.dbPath, uint(userID))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving user: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Parse request body, omitted fields are left unchanged
+	var updateRequest struct {
+		Disabled *bool `json:"disabled,omitempty"`
+		IsAdmin  *bool `json:"is_admin,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Admins cannot lock themselves out
+	if current := currentUser(r); current != nil && current.ID == user.ID {
+		if (updateRequest.Disabled != nil && *updateRequest.Disabled) || (updateRequest.IsAdmin != nil && !*updateRequest.IsAdmin) {
+			http.Error(w, "Cannot disable or demote your own account", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if updateRequest.Disabled != nil {
+		if err := database.SetUserDisabled(s.dbPath, user.ID, *updateRequest.Disabled); err != nil {
+			http.Error(w, fmt.Sprintf("Error updating user: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if updateRequest.IsAdmin != nil {
+		if err := database.SetUserAdmin(s.dbPath, user.ID, *updateRequest.IsAdmin); err != nil {
+			http.Error(w, fmt.Sprintf("Error updating user: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	user, err = database.GetUserByID(s.dbPath, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving updated user: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "User updated successfully",
+		"user":    newUser(r, user),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAdminStats reports counts for the whole database
+func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := database.GetStats(s.dbPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"stats":   stats,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleAdminBackup writes a backup of the database next to it on the server
+func (s *Server) handleAdminBackup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	backupPath, err := database.BackupDatabase(s.dbPath, database.GetBackupDir(s.dbPath))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating backup: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Backup created successfully",
+		"path":    backupPath,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// contextKey is the type of the request context keys set by the API
+type contextKey string
+
+// userContextKey holds the authenticated user in the request context
+const userContextKey contextKey = "user"
+
+// authenticate wraps a handler so it requires a valid API token once user
+// accounts exist. Without accounts the API runs in single-user mode and all
+// requests are allowed. Retries with an idempotency key are answered once
+// the user is known.
+func (s *Server) authenticate(next http.HandlerFunc) http.HandlerFunc {
+	next = s.idempotent(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		count, err := database.CountUsers(s.dbPath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error checking users: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if count == 0 {
+			next(w, r)
+			return
+		}
+
+		token := requestToken(r)
+		if token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := database.GetUserByToken(s.dbPath, token)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error checking token: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+	}
+}
+
+// currentUser returns the authenticated user, or nil in single-user mode
+func currentUser(r *http.Request) *database.User {
+	user, _ := r.Context().Value(userContextKey).(*database.User)
+	return user
+}
+
+// canAccess reports whether a user may access an item with the given owner.
+// In single-user mode everything is accessible.
+func canAccess(user *database.User, ownerID sql.NullInt64) bool {
+	return user == nil || (ownerID.Valid && uint(ownerID.Int64) == user.ID)
+}
+
+// requestToken returns the API token from the Authorization header, or from
+// the token query parameter for clients such as calendar apps that cannot set
+// headers
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+	return r.URL.Query().Get("token")
+}
+
+// handleLogin issues an API token for a username and password
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var loginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	user, err := database.Authenticate(s.dbPath, loginRequest.Username, loginRequest.Password)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error checking credentials: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "Invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := database.CreateToken(s.dbPath, user.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating token: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"token":    token,
+		"user_id":  user.ID,
+		"username": user.Username,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleLogout revokes the API token used for the request
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if token := requestToken(r); token != "" {
+		if err := database.DeleteToken(s.dbPath, token); err != nil {
+			http.Error(w, fmt.Sprintf("Error revoking token: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Logged out",
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// accessibleAction retrieves an action together with the role of the current
+// user for it, returning nil when it does not exist or is not shared with the
+// user
+func (s *Server) accessibleAction(r *http.Request, actionID uint) (*database.Action, string, error) {
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil || action == nil {
+		return nil, "", err
+	}
+
+	role, err := s.actionRole(r, action)
+	if err != nil || role == "" {
+		return nil, "", err
+	}
+	return action, role, nil
+}
+
+// actionRole returns the role of the current user for an action. The owner of
+// an action owns it and its assignee can edit it, otherwise the role in the
+// project of the action applies.
+func (s *Server) actionRole(r *http.Request, action *database.Action) (string, error) {
+	user := currentUser(r)
+	if canAccess(user, action.OwnerID) {
+		return database.RoleOwner, nil
+	}
+	if canAccess(user, action.AssigneeID) {
+		return database.RoleEditor, nil
+	}
+	if !action.ProjectID.Valid {
+		return "", nil
+	}
+	return database.GetProjectRole(s.dbPath, uint(action.ProjectID.Int64), user.ID)
+}
+
+// accessibleProject retrieves a project together with the role of the current
+// user in it, returning nil when it does not exist or is not shared with the
+// user
+func (s *Server) accessibleProject(r *http.Request, projectID uint) (*database.Project, string, error) {
+	project, err := database.GetProjectByID(s.dbPath, projectID)
+	if err != nil || project == nil {
+		return nil, "", err
+	}
+
+	user := currentUser(r)
+	if user == nil {
+		return project, database.RoleOwner, nil
+	}
+
+	role, err := database.GetProjectRole(s.dbPath, projectID, user.ID)
+	if err != nil || role == "" {
+		return nil, "", err
+	}
+	return project, role, nil
+}
+
+// requireRole verifies that a role grants the required permissions, writing
+// an error response when not
+func requireRole(w http.ResponseWriter, role, required string) bool {
+	if !database.HasRole(role, required) {
+		http.Error(w, fmt.Sprintf("Forbidden: requires the %s role", required), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// checkProjectAccess verifies that the current user may add actions to a
+// project, writing an error response when not
+func (s *Server) checkProjectAccess(w http.ResponseWriter, r *http.Request, projectID *uint) bool {
+	if projectID == nil || currentUser(r) == nil {
+		return true
+	}
+
+	project, role, err := s.accessibleProject(r, *projectID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving project: %v", err), http.StatusInternalServerError)
+		return false
+	}
+	if project == nil {
+		http.Error(w, "Project not found", http.StatusBadRequest)
+		return false
+	}
+	return requireRole(w, role, database.RoleEditor)
+}
+
+// userActions retrieves the actions the current user owns, is assigned to or
+// can see through a shared project, or all actions in single-user mode
+func (s *Server) userActions(r *http.Request) ([]database.Action, error) {
+	if user := currentUser(r); user != nil {
+		return database.GetActionsForUser(s.dbPath, user.ID)
+	}
+	return database.GetAllActions(s.dbPath)
+}
+
+// userProjects retrieves the projects owned by or shared with the current
+// user, or all projects in single-user mode
+func (s *Server) userProjects(r *http.Request) ([]database.Project, error) {
+	if user := currentUser(r); user != nil {
+		return database.GetProjectsForUser(s.dbPath, user.ID)
+	}
+	return database.GetAllProjects(s.dbPath)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// BurndownDay is the JSON form of a day of a project burndown
+type BurndownDay struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Open   int    `json:"open"`
+	Closed int    `json:"closed"`
+}
+
+// handleProjectBurndown returns the number of open and closed actions of a
+// project for every day from ?from= to ?to= (YYYY-MM-DD). Without from the
+// burndown starts at the first day the project had actions, without to it
+// ends today.
+func (s *Server) handleProjectBurndown(w http.ResponseWriter, r *http.Request, project *database.Project) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			http.Error(w, fmt.Sprintf("Invalid date format: %s. Expected format: YYYY-MM-DD", date), http.StatusBadRequest)
+			return
+		}
+	}
+	if from != "" && to != "" && from > to {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+
+	days, err := database.GetProjectBurndown(s.dbPath, project.ID, from, to)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error computing burndown: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	converted := make([]BurndownDay, 0, len(days))
+	for _, day := range days {
+		converted = append(converted, BurndownDay(day))
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"project_id": project.ID,
+		"days":       converted,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// handleCapture creates an action from a single line of text in the syntax
+// of `projector add` with POST /api/capture and {"text": "..."}. It is the
+// smallest way to get something into projector, for shortcuts, browser
+// extensions and mail gateways that only know the API token.
+func (s *Server) handleCapture(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var captureRequest struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&captureRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(captureRequest.Text) == "" {
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return
+	}
+
+	// Only the first line counts, mail gateways may send a whole message
+	line, _, _ := strings.Cut(strings.TrimSpace(captureRequest.Text), "\n")
+
+	entry, err := quickadd.Parse(line, time.Now())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid text: %v", err), http.StatusBadRequest)
+		return
+	}
+	resolved, err := entry.Resolve(s.dbPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid text: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !s.checkProjectAccess(w, r, resolved.ProjectID) {
+		return
+	}
+
+	if user := currentUser(r); user != nil {
+		resolved.OwnerID = &user.ID
+	}
+
+	actionIDs, err := database.CreateActions(s.dbPath, []database.NewAction{resolved})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating action: %v", err), http.StatusBadRequest)
+		return
+	}
+	actionID := actionIDs[0]
+
+	s.applyRules(r, nil, actionID)
+
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving created action: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.runActionHooks(hooks.ActionCreated, action)
+
+	if s.dispatcher != nil {
+		go func() {
+			if err := notify.SendActionCreated(s.dbPath, s.dispatcher, actionID); err != nil {
+				logf(r, "Failed to send action notification: %v", err)
+			}
+		}()
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Action captured successfully",
+		"action_id": actionID,
+		"action":    newAction(r, action),
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// capturedPage is the confirmation shown by GET /capture, which closes itself
+// when a bookmarklet opened it in a popup
+var capturedPage = template.Must(template.New("captured").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Captured</title>
+</head>
+<body style="font-family: sans-serif; margin: 2em">
+<p>✅ Captured <strong>{{.Name}}</strong></p>
+{{if .URL}}<p><a href="{{.URL}}">{{.URL}}</a></p>{{end}}
+<script>setTimeout(function () { window.close() }, 1500)</script>
+</body>
+</html>
+`))
+
+// handleCaptureLink creates an action from a page with
+// GET /capture?title=...&url=..., for bookmarklets and share targets, and
+// returns a small HTML confirmation instead of JSON. The title becomes the
+// name of the action and the URL is attached to it. Share targets that only
+// send text, or a URL in the text, are accepted as well.
+func (s *Server) handleCaptureLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	title := strings.TrimSpace(query.Get("title"))
+	text := strings.TrimSpace(query.Get("text"))
+	link := strings.TrimSpace(query.Get("url"))
+	if link == "" && database.ValidateAttachmentURL(text) == nil {
+		link, text = text, ""
+	}
+	if link != "" {
+		if err := database.ValidateAttachmentURL(link); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Text shared next to a title is kept in the note
+	name, note := title, text
+	if name == "" {
+		name, note = text, ""
+	}
+	if name == "" {
+		name = link
+	}
+	if name == "" {
+		http.Error(w, "title or url is required", http.StatusBadRequest)
+		return
+	}
+	name = strings.Join(strings.Fields(name), " ")
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	captured := database.NewAction{Name: name, Note: note}
+	if user := currentUser(r); user != nil {
+		captured.OwnerID = &user.ID
+	}
+
+	actionIDs, err := database.CreateActions(s.dbPath, []database.NewAction{captured})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating action: %v", err), http.StatusBadRequest)
+		return
+	}
+	actionID := actionIDs[0]
+	if link != "" {
+		if _, err := database.AddAttachment(s.dbPath, actionID, link, title); err != nil {
+			http.Error(w, fmt.Sprintf("Error attaching URL: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	s.applyRules(r, nil, actionID)
+	s.runActionHooksByID(hooks.ActionCreated, actionID)
+	if s.dispatcher != nil {
+		go func() {
+			if err := notify.SendActionCreated(s.dbPath, s.dispatcher, actionID); err != nil {
+				logf(r, "Failed to send action notification: %v", err)
+			}
+		}()
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	capturedPage.Execute(w, struct{ Name, URL string }{name, link})
+}
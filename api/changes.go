@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// defaultChangesLimit is the number of events returned when ?limit= is not given
+const defaultChangesLimit = 100
+
+// handleChanges serves the event log of the projects and actions after the
+// cursor given by ?since=. The response carries the cursor to pass as the
+// next since, and has_more while there are more events to read, so that
+// clients can replay every change in order instead of comparing snapshots.
+func (s *Server) handleChanges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	var cursor int64
+	if since := query.Get("since"); since != "" {
+		parsed, err := strconv.ParseInt(since, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "since must be a cursor returned by an earlier request", http.StatusBadRequest)
+			return
+		}
+		cursor = parsed
+	}
+
+	limit := defaultChangesLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPerPage {
+			http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPerPage), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	var ownerID uint
+	if user := currentUser(r); user != nil {
+		ownerID = user.ID
+	}
+
+	// Read one event more than asked for, to tell whether there are more
+	events, err := database.GetChangeEvents(s.dbPath, cursor, limit+1, ownerID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving changes: %v", err), http.StatusInternalServerError)
+		return
+	}
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+	if len(events) > 0 {
+		cursor = events[len(events)-1].Cursor
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"changes":  events,
+		"cursor":   strconv.FormatInt(cursor, 10),
+		"has_more": hasMore,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// cloneRequest is the body of a clone request. Everything is copied unless
+// an include option is false.
+type cloneRequest struct {
+	Name           string `json:"name,omitempty"`
+	IncludeActions *bool  `json:"include_actions,omitempty"`
+	IncludeTags    *bool  `json:"include_tags,omitempty"`
+	IncludeNotes   *bool  `json:"include_notes,omitempty"`
+	Shift          string `json:"shift,omitempty"` // Such as 3d, 2w, 1m or -1d
+}
+
+// decodeCloneRequest reads the optional body of a clone request, writing an
+// error response when it is invalid
+func decodeCloneRequest(w http.ResponseWriter, r *http.Request) (database.CloneOptions, bool) {
+	var cloneRequest cloneRequest
+	if err := json.NewDecoder(r.Body).Decode(&cloneRequest); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return database.CloneOptions{}, false
+	}
+
+	include := func(option *bool) bool {
+		return option == nil || *option
+	}
+	return database.CloneOptions{
+		Name:    cloneRequest.Name,
+		Actions: include(cloneRequest.IncludeActions),
+		Tags:    include(cloneRequest.IncludeTags),
+		Notes:   include(cloneRequest.IncludeNotes),
+		Shift:   cloneRequest.Shift,
+	}, true
+}
+
+// handleActionClone copies an action into the same project
+func (s *Server) handleActionClone(w http.ResponseWriter, r *http.Request, action *database.Action) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	options, ok := decodeCloneRequest(w, r)
+	if !ok {
+		return
+	}
+
+	cloneID, err := database.CloneAction(s.dbPath, action.ID, options)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error cloning action: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if user := currentUser(r); user != nil {
+		if err := database.SetActionOwner(s.dbPath, cloneID, user.ID); err != nil {
+			http.Error(w, fmt.Sprintf("Error setting action owner: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	clone, err := database.GetActionByID(s.dbPath, cloneID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving cloned action: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Action cloned successfully",
+		"action_id": cloneID,
+		"action":    newAction(r, clone),
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleProjectClone copies a project with its actions. The copy belongs to
+// the current user, so any member of the project may clone it.
+func (s *Server) handleProjectClone(w http.ResponseWriter, r *http.Request, project *database.Project) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	options, ok := decodeCloneRequest(w, r)
+	if !ok {
+		return
+	}
+
+	cloneID, err := database.CloneProject(s.dbPath, project.ID, options)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error cloning project: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if user := currentUser(r); user != nil {
+		if err := database.SetProjectOwner(s.dbPath, cloneID, user.ID); err != nil {
+			http.Error(w, fmt.Sprintf("Error setting project owner: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	clone, err := database.GetProjectByID(s.dbPath, cloneID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving cloned project: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"message":    "Project cloned successfully",
+		"project_id": cloneID,
+		"project":    newProject(r, clone),
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
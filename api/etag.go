@@ -0,0 +1,55 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// notModified sets the ETag of a list response and reports whether the
+// client already has it, in which case it responds with 304 Not Modified.
+// The ETag is derived from the change counters of the tables the list is
+// read from, so polling clients do not download an unchanged list again.
+// Lists differ per user, API version and query, which are part of the ETag,
+// and per day, since deferred actions become active at midnight.
+func (s *Server) notModified(w http.ResponseWriter, r *http.Request, tables ...string) bool {
+	count, err := database.GetChangeCount(s.dbPath, tables...)
+	if err != nil {
+		// Databases that have not been migrated yet have no change counters
+		logf(r, "Failed to read change counters, responding without an ETag: %v", err)
+		return false
+	}
+
+	var userID uint
+	if user := currentUser(r); user != nil {
+		userID = user.ID
+	}
+
+	today := time.Now().Format("2006-01-02")
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d\x00%d\x00%t\x00%s\x00%s", count, userID, isV1(r), r.URL.RawQuery, today)))
+	etag := `W/"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if !etagMatches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag, using
+// the weak comparison of RFC 9110
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+)
+
+// handleFilters lists the saved filters and saves filters
+func (s *Server) handleFilters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		filters, err := database.GetAllSavedFilters(s.dbPath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving filters: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"count":   len(filters),
+			"filters": newSavedFilters(r, filters),
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case "PUT":
+		var filterRequest struct {
+			Name  string `json:"name"`
+			Query string `json:"query"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&filterRequest); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if filterRequest.Name == "" || filterRequest.Query == "" {
+			http.Error(w, "Filter name and query are required", http.StatusBadRequest)
+			return
+		}
+		if _, err := filter.Parse(filterRequest.Query, time.Now()); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if err := database.SaveFilter(s.dbPath, filterRequest.Name, filterRequest.Query); err != nil {
+			http.Error(w, fmt.Sprintf("Error saving filter: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"message": "Filter saved successfully",
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleFilterByName deletes a saved filter
+func (s *Server) handleFilterByName(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/filters/"))
+	if err != nil || name == "" {
+		http.Error(w, "Invalid filter name", http.StatusBadRequest)
+		return
+	}
+
+	savedFilter, err := database.GetSavedFilter(s.dbPath, name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving filter: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if savedFilter == nil {
+		http.Error(w, "Filter not found", http.StatusNotFound)
+		return
+	}
+
+	if err := database.DeleteSavedFilter(s.dbPath, name); err != nil {
+		http.Error(w, fmt.Sprintf("Error deleting filter: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Filter deleted successfully",
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// requestFilter parses the ?q= query and the ?saved= filter of a request,
+// combining them when both are given. It returns nil without either, and the
+// status to respond with on errors.
+func (s *Server) requestFilter(r *http.Request) (*filter.Filter, int, error) {
+	var match *filter.Filter
+	if query := r.URL.Query().Get("q"); query != "" {
+		parsed, err := filter.Parse(query, time.Now())
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("Invalid q: %v", err)
+		}
+		match = parsed
+	}
+
+	if name := r.URL.Query().Get("saved"); name != "" {
+		savedFilter, err := database.GetSavedFilter(s.dbPath, name)
+		if err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("Error retrieving filter: %v", err)
+		}
+		if savedFilter == nil {
+			return nil, http.StatusNotFound, fmt.Errorf("Filter not found")
+		}
+		parsed, err := filter.Parse(savedFilter.Query, time.Now())
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("Invalid saved filter: %v", err)
+		}
+		match = filter.And(parsed, match)
+	}
+
+	return match, http.StatusOK, nil
+}
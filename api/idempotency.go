@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder keeps a copy of the status and body written to a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// idempotent makes requests that change data safe to retry: the successful
+// response to a request with an Idempotency-Key header is stored, and a retry
+// with the same key gets that response again without the change being made
+// twice. Clients that queue changes while offline send a key with each one.
+//
+// It runs after authenticate, and keys belong to the authenticated user, so
+// a response is only replayed to the user whose request made it. The key is
+// reserved before the request runs, and a retry that arrives in the meantime
+// gets a conflict rather than making the change a second time.
+func (s *Server) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || readOnlyMethods[r.Method] {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(w, "Idempotency-Key is too long", http.StatusBadRequest)
+			return
+		}
+
+		var userID uint
+		if user := currentUser(r); user != nil {
+			userID = user.ID
+		}
+
+		stored, err := database.ReserveIdempotencyKey(s.dbPath, userID, key, r.Method, r.URL.Path)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error checking idempotency key: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if stored != nil {
+			if stored.Method != r.Method || stored.Path != r.URL.Path {
+				http.Error(w, "Idempotency-Key was already used for another request", http.StatusUnprocessableEntity)
+				return
+			}
+			if stored.Status == 0 {
+				http.Error(w, "A request with this Idempotency-Key is still in progress", http.StatusConflict)
+				return
+			}
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.Status)
+			w.Write([]byte(stored.Body))
+			return
+		}
+
+		// Failed requests changed nothing, so they may run again with the
+		// key, also when the handler panicked
+		recorder := &responseRecorder{ResponseWriter: w}
+		succeeded := false
+		defer func() {
+			if !succeeded {
+				if err := database.ReleaseIdempotencyKey(s.dbPath, userID, key); err != nil {
+					logf(r, "Failed to release idempotency key: %v", err)
+				}
+			}
+		}()
+
+		next(recorder, r)
+
+		if recorder.status < 200 || recorder.status > 299 {
+			return
+		}
+		succeeded = true
+		err = database.SaveIdempotentResponse(s.dbPath, database.IdempotentResponse{
+			UserID:      userID,
+			Key:         key,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Status:      recorder.status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        recorder.body.String(),
+		})
+		if err != nil {
+			logf(r, "Failed to save idempotency key: %v", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/taskwarrior"
+	"github.com/joelgrimberg/projector/todotxt"
+	"github.com/joelgrimberg/projector/wire"
+)
+
+// ImportResult summarizes an import, with the rows that failed and why
+type ImportResult = wire.ImportResult
+
+// ImportProgress is the progress event of a streamed import
+type ImportProgress = wire.ImportProgress
+
+// importer imports a file of one format
+type importer func(dbPath string, r io.Reader, progress database.ImportProgress) (*ImportResult, error)
+
+// importers are the file formats accepted by POST /api/import
+var importers = map[string]importer{
+	"todotxt": func(dbPath string, r io.Reader, progress database.ImportProgress) (*ImportResult, error) {
+		result, err := todotxt.Import(dbPath, r, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportResult{Imported: result.Imported, Failed: result.Failed, Warnings: result.Warnings}, nil
+	},
+	"taskwarrior": func(dbPath string, r io.Reader, progress database.ImportProgress) (*ImportResult, error) {
+		result, err := taskwarrior.Import(dbPath, r, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportResult{Imported: result.Imported, Skipped: result.Skipped, Failed: result.Failed, Warnings: result.Warnings}, nil
+	},
+}
+
+// handleImport imports the todo.txt or Taskwarrior file in the request body,
+// given by ?format=. With Accept: text/event-stream the progress is streamed
+// as server-sent events: progress events with the rows done, then a result
+// or error event. Imported actions and projects have no owner, so importing
+// requires a server without user accounts.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if currentUser(r) != nil {
+		http.Error(w, "Importing requires a server without user accounts", http.StatusForbidden)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	run, ok := importers[format]
+	if !ok {
+		http.Error(w, "format must be todotxt or taskwarrior", http.StatusBadRequest)
+		return
+	}
+
+	// The body is read before streaming, the server closes it once the
+	// response has started
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read the file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "application/json")
+		result, err := run(s.dbPath, bytes.NewReader(body), nil)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Import failed: %v", err), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher := http.NewResponseController(w)
+	flusher.Flush()
+
+	// Only whole percentages are sent, so large imports do not flood the client
+	lastPercent := -1
+	progress := func(done, total int) {
+		percent := done * 100 / total
+		if percent == lastPercent && done != total {
+			return
+		}
+		lastPercent = percent
+		writeEvent(w, "progress", ImportProgress{Done: done, Total: total})
+		flusher.Flush()
+	}
+
+	result, err := run(s.dbPath, bytes.NewReader(body), progress)
+	if err != nil {
+		writeEvent(w, "error", map[string]string{"error": err.Error()})
+	} else {
+		writeEvent(w, "result", result)
+	}
+	flusher.Flush()
+}
+
+// writeEvent writes a server-sent event with a JSON payload
+func writeEvent(w io.Writer, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
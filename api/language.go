@@ -0,0 +1,13 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joelgrimberg/projector/i18n"
+)
+
+// localize translates a response message into the first supported language
+// of the Accept-Language header of the request, English by default
+func localize(r *http.Request, id string, data ...i18n.Data) string {
+	return i18n.New(i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))).T(id, data...)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Connection limits of the HTTP server, so that slow or stalled clients
+// cannot hold on to connections forever
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = time.Minute
+	idleTimeout       = 2 * time.Minute
+	maxHeaderBytes    = 1 << 20
+)
+
+// slowRoutes are the endpoints that may take longer than the request
+// timeout, with the time they are given instead
+var slowRoutes = map[string]time.Duration{
+	"/api/admin/backup": 5 * time.Minute,
+	"/api/sync":         5 * time.Minute,
+	"/api/import":       5 * time.Minute,
+}
+
+// streamingRoutes send their response as they go. http.TimeoutHandler
+// buffers the whole response, so these are only bounded by the write timeout.
+var streamingRoutes = map[string]bool{
+	"/api/import": true,
+}
+
+// timeoutBody is the response to a request that ran out of time
+const timeoutBody = `{"success":false,"error":"Request timed out"}`
+
+// SetRequestTimeout sets how long a request may take before it is answered
+// with 503 Service Unavailable, apart from the slow routes
+func (s *Server) SetRequestTimeout(timeout time.Duration) {
+	s.requestTimeout = timeout
+}
+
+// SetMaxBodySize sets the largest request body the server reads, in bytes
+func (s *Server) SetMaxBodySize(size int64) {
+	s.maxBodySize = size
+}
+
+// httpServer returns the HTTP server for a handler, with its connection
+// limits. The write timeout leaves the slowest route time to respond.
+func (s *Server) httpServer(handler http.Handler) *http.Server {
+	writeTimeout := s.requestTimeout
+	for _, timeout := range slowRoutes {
+		if timeout > writeTimeout {
+			writeTimeout = timeout
+		}
+	}
+
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout + 5*time.Second,
+		IdleTimeout:       idleTimeout,
+		MaxHeaderBytes:    maxHeaderBytes,
+	}
+}
+
+// jsonTimeoutWriter marks the timeout response of http.TimeoutHandler as
+// JSON, leaving the responses of the handlers as they are
+type jsonTimeoutWriter struct {
+	http.ResponseWriter
+}
+
+func (w jsonTimeoutWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w jsonTimeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitRequests bounds the size of request bodies and the time a handler
+// may take, answering 503 Service Unavailable with a JSON error when it runs
+// out of time
+func (s *Server) limitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.maxBodySize > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+		}
+
+		timeout := s.requestTimeout
+		path := r.URL.Path
+		if strings.HasPrefix(path, "/api/v1/") {
+			path = "/api" + strings.TrimPrefix(path, "/api/v1")
+		}
+		if slow, ok := slowRoutes[path]; ok {
+			timeout = slow
+		}
+		if timeout <= 0 || streamingRoutes[path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		http.TimeoutHandler(next, timeout, timeoutBody).ServeHTTP(jsonTimeoutWriter{w}, r)
+	})
+}
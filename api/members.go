@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// handleProjectMembers handles requests for the members of a project. The
+// member path holds the user ID for requests about a single member.
+func (s *Server) handleProjectMembers(w http.ResponseWriter, r *http.Request, project *database.Project, role, memberPath string) {
+	switch {
+	case memberPath == "" && r.Method == "GET":
+		members, err := database.GetProjectMembers(s.dbPath, project.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving project members: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success":    true,
+			"project_id": project.ID,
+			"owner_id":   optionalID(project.OwnerID),
+			"count":      len(members),
+			"members":    newMembers(r, members),
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case memberPath == "" && r.Method == "POST":
+		if !requireRole(w, role, database.RoleOwner) {
+			return
+		}
+
+		// Parse request body, the member is given by username or user ID
+		var memberRequest struct {
+			Username string `json:"username,omitempty"`
+			UserID   uint   `json:"user_id,omitempty"`
+			Role     string `json:"role"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&memberRequest); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if memberRequest.Role == "" {
+			memberRequest.Role = database.RoleViewer
+		}
+
+		if memberRequest.Username != "" {
+			user, err := database.GetUserByUsername(s.dbPath, memberRequest.Username)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error retrieving user: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if user == nil {
+				http.Error(w, "User not found", http.StatusBadRequest)
+				return
+			}
+			memberRequest.UserID = user.ID
+		}
+		if memberRequest.UserID == 0 {
+			http.Error(w, "Username or user ID is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := database.SetProjectMember(s.dbPath, project.ID, memberRequest.UserID, memberRequest.Role); err != nil {
+			http.Error(w, fmt.Sprintf("Error adding project member: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success":    true,
+			"message":    "Project shared successfully",
+			"project_id": project.ID,
+			"user_id":    memberRequest.UserID,
+			"role":       memberRequest.Role,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case memberPath != "" && r.Method == "DELETE":
+		userID, err := strconv.ParseUint(memberPath, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		// Members can leave a project themselves
+		user := currentUser(r)
+		if (user == nil || user.ID != uint(userID)) && !requireRole(w, role, database.RoleOwner) {
+			return
+		}
+
+		removed, err := database.RemoveProjectMember(s.dbPath, project.ID, uint(userID))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error removing project member: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			http.Error(w, "Member not found", http.StatusNotFound)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success":    true,
+			"message":    "Project member removed successfully",
+			"project_id": project.ID,
+			"user_id":    userID,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// handleProjectMerge moves the actions of a project to another project and
+// archives it. With dry_run the response shows what would be moved. Merging
+// requires owning the source project and editing the target project.
+func (s *Server) handleProjectMerge(w http.ResponseWriter, r *http.Request, project *database.Project, role string) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mergeRequest struct {
+		Into   uint `json:"into"`
+		DryRun bool `json:"dry_run,omitempty"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&mergeRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if mergeRequest.Into == 0 {
+		http.Error(w, "Target project (into) is required", http.StatusBadRequest)
+		return
+	}
+
+	if !mergeRequest.DryRun && !requireRole(w, role, database.RoleOwner) {
+		return
+	}
+	if !s.checkProjectAccess(w, r, &mergeRequest.Into) {
+		return
+	}
+
+	result, err := database.MergeProjects(s.dbPath, project.ID, mergeRequest.Into, mergeRequest.DryRun)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error merging projects: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	message := "Projects merged successfully"
+	if mergeRequest.DryRun {
+		message = "Dry run, nothing was changed"
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"message":    message,
+		"dry_run":    mergeRequest.DryRun,
+		"project_id": project.ID,
+		"into":       mergeRequest.Into,
+		"count":      len(result.Actions),
+		"actions":    newActions(r, result.Actions),
+		"tags":       result.Tags,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,373 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/wire"
+)
+
+// Action is the JSON form of an action in API responses
+type Action = wire.Action
+
+// Project is the JSON form of a project in API responses
+type Project = wire.Project
+
+// User is the JSON form of a user account in API responses
+type User = wire.User
+
+// Member is the JSON form of a project member in API responses
+type Member struct {
+	ProjectID uint   `json:"project_id"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Template is the JSON form of a project template in API responses
+type Template struct {
+	ID         uint             `json:"id"`
+	Name       string           `json:"name"`
+	ProjectDue string           `json:"project_due,omitempty"` // Offset such as start+30d
+	CreatedAt  string           `json:"created_at"`
+	Actions    []TemplateAction `json:"actions"`
+}
+
+// TemplateAction is the JSON form of an action of a template
+type TemplateAction struct {
+	Name           string   `json:"name"`
+	Note           string   `json:"note,omitempty"`
+	Due            string   `json:"due,omitempty"` // Offset such as start+3d
+	Tags           []string `json:"tags"`
+	RepeatMode     string   `json:"repeat_mode,omitempty"`
+	RepeatCount    uint     `json:"repeat_count,omitempty"`
+	RepeatInterval string   `json:"repeat_interval,omitempty"`
+	RepeatPattern  string   `json:"repeat_pattern,omitempty"`
+	RepeatUntil    string   `json:"repeat_until,omitempty"` // Offset such as start+90d
+}
+
+// SavedFilter is the JSON form of a saved filter in API responses
+type SavedFilter struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Query     string `json:"query"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Attachment is the JSON form of a link attached to an action
+type Attachment struct {
+	ID        uint    `json:"id"`
+	ActionID  uint    `json:"action_id"`
+	URL       string  `json:"url"`
+	Title     *string `json:"title,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// legacyAction and legacyProject are the shapes served under /api before
+// /api/v1, with the Go field names as keys. They have the same fields as
+// Action and Project, so the v1 models convert to them directly.
+type legacyAction struct {
+	ID             uint
+	ProjectID      *uint `json:",omitempty"`
+	Name           string
+	Note           *string `json:",omitempty"`
+	DueDate        *string `json:",omitempty"`
+	StartDate      *string `json:",omitempty"`
+	StatusID       uint
+	RepeatCount    uint
+	RepeatInterval *string `json:",omitempty"`
+	RepeatPattern  *string `json:",omitempty"`
+	RepeatUntil    *string `json:",omitempty"`
+	ParentActionID *uint   `json:",omitempty"`
+	RepeatMode     *string `json:",omitempty"`
+	OwnerID        *uint   `json:",omitempty"`
+	AssigneeID     *uint   `json:",omitempty"`
+	Flagged        bool
+	Location       *string  `json:",omitempty"`
+	Latitude       *float64 `json:",omitempty"`
+	Longitude      *float64 `json:",omitempty"`
+	Energy         *string  `json:",omitempty"`
+	WaitingOn      *string  `json:",omitempty"`
+	FollowUp       *string  `json:",omitempty"`
+	AssigneeName   *string  `json:",omitempty"`
+	ProjectName    *string  `json:",omitempty"`
+	StatusName     string
+	Tags           []string
+	Reminders      []string
+	Version        uint
+}
+
+type legacyProject struct {
+	ID         uint
+	Name       string
+	DueDate    *string `json:",omitempty"`
+	OwnerID    *uint   `json:",omitempty"`
+	ArchivedAt *string `json:",omitempty"`
+
+	RepeatInterval  *string `json:",omitempty"`
+	ParentProjectID *uint   `json:",omitempty"`
+
+	ReviewInterval *string `json:",omitempty"`
+	LastReviewedAt *string `json:",omitempty"`
+
+	Version uint
+}
+
+// newAction converts an action from the database for a response in the
+// API version of the request
+func newAction(r *http.Request, action *database.Action) interface{} {
+	if action == nil {
+		return nil
+	}
+
+	converted := ToAction(action)
+	if !isV1(r) {
+		return legacyAction(converted)
+	}
+	return converted
+}
+
+// newActions converts a list of actions, an empty list becomes [] rather than null
+func newActions(r *http.Request, actions []database.Action) interface{} {
+	if !isV1(r) {
+		converted := make([]legacyAction, 0, len(actions))
+		for i := range actions {
+			converted = append(converted, legacyAction(ToAction(&actions[i])))
+		}
+		return converted
+	}
+
+	converted := make([]Action, 0, len(actions))
+	for i := range actions {
+		converted = append(converted, ToAction(&actions[i]))
+	}
+	return converted
+}
+
+// ToAction converts an action from the database to the v1 model, the JSON
+// form of an action under /api/v1
+func ToAction(action *database.Action) Action {
+	tags := action.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	reminders := action.Reminders
+	if reminders == nil {
+		reminders = []string{}
+	}
+
+	return Action{
+		ID:             action.ID,
+		ProjectID:      optionalID(action.ProjectID),
+		Name:           action.Name,
+		Note:           optionalString(action.Note),
+		DueDate:        optionalDate(action.DueDate),
+		StartDate:      optionalDate(action.StartDate),
+		StatusID:       action.StatusID,
+		RepeatCount:    action.RepeatCount,
+		RepeatInterval: optionalString(action.RepeatInterval),
+		RepeatPattern:  optionalString(action.RepeatPattern),
+		RepeatUntil:    optionalDate(action.RepeatUntil),
+		ParentActionID: optionalID(action.ParentActionID),
+		RepeatMode:     optionalString(action.RepeatMode),
+		OwnerID:        optionalID(action.OwnerID),
+		AssigneeID:     optionalID(action.AssigneeID),
+		Flagged:        action.Flagged,
+		Location:       optionalString(action.Location),
+		Latitude:       optionalFloat(action.Latitude),
+		Longitude:      optionalFloat(action.Longitude),
+		Energy:         optionalString(action.Energy),
+		WaitingOn:      optionalString(action.WaitingOn),
+		FollowUp:       optionalDate(action.FollowUp),
+		AssigneeName:   optionalString(action.AssigneeName),
+		ProjectName:    optionalString(action.ProjectName),
+		StatusName:     action.StatusName,
+		Tags:           tags,
+		Reminders:      reminders,
+		Version:        action.Version,
+	}
+}
+
+// newProject converts a project from the database for a response in the
+// API version of the request
+func newProject(r *http.Request, project *database.Project) interface{} {
+	if project == nil {
+		return nil
+	}
+
+	converted := ToProject(project)
+	if !isV1(r) {
+		return legacyProject(converted)
+	}
+	return converted
+}
+
+// newProjects converts a list of projects, an empty list becomes [] rather than null
+func newProjects(r *http.Request, projects []database.Project) interface{} {
+	if !isV1(r) {
+		converted := make([]legacyProject, 0, len(projects))
+		for i := range projects {
+			converted = append(converted, legacyProject(ToProject(&projects[i])))
+		}
+		return converted
+	}
+
+	converted := make([]Project, 0, len(projects))
+	for i := range projects {
+		converted = append(converted, ToProject(&projects[i]))
+	}
+	return converted
+}
+
+// ToProject converts a project from the database to the v1 model
+func ToProject(project *database.Project) Project {
+	return Project{
+		ID:         project.ID,
+		Name:       project.Name,
+		DueDate:    optionalDate(project.DueDate),
+		OwnerID:    optionalID(project.OwnerID),
+		ArchivedAt: optionalString(project.ArchivedAt),
+
+		RepeatInterval:  optionalString(project.RepeatInterval),
+		ParentProjectID: optionalID(project.ParentProjectID),
+
+		ReviewInterval: optionalString(project.ReviewInterval),
+		LastReviewedAt: optionalString(project.LastReviewedAt),
+
+		Version: project.Version,
+	}
+}
+
+// newUser converts a user for a response. The legacy API returns the
+// database model as is.
+func newUser(r *http.Request, user *database.User) interface{} {
+	if !isV1(r) {
+		return user
+	}
+	return User(*user)
+}
+
+// newUsers converts a list of users for a response
+func newUsers(r *http.Request, users []database.User) interface{} {
+	if !isV1(r) {
+		return users
+	}
+
+	converted := make([]User, 0, len(users))
+	for _, user := range users {
+		converted = append(converted, User(user))
+	}
+	return converted
+}
+
+// newMembers converts a list of project members for a response. The legacy
+// API returns the database models as is.
+func newMembers(r *http.Request, members []database.ProjectMember) interface{} {
+	if !isV1(r) {
+		return members
+	}
+
+	converted := make([]Member, 0, len(members))
+	for _, member := range members {
+		converted = append(converted, Member(member))
+	}
+	return converted
+}
+
+// newTemplates converts a list of templates for a response. The legacy API
+// returns the database models as is.
+func newTemplates(r *http.Request, templates []database.Template) interface{} {
+	if !isV1(r) {
+		return templates
+	}
+
+	converted := make([]Template, 0, len(templates))
+	for _, template := range templates {
+		actions := make([]TemplateAction, 0, len(template.Actions))
+		for _, action := range template.Actions {
+			if action.Tags == nil {
+				action.Tags = []string{}
+			}
+			actions = append(actions, TemplateAction(action))
+		}
+
+		converted = append(converted, Template{
+			ID:         template.ID,
+			Name:       template.Name,
+			ProjectDue: template.ProjectDue,
+			CreatedAt:  template.CreatedAt,
+			Actions:    actions,
+		})
+	}
+	return converted
+}
+
+// newSavedFilters converts a list of saved filters for a response. The legacy
+// API returns the database models as is.
+func newSavedFilters(r *http.Request, filters []database.SavedFilter) interface{} {
+	if !isV1(r) {
+		return filters
+	}
+
+	converted := make([]SavedFilter, 0, len(filters))
+	for _, savedFilter := range filters {
+		converted = append(converted, SavedFilter(savedFilter))
+	}
+	return converted
+}
+
+// newAttachments converts the attachments of an action for a response. The
+// legacy API returns the database models as is.
+func newAttachments(r *http.Request, attachments []database.Attachment) interface{} {
+	if !isV1(r) {
+		return attachments
+	}
+
+	converted := make([]Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		converted = append(converted, Attachment{
+			ID:        attachment.ID,
+			ActionID:  attachment.ActionID,
+			URL:       attachment.URL,
+			Title:     optionalString(attachment.Title),
+			CreatedAt: attachment.CreatedAt,
+		})
+	}
+	return converted
+}
+
+// optionalString returns nil for NULL and empty strings
+func optionalString(value sql.NullString) *string {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	return &value.String
+}
+
+// optionalDate returns the date part of a stored date, or nil when there is none
+func optionalDate(value sql.NullString) *string {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	date := database.StoredDate(value.String)
+	return &date
+}
+
+// optionalFloat returns nil for a NULL number
+func optionalFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// optionalID returns nil for a NULL ID
+func optionalID(value sql.NullInt64) *uint {
+	if !value.Valid {
+		return nil
+	}
+	id := uint(value.Int64)
+	return &id
+}
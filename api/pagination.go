@@ -0,0 +1,86 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPerPage is the page size when only ?page= is given
+	defaultPerPage = 50
+	// maxPerPage is the largest page size a client can ask for
+	maxPerPage = 500
+)
+
+// paginate returns the page of a list asked for with ?page= and ?per_page=,
+// or the whole list when neither is given. It sets X-Total-Count to the
+// length of the whole list and, for a page, a Link header (RFC 8288) with
+// the first, prev, next and last pages, so generic REST clients can page
+// through a list without reading the response body. It returns false after
+// responding with 400 Bad Request to invalid parameters.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, bool) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(items)))
+
+	query := r.URL.Query()
+	if query.Get("page") == "" && query.Get("per_page") == "" {
+		return items, true
+	}
+
+	page, perPage := 1, defaultPerPage
+	if value := query.Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "page must be a positive number", http.StatusBadRequest)
+			return nil, false
+		}
+		page = parsed
+	}
+	if value := query.Get("per_page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxPerPage {
+			http.Error(w, fmt.Sprintf("per_page must be between 1 and %d", maxPerPage), http.StatusBadRequest)
+			return nil, false
+		}
+		perPage = parsed
+	}
+
+	lastPage := max(1, (len(items)+perPage-1)/perPage)
+	links := []string{pageLink(r, 1, perPage, "first")}
+	if page > 1 {
+		links = append(links, pageLink(r, min(page-1, lastPage), perPage, "prev"))
+	}
+	if page < lastPage {
+		links = append(links, pageLink(r, page+1, perPage, "next"))
+	}
+	links = append(links, pageLink(r, lastPage, perPage, "last"))
+	w.Header().Set("Link", strings.Join(links, ", "))
+
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	return items[start:end], true
+}
+
+// pageLink returns a Link header entry for a page of the requested list,
+// keeping the other query parameters. The path is the one the client used,
+// which differs from r.URL.Path for /api/v1 requests.
+func pageLink(r *http.Request, page, perPage int, rel string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	path := r.URL.Path
+	if requestURL, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		path = requestURL.Path
+	}
+
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+
+	link := url.URL{Scheme: scheme, Host: r.Host, Path: path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, link.String(), rel)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+)
+
+// readOnlyMethods are the HTTP methods a read-only server serves
+var readOnlyMethods = map[string]bool{"GET": true, "HEAD": true, "OPTIONS": true}
+
+// mutatingGETs are the paths that change data on GET requests, for clients
+// such as bookmarklets that can only follow links
+var mutatingGETs = map[string]bool{"/capture": true}
+
+// SetReadOnly makes the server reject every request that could change data,
+// for instances exposed publicly such as status dashboards
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// rejectWrites responds with 403 Forbidden to the requests that could change
+// data when the server is read-only, before they reach any handler
+func (s *Server) rejectWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.readOnly && (!readOnlyMethods[r.Method] || mutatingGETs[r.URL.Path]) {
+			http.Error(w, "Server is read-only", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"time"
+)
+
+// SetCrashDir sets the directory a crash report is written to when a request
+// panics. No reports are written when it is empty.
+func (s *Server) SetCrashDir(dir string) {
+	s.crashDir = dir
+}
+
+// statusWriter records the status of a response, zero until it is started
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, to flush
+// streamed responses
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// recoverPanics turns a panic in a handler into a 500 response and a logged
+// stack trace, so that one bad request does not take down the server
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &statusWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// The server uses ErrAbortHandler to abort a response on purpose
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			logf(r, "Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, recovered, stack)
+			if s.crashDir != "" {
+				if path, err := writeCrashReport(s.crashDir, r, recovered, stack); err != nil {
+					logf(r, "Failed to write crash report: %v", err)
+				} else {
+					logf(r, "Crash report written to %s", path)
+				}
+			}
+
+			// A partly written response cannot be replaced by an error
+			if writer.status != 0 {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":    false,
+				"error":      "Internal server error",
+				"request_id": requestID(r),
+			})
+		}()
+
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// writeCrashReport writes the request, the panic value and the stack trace to
+// a new file in dir and returns its path. The file is only readable by the
+// user running the server.
+func writeCrashReport(dir string, r *http.Request, recovered interface{}, stack []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory: %v", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s-%09d.txt", now.Format("20060102-150405"), now.Nanosecond()))
+	report := fmt.Sprintf("Time:       %s\nRequest:    %s %s\nRequest ID: %s\nPanic:      %v\n\n%s",
+		now.Format(time.RFC3339), r.Method, redactedURI(r), requestID(r), recovered, stack)
+	if err := os.WriteFile(path, []byte(report), 0600); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %v", err)
+	}
+	return path, nil
+}
+
+// redactedURI returns the path and query of a request with the API token a
+// client may pass as the token query parameter left out, see requestToken
+func redactedURI(r *http.Request) string {
+	query := r.URL.Query()
+	if !query.Has("token") {
+		return r.URL.RequestURI()
+	}
+	query.Set("token", "REDACTED")
+	return r.URL.Path + "?" + query.Encode()
+}
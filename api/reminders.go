@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/notify"
+)
+
+// Reminder is the JSON form of a scheduled reminder in API responses. The
+// reminders have no legacy shape, they are the same under /api and /api/v1.
+type Reminder struct {
+	ActionID   uint   `json:"action_id"`
+	ActionName string `json:"action_name"`
+	DueDate    string `json:"due_date"`
+	Offset     string `json:"offset,omitempty"` // Reminder of the action such as -1d, left out on the due date
+	Channel    string `json:"channel"`
+	FireAt     string `json:"fire_at"`
+}
+
+// handleReminders lists the reminders the server will send in the coming
+// days for the actions the user can access
+func (s *Server) handleReminders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var scheduled []notify.ScheduledReminder
+	if s.dispatcher != nil {
+		var err error
+		scheduled, err = notify.UpcomingReminders(s.dbPath, s.dispatcher, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving reminders: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	actions, err := s.userActions(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving actions: %v", err), http.StatusInternalServerError)
+		return
+	}
+	accessible := make(map[uint]bool, len(actions))
+	for _, action := range actions {
+		accessible[action.ID] = true
+	}
+
+	reminders := []Reminder{}
+	for _, reminder := range scheduled {
+		if !accessible[reminder.Action.ID] {
+			continue
+		}
+		reminders = append(reminders, Reminder{
+			ActionID:   reminder.Action.ID,
+			ActionName: reminder.Action.Name,
+			DueDate:    database.StoredDate(reminder.Action.DueDate.String),
+			Offset:     reminder.Offset,
+			Channel:    reminder.Channel,
+			FireAt:     reminder.FireAt.Format(time.RFC3339),
+		})
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"count":     len(reminders),
+		"reminders": reminders,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleReminderByAction cancels the upcoming reminder of an action with
+// DELETE /api/reminders/:action_id, on every channel or only on ?channel=
+func (s *Server) handleReminderByAction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actionID, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/api/reminders/"), 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid action ID", http.StatusBadRequest)
+		return
+	}
+
+	action, role, err := s.accessibleAction(r, uint(actionID))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving action: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if action == nil {
+		http.Error(w, "Action not found", http.StatusNotFound)
+		return
+	}
+	if !requireRole(w, role, database.RoleEditor) {
+		return
+	}
+
+	cancelled := 0
+	if s.dispatcher != nil {
+		cancelled, err = notify.CancelReminder(s.dbPath, s.dispatcher, action.ID, r.URL.Query().Get("channel"), time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error cancelling reminder: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if cancelled == 0 {
+		http.Error(w, "No upcoming reminder for this action", http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   "Reminder cancelled successfully",
+		"action_id": action.ID,
+		"cancelled": cancelled,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
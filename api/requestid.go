@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// requestIDHeader carries the ID of a request, from the client or generated
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// maxLoggedErrorLength bounds the part of a server error response that is
+// logged
+const maxLoggedErrorLength = 1024
+
+// requestIDContextKey holds the ID of the request in the request context
+const requestIDContextKey contextKey = "request_id"
+
+// withRequestID gives every request an ID: the X-Request-ID header sent by
+// the client, such as a proxy, or a new random one. The ID is sent back in
+// the response and prefixes the log messages about the request, so that a
+// failure a client reports can be found in the log.
+//
+// The database functions take no context, so the ID does not reach them.
+// The handlers put the error of a failed call in the response instead, and
+// a server error is logged here with that text and the ID. Plain text error
+// responses, written by http.Error, end with the ID too.
+func (s *Server) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDContextKey, id))
+
+		writer := &serverErrorWriter{ResponseWriter: w}
+		next.ServeHTTP(writer, r)
+		if writer.status < http.StatusInternalServerError {
+			return
+		}
+
+		message := strings.TrimSpace(writer.body.String())
+		if len(message) > maxLoggedErrorLength {
+			message = message[:maxLoggedErrorLength] + "..."
+		}
+		logf(r, "%s %s responded with %d: %s", r.Method, r.URL.Path, writer.status, message)
+		if writer.held {
+			w.WriteHeader(writer.status)
+			fmt.Fprintf(w, "%s\nRequest ID: %s\n", strings.TrimRight(writer.body.String(), "\n"), id)
+		}
+	})
+}
+
+// serverErrorWriter keeps a copy of the body of a server error response, to
+// log it. The body of a plain text server error is held back, so that the
+// request ID can be added to it.
+type serverErrorWriter struct {
+	http.ResponseWriter
+	status int
+	held   bool
+	body   bytes.Buffer
+}
+
+func (w *serverErrorWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if status >= http.StatusInternalServerError && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.held = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *serverErrorWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.held || (w.status >= http.StatusInternalServerError && w.body.Len() < maxLoggedErrorLength) {
+		w.body.Write(data)
+	}
+	if w.held {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, to flush
+// streamed responses
+func (w *serverErrorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// validRequestID reports whether a client request ID is short and printable,
+// so that it cannot break up log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random request ID
+func newRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// requestID returns the ID of a request, empty outside withRequestID
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDContextKey).(string)
+	return id
+}
+
+// logf logs a message about a request, prefixed with the request ID
+func logf(r *http.Request, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if id := requestID(r); id != "" {
+		message = fmt.Sprintf("[%s] %s", id, message)
+	}
+	log.Print(message)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// handleReschedule moves the open actions that are overdue, match a query,
+// or both, to a new due date with POST /api/actions/reschedule and
+// {"overdue": true, "query": "...", "to": "tomorrow"}. With "dry_run": true
+// it only returns the actions that would move. The response lists the
+// actions as they were before they moved; actions the user can only view are
+// left out.
+func (s *Server) handleReschedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var rescheduleRequest struct {
+		Overdue bool   `json:"overdue"`
+		Query   string `json:"query"`
+		To      string `json:"to"`
+		DryRun  bool   `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&rescheduleRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !rescheduleRequest.Overdue && rescheduleRequest.Query == "" {
+		http.Error(w, "overdue or query is required", http.StatusBadRequest)
+		return
+	}
+	if rescheduleRequest.To == "" {
+		rescheduleRequest.To = "tomorrow"
+	}
+
+	now := time.Now()
+	dueDate, err := quickadd.ParseRelativeDate(rescheduleRequest.To, now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var match *filter.Filter
+	if rescheduleRequest.Query != "" {
+		if match, err = filter.Parse(rescheduleRequest.Query, now); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid query: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	actions, err := s.userActions(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving actions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	today := now.Format("2006-01-02")
+	selected := []database.Action{}
+	var ids []uint
+	for i := range actions {
+		action := &actions[i]
+		if action.StatusName == "done" {
+			continue
+		}
+		if rescheduleRequest.Overdue {
+			dueDate := database.StoredDate(action.DueDate.String)
+			if dueDate == "" || dueDate >= today {
+				continue
+			}
+		}
+		if match != nil && !match.Match(action) {
+			continue
+		}
+		role, err := s.actionRole(r, action)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error checking access: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !database.HasRole(role, database.RoleEditor) {
+			continue
+		}
+		ids = append(ids, action.ID)
+		selected = append(selected, *action)
+	}
+
+	message := fmt.Sprintf("Would reschedule %d action(s)", len(ids))
+	if !rescheduleRequest.DryRun && len(ids) > 0 {
+		if err := database.RescheduleActions(s.dbPath, ids, dueDate); err != nil {
+			http.Error(w, fmt.Sprintf("Error rescheduling actions: %v", err), http.StatusBadRequest)
+			return
+		}
+		message = fmt.Sprintf("Rescheduled %d action(s)", len(ids))
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"message":  message,
+		"due_date": dueDate,
+		"dry_run":  rescheduleRequest.DryRun,
+		"count":    len(ids),
+		"actions":  newActions(r, selected),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// handleProjectReview marks a project reviewed with
+// POST /api/projects/:id/review, which starts its next review interval
+func (s *Server) handleProjectReview(w http.ResponseWriter, r *http.Request, project *database.Project, role string) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requireRole(w, role, database.RoleEditor) {
+		return
+	}
+
+	if err := database.MarkProjectReviewed(s.dbPath, project.ID); err != nil {
+		http.Error(w, fmt.Sprintf("Error marking project reviewed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	reviewed, err := database.GetProjectByID(s.dbPath, project.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving updated project: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":     true,
+		"message":     "Project reviewed successfully",
+		"project":     newProject(r, reviewed),
+		"next_review": reviewed.NextReview(),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,17 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/rules"
+)
+
+// applyRules runs the action rules on an action that was just created, or
+// updated from previous. A failing rule is logged and does not fail the
+// request, the action itself was stored.
+func (s *Server) applyRules(r *http.Request, previous *database.Action, actionID uint) {
+	if _, err := rules.Apply(s.dbPath, previous, actionID); err != nil {
+		logf(r, "Failed to apply rules to action %d: %v", actionID, err)
+	}
+}
@@ -1,83 +1,453 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/joelgrimberg/projector/config"
 	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/export"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/quickadd"
+	"github.com/joelgrimberg/projector/web"
 )
 
 // Server represents the HTTP API server
 type Server struct {
-	port   int
-	dbPath string
+	port       int
+	dbPath     string
+	dispatcher *notify.Dispatcher
+	listener   net.Listener
+	ready      atomic.Bool
+	readOnly   bool
+	crashDir   string
+
+	trashRetention int
+	requestTimeout time.Duration
+	maxBodySize    int64
+
+	mux *http.ServeMux
 }
 
 // NewServer creates a new API server
 func NewServer(port int, dbPath string) *Server {
-	return &Server{
-		port:   port,
-		dbPath: dbPath,
+	s := &Server{
+		port:           port,
+		dbPath:         dbPath,
+		trashRetention: config.DefaultTrashRetentionDays,
+		requestTimeout: config.DefaultRequestTimeoutSeconds * time.Second,
+		maxBodySize:    config.DefaultMaxBodyMB << 20,
 	}
+	s.mux = s.routes()
+	return s
 }
 
-// Start starts the HTTP server
-func (s *Server) Start() error {
-	// Set up routes
-	http.HandleFunc("/api/actions", s.handleActions)
-	http.HandleFunc("/api/projects", s.handleProjects)
-	http.HandleFunc("/api/actions/", s.handleActionByID)
-	http.HandleFunc("/api/projects/", s.handleProjectByID)
+// routes returns the mux of the server with all endpoints registered. Every
+// server has its own, so that several can run in one process.
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/v1/", s.handleV1)
+	mux.HandleFunc("/api/actions", s.authenticate(s.handleActions))
+	mux.HandleFunc("/api/projects", s.authenticate(s.handleProjects))
+	mux.HandleFunc("/api/actions/", s.authenticate(s.handleActionByID))
+	mux.HandleFunc("/api/actions/reschedule", s.authenticate(s.handleReschedule))
+	mux.HandleFunc("/api/projects/", s.authenticate(s.handleProjectByID))
+	mux.HandleFunc("/api/projects/from-template", s.authenticate(s.handleProjectFromTemplate))
+	mux.HandleFunc("/api/templates", s.authenticate(s.handleTemplates))
+	mux.HandleFunc("/api/filters", s.authenticate(s.handleFilters))
+	mux.HandleFunc("/api/filters/", s.authenticate(s.handleFilterByName))
+	mux.HandleFunc("/api/tags", s.authenticate(s.handleTags))
+	mux.HandleFunc("/api/tags/", s.authenticate(s.handleTagByName))
+	mux.HandleFunc("/api/statuses", s.authenticate(s.handleStatuses))
+	mux.HandleFunc("/api/statuses/", s.authenticate(s.handleStatusByID))
+	mux.HandleFunc("/api/trash", s.authenticate(s.handleTrash))
+	mux.HandleFunc("/api/reminders", s.authenticate(s.handleReminders))
+	mux.HandleFunc("/api/reminders/", s.authenticate(s.handleReminderByAction))
+	mux.HandleFunc("/api/trash/", s.authenticate(s.handleTrashByID))
+	mux.HandleFunc("/api/capture", s.authenticate(s.handleCapture))
+	mux.HandleFunc("/api/import", s.authenticate(s.handleImport))
+	mux.HandleFunc("/capture", s.authenticate(s.handleCaptureLink))
+
+	// Authentication endpoints
+	mux.HandleFunc("/api/login", s.handleLogin)
+	mux.HandleFunc("/api/logout", s.handleLogout)
+
+	// Admin endpoints
+	mux.HandleFunc("/api/admin/users", s.authenticate(s.requireAdmin(s.handleAdminUsers)))
+	mux.HandleFunc("/api/admin/users/", s.authenticate(s.requireAdmin(s.handleAdminUserByID)))
+	mux.HandleFunc("/api/admin/stats", s.authenticate(s.requireAdmin(s.handleAdminStats)))
+	mux.HandleFunc("/api/admin/backup", s.authenticate(s.requireAdmin(s.handleAdminBackup)))
+
+	// Remote sync endpoint
+	mux.HandleFunc("/api/sync", s.authenticate(s.handleSync))
+	mux.HandleFunc("/api/changes", s.authenticate(s.handleChanges))
+
+	// Calendar feed endpoint
+	mux.HandleFunc("/calendar.ics", s.authenticate(s.handleCalendar))
 
 	// Health check endpoint
-	http.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/health", s.handleHealth)
+
+	// Liveness and readiness probes, for example for Kubernetes
+	mux.HandleFunc("/healthz", s.handleLiveness)
+	mux.HandleFunc("/readyz", s.handleReadiness)
+
+	// Built-in web interface, it authenticates through the API itself
+	mux.Handle("/", web.Handler())
+
+	return mux
+}
+
+// Handler returns the handler serving the API and the web interface, for
+// embedding the server in another Go program instead of calling Start
+func (s *Server) Handler() http.Handler {
+	return s.withRequestID(s.recoverPanics(s.limitRequests(s.rejectWrites(s.mux))))
+}
+
+// SetDispatcher sets the dispatcher used to send notifications about API changes
+func (s *Server) SetDispatcher(dispatcher *notify.Dispatcher) {
+	s.dispatcher = dispatcher
+}
+
+// SetReady marks whether the database migrations have been applied, which
+// /readyz requires before it reports the server as ready
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Listen binds the server port ahead of Start, so that a failure to bind is
+// reported before the server runs in the background
+func (s *Server) Listen() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %v", s.port, err)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Start starts the HTTP server, listening first when Listen was not called
+func (s *Server) Start() error {
+	if s.listener == nil {
+		if err := s.Listen(); err != nil {
+			return err
+		}
+	}
 
-	addr := fmt.Sprintf(":%d", s.port)
 	fmt.Printf("🚀 API server starting on port %d...\n", s.port)
-	fmt.Printf("📡 Endpoints available:\n")
-	fmt.Printf("   GET    /api/actions      - List all actions\n")
+	fmt.Printf("📡 Endpoints available (also under /api/v1 with snake_case fields):\n")
+	fmt.Printf("   GET    /api/actions      - List all actions (?q= query, ?saved= filter, ?today=true, ?page= and ?per_page=, 304 for an unchanged If-None-Match)\n")
 	fmt.Printf("   PUT    /api/actions      - Create new action\n")
+	fmt.Printf("   POST   /api/capture      - Create an action from {\"text\": \"...\"} in quick-add syntax\n")
+	fmt.Printf("   GET    /capture          - Create an action from ?title=...&url=... for bookmarklets\n")
 	fmt.Printf("   GET    /api/actions/:id  - Get action by ID\n")
-	fmt.Printf("   PUT    /api/actions/:id  - Mark action as done\n")
-	fmt.Printf("   DELETE /api/actions/:id  - Delete action\n")
-	fmt.Printf("   GET    /api/projects   - List all projects\n")
+	fmt.Printf("   PUT    /api/actions/:id  - Mark action as done or detach it from its series\n")
+	fmt.Printf("   PATCH  /api/actions/:id  - Update action (?scope=series for future occurrences)\n")
+	fmt.Printf("   POST   /api/actions/:id/clone - Copy an action, optionally shifting its due date\n")
+	fmt.Printf("   POST   /api/actions/reschedule - Move overdue actions or those matching a query to a new due date\n")
+	fmt.Printf("   POST   /api/actions/:id/skip - Skip the occurrence of a repeating action without completing it\n")
+	fmt.Printf("   DELETE /api/actions/:id  - Move action to the trash\n")
+	fmt.Printf("   GET    /api/projects   - List all projects (?include_archived=true for archived ones, ?page= and ?per_page=)\n")
 	fmt.Printf("   PUT    /api/projects   - Create new project\n")
 	fmt.Printf("   GET    /api/projects/:id - Get project by ID\n")
-	fmt.Printf("   DELETE /api/projects/:id - Delete project\n")
-	fmt.Printf("   GET    /health         - Health check\n")
+	fmt.Printf("   PATCH  /api/projects/:id - Set the repeat and review interval of a project\n")
+	fmt.Printf("   DELETE /api/projects/:id - Move project to the trash\n")
+	fmt.Printf("   GET    /api/projects/:id/members - List the users a project is shared with\n")
+	fmt.Printf("   POST   /api/projects/:id/members - Share a project or change a member role\n")
+	fmt.Printf("   DELETE /api/projects/:id/members/:user_id - Remove a project member\n")
+	fmt.Printf("   POST   /api/projects/:id/clone - Copy a project with its actions\n")
+	fmt.Printf("   POST   /api/projects/:id/merge - Move the actions to another project and archive it\n")
+	fmt.Printf("   GET    /api/projects/:id/burndown - Daily open and closed action counts (?from=, ?to=)\n")
+	fmt.Printf("   POST   /api/projects/:id/review - Mark a project reviewed\n")
+	fmt.Printf("   POST   /api/projects/from-template - Create a project from a template\n")
+	fmt.Printf("   GET    /api/templates  - List project templates\n")
+	fmt.Printf("   GET    /api/filters    - List saved filters\n")
+	fmt.Printf("   PUT    /api/filters    - Save a filter query under a name\n")
+	fmt.Printf("   DELETE /api/filters/:name - Delete a saved filter\n")
+	fmt.Printf("   GET    /api/tags       - List tags (?include=counts for open and done action counts)\n")
+	fmt.Printf("   DELETE /api/tags       - Delete the tags no action carries (admin, ?orphaned=true)\n")
+	fmt.Printf("   POST   /api/tags/:name/rename - Rename a tag on all its actions (admin)\n")
+	fmt.Printf("   POST   /api/tags/:name/merge - Move a tag to another tag and delete it (admin)\n")
+	fmt.Printf("   GET    /api/statuses   - List statuses with their color and icon\n")
+	fmt.Printf("   PATCH  /api/statuses/:id - Set the color and icon of a status (admin)\n")
+	fmt.Printf("   GET    /api/trash      - List deleted actions and projects\n")
+	fmt.Printf("   DELETE /api/trash      - Empty the trash (admin, ?expired=true for expired items only)\n")
+	fmt.Printf("   POST   /api/trash/:id/restore - Restore a deleted action or project\n")
+	fmt.Printf("   POST   /api/import     - Import a todo.txt or Taskwarrior file (?format=, progress as server-sent events)\n")
+	fmt.Printf("   GET    /api/reminders  - Reminders scheduled for the coming week\n")
+	fmt.Printf("   DELETE /api/reminders/:action_id - Cancel the upcoming reminder of an action (?channel=)\n")
+	fmt.Printf("   POST   /api/login      - Get an API token for a username and password\n")
+	fmt.Printf("   POST   /api/logout     - Revoke the API token\n")
+	fmt.Printf("   GET    /api/admin/users - List user accounts (admin)\n")
+	fmt.Printf("   PATCH  /api/admin/users/:id - Disable, enable or promote a user (admin)\n")
+	fmt.Printf("   GET    /api/admin/stats - Database statistics (admin)\n")
+	fmt.Printf("   POST   /api/admin/backup - Back up the database on the server (admin)\n")
+	fmt.Printf("   GET    /api/sync       - Changes since ?since= for remote sync\n")
+	fmt.Printf("   POST   /api/sync       - Apply changes from another instance\n")
+	fmt.Printf("   GET    /api/changes    - Event log of the changes after ?since= cursor (?limit=)\n")
+	fmt.Printf("   GET    /calendar.ics   - iCalendar feed of due actions and project deadlines\n")
+	fmt.Printf("   GET    /health         - Health check, 503 when the database is unavailable\n")
+	fmt.Printf("   GET    /healthz        - Liveness probe\n")
+	fmt.Printf("   GET    /readyz         - Readiness probe, 503 until migrations are applied\n")
+	fmt.Printf("   GET    /               - Web interface\n")
+	if s.readOnly {
+		fmt.Printf("🔒 Read-only: only GET requests are served\n")
+	}
 	fmt.Printf("   Press 'q' to quit\n\n")
 
-	return http.ListenAndServe(addr, nil)
+	return s.httpServer(s.Handler()).Serve(s.listener)
 }
 
-// handleHealth handles health check requests
+// handleHealth handles health check requests. It responds with 503 when the
+// database cannot be queried, so that monitoring notices broken storage.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "healthy",
-		"message": "Projector API is running",
+
+	health, err := database.CheckHealth(s.dbPath)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "unhealthy",
+			"message": localize(r, "APIDatabaseUnavailable"),
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "healthy",
+		"message":  localize(r, "APIRunning"),
+		"database": health,
 	})
 }
 
+// handleLiveness reports that the server process is responding, without
+// touching the database, so that a slow migration or a locked database does
+// not get the server restarted
+func (s *Server) handleLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "alive"})
+}
+
+// handleReadiness reports whether the server can handle requests: the startup
+// migrations have been applied and the database can be queried
+func (s *Server) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	notReady := func(reason string) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status": "not ready",
+			"reason": reason,
+		})
+	}
+
+	if !s.ready.Load() {
+		notReady("database migrations have not been applied")
+		return
+	}
+
+	health, err := database.CheckHealth(s.dbPath)
+	if err != nil {
+		notReady(err.Error())
+		return
+	}
+	if !health.SchemaUpToDate {
+		notReady(fmt.Sprintf("schema version %d, expected %d", health.SchemaVersion, health.ExpectedSchemaVersion))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"status": "ready"})
+}
+
+// handleCalendar serves an iCalendar feed of due actions and project deadlines
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actions, err := s.userActions(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving actions: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	projects, err := s.userProjects(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving projects: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// The feed is built before it is sent, so a failure can still be reported
+	var calendar bytes.Buffer
+	if err := export.WriteICal(&calendar, actions, projects); err != nil {
+		http.Error(w, fmt.Sprintf("Error writing calendar: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="projector.ics"`)
+	w.Write(calendar.Bytes())
+}
+
+// handleSync exchanges changes with another projector instance
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var ownerID uint
+	if user := currentUser(r); user != nil {
+		ownerID = user.ID
+	}
+
+	switch r.Method {
+	case "GET":
+		changes, err := database.GetChangesSince(s.dbPath, r.URL.Query().Get("since"), ownerID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving changes: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"changes": changes,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case "POST":
+		var changes database.Changes
+		if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		result, err := database.ApplyChanges(s.dbPath, &changes, ownerID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error applying changes: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"result":  result,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // handleActions handles action-related requests
 func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch r.Method {
 	case "GET":
-		actions, err := database.GetAllActions(s.dbPath)
+		// Actions include the project, assignee, status and tag names, ?saved= reads a filter
+		if s.notModified(w, r, "action", "action_tag", "tag", "status", "project", "user", "project_member", "saved_filter") {
+			return
+		}
+
+		actions, err := s.userActions(r)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error retrieving actions: %v", err), http.StatusInternalServerError)
 			return
 		}
 
+		// ?assignee=me or ?assignee=<username> limits the list to assigned actions
+		if assignee := r.URL.Query().Get("assignee"); assignee != "" {
+			if assignee == "me" {
+				user := currentUser(r)
+				if user == nil {
+					http.Error(w, "assignee=me requires a login", http.StatusBadRequest)
+					return
+				}
+				assignee = user.Username
+			}
+			actions = assignedTo(actions, assignee)
+		}
+
+		// ?today=true limits the list to flagged, due and overdue open actions
+		if r.URL.Query().Get("today") == "true" {
+			actions = todayActions(actions, time.Now().Format("2006-01-02"))
+		}
+
+		// ?near= limits the list to the actions at a location name or
+		// latitude,longitude, within ?radius= kilometers
+		if near := r.URL.Query().Get("near"); near != "" {
+			radius := database.DefaultNearRadius
+			if value := r.URL.Query().Get("radius"); value != "" {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err != nil || parsed <= 0 {
+					http.Error(w, "radius must be a positive number of kilometers", http.StatusBadRequest)
+					return
+				}
+				radius = parsed
+			}
+			actions = nearActions(actions, database.ResolvePlace(actions, near), radius)
+		}
+
+		// ?energy= limits the list to the actions that take at most that
+		// energy level
+		if energy := r.URL.Query().Get("energy"); energy != "" {
+			if _, err := database.ValidateEnergy(energy); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			actions = lowEnergyActions(actions, energy)
+		}
+
+		// ?waiting=true limits the list to the open actions waiting on
+		// someone, and ?waiting=due to those whose follow-up date has arrived
+		switch waiting := r.URL.Query().Get("waiting"); waiting {
+		case "":
+		case "true", "due":
+			actions = waitingActions(actions, waiting == "due")
+		default:
+			http.Error(w, "waiting must be true or due", http.StatusBadRequest)
+			return
+		}
+
+		// ?q= and ?saved= filter the list with a query, see the filter package
+		match, status, err := s.requestFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		if match != nil {
+			actions = match.Apply(actions)
+		}
+
+		// Deferred actions are only listed with ?include_deferred=true, or
+		// when the query filters on the start date
+		if r.URL.Query().Get("include_deferred") != "true" && (match == nil || !match.Uses("start")) {
+			actions = startedActions(actions, time.Now().Format("2006-01-02"))
+		}
+
+		// ?page= and ?per_page= return a page of the list
+		actions, ok := paginate(w, r, actions)
+		if !ok {
+			return
+		}
+
 		// Convert to JSON response
 		response := map[string]interface{}{
 			"success": true,
 			"count":   len(actions),
-			"actions": actions,
+			"actions": newActions(r, actions),
 		}
 
 		json.NewEncoder(w).Encode(response)
@@ -85,15 +455,29 @@ func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
 	case "PUT":
 		// Parse request body
 		var actionRequest struct {
-			Name           string `json:"name"`
-			Note           string `json:"note,omitempty"`
-			ProjectID      *uint  `json:"project_id,omitempty"`
-			DueDate        string `json:"due_date,omitempty"`
-			StatusID       uint   `json:"status_id"`
-			RepeatCount    uint   `json:"repeat_count,omitempty"`
-			RepeatInterval string `json:"repeat_interval,omitempty"`
-			RepeatPattern  string `json:"repeat_pattern,omitempty"`
-			RepeatUntil    string `json:"repeat_until,omitempty"`
+			Name           string   `json:"name"`
+			Note           string   `json:"note,omitempty"`
+			ProjectID      *uint    `json:"project_id,omitempty"`
+			DueDate        string   `json:"due_date,omitempty"`
+			StatusID       uint     `json:"status_id"`
+			RepeatMode     string   `json:"repeat_mode,omitempty"`
+			RepeatCount    uint     `json:"repeat_count,omitempty"`
+			RepeatInterval string   `json:"repeat_interval,omitempty"`
+			RepeatPattern  string   `json:"repeat_pattern,omitempty"`
+			RepeatUntil    string   `json:"repeat_until,omitempty"`
+			AssigneeID     uint     `json:"assignee_id,omitempty"`
+			QuickAdd       string   `json:"quick_add,omitempty"`
+			AllowDuplicate bool     `json:"allow_duplicate,omitempty"`
+			ExceedLimit    bool     `json:"exceed_limit,omitempty"`
+			Flagged        bool     `json:"flagged,omitempty"`
+			Location       string   `json:"location,omitempty"`
+			Latitude       *float64 `json:"latitude,omitempty"`
+			Longitude      *float64 `json:"longitude,omitempty"`
+			Energy         string   `json:"energy,omitempty"`
+			WaitingOn      string   `json:"waiting_on,omitempty"`
+			FollowUp       string   `json:"follow_up,omitempty"`
+			StartDate      string   `json:"start_date,omitempty"`
+			Reminders      []string `json:"reminders,omitempty"` // Offsets before the due date such as -1d or -2h
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&actionRequest); err != nil {
@@ -101,35 +485,232 @@ func (s *Server) handleActions(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Validate required fields
-		if actionRequest.Name == "" {
-			http.Error(w, "Action name is required", http.StatusBadRequest)
-			return
+		// quick_add is a line in the syntax of `projector add`, the other
+		// fields take precedence over what it sets
+		var tags []string
+		if actionRequest.QuickAdd != "" {
+			entry, err := quickadd.Parse(actionRequest.QuickAdd, time.Now())
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid quick_add: %v", err), http.StatusBadRequest)
+				return
+			}
+			quick, err := entry.Resolve(s.dbPath)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid quick_add: %v", err), http.StatusBadRequest)
+				return
+			}
+
+			if actionRequest.Name == "" {
+				actionRequest.Name = quick.Name
+			}
+			if actionRequest.DueDate == "" {
+				actionRequest.DueDate = quick.DueDate
+			}
+			if actionRequest.StartDate == "" {
+				actionRequest.StartDate = quick.StartDate
+			}
+			if actionRequest.ProjectID == nil {
+				actionRequest.ProjectID = quick.ProjectID
+			}
+			if actionRequest.RepeatInterval == "" && actionRequest.RepeatMode == "" {
+				actionRequest.RepeatMode = quick.RepeatMode
+				actionRequest.RepeatInterval = quick.RepeatInterval
+				actionRequest.RepeatPattern = quick.RepeatPattern
+			}
+			if len(actionRequest.Reminders) == 0 {
+				actionRequest.Reminders = quick.Reminders
+			}
+			tags = quick.Tags
 		}
 
 		if actionRequest.StatusID == 0 {
-			actionRequest.StatusID = 1 // Default to 'todo' status
+			statusID, err := database.DefaultStatusID(s.dbPath)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error reading default status: %v", err), http.StatusInternalServerError)
+				return
+			}
+			actionRequest.StatusID = statusID
+		}
+
+		if !s.checkProjectAccess(w, r, actionRequest.ProjectID) {
+			return
+		}
+
+		coordinates, err := requestCoordinates(actionRequest.Latitude, actionRequest.Longitude)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// All fields are validated before the action is created, as the
+		// fields past the name are set one by one afterwards
+		input := database.ActionInput{
+			Name:           actionRequest.Name,
+			ProjectID:      actionRequest.ProjectID,
+			StatusID:       actionRequest.StatusID,
+			DueDate:        actionRequest.DueDate,
+			StartDate:      actionRequest.StartDate,
+			FollowUp:       actionRequest.FollowUp,
+			RepeatMode:     actionRequest.RepeatMode,
+			RepeatCount:    actionRequest.RepeatCount,
+			RepeatInterval: actionRequest.RepeatInterval,
+			RepeatUntil:    actionRequest.RepeatUntil,
+			Energy:         actionRequest.Energy,
+			Reminders:      actionRequest.Reminders,
+			AssigneeID:     &actionRequest.AssigneeID,
+		}
+		if err := database.ValidateAction(s.dbPath, &input); err != nil {
+			if !writeValidationError(w, err) {
+				http.Error(w, fmt.Sprintf("Error validating action: %v", err), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// Scripts that retry or run twice should not pile up identical actions
+		if !actionRequest.AllowDuplicate {
+			existing, err := database.FindOpenDuplicate(s.dbPath, actionRequest.Name, actionRequest.ProjectID)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error checking for duplicates: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if existing != nil {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"success":   false,
+					"message":   localize(r, "APIDuplicateAction"),
+					"action_id": existing.ID,
+					"action":    newAction(r, existing),
+				})
+				return
+			}
+		}
+
+		// Work-in-progress limits are a warning the client can override
+		if !actionRequest.ExceedLimit {
+			var ownerID, projectID uint
+			if user := currentUser(r); user != nil {
+				ownerID = user.ID
+			}
+			if actionRequest.ProjectID != nil {
+				projectID = *actionRequest.ProjectID
+			}
+			err := database.CheckNewActionWIPLimit(s.dbPath, actionRequest.StatusID, projectID, ownerID)
+			var limitErr *database.WIPLimitError
+			if errors.As(err, &limitErr) {
+				writeWIPLimitError(w, r, limitErr)
+				return
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error checking work-in-progress limits: %v", err), http.StatusInternalServerError)
+				return
+			}
 		}
 
 		// Create the action
-		actionID, err := database.CreateAction(s.dbPath, actionRequest.Name, actionRequest.Note, actionRequest.ProjectID, actionRequest.DueDate, actionRequest.StatusID, actionRequest.RepeatCount, actionRequest.RepeatInterval, actionRequest.RepeatPattern, actionRequest.RepeatUntil, nil)
+		actionID, err := database.CreateAction(s.dbPath, actionRequest.Name, actionRequest.Note, actionRequest.ProjectID, actionRequest.DueDate, actionRequest.StatusID, actionRequest.RepeatMode, actionRequest.RepeatCount, actionRequest.RepeatInterval, actionRequest.RepeatPattern, actionRequest.RepeatUntil, nil)
+		if writeValidationError(w, err) {
+			return
+		}
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error creating action: %v", err), http.StatusInternalServerError)
 			return
 		}
 
+		if user := currentUser(r); user != nil {
+			if err := database.SetActionOwner(s.dbPath, actionID, user.ID); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action owner: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.AssigneeID != 0 {
+			if err := database.UpdateAction(s.dbPath, actionID, database.ActionUpdate{AssigneeID: &actionRequest.AssigneeID}); err != nil {
+				http.Error(w, fmt.Sprintf("Error assigning action: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.Flagged {
+			if err := database.UpdateAction(s.dbPath, actionID, database.ActionUpdate{Flagged: &actionRequest.Flagged}); err != nil {
+				http.Error(w, fmt.Sprintf("Error flagging action: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.Location != "" || coordinates != nil {
+			update := database.ActionUpdate{Location: &actionRequest.Location, Coordinates: coordinates}
+			if err := database.UpdateAction(s.dbPath, actionID, update); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action location: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.Energy != "" {
+			if err := database.UpdateAction(s.dbPath, actionID, database.ActionUpdate{Energy: &actionRequest.Energy}); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action energy: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.WaitingOn != "" || actionRequest.FollowUp != "" {
+			update := database.ActionUpdate{WaitingOn: &actionRequest.WaitingOn, FollowUp: &actionRequest.FollowUp}
+			if err := database.UpdateAction(s.dbPath, actionID, update); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action follow-up: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if actionRequest.StartDate != "" {
+			if err := database.UpdateAction(s.dbPath, actionID, database.ActionUpdate{StartDate: &actionRequest.StartDate}); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action start date: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if len(tags) > 0 {
+			if err := database.SetActionTags(s.dbPath, actionID, tags); err != nil {
+				http.Error(w, fmt.Sprintf("Error tagging action: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if len(actionRequest.Reminders) > 0 {
+			if err := database.SetReminders(s.dbPath, actionID, actionRequest.Reminders); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action reminders: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// The request is a single write, whatever fields it sets
+		if err := database.ResetActionVersion(s.dbPath, actionID); err != nil {
+			http.Error(w, fmt.Sprintf("Error creating action: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		s.applyRules(r, nil, actionID)
+
 		// Get the created action
 		action, err := database.GetActionByID(s.dbPath, actionID)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error retrieving created action: %v", err), http.StatusInternalServerError)
 			return
 		}
+		s.runActionHooks(hooks.ActionCreated, action)
+
+		if s.dispatcher != nil {
+			go func() {
+				if err := notify.SendActionCreated(s.dbPath, s.dispatcher, actionID); err != nil {
+					logf(r, "Failed to send action notification: %v", err)
+				}
+			}()
+			s.notifyAssignee(r, nil, action)
+		}
 
 		response := map[string]interface{}{
 			"success": true,
-			"message": "Action created successfully",
+			"message": localize(r, "APIActionCreated"),
 			"action_id": actionID,
-			"action":    action,
+			"action":    newAction(r, action),
 		}
 
 		w.WriteHeader(http.StatusCreated)
@@ -151,7 +732,8 @@ func (s *Server) handleActionByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	actionIDStr := path[13:] // Remove "/api/actions/" prefix
+	// Remove "/api/actions/" prefix, the rest may be an operation on the action
+	actionIDStr, subPath, _ := strings.Cut(path[13:], "/")
 	actionID, err := strconv.ParseUint(actionIDStr, 10, 32)
 	if err != nil {
 		http.Error(w, "Invalid action ID", http.StatusBadRequest)
@@ -159,23 +741,47 @@ func (s *Server) handleActionByID(w http.ResponseWriter, r *http.Request) {
 	}
 	actionIDUint := uint(actionID)
 
+	// Actions that are not shared with the user are reported as not found
+	action, role, err := s.accessibleAction(r, actionIDUint)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving action: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if action == nil {
+		http.Error(w, "Action not found", http.StatusNotFound)
+		return
+	}
+
+	// Viewers can only read the action
+	if r.Method != "GET" && !requireRole(w, role, database.RoleEditor) {
+		return
+	}
+
+	if subPath == "clone" {
+		s.handleActionClone(w, r, action)
+		return
+	}
+	if subPath == "skip" {
+		s.handleActionSkip(w, r, action)
+		return
+	}
+	if subPath != "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
 	case "GET":
-		// Get action by ID
-		action, err := database.GetActionByID(s.dbPath, actionIDUint)
+		attachments, err := database.GetAttachments(s.dbPath, actionIDUint)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error retrieving action: %v", err), http.StatusInternalServerError)
-			return
-		}
-
-		if action == nil {
-			http.Error(w, "Action not found", http.StatusNotFound)
+			http.Error(w, fmt.Sprintf("Error retrieving attachments: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		response := map[string]interface{}{
 			"success": true,
-			"action":    action,
+			"action":    newAction(r, action),
+			"attachments": newAttachments(r, attachments),
 		}
 
 		json.NewEncoder(w).Encode(response)
@@ -187,10 +793,11 @@ func (s *Server) handleActionByID(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("Error deleting action: %v", err), http.StatusInternalServerError)
 			return
 		}
+		s.runActionHooks(hooks.ActionDeleted, action)
 
 		response := map[string]interface{}{
 			"success": true,
-			"message": "Action deleted successfully",
+			"message": localize(r, "APIActionDeleted"),
 			"action_id": actionIDUint,
 		}
 
@@ -211,14 +818,36 @@ func (s *Server) handleActionByID(w http.ResponseWriter, r *http.Request) {
 		case "done":
 			// Mark action as done and handle repetition
 			err := database.MarkActionAsDone(s.dbPath, actionIDUint)
+			var transitionErr *database.TransitionError
+			if errors.As(err, &transitionErr) {
+				http.Error(w, fmt.Sprintf("Error marking action as done: %v", err), http.StatusConflict)
+				return
+			}
 			if err != nil {
 				http.Error(w, fmt.Sprintf("Error marking action as done: %v", err), http.StatusInternalServerError)
 				return
 			}
+			s.runActionHooksByID(hooks.ActionDone, actionIDUint)
+
+			response := map[string]interface{}{
+				"success": true,
+				"message": localize(r, "APIActionDone"),
+				"action_id": actionIDUint,
+			}
+
+			json.NewEncoder(w).Encode(response)
+
+		case "detach":
+			// Detach this occurrence from its repeat series
+			err := database.DetachAction(s.dbPath, actionIDUint)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error detaching action: %v", err), http.StatusInternalServerError)
+				return
+			}
 
 			response := map[string]interface{}{
 				"success": true,
-				"message": "Action marked as done",
+				"message": localize(r, "APIActionDetached"),
 				"action_id": actionIDUint,
 			}
 
@@ -228,27 +857,298 @@ func (s *Server) handleActionByID(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("Unknown action: %s", actionRequest.Action), http.StatusBadRequest)
 		}
 
+	case "PATCH":
+		// Parse request body, omitted fields are left unchanged
+		var updateRequest struct {
+			Name           *string   `json:"name,omitempty"`
+			Note           *string   `json:"note,omitempty"`
+			ProjectID      *uint     `json:"project_id,omitempty"`
+			DueDate        *string   `json:"due_date,omitempty"`
+			StatusID       *uint     `json:"status_id,omitempty"`
+			RepeatMode     *string   `json:"repeat_mode,omitempty"`
+			RepeatCount    *uint     `json:"repeat_count,omitempty"`
+			RepeatInterval *string   `json:"repeat_interval,omitempty"`
+			RepeatPattern  *string   `json:"repeat_pattern,omitempty"`
+			RepeatUntil    *string   `json:"repeat_until,omitempty"`
+			AssigneeID     *uint     `json:"assignee_id,omitempty"`
+			Flagged        *bool     `json:"flagged,omitempty"`
+			Location       *string   `json:"location,omitempty"`
+			Latitude       *float64  `json:"latitude,omitempty"`
+			Longitude      *float64  `json:"longitude,omitempty"`
+			Energy         *string   `json:"energy,omitempty"`
+			WaitingOn      *string   `json:"waiting_on,omitempty"`
+			FollowUp       *string   `json:"follow_up,omitempty"`
+			StartDate      *string   `json:"start_date,omitempty"`
+			Reminders      *[]string `json:"reminders,omitempty"` // Replaces the reminders, [] removes them
+			ExceedLimit    bool      `json:"exceed_limit,omitempty"`
+			Version        *uint     `json:"version"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !requireVersion(w, updateRequest.Version) {
+			return
+		}
+
+		if updateRequest.ProjectID != nil && *updateRequest.ProjectID != 0 && !s.checkProjectAccess(w, r, updateRequest.ProjectID) {
+			return
+		}
+
+		coordinates, err := requestCoordinates(updateRequest.Latitude, updateRequest.Longitude)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if updateRequest.Reminders != nil {
+			if err := database.ValidateReminders(*updateRequest.Reminders); err != nil {
+				writeValidationError(w, &database.ValidationError{Fields: []database.FieldError{{Field: "reminders", Message: err.Error()}}})
+				return
+			}
+		}
+
+		update := database.ActionUpdate{
+			Name:           updateRequest.Name,
+			Note:           updateRequest.Note,
+			ProjectID:      updateRequest.ProjectID,
+			DueDate:        updateRequest.DueDate,
+			StatusID:       updateRequest.StatusID,
+			RepeatMode:     updateRequest.RepeatMode,
+			RepeatCount:    updateRequest.RepeatCount,
+			RepeatInterval: updateRequest.RepeatInterval,
+			RepeatPattern:  updateRequest.RepeatPattern,
+			RepeatUntil:    updateRequest.RepeatUntil,
+			AssigneeID:     updateRequest.AssigneeID,
+			Flagged:        updateRequest.Flagged,
+			Location:       updateRequest.Location,
+			Coordinates:    coordinates,
+			Energy:         updateRequest.Energy,
+			WaitingOn:      updateRequest.WaitingOn,
+			FollowUp:       updateRequest.FollowUp,
+			StartDate:      updateRequest.StartDate,
+			Version:        updateRequest.Version,
+		}
+
+		// Work-in-progress limits are a warning the client can override
+		if !updateRequest.ExceedLimit {
+			err := database.CheckWIPLimit(s.dbPath, actionIDUint, update)
+			var limitErr *database.WIPLimitError
+			if errors.As(err, &limitErr) {
+				writeWIPLimitError(w, r, limitErr)
+				return
+			}
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error checking work-in-progress limits: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// With scope=series the change also applies to all later occurrences
+		updated := 1
+		switch r.URL.Query().Get("scope") {
+		case "", "single":
+			err = database.UpdateAction(s.dbPath, actionIDUint, update)
+		case "series":
+			updated, err = database.UpdateActionSeries(s.dbPath, actionIDUint, update)
+		default:
+			http.Error(w, fmt.Sprintf("Unknown scope: %s", r.URL.Query().Get("scope")), http.StatusBadRequest)
+			return
+		}
+		var conflict *database.VersionConflictError
+		if errors.As(err, &conflict) {
+			current, _ := database.GetActionByID(s.dbPath, actionIDUint)
+			writeVersionConflict(w, conflict, "action", newAction(r, current))
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error updating action: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if updateRequest.Reminders != nil {
+			if err := database.SetReminders(s.dbPath, actionIDUint, *updateRequest.Reminders); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting action reminders: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		// Get the updated action
+		previous := action
+		s.applyRules(r, previous, actionIDUint)
+		action, err = database.GetActionByID(s.dbPath, actionIDUint)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving updated action: %v", err), http.StatusInternalServerError)
+			return
+		}
+		s.runActionHooks(hooks.ActionUpdated, action)
+
+		if s.dispatcher != nil {
+			s.notifyAssignee(r, previous, action)
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"message": localize(r, "APIActionUpdated"),
+			"action_id": actionIDUint,
+			"updated":   updated,
+			"action":    newAction(r, action),
+		}
+
+		json.NewEncoder(w).Encode(response)
+
 	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
+// notifyAssignee notifies the assignee of an action when it was newly
+// assigned to them, or when its due date changed. The previous state is nil
+// for new actions.
+func (s *Server) notifyAssignee(r *http.Request, previous, action *database.Action) {
+	if action == nil || !action.AssigneeID.Valid {
+		return
+	}
+
+	switch {
+	case previous == nil || previous.AssigneeID != action.AssigneeID:
+		go func() {
+			if err := notify.SendAssigned(s.dbPath, s.dispatcher, action.ID); err != nil {
+				logf(r, "Failed to send assignment notification: %v", err)
+			}
+		}()
+	case previous.DueDate != action.DueDate:
+		go func() {
+			if err := notify.SendDueChanged(s.dbPath, s.dispatcher, action.ID); err != nil {
+				logf(r, "Failed to send due date notification: %v", err)
+			}
+		}()
+	}
+}
+
+// assignedTo returns the actions assigned to the user with the given username
+func assignedTo(actions []database.Action, username string) []database.Action {
+	var assigned []database.Action
+	for _, action := range actions {
+		if action.AssigneeName.Valid && action.AssigneeName.String == username {
+			assigned = append(assigned, action)
+		}
+	}
+	return assigned
+}
+
+// todayActions returns the open actions that are flagged, due today or overdue
+func todayActions(actions []database.Action, today string) []database.Action {
+	var selected []database.Action
+	for _, action := range actions {
+		if action.IsToday(today) {
+			selected = append(selected, action)
+		}
+	}
+	return selected
+}
+
+// nearActions returns the actions at a place
+func nearActions(actions []database.Action, place database.Place, radius float64) []database.Action {
+	var selected []database.Action
+	for _, action := range actions {
+		if action.IsNear(place, radius) {
+			selected = append(selected, action)
+		}
+	}
+	return selected
+}
+
+// lowEnergyActions returns the actions that take at most an energy level
+func lowEnergyActions(actions []database.Action, energy string) []database.Action {
+	var selected []database.Action
+	for _, action := range actions {
+		if action.NeedsAtMost(energy) {
+			selected = append(selected, action)
+		}
+	}
+	return selected
+}
+
+// startedActions returns the actions that are not deferred to a later date
+func startedActions(actions []database.Action, today string) []database.Action {
+	var selected []database.Action
+	for _, action := range actions {
+		if !action.IsDeferred(today) {
+			selected = append(selected, action)
+		}
+	}
+	return selected
+}
+
+// waitingActions returns the open actions waiting on someone, or with due
+// only those whose follow-up date has arrived
+func waitingActions(actions []database.Action, due bool) []database.Action {
+	today := time.Now().Format("2006-01-02")
+	var selected []database.Action
+	for _, action := range actions {
+		if !action.WaitingOn.Valid || action.StatusName == "done" {
+			continue
+		}
+		if due && !action.FollowUpDue(today) {
+			continue
+		}
+		selected = append(selected, action)
+	}
+	return selected
+}
+
+// requestCoordinates combines the latitude and longitude of a request, which
+// are given together or not at all
+func requestCoordinates(latitude, longitude *float64) (*database.Coordinates, error) {
+	if latitude == nil && longitude == nil {
+		return nil, nil
+	}
+	if latitude == nil || longitude == nil {
+		return nil, fmt.Errorf("latitude and longitude must be given together")
+	}
+
+	coordinates := database.Coordinates{Latitude: *latitude, Longitude: *longitude}
+	if err := coordinates.Validate(); err != nil {
+		return nil, err
+	}
+	return &coordinates, nil
+}
+
 // handleProjects handles project-related requests
 func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch r.Method {
 	case "GET":
-		projects, err := database.GetAllProjects(s.dbPath)
+		if s.notModified(w, r, "project", "project_member") {
+			return
+		}
+
+		projects, err := s.userProjects(r)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error retrieving projects: %v", err), http.StatusInternalServerError)
 			return
 		}
 
+		// Archived projects are only listed with ?include_archived=true
+		if r.URL.Query().Get("include_archived") != "true" {
+			projects = activeProjects(projects)
+		}
+
+		// ?page= and ?per_page= return a page of the list
+		projects, ok := paginate(w, r, projects)
+		if !ok {
+			return
+		}
+
 		response := map[string]interface{}{
 			"success":  true,
 			"count":    len(projects),
-			"projects": projects,
+			"projects": newProjects(r, projects),
 		}
 
 		json.NewEncoder(w).Encode(response)
@@ -256,8 +1156,9 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 	case "PUT":
 		// Parse request body
 		var projectRequest struct {
-			Name    string `json:"name"`
-			DueDate string `json:"due_date,omitempty"`
+			Name           string `json:"name"`
+			DueDate        string `json:"due_date,omitempty"`
+			RepeatInterval string `json:"repeat_interval,omitempty"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&projectRequest); err != nil {
@@ -265,12 +1166,22 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// Validate required fields
-		if projectRequest.Name == "" {
-			http.Error(w, "Project name is required", http.StatusBadRequest)
+		if writeValidationError(w, database.ValidateProjectInput(projectRequest.Name, projectRequest.DueDate)) {
 			return
 		}
 
+		// A recurring project repeats from its deadline
+		if projectRequest.RepeatInterval != "" {
+			if err := database.ValidateProjectRepeatInterval(projectRequest.RepeatInterval); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if projectRequest.DueDate == "" {
+				http.Error(w, "A repeating project needs a due date", http.StatusBadRequest)
+				return
+			}
+		}
+
 		// Create the project
 		projectID, err := database.CreateProject(s.dbPath, projectRequest.Name, projectRequest.DueDate)
 		if err != nil {
@@ -278,18 +1189,33 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if user := currentUser(r); user != nil {
+			if err := database.SetProjectOwner(s.dbPath, projectID, user.ID); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting project owner: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		if projectRequest.RepeatInterval != "" {
+			if err := database.SetProjectRepeat(s.dbPath, projectID, projectRequest.RepeatInterval); err != nil {
+				http.Error(w, fmt.Sprintf("Error setting project repeat: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
 		// Get the created project
 		project, err := database.GetProjectByID(s.dbPath, projectID)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error retrieving created project: %v", err), http.StatusInternalServerError)
 			return
 		}
+		s.runProjectHooks(hooks.ProjectCreated, project)
 
 		response := map[string]interface{}{
 			"success":    true,
-			"message":    "Project created successfully",
+			"message":    localize(r, "APIProjectCreated"),
 			"project_id": projectID,
-			"project":    project,
+			"project":    newProject(r, project),
 		}
 
 		w.WriteHeader(http.StatusCreated)
@@ -300,18 +1226,30 @@ func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// activeProjects leaves out the archived projects
+func activeProjects(projects []database.Project) []database.Project {
+	active := []database.Project{}
+	for _, project := range projects {
+		if !project.IsArchived() {
+			active = append(active, project)
+		}
+	}
+	return active
+}
+
 // handleProjectByID handles requests for a specific project
 func (s *Server) handleProjectByID(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Extract ID from URL path
 	path := r.URL.Path
-	if len(path) < 15 { // "/api/projects/" is 15 characters
+	if len(path) < 14 { // "/api/projects/" is 14 characters
 		http.Error(w, "Invalid project ID", http.StatusBadRequest)
 		return
 	}
 
-	projectIDStr := path[15:] // Remove "/api/projects/" prefix
+	// Remove "/api/projects/" prefix, the rest may address the project members
+	projectIDStr, subPath, _ := strings.Cut(path[14:], "/")
 	projectID, err := strconv.ParseUint(projectIDStr, 10, 32)
 	if err != nil {
 		http.Error(w, "Invalid project ID", http.StatusBadRequest)
@@ -319,38 +1257,118 @@ func (s *Server) handleProjectByID(w http.ResponseWriter, r *http.Request) {
 	}
 	projectIDUint := uint(projectID)
 
+	// Projects that are not shared with the user are reported as not found
+	project, role, err := s.accessibleProject(r, projectIDUint)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving project: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	if subPath == "members" || strings.HasPrefix(subPath, "members/") {
+		s.handleProjectMembers(w, r, project, role, strings.TrimPrefix(strings.TrimPrefix(subPath, "members"), "/"))
+		return
+	}
+	if subPath == "clone" {
+		s.handleProjectClone(w, r, project)
+		return
+	}
+	if subPath == "merge" {
+		s.handleProjectMerge(w, r, project, role)
+		return
+	}
+	if subPath == "burndown" {
+		s.handleProjectBurndown(w, r, project)
+		return
+	}
+	if subPath == "review" {
+		s.handleProjectReview(w, r, project, role)
+		return
+	}
+	if subPath != "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
 	case "GET":
-		// Get project by ID
-		project, err := database.GetProjectByID(s.dbPath, projectIDUint)
+		response := map[string]interface{}{
+			"success": true,
+			"project": newProject(r, project),
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case "PATCH":
+		if !requireRole(w, role, database.RoleEditor) {
+			return
+		}
+
+		// An empty repeat_interval stops the project from recurring, an
+		// empty review_interval stops its reviews
+		var updateRequest struct {
+			RepeatInterval *string `json:"repeat_interval"`
+			ReviewInterval *string `json:"review_interval"`
+			Version        *uint   `json:"version"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !requireVersion(w, updateRequest.Version) {
+			return
+		}
+
+		err := database.UpdateProject(s.dbPath, projectIDUint, database.ProjectUpdate{
+			RepeatInterval: updateRequest.RepeatInterval,
+			ReviewInterval: updateRequest.ReviewInterval,
+			Version:        updateRequest.Version,
+		})
+		var conflict *database.VersionConflictError
+		if errors.As(err, &conflict) {
+			current, _ := database.GetProjectByID(s.dbPath, projectIDUint)
+			writeVersionConflict(w, conflict, "project", newProject(r, current))
+			return
+		}
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Error retrieving project: %v", err), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("Error updating project: %v", err), http.StatusBadRequest)
 			return
 		}
 
-		if project == nil {
-			http.Error(w, "Project not found", http.StatusNotFound)
+		project, err := database.GetProjectByID(s.dbPath, projectIDUint)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving updated project: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		response := map[string]interface{}{
 			"success": true,
-			"project": project,
+			"message": localize(r, "APIProjectUpdated"),
+			"project": newProject(r, project),
 		}
 
 		json.NewEncoder(w).Encode(response)
 
 	case "DELETE":
+		if !requireRole(w, role, database.RoleOwner) {
+			return
+		}
+
 		// Delete the project
 		err := database.DeleteProject(s.dbPath, projectIDUint)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Error deleting project: %v", err), http.StatusInternalServerError)
 			return
 		}
+		s.runProjectHooks(hooks.ProjectDeleted, project)
 
 		response := map[string]interface{}{
 			"success":    true,
-			"message":    "Project deleted successfully",
+			"message":    localize(r, "APIProjectDeleted"),
 			"project_id": projectIDUint,
 		}
 
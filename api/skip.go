@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+)
+
+// handleActionSkip skips the open occurrence of a repeating action without
+// completing it, moving the action to its next due date
+func (s *Server) handleActionSkip(w http.ResponseWriter, r *http.Request, action *database.Action) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := database.SkipOccurrence(s.dbPath, action.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error skipping occurrence: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	skipped, err := database.GetActionByID(s.dbPath, action.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving action: %v", err), http.StatusInternalServerError)
+		return
+	}
+	s.runActionHooks(hooks.ActionUpdated, skipped)
+
+	response := map[string]interface{}{
+		"success":       true,
+		"message":       "Occurrence skipped",
+		"action_id":     action.ID,
+		"skipped_date":  result.SkippedDate,
+		"next_due_date": result.NextDueDate,
+		"action":        newAction(r, skipped),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
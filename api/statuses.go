@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// Status is the JSON form of an action status in API responses. The statuses
+// have no legacy shape, they are the same under /api and /api/v1.
+type Status struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+}
+
+// convertStatus converts a database status for API responses
+func convertStatus(status database.Status) Status {
+	return Status{
+		ID:    status.ID,
+		Name:  status.Name,
+		Color: status.Color.String,
+		Icon:  status.Icon.String,
+	}
+}
+
+// handleStatuses lists the statuses with the color and icon clients show
+// them with
+func (s *Server) handleStatuses(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses, err := database.GetAllStatuses(s.dbPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving statuses: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	converted := []Status{}
+	for _, status := range statuses {
+		converted = append(converted, convertStatus(status))
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"count":    len(converted),
+		"statuses": converted,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleStatusByID sets the color and icon of a status with
+// PATCH /api/statuses/:id, where the ID may also be the status name. The
+// statuses are shared by all users, so this requires the admin role.
+func (s *Server) handleStatusByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "PATCH" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if user := currentUser(r); user != nil && !user.IsAdmin {
+		http.Error(w, "Forbidden: requires the admin role", http.StatusForbidden)
+		return
+	}
+
+	status, err := database.GetStatus(s.dbPath, strings.TrimPrefix(r.URL.Path, "/api/statuses/"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving status: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if status == nil {
+		http.Error(w, "Status not found", http.StatusNotFound)
+		return
+	}
+
+	var updateRequest struct {
+		Color *string `json:"color,omitempty"`
+		Icon  *string `json:"icon,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&updateRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if updateRequest.Color != nil {
+		if _, err := database.ValidateStatusColor(*updateRequest.Color); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if updateRequest.Icon != nil {
+		if _, err := database.ValidateStatusIcon(*updateRequest.Icon); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := database.UpdateStatusStyle(s.dbPath, status.ID, updateRequest.Color, updateRequest.Icon); err != nil {
+		http.Error(w, fmt.Sprintf("Error updating status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := database.GetStatus(s.dbPath, status.Name)
+	if err != nil || updated == nil {
+		http.Error(w, fmt.Sprintf("Error retrieving updated status: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success": true,
+		"message": "Status updated successfully",
+		"status":  convertStatus(*updated),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
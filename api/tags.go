@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// Tag is the JSON form of a tag in API responses, with the number of open
+// and done actions when ?include=counts is given. The tags have no legacy
+// shape, they are the same under /api and /api/v1.
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Open *int   `json:"open,omitempty"`
+	Done *int   `json:"done,omitempty"`
+}
+
+// handleTags lists the tags and deletes the tags no action carries with
+// DELETE /api/tags?orphaned=true, which requires the admin role
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		tags, err := database.GetAllTags(s.dbPath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving tags: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		// Only the actions the user can access are counted
+		var open, done map[string]int
+		if r.URL.Query().Get("include") == "counts" {
+			actions, err := s.userActions(r)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Error retrieving actions: %v", err), http.StatusInternalServerError)
+				return
+			}
+			open, done = make(map[string]int), make(map[string]int)
+			for _, action := range actions {
+				for _, name := range action.Tags {
+					if action.StatusName == "done" {
+						done[name]++
+					} else {
+						open[name]++
+					}
+				}
+			}
+		}
+
+		converted := []Tag{}
+		for _, tag := range tags {
+			item := Tag{ID: tag.ID, Name: tag.Name}
+			if open != nil {
+				openCount, doneCount := open[tag.Name], done[tag.Name]
+				item.Open, item.Done = &openCount, &doneCount
+			}
+			converted = append(converted, item)
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"count":   len(converted),
+			"tags":    converted,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case "DELETE":
+		if r.URL.Query().Get("orphaned") != "true" {
+			http.Error(w, "Only unused tags can be deleted, pass ?orphaned=true", http.StatusBadRequest)
+			return
+		}
+		if user := currentUser(r); user != nil && !user.IsAdmin {
+			http.Error(w, "Forbidden: requires the admin role", http.StatusForbidden)
+			return
+		}
+
+		deleted, err := database.DeleteOrphanTags(s.dbPath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error deleting tags: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"message": "Unused tags deleted successfully",
+			"deleted": deleted,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleTagByName renames a tag with POST /api/tags/:name/rename and
+// {"name": "new"}, and merges it into another tag with
+// POST /api/tags/:name/merge and {"into": "other"}. Tags are shared by all
+// users, so both require the admin role.
+func (s *Server) handleTagByName(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	namePart, subPath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/tags/"), "/")
+	if subPath != "rename" && subPath != "merge" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if user := currentUser(r); user != nil && !user.IsAdmin {
+		http.Error(w, "Forbidden: requires the admin role", http.StatusForbidden)
+		return
+	}
+
+	name, err := url.PathUnescape(namePart)
+	if err != nil {
+		http.Error(w, "Invalid tag name", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := database.GetTagByName(s.dbPath, name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving tag: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if tag == nil {
+		http.Error(w, "Tag not found", http.StatusNotFound)
+		return
+	}
+
+	var request struct {
+		Name string `json:"name"`
+		Into string `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var response map[string]interface{}
+	if subPath == "rename" {
+		if request.Name == "" {
+			http.Error(w, "name is required", http.StatusBadRequest)
+			return
+		}
+		existing, err := database.GetTagByName(s.dbPath, request.Name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving tag: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if existing != nil {
+			http.Error(w, fmt.Sprintf("Tag %s already exists, merge the tags instead", request.Name), http.StatusConflict)
+			return
+		}
+
+		actions, err := database.RenameTag(s.dbPath, tag.Name, request.Name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error renaming tag: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response = map[string]interface{}{
+			"success": true,
+			"message": "Tag renamed successfully",
+			"name":    request.Name,
+			"actions": actions,
+		}
+	} else {
+		if request.Into == "" {
+			http.Error(w, "into is required", http.StatusBadRequest)
+			return
+		}
+		if request.Into == tag.Name {
+			http.Error(w, "Cannot merge a tag into itself", http.StatusBadRequest)
+			return
+		}
+
+		actions, err := database.MergeTags(s.dbPath, tag.Name, request.Into)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error merging tags: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response = map[string]interface{}{
+			"success": true,
+			"message": "Tags merged successfully",
+			"name":    request.Into,
+			"actions": actions,
+		}
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
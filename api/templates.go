@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// handleTemplates lists the project templates
+func (s *Server) handleTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	templates, err := database.GetAllTemplates(s.dbPath)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving templates: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"count":     len(templates),
+		"templates": newTemplates(r, templates),
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleProjectFromTemplate creates a project with the actions of a template,
+// resolving the due dates of the template against the given start date
+func (s *Server) handleProjectFromTemplate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var templateRequest struct {
+		Template  string `json:"template"`
+		Name      string `json:"name"`
+		StartDate string `json:"start_date,omitempty"` // YYYY-MM-DD, defaults to today
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&templateRequest); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if templateRequest.Template == "" || templateRequest.Name == "" {
+		http.Error(w, "Template and project name are required", http.StatusBadRequest)
+		return
+	}
+	if templateRequest.StartDate == "" {
+		templateRequest.StartDate = time.Now().Format("2006-01-02")
+	}
+
+	template, err := database.GetTemplateByName(s.dbPath, templateRequest.Template)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving template: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if template == nil {
+		http.Error(w, "Template not found", http.StatusNotFound)
+		return
+	}
+
+	projectID, err := database.CreateProjectFromTemplate(s.dbPath, template, templateRequest.Name, templateRequest.StartDate)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error creating project: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if user := currentUser(r); user != nil {
+		if err := database.SetProjectOwner(s.dbPath, projectID, user.ID); err != nil {
+			http.Error(w, fmt.Sprintf("Error setting project owner: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	project, err := database.GetProjectByID(s.dbPath, projectID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving created project: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"message":    "Project created from template",
+		"project_id": projectID,
+		"actions":    len(template.Actions),
+		"project":    newProject(r, project),
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// TrashItem is the JSON form of a deleted action or project in API responses.
+// The trash has no legacy shape, it is the same under /api and /api/v1.
+type TrashItem struct {
+	ID        uint   `json:"id"`
+	Entity    string `json:"entity"` // action or project
+	EntityID  uint   `json:"entity_id"`
+	Name      string `json:"name"`
+	OwnerID   *uint  `json:"owner_id,omitempty"`
+	DeletedAt string `json:"deleted_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// SetTrashRetention sets the number of days deleted items are kept, which is
+// reported as their expiry
+func (s *Server) SetTrashRetention(days int) {
+	s.trashRetention = days
+}
+
+// handleTrash lists the deleted actions and projects the user may restore,
+// and empties the trash. Emptying requires the admin role, ?expired=true only
+// removes the items older than the retention period.
+func (s *Server) handleTrash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		items, err := database.GetTrash(s.dbPath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error retrieving trash: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		user := currentUser(r)
+		converted := []TrashItem{}
+		for _, item := range items {
+			if !canAccess(user, item.OwnerID) {
+				continue
+			}
+			converted = append(converted, TrashItem{
+				ID:        item.ID,
+				Entity:    item.Entity,
+				EntityID:  item.EntityID,
+				Name:      item.Name,
+				OwnerID:   optionalID(item.OwnerID),
+				DeletedAt: item.DeletedAt,
+				ExpiresAt: item.ExpiresAt(s.trashRetention).Format(time.RFC3339),
+			})
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"count":   len(converted),
+			"trash":   converted,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	case "DELETE":
+		if user := currentUser(r); user != nil && !user.IsAdmin {
+			http.Error(w, "Forbidden: requires the admin role", http.StatusForbidden)
+			return
+		}
+
+		var before time.Time
+		if r.URL.Query().Get("expired") == "true" {
+			before = time.Now().AddDate(0, 0, -s.trashRetention)
+		}
+
+		removed, err := database.EmptyTrash(s.dbPath, before)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Error emptying trash: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"success": true,
+			"message": "Trash emptied successfully",
+			"removed": removed,
+		}
+
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleTrashByID restores a deleted action or project with
+// POST /api/trash/:id/restore
+func (s *Server) handleTrashByID(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	idPart, subPath, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/trash/"), "/")
+	if subPath != "restore" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trashID, err := strconv.ParseUint(idPart, 10, 32)
+	if err != nil {
+		http.Error(w, "Invalid trash ID", http.StatusBadRequest)
+		return
+	}
+
+	item, err := database.GetTrashItem(s.dbPath, uint(trashID))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving trash: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if item == nil || !canAccess(currentUser(r), item.OwnerID) {
+		http.Error(w, "Trash item not found", http.StatusNotFound)
+		return
+	}
+
+	if err := database.RestoreTrash(s.dbPath, item.ID); err != nil {
+		http.Error(w, fmt.Sprintf("Error restoring %s: %v", item.Entity, err), http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":   true,
+		"message":   fmt.Sprintf("Restored %s successfully", item.Entity),
+		"entity":    item.Entity,
+		"entity_id": item.EntityID,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
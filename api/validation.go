@@ -0,0 +1,28 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// writeValidationError answers 422 Unprocessable Entity with the fields that
+// broke a validation rule, and reports whether err was a validation error.
+// Other errors are left to the caller.
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	var invalid *database.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"message": invalid.Error(),
+		"errors":  invalid.Fields,
+	})
+	return true
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// v1ContextKey marks requests made through /api/v1
+const v1ContextKey contextKey = "v1"
+
+// handleV1 serves the /api/v1 endpoints. They are the /api endpoints with
+// snake_case field names in the responses: the request is passed on to the
+// /api handler with the version recorded in its context. The /api endpoints
+// keep the Go field names of earlier releases for existing clients.
+func (s *Server) handleV1(w http.ResponseWriter, r *http.Request) {
+	path := "/api" + strings.TrimPrefix(r.URL.Path, "/api/v1")
+
+	v1 := r.Clone(context.WithValue(r.Context(), v1ContextKey, true))
+	v1.URL.Path = path
+	v1.URL.RawPath = ""
+	s.mux.ServeHTTP(w, v1)
+}
+
+// isV1 reports whether the request was made through /api/v1
+func isV1(r *http.Request) bool {
+	v1, _ := r.Context().Value(v1ContextKey).(bool)
+	return v1
+}
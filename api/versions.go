@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// requireVersion reports whether an update carries the version of the action
+// or project it is based on, responding with 428 Precondition Required when
+// it does not
+func requireVersion(w http.ResponseWriter, version *uint) bool {
+	if version == nil {
+		http.Error(w, "version is required: send the version of the last read, so that concurrent changes are not overwritten", http.StatusPreconditionRequired)
+		return false
+	}
+	return true
+}
+
+// writeVersionConflict responds with 409 Conflict to an update based on an
+// outdated version, with the current state under key so that the client can
+// merge and retry
+func writeVersionConflict(w http.ResponseWriter, conflict *database.VersionConflictError, key string, current interface{}) {
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"message": conflict.Error(),
+		"version": conflict.Current,
+		key:       current,
+	})
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+)
+
+// writeWIPLimitError responds with 409 Conflict to a change that would exceed
+// a work-in-progress limit. The client may repeat it with exceed_limit set.
+func writeWIPLimitError(w http.ResponseWriter, r *http.Request, limitErr *database.WIPLimitError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"message": localize(r, "APIWIPLimit", i18n.Data{"Error": limitErr}),
+		"status":  limitErr.Status,
+		"project": limitErr.Project,
+		"limit":   limitErr.Limit,
+		"count":   limitErr.Count,
+	})
+}
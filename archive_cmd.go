@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// archiveRecord is the JSON form of an archived action in command output
+type archiveRecord struct {
+	ID          uint   `json:"id"`
+	ActionID    uint   `json:"action_id"`
+	Name        string `json:"name"`
+	Project     string `json:"project,omitempty"`
+	CompletedAt string `json:"completed_at"`
+	ArchivedAt  string `json:"archived_at"`
+}
+
+func archiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "List and restore archived done actions",
+		Long: `Done actions are moved to the archive once they were completed longer
+than archive.done_after_days in the config ago, by the server or by
+'projector gc'. Archived actions no longer show up in listings, and can be
+restored.`,
+	}
+
+	cmd.AddCommand(archiveListCmd())
+	cmd.AddCommand(archiveRestoreCmd())
+	return cmd
+}
+
+func archiveListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List archived actions",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			archived, err := database.GetArchive(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingArchive", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []archiveRecord{}
+			table := output.Table{Headers: []string{"ID", "ACTION", "NAME", "PROJECT", "COMPLETED"}}
+			for _, action := range archived {
+				record := archiveRecord{
+					ID:          action.ID,
+					ActionID:    action.ActionID,
+					Name:        action.Name,
+					Project:     action.ProjectName.String,
+					CompletedAt: action.CompletedAt,
+					ArchivedAt:  action.ArchivedAt,
+				}
+				records = append(records, record)
+				table.AddRow(fmt.Sprintf("%d", record.ID), fmt.Sprintf("%d", record.ActionID), record.Name, record.Project, displayDate(record.CompletedAt))
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("ArchiveEmpty"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintArchive", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func archiveRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <archive-id>",
+		Short: "Restore an archived action",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			archiveID, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				fmt.Println(i18n.T("InvalidArchiveID", i18n.Data{"ID": args[0]}))
+				return
+			}
+
+			actionID, err := database.RestoreArchived(database.GetDatabasePath(), uint(archiveID))
+			if err != nil {
+				fmt.Println(i18n.T("FailedToRestoreAction", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("RestoredAction", i18n.Data{"ID": actionID}))
+		},
+	}
+}
+
+func gcCmd() *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Archive old done actions and empty expired trash",
+		Long: `Moves the done actions that were completed longer than the given number
+of days ago to the archive (see 'projector archive'), and permanently removes
+the items that have been in the trash longer than the retention period. The
+server does the same every hour when archive.done_after_days is set in the
+config, which is also the default for --days.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			if !cmd.Flags().Changed("days") {
+				cfg, err := config.Load()
+				if err != nil {
+					fmt.Printf("❌ %v\n", err)
+					return
+				}
+				days = cfg.Archive.DoneAfterDays
+			}
+			if days <= 0 {
+				fmt.Println(i18n.T("NoArchivePeriod"))
+				return
+			}
+
+			now := time.Now()
+			retention := trashRetention()
+			archiveBefore := now.AddDate(0, 0, -days)
+			trashBefore := now.AddDate(0, 0, -retention)
+
+			actions, err := database.GetArchivableActions(database.GetDatabasePath(), archiveBefore)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingDoneActions", i18n.Data{"Error": err}))
+				return
+			}
+			items, err := database.GetTrash(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTrash", i18n.Data{"Error": err}))
+				return
+			}
+			var expired []database.TrashItem
+			for _, item := range items {
+				if !item.ExpiresAt(retention).After(now) {
+					expired = append(expired, item)
+				}
+			}
+
+			if len(actions) == 0 && len(expired) == 0 {
+				fmt.Println(i18n.T("NothingToCleanUp"))
+				return
+			}
+
+			if isDryRun(cmd) {
+				for _, action := range actions {
+					fmt.Printf("   archive action %d: %s\n", action.ID, action.Name)
+				}
+				for _, item := range expired {
+					fmt.Printf("   remove %s %d: %s\n", item.Entity, item.EntityID, item.Name)
+				}
+				fmt.Println(i18n.T("WouldArchiveAndEmptyTrash", i18n.Data{"Archived": len(actions), "Removed": len(expired)}))
+				return
+			}
+			if !confirm(cmd, i18n.T("ArchiveAndEmptyTrashConfirm", i18n.Data{"Archived": len(actions), "Removed": len(expired)})) {
+				return
+			}
+
+			archived, err := database.ArchiveDoneActions(database.GetDatabasePath(), archiveBefore)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToArchiveDoneActions", i18n.Data{"Error": err}))
+				return
+			}
+			var removed int64
+			if len(expired) > 0 {
+				removed, err = database.EmptyTrash(database.GetDatabasePath(), trashBefore)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToEmptyTrash", i18n.Data{"Error": err}))
+					return
+				}
+			}
+
+			fmt.Println(i18n.T("ArchivedAndEmptiedTrash", i18n.Data{"Archived": archived, "Removed": removed}))
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 0, "Archive the actions done longer than this many days ago (default archive.done_after_days)")
+	return cmd
+}
@@ -0,0 +1,37 @@
+// Package browser opens links in the web browser of the system
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// command returns the command that opens a URL: $BROWSER when it is set,
+// otherwise the opener of the operating system
+func command(url string) *exec.Cmd {
+	if browser := strings.TrimSpace(os.Getenv("BROWSER")); browser != "" {
+		return exec.Command("sh", "-c", browser+` "$1"`, "sh", url)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return exec.Command("xdg-open", url)
+	}
+}
+
+// Open opens a URL in the browser without waiting for the browser to close
+func Open(url string) error {
+	cmd := command(url)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %v", err)
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func bulkCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bulk",
+		Short: "Change all actions matching a filter query at once",
+		Long: `Change all actions matching a filter query at once, in one transaction.
+The affected actions are listed before anything changes; use --dry-run to
+only list them. See 'projector list --help' for the query language.`,
+	}
+
+	cmd.AddCommand(bulkDoneCmd())
+	cmd.AddCommand(bulkRetagCmd())
+	return cmd
+}
+
+func bulkDoneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "done",
+		Short: "Mark all open actions matching a filter query as done",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			query, _ := cmd.Flags().GetString("filter")
+
+			actions, ok := bulkActions(query)
+			if !ok {
+				return
+			}
+
+			var open []database.Action
+			for _, action := range actions {
+				if action.StatusName != "done" {
+					open = append(open, action)
+				}
+			}
+			if !previewBulk(cmd, open, i18n.T("BulkMarkDone", i18n.Data{"Count": len(open)})) {
+				return
+			}
+
+			if err := database.MarkActionsAsDone(database.GetDatabasePath(), actionIDs(open)); err != nil {
+				fmt.Println(i18n.T("FailedToMarkActionsDone", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("MarkedActionsDone", i18n.Data{"Count": len(open)}))
+		},
+	}
+
+	cmd.Flags().StringP("filter", "f", "", "Filter query selecting the actions (required)")
+	cmd.MarkFlagRequired("filter")
+	return cmd
+}
+
+func bulkRetagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retag",
+		Short: "Add and remove tags on all actions matching a filter query",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			query, _ := cmd.Flags().GetString("filter")
+			add, _ := cmd.Flags().GetStringSlice("add-tag")
+			remove, _ := cmd.Flags().GetStringSlice("remove-tag")
+
+			if len(add) == 0 && len(remove) == 0 {
+				fmt.Println(i18n.T("NothingToRetag"))
+				return
+			}
+
+			actions, ok := bulkActions(query)
+			if !ok {
+				return
+			}
+
+			var changes []string
+			if len(add) > 0 {
+				changes = append(changes, i18n.T("BulkAddTags", i18n.Data{"Tags": strings.Join(add, ",")}))
+			}
+			if len(remove) > 0 {
+				changes = append(changes, i18n.T("BulkRemoveTags", i18n.Data{"Tags": strings.Join(remove, ",")}))
+			}
+			if !previewBulk(cmd, actions, i18n.T("BulkRetag", i18n.Data{"Changes": strings.Join(changes, i18n.T("BulkAnd")), "Count": len(actions)})) {
+				return
+			}
+
+			if err := database.RetagActions(database.GetDatabasePath(), actionIDs(actions), add, remove); err != nil {
+				fmt.Println(i18n.T("FailedToRetagActions", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("RetaggedActions", i18n.Data{"Count": len(actions)}))
+		},
+	}
+
+	cmd.Flags().StringP("filter", "f", "", "Filter query selecting the actions (required)")
+	cmd.Flags().StringSlice("add-tag", nil, "Tag to add (repeatable)")
+	cmd.Flags().StringSlice("remove-tag", nil, "Tag to remove (repeatable)")
+	cmd.MarkFlagRequired("filter")
+	cmd.RegisterFlagCompletionFunc("add-tag", completeTagNames)
+	cmd.RegisterFlagCompletionFunc("remove-tag", completeTagNames)
+	return cmd
+}
+
+// bulkActions returns the actions matching a filter query. Like with
+// 'projector list', done actions only match when the query filters on status.
+func bulkActions(query string) ([]database.Action, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return nil, false
+	}
+
+	match, err := filter.Parse(query, time.Now())
+	if err != nil {
+		fmt.Println(i18n.T("InvalidQuery", i18n.Data{"Error": err}))
+		return nil, false
+	}
+
+	actions, err := database.GetAllActions(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return nil, false
+	}
+
+	var matching []database.Action
+	for _, action := range actions {
+		if !match.Uses("status") && action.StatusName == "done" {
+			continue
+		}
+		if match.Match(&action) {
+			matching = append(matching, action)
+		}
+	}
+	return matching, true
+}
+
+// previewBulk lists the actions a bulk operation affects and asks to confirm
+// the described change. It returns false when nothing should change: no
+// action matched, --dry-run is set or the operation was cancelled.
+func previewBulk(cmd *cobra.Command, actions []database.Action, description string) bool {
+	if len(actions) == 0 {
+		if !output.IsJSON() {
+			fmt.Println(i18n.T("NoActionsFound"))
+		}
+		return false
+	}
+
+	records := []actionRecord{}
+	table := output.Table{Headers: []string{"ID", "NAME", "PROJECT", "DUE", "STATUS", "TAGS"}}
+	for _, action := range actions {
+		record := newActionRecord(action)
+		records = append(records, record)
+		table.AddRow(fmt.Sprint(record.ID), record.Name, record.Project, displayDate(record.DueDate), statusLabel(record.Status), strings.Join(record.Tags, ","))
+	}
+	if err := output.Print(records, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+		return false
+	}
+
+	if isDryRun(cmd) {
+		if !output.IsJSON() {
+			fmt.Println(i18n.T("WouldChange", i18n.Data{"Change": description}))
+		}
+		return false
+	}
+	return confirm(cmd, strings.ToUpper(description[:1])+description[1:]+"?")
+}
+
+// actionIDs returns the IDs of actions
+func actionIDs(actions []database.Action) []uint {
+	ids := make([]uint, len(actions))
+	for i, action := range actions {
+		ids[i] = action.ID
+	}
+	return ids
+}
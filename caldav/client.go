@@ -0,0 +1,201 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to a single CalDAV task collection
+type Client struct {
+	collectionURL string
+	username      string
+	password      string
+	httpClient    *http.Client
+}
+
+// RemoteTodo is a VTODO resource stored on the CalDAV server
+type RemoteTodo struct {
+	Href string
+	ETag string
+	Todo Todo
+}
+
+// NewClient creates a new CalDAV client for the given collection URL
+func NewClient(collectionURL, username, password string) *Client {
+	if !strings.HasSuffix(collectionURL, "/") {
+		collectionURL += "/"
+	}
+
+	return &Client{
+		collectionURL: collectionURL,
+		username:      username,
+		password:      password,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// calendarQuery requests the ETag and data of every VTODO in the collection
+const calendarQuery = `<?xml version="1.0" encoding="utf-8" ?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <d:getetag />
+    <c:calendar-data />
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VTODO" />
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+// multistatus is the subset of a WebDAV multistatus response used by the client
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ETag         string `xml:"getetag"`
+				CalendarData string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// ListTodos retrieves all VTODO resources in the collection
+func (c *Client) ListTodos() ([]RemoteTodo, error) {
+	req, err := c.newRequest("REPORT", c.collectionURL, strings.NewReader(calendarQuery))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("unexpected response from CalDAV server: %s", resp.Status)
+	}
+
+	var result multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response from CalDAV server: %v", err)
+	}
+
+	var todos []RemoteTodo
+	for _, response := range result.Responses {
+		for _, propstat := range response.Propstat {
+			if propstat.Prop.CalendarData == "" {
+				continue
+			}
+
+			todo, ok := ParseTodo(propstat.Prop.CalendarData)
+			if !ok {
+				continue
+			}
+
+			todos = append(todos, RemoteTodo{
+				Href: c.resolve(response.Href),
+				ETag: propstat.Prop.ETag,
+				Todo: todo,
+			})
+		}
+	}
+
+	return todos, nil
+}
+
+// PutTodo stores a calendar object at href and returns its new ETag. An empty
+// etag creates a new resource, otherwise the existing resource must still match it.
+func (c *Client) PutTodo(href string, data []byte, etag string) (string, error) {
+	req, err := c.newRequest("PUT", href, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	if etag == "" {
+		req.Header.Set("If-None-Match", "*")
+	} else {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to store todo: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return resp.Header.Get("ETag"), nil
+	case http.StatusPreconditionFailed:
+		return "", fmt.Errorf("todo was changed on the server")
+	default:
+		return "", fmt.Errorf("unexpected response from CalDAV server: %s", resp.Status)
+	}
+}
+
+// DeleteTodo removes the resource at href
+func (c *Client) DeleteTodo(href, etag string) error {
+	req, err := c.newRequest("DELETE", href, nil)
+	if err != nil {
+		return err
+	}
+	if etag != "" {
+		req.Header.Set("If-Match", etag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete todo: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("unexpected response from CalDAV server: %s", resp.Status)
+	}
+}
+
+// TodoHref returns the resource URL used for a new todo with the given UID
+func (c *Client) TodoHref(uid string) string {
+	return c.collectionURL + url.PathEscape(uid) + ".ics"
+}
+
+// newRequest creates an authenticated request
+func (c *Client) newRequest(method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return req, nil
+}
+
+// resolve turns an href from a multistatus response into an absolute URL
+func (c *Client) resolve(href string) string {
+	base, err := url.Parse(c.collectionURL)
+	if err != nil {
+		return href
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
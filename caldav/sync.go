@@ -0,0 +1,274 @@
+package caldav
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/export"
+)
+
+// SyncSource is the sync_state source name used for CalDAV
+const SyncSource = "caldav"
+
+// Conflict resolution strategies for items changed on both sides
+const (
+	PreferRemote = "remote"
+	PreferLocal  = "local"
+)
+
+// Result summarizes the changes made by a sync run
+type Result struct {
+	Pushed    int
+	Pulled    int
+	Deleted   int
+	Conflicts int
+	Errors    []string
+	Warnings  []string // Todos pulled without their due date
+}
+
+// Sync performs a two-way synchronization between the local actions and the
+// CalDAV collection. Items changed on both sides since the last sync are
+// resolved according to prefer.
+func Sync(dbPath string, client *Client, prefer string) (*Result, error) {
+	if prefer != PreferRemote && prefer != PreferLocal {
+		return nil, fmt.Errorf("invalid conflict strategy: %s. Expected one of: remote, local", prefer)
+	}
+
+	actions, err := database.GetAllActions(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving actions: %v", err)
+	}
+
+	states, err := database.GetSyncStates(dbPath, SyncSource)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync state: %v", err)
+	}
+
+	remoteTodos, err := client.ListTodos()
+	if err != nil {
+		return nil, err
+	}
+
+	localByID := make(map[uint]database.Action)
+	for _, action := range actions {
+		localByID[action.ID] = action
+	}
+
+	remoteByHref := make(map[string]RemoteTodo)
+	for _, todo := range remoteTodos {
+		remoteByHref[todo.Href] = todo
+	}
+
+	result := &Result{}
+	s := &syncer{dbPath: dbPath, client: client, result: result}
+	synced := make(map[uint]bool)
+	seenHrefs := make(map[string]bool)
+
+	// Reconcile items that were synchronized before
+	for _, state := range states {
+		synced[state.ActionID] = true
+		seenHrefs[state.RemoteID] = true
+
+		action, hasLocal := localByID[state.ActionID]
+		remote, hasRemote := remoteByHref[state.RemoteID]
+
+		switch {
+		case !hasLocal && !hasRemote:
+			s.forget(state.ActionID)
+		case !hasLocal:
+			// Deleted locally
+			if err := client.DeleteTodo(remote.Href, remote.ETag); err != nil {
+				s.fail("delete remote %s: %v", remote.Href, err)
+				continue
+			}
+			s.forget(state.ActionID)
+			result.Deleted++
+		case !hasRemote:
+			// Deleted remotely, keep the local copy if it was changed since
+			if database.ActionHash(&action) != state.LocalHash {
+				s.push(action, state.RemoteID, "", uidFromState(state))
+				continue
+			}
+			if err := database.DeleteAction(dbPath, action.ID); err != nil {
+				s.fail("delete action %d: %v", action.ID, err)
+				continue
+			}
+			s.forget(state.ActionID)
+			result.Deleted++
+		default:
+			localChanged := database.ActionHash(&action) != state.LocalHash
+			remoteChanged := remote.ETag != state.ETag
+
+			if localChanged && remoteChanged {
+				result.Conflicts++
+				if prefer == PreferLocal {
+					remoteChanged = false
+				} else {
+					localChanged = false
+				}
+			}
+
+			switch {
+			case localChanged:
+				s.push(action, remote.Href, remote.ETag, remote.Todo.UID)
+			case remoteChanged:
+				s.pull(&action, remote)
+			}
+		}
+	}
+
+	// Push actions that were never synchronized
+	for _, action := range actions {
+		if synced[action.ID] {
+			continue
+		}
+		uid := fmt.Sprintf("action-%d@projector", action.ID)
+		s.push(action, client.TodoHref(uid), "", uid)
+	}
+
+	// Create actions for todos that only exist remotely
+	for _, remote := range remoteTodos {
+		if seenHrefs[remote.Href] {
+			continue
+		}
+		s.create(remote)
+	}
+
+	return result, nil
+}
+
+// syncer applies the individual sync operations and records their outcome
+type syncer struct {
+	dbPath string
+	client *Client
+	result *Result
+}
+
+// push uploads the local action to href and records the new sync state
+func (s *syncer) push(action database.Action, href, etag, uid string) {
+	var data bytes.Buffer
+	if err := export.WriteActionICal(&data, action, uid); err != nil {
+		s.fail("encode action %d: %v", action.ID, err)
+		return
+	}
+
+	newETag, err := s.client.PutTodo(href, data.Bytes(), etag)
+	if err != nil {
+		s.fail("push action %d: %v", action.ID, err)
+		return
+	}
+
+	s.remember(action.ID, href, newETag)
+	s.result.Pushed++
+}
+
+// pull applies the remote todo to the local action and records the new sync state
+func (s *syncer) pull(action *database.Action, remote RemoteTodo) {
+	update := database.ActionUpdate{}
+	if remote.Todo.Summary != action.Name {
+		update.Name = &remote.Todo.Summary
+	}
+	if remote.Todo.Description != action.Note.String {
+		update.Note = &remote.Todo.Description
+	}
+	if remote.Todo.Due != database.StoredDate(action.DueDate.String) {
+		if due := s.due(remote); due != "" || remote.Todo.Due == "" {
+			update.DueDate = &due
+		}
+	}
+
+	if err := database.UpdateAction(s.dbPath, action.ID, update); err != nil {
+		s.fail("pull action %d: %v", action.ID, err)
+		return
+	}
+
+	if remote.Todo.Completed && action.StatusName != "done" {
+		if err := database.MarkActionAsDone(s.dbPath, action.ID); err != nil {
+			s.fail("complete action %d: %v", action.ID, err)
+			return
+		}
+	} else if !remote.Todo.Completed && action.StatusName == "done" {
+		statusID, err := database.DefaultStatusID(s.dbPath)
+		if err != nil {
+			s.fail("reopen action %d: %v", action.ID, err)
+			return
+		}
+		if err := database.UpdateAction(s.dbPath, action.ID, database.ActionUpdate{StatusID: &statusID}); err != nil {
+			s.fail("reopen action %d: %v", action.ID, err)
+			return
+		}
+	}
+
+	s.remember(action.ID, remote.Href, remote.ETag)
+	s.result.Pulled++
+}
+
+// create adds a local action for a todo that only exists remotely
+func (s *syncer) create(remote RemoteTodo) {
+	statusID := uint(2)
+	if !remote.Todo.Completed {
+		var err error
+		if statusID, err = database.DefaultStatusID(s.dbPath); err != nil {
+			s.fail("import %s: %v", remote.Href, err)
+			return
+		}
+	}
+
+	actionID, err := database.CreateAction(s.dbPath, remote.Todo.Summary, remote.Todo.Description, nil, s.due(remote), statusID, "", 0, "", "", "", nil)
+	if err != nil {
+		s.fail("import %s: %v", remote.Href, err)
+		return
+	}
+
+	s.remember(actionID, remote.Href, remote.ETag)
+	s.result.Pulled++
+}
+
+// due returns the due date of a remote todo. Due dates that cannot be
+// stored, such as the past due dates of overdue todos, are dropped with a
+// warning, as the importers do; pulling then keeps the local due date.
+func (s *syncer) due(remote RemoteTodo) string {
+	if _, err := database.ValidateDate(remote.Todo.Due); err != nil {
+		s.result.Warnings = append(s.result.Warnings, fmt.Sprintf("todo %q: dropped due date: %v", remote.Todo.Summary, err))
+		return ""
+	}
+	return remote.Todo.Due
+}
+
+// remember stores the sync state of an action after a successful operation
+func (s *syncer) remember(actionID uint, href, etag string) {
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil || action == nil {
+		s.fail("reload action %d: %v", actionID, err)
+		return
+	}
+
+	err = database.SaveSyncState(s.dbPath, database.SyncState{
+		Source:    SyncSource,
+		ActionID:  actionID,
+		RemoteID:  href,
+		ETag:      etag,
+		LocalHash: database.ActionHash(action),
+	})
+	if err != nil {
+		s.fail("save sync state of action %d: %v", actionID, err)
+	}
+}
+
+// forget removes the sync state of an action
+func (s *syncer) forget(actionID uint) {
+	if err := database.DeleteSyncState(s.dbPath, SyncSource, actionID); err != nil {
+		s.fail("forget action %d: %v", actionID, err)
+	}
+}
+
+// fail records a non-fatal error
+func (s *syncer) fail(format string, args ...interface{}) {
+	s.result.Errors = append(s.result.Errors, fmt.Sprintf(format, args...))
+}
+
+// uidFromState returns the UID used when recreating a remotely deleted todo
+func uidFromState(state database.SyncState) string {
+	return fmt.Sprintf("action-%d@projector", state.ActionID)
+}
@@ -0,0 +1,108 @@
+package caldav
+
+import (
+	"strings"
+)
+
+// Todo holds the VTODO properties that are synchronized with actions
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Due         string // Due date formatted as YYYY-MM-DD
+	Completed   bool
+}
+
+// ParseTodo extracts the first VTODO from an iCalendar object
+func ParseTodo(data string) (Todo, bool) {
+	var todo Todo
+	inTodo := false
+	found := false
+
+	for _, line := range unfoldLines(data) {
+		name, value := splitContentLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VTODO" && !found:
+			inTodo = true
+			continue
+		case name == "END" && value == "VTODO" && inTodo:
+			inTodo = false
+			found = true
+			continue
+		}
+
+		if !inTodo {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			todo.UID = value
+		case "SUMMARY":
+			todo.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			todo.Description = unescapeText(value)
+		case "DUE":
+			todo.Due = parseDateValue(value)
+		case "STATUS":
+			todo.Completed = value == "COMPLETED"
+		case "COMPLETED":
+			todo.Completed = true
+		}
+	}
+
+	return todo, found
+}
+
+// unfoldLines splits iCalendar data into unfolded content lines
+func unfoldLines(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitContentLine splits a content line into its name and value, dropping any parameters
+func splitContentLine(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return strings.ToUpper(line), ""
+	}
+
+	name, value := line[:colon], line[colon+1:]
+	if semicolon := strings.Index(name, ";"); semicolon >= 0 {
+		name = name[:semicolon]
+	}
+
+	return strings.ToUpper(name), value
+}
+
+// parseDateValue converts a DATE or DATE-TIME value into a YYYY-MM-DD date
+func parseDateValue(value string) string {
+	if len(value) < 8 {
+		return ""
+	}
+	return value[0:4] + "-" + value[4:6] + "-" + value[6:8]
+}
+
+// unescapeText reverses the TEXT escaping described in RFC 5545 section 3.3.11
+func unescapeText(text string) string {
+	replacer := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+	return replacer.Replace(text)
+}
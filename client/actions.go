@@ -0,0 +1,125 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// NewAction is an action to create. QuickAdd is a line in the syntax of
+// `projector add`, the other fields take precedence over it.
+type NewAction struct {
+	QuickAdd       string   `json:"quick_add"`
+	ProjectID      *uint    `json:"project_id,omitempty"`
+	DueDate        string   `json:"due_date,omitempty"`
+	Location       string   `json:"location,omitempty"`
+	Energy         string   `json:"energy,omitempty"`
+	Reminders      []string `json:"reminders,omitempty"`
+	AllowDuplicate bool     `json:"allow_duplicate,omitempty"`
+}
+
+// ActionUpdate holds the changes to an action. Nil fields are left as they
+// are; Version must be the version of the action the changes are based on.
+type ActionUpdate struct {
+	Name       *string   `json:"name,omitempty"`
+	Note       *string   `json:"note,omitempty"`
+	ProjectID  *uint     `json:"project_id,omitempty"`
+	DueDate    *string   `json:"due_date,omitempty"`
+	StartDate  *string   `json:"start_date,omitempty"`
+	StatusID   *uint     `json:"status_id,omitempty"`
+	AssigneeID *uint     `json:"assignee_id,omitempty"`
+	Flagged    *bool     `json:"flagged,omitempty"`
+	Location   *string   `json:"location,omitempty"`
+	Energy     *string   `json:"energy,omitempty"`
+	WaitingOn  *string   `json:"waiting_on,omitempty"`
+	FollowUp   *string   `json:"follow_up,omitempty"`
+	Reminders  *[]string `json:"reminders,omitempty"` // An empty list removes the reminders
+	Version    uint      `json:"version"`
+}
+
+// DuplicateError is returned when the server refuses an action because an
+// open action with the same name exists in the project
+type DuplicateError struct {
+	ActionID uint
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("already exists as open action %d", e.ActionID)
+}
+
+// ListActions retrieves all actions. The query takes the parameters of
+// GET /api/v1/actions, such as q, saved and include_deferred.
+func (c *Client) ListActions(query url.Values) ([]Action, error) {
+	page, err := listPage[Action](c, "/api/v1/actions", "actions", query, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListActionsPage retrieves a page of the actions, counting from 1
+func (c *Client) ListActionsPage(query url.Values, page, perPage int) (*Page[Action], error) {
+	return listPage[Action](c, "/api/v1/actions", "actions", query, page, perPage)
+}
+
+// GetAction retrieves an action
+func (c *Client) GetAction(actionID uint) (*Action, error) {
+	var response struct {
+		Action Action `json:"action"`
+	}
+	if err := c.Call("GET", fmt.Sprintf("/api/v1/actions/%d", actionID), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Action, nil
+}
+
+// CreateAction creates an action and returns it as created
+func (c *Client) CreateAction(action NewAction) (*Action, error) {
+	var response struct {
+		Action Action `json:"action"`
+	}
+	if err := c.Call("PUT", "/api/v1/actions", action, &response); err != nil {
+		return nil, err
+	}
+	return &response.Action, nil
+}
+
+// UpdateAction changes an action and returns it as updated. A change based on
+// an outdated version fails with an *APIError with status 409.
+func (c *Client) UpdateAction(actionID uint, update ActionUpdate) (*Action, error) {
+	var response struct {
+		Action Action `json:"action"`
+	}
+	if err := c.Call("PATCH", fmt.Sprintf("/api/v1/actions/%d", actionID), update, &response); err != nil {
+		return nil, err
+	}
+	return &response.Action, nil
+}
+
+// CompleteAction marks an action as done
+func (c *Client) CompleteAction(actionID uint) error {
+	body := map[string]string{"action": "done"}
+	return c.Call("PUT", fmt.Sprintf("/api/v1/actions/%d", actionID), body, &struct{}{})
+}
+
+// SkippedOccurrence is an occurrence of a repeating action skipped with
+// SkipAction, and the action as it moved on to its next due date
+type SkippedOccurrence struct {
+	SkippedDate string `json:"skipped_date"`
+	NextDueDate string `json:"next_due_date"`
+	Action      Action `json:"action"`
+}
+
+// SkipAction skips the open occurrence of a repeating action without
+// completing it
+func (c *Client) SkipAction(actionID uint) (*SkippedOccurrence, error) {
+	var response SkippedOccurrence
+	if err := c.Call("POST", fmt.Sprintf("/api/v1/actions/%d/skip", actionID), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeleteAction moves an action to the trash
+func (c *Client) DeleteAction(actionID uint) error {
+	return c.Call("DELETE", fmt.Sprintf("/api/v1/actions/%d", actionID), nil, &struct{}{})
+}
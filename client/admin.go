@@ -0,0 +1,44 @@
+package client
+
+import (
+	"fmt"
+)
+
+// ListUsers retrieves all user accounts of the server
+func (c *Client) ListUsers() ([]User, error) {
+	var response struct {
+		Users []User `json:"users"`
+	}
+	if err := c.Call("GET", "/api/v1/admin/users", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Users, nil
+}
+
+// SetUserDisabled disables or re-enables a user account on the server
+func (c *Client) SetUserDisabled(userID uint, disabled bool) error {
+	body := map[string]bool{"disabled": disabled}
+	return c.Call("PATCH", fmt.Sprintf("/api/admin/users/%d", userID), body, &struct{}{})
+}
+
+// GetStats retrieves the database statistics of the server
+func (c *Client) GetStats() (*Stats, error) {
+	var response struct {
+		Stats Stats `json:"stats"`
+	}
+	if err := c.Call("GET", "/api/admin/stats", nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Stats, nil
+}
+
+// Backup makes the server back up its database, returning the backup path on the server
+func (c *Client) Backup() (string, error) {
+	var response struct {
+		Path string `json:"path"`
+	}
+	if err := c.Call("POST", "/api/admin/backup", nil, &response); err != nil {
+		return "", err
+	}
+	return response.Path, nil
+}
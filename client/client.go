@@ -0,0 +1,215 @@
+// Package client is a Go client for the projector HTTP API. It wraps the
+// /api/v1 endpoints in typed methods, authenticates with an API token and
+// retries requests that are safe to send again, so that scripts and
+// integrations do not need to write the HTTP calls themselves.
+//
+//	c := client.NewClient("http://localhost:8080", token)
+//	actions, err := c.ListActions(url.Values{"q": {"due<=+7d"}})
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRetries is how often a request that failed to reach the server, or
+// that a proxy answered with 502, 503 or 504, is sent again
+const DefaultRetries = 2
+
+// retryDelay is the wait before the first retry, it doubles for every next one
+const retryDelay = 250 * time.Millisecond
+
+// Client talks to the API of a projector server
+type Client struct {
+	baseURL    string
+	token      string
+	retries    int
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the projector API at baseURL, e.g.
+// http://host:8080. The token is only needed when the server has user accounts.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		token:      token,
+		retries:    DefaultRetries,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// BaseURL returns the URL of the server the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// SetToken sets the API token sent with every request
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetRetries sets how often a failed request is sent again, zero disables retries
+func (c *Client) SetRetries(retries int) {
+	c.retries = retries
+}
+
+// SetHTTPClient replaces the HTTP client, for example to change the timeout
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// APIError is a response from the server with an error status
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("unexpected response from server: %s: %s", e.Status, e.Message)
+}
+
+// Login exchanges a username and password for an API token, which the
+// client uses from then on
+func (c *Client) Login(username, password string) (string, error) {
+	body := map[string]string{"username": username, "password": password}
+	var response struct {
+		Token string `json:"token"`
+	}
+	if err := c.Call("POST", "/api/login", body, &response); err != nil {
+		return "", err
+	}
+	c.token = response.Token
+	return response.Token, nil
+}
+
+// Call sends a request with an optional JSON body to an API endpoint and
+// decodes the JSON response into v
+func (c *Client) Call(method, path string, body interface{}, v interface{}) error {
+	resp, err := c.send(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return DecodeResponse(resp, v)
+}
+
+// send sends a request with an optional JSON body
+func (c *Client) send(method, path string, body interface{}) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %v", c.baseURL, err)
+	}
+	return resp, nil
+}
+
+// Do sends a request, authenticating with the token when one is set. Reads
+// and requests with an Idempotency-Key header are retried when the server
+// cannot be reached or is unavailable behind a proxy, other requests could be
+// applied twice and are sent once.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	retries := c.retries
+	if !retryable(req) {
+		retries = 0
+	}
+
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := c.httpClient.Do(req)
+		if attempt >= retries || !unavailable(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryable reports whether sending a request twice does no harm
+func retryable(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// unavailable reports whether a request failed to reach the server, or a
+// proxy in front of it reported it unavailable
+func unavailable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// DecodeResponse decodes a successful JSON response into v. An error status
+// is returned as an *APIError, or a *DuplicateError for an action refused as
+// a duplicate.
+func DecodeResponse(resp *http.Response, v interface{}) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		// JSON errors carry a message, and the existing action for duplicates
+		var refused struct {
+			Message  string `json:"message"`
+			Error    string `json:"error"`
+			ActionID uint   `json:"action_id"`
+		}
+		if json.Unmarshal(message, &refused) == nil {
+			if resp.StatusCode == http.StatusConflict && refused.ActionID != 0 {
+				return &DuplicateError{ActionID: refused.ActionID}
+			}
+			switch {
+			case refused.Message != "":
+				message = []byte(refused.Message)
+			case refused.Error != "":
+				message = []byte(refused.Error)
+			}
+		}
+		return &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(message))}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from server: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/joelgrimberg/projector/wire"
+)
+
+// ImportActions imports a todo.txt or Taskwarrior file on the server, format
+// being todotxt or taskwarrior. The server streams its progress, which is
+// passed to progress when it is not nil. Rows that failed are listed in the
+// result rather than returned as an error.
+func (c *Client) ImportActions(format string, r io.Reader, progress func(done, total int)) (*ImportResult, error) {
+	req, err := http.NewRequest("POST", c.baseURL+"/api/import?format="+url.QueryEscape(format), r)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %v", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var result ImportResult
+		if err := DecodeResponse(resp, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	// Events are an event line and a data line, separated by blank lines
+	var event string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			switch event {
+			case "progress":
+				var update wire.ImportProgress
+				if err := json.Unmarshal(data, &update); err == nil && progress != nil {
+					progress(update.Done, update.Total)
+				}
+			case "result":
+				var result ImportResult
+				if err := json.Unmarshal(data, &result); err != nil {
+					return nil, fmt.Errorf("invalid response from server: %v", err)
+				}
+				return &result, nil
+			case "error":
+				var failed struct {
+					Error string `json:"error"`
+				}
+				json.Unmarshal(data, &failed)
+				return nil, fmt.Errorf("import failed on the server: %s", failed.Error)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the import progress: %v", err)
+	}
+	return nil, fmt.Errorf("the server closed the connection before the import finished")
+}
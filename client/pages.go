@@ -0,0 +1,66 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page is a page of a list, with the length of the whole list and whether
+// there is a page after it
+type Page[T any] struct {
+	Items   []T
+	Total   int
+	Page    int
+	PerPage int
+	HasNext bool
+}
+
+// listPage retrieves a page of the list at path, whose items the response
+// holds under key. A page of zero retrieves the whole list.
+func listPage[T any](c *Client, path, key string, query url.Values, page, perPage int) (*Page[T], error) {
+	params := url.Values{}
+	for name, values := range query {
+		params[name] = values
+	}
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+		params.Set("per_page", strconv.Itoa(perPage))
+	}
+
+	resp, err := c.send("GET", path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response map[string]json.RawMessage
+	if err := DecodeResponse(resp, &response); err != nil {
+		return nil, err
+	}
+	var items []T
+	if err := json.Unmarshal(response[key], &items); err != nil {
+		return nil, fmt.Errorf("invalid response from server: %v", err)
+	}
+
+	result := &Page[T]{Items: items, Page: page, PerPage: perPage, HasNext: hasNextLink(resp)}
+	if total := resp.Header.Get("X-Total-Count"); total != "" {
+		if result.Total, err = strconv.Atoi(total); err != nil {
+			return nil, fmt.Errorf("invalid X-Total-Count from server: %s", total)
+		}
+	}
+	return result, nil
+}
+
+// hasNextLink reports whether the Link header of a response has a next page
+func hasNextLink(resp *http.Response) bool {
+	for _, link := range strings.Split(resp.Header.Get("Link"), ",") {
+		if strings.Contains(link, `rel="next"`) {
+			return true
+		}
+	}
+	return false
+}
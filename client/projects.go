@@ -0,0 +1,49 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ListProjects retrieves all projects, with archived ones when
+// include_archived is set in the query
+func (c *Client) ListProjects(query url.Values) ([]Project, error) {
+	page, err := listPage[Project](c, "/api/v1/projects", "projects", query, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListProjectsPage retrieves a page of the projects, counting from 1
+func (c *Client) ListProjectsPage(query url.Values, page, perPage int) (*Page[Project], error) {
+	return listPage[Project](c, "/api/v1/projects", "projects", query, page, perPage)
+}
+
+// GetProject retrieves a project
+func (c *Client) GetProject(projectID uint) (*Project, error) {
+	var response struct {
+		Project Project `json:"project"`
+	}
+	if err := c.Call("GET", fmt.Sprintf("/api/v1/projects/%d", projectID), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Project, nil
+}
+
+// CreateProject creates a project with an optional YYYY-MM-DD due date
+func (c *Client) CreateProject(name, dueDate string) (*Project, error) {
+	body := map[string]string{"name": name, "due_date": dueDate}
+	var response struct {
+		Project Project `json:"project"`
+	}
+	if err := c.Call("PUT", "/api/v1/projects", body, &response); err != nil {
+		return nil, err
+	}
+	return &response.Project, nil
+}
+
+// DeleteProject moves a project with its actions to the trash
+func (c *Client) DeleteProject(projectID uint) error {
+	return c.Call("DELETE", fmt.Sprintf("/api/v1/projects/%d", projectID), nil, &struct{}{})
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"net/url"
+)
+
+// GetChanges retrieves the changes made on the server at or after since, for
+// remote sync
+func (c *Client) GetChanges(since string) (*Changes, error) {
+	var response struct {
+		Changes Changes `json:"changes"`
+	}
+	if err := c.Call("GET", "/api/sync?since="+url.QueryEscape(since), nil, &response); err != nil {
+		return nil, err
+	}
+	return &response.Changes, nil
+}
+
+// PushChanges sends local changes to the server to be applied there
+func (c *Client) PushChanges(changes *Changes) (*ApplyResult, error) {
+	var response struct {
+		Result ApplyResult `json:"result"`
+	}
+	if err := c.Call("POST", "/api/sync", changes, &response); err != nil {
+		return nil, err
+	}
+	return &response.Result, nil
+}
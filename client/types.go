@@ -0,0 +1,27 @@
+package client
+
+import "github.com/joelgrimberg/projector/wire"
+
+// Action is an action as returned by the server
+type Action = wire.Action
+
+// Project is a project as returned by the server
+type Project = wire.Project
+
+// User is a user account as returned by the server
+type User = wire.User
+
+// Stats summarizes the contents of the server database
+type Stats = wire.Stats
+
+// ImportResult summarizes an import, with the rows that failed and why
+type ImportResult = wire.ImportResult
+
+// ImportFailure is a row of an import file that was not imported
+type ImportFailure = wire.ImportFailure
+
+// Changes holds everything that changed since a point in time, for remote sync
+type Changes = wire.Changes
+
+// ApplyResult summarizes the outcome of applying Changes
+type ApplyResult = wire.ApplyResult
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joelgrimberg/projector/database"
+
+	"github.com/spf13/cobra"
+)
+
+func completionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate a shell completion script",
+		Long: `Generate a shell completion script. Action IDs, project names, tags and
+usernames are completed from the database.
+
+  bash:       source <(projector completion bash)
+  zsh:        projector completion zsh > "${fpath[1]}/_projector"
+  fish:       projector completion fish > ~/.config/fish/completions/projector.fish
+  powershell: projector completion powershell | Out-String | Invoke-Expression`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			root := cmd.Root()
+
+			var err error
+			switch args[0] {
+			case "bash":
+				err = root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = root.GenZshCompletion(os.Stdout)
+			case "fish":
+				err = root.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				err = root.GenPowerShellCompletionWithDesc(os.Stdout)
+			default:
+				fmt.Printf("❌ Unknown shell: %s (expected bash, zsh, fish or powershell)\n", args[0])
+				return
+			}
+			if err != nil {
+				fmt.Printf("❌ Failed to generate completion: %v\n", err)
+			}
+		},
+	}
+}
+
+// completeActionIDs completes the IDs of open actions, described by their names
+func completeActionIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	actions, err := database.GetAllActions(database.GetDatabasePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, action := range actions {
+		if action.StatusName != "done" {
+			completions = append(completions, fmt.Sprintf("%d\t%s", action.ID, action.Name))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectNames completes project names
+func completeProjectNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	projects, ok := completionProjects()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, project := range projects {
+		completions = append(completions, project.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectIDs completes project IDs, described by their names
+func completeProjectIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	projects, ok := completionProjects()
+	if !ok {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, project := range projects {
+		completions = append(completions, fmt.Sprintf("%d\t%s", project.ID, project.Name))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagNames completes tag names
+func completeTagNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	tags, err := database.GetAllTags(database.GetDatabasePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, tag := range tags {
+		completions = append(completions, tag.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeUsernames completes the usernames of local user accounts
+func completeUsernames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	users, err := database.GetAllUsers(database.GetDatabasePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, user := range users {
+		completions = append(completions, user.Username)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplateNames completes the names of project templates
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	templates, err := database.GetAllTemplates(database.GetDatabasePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, template := range templates {
+		completions = append(completions, template.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFilterNames completes the names of saved filters
+func completeFilterNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	filters, err := database.GetAllSavedFilters(database.GetDatabasePath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var completions []string
+	for _, filter := range filters {
+		completions = append(completions, filter.Name)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionProjects retrieves the projects to complete, leaving out archived
+// ones, reporting false on errors
+func completionProjects() ([]database.Project, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		return nil, true
+	}
+
+	projects, err := database.GetAllProjects(database.GetDatabasePath())
+	if err != nil {
+		return nil, false
+	}
+
+	var active []database.Project
+	for _, project := range projects {
+		if !project.IsArchived() {
+			active = append(active, project)
+		}
+	}
+	return active, true
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const ConfigName = "config.json"
+
+// Config holds the user configuration
+type Config struct {
+	Notifications Notifications `json:"notifications"`
+	Maintenance   Maintenance   `json:"maintenance"`
+	Escalations   []Escalation  `json:"escalations"`
+	Trash         Trash         `json:"trash"`
+	Archive       Archive       `json:"archive"`
+	Display       Display       `json:"display"`
+	Workflow      Workflow      `json:"workflow"`
+	Mail          Mail          `json:"mail"`
+	Remote        Remote        `json:"remote"`
+	Server        Server        `json:"server"`
+	Hooks         []Hook        `json:"hooks"`
+	Rules         []ActionRule  `json:"rules"`
+}
+
+// ActionRule changes actions that match a filter expression as they are
+// created or updated, such as setting the due date of actions tagged bill to
+// the end of the month
+type ActionRule struct {
+	// Name identifies the rule in the output of projector rules test
+	Name string `json:"name"`
+	// On is created or updated, the rule runs on both when empty. On an
+	// update the rule only runs when the action starts to match.
+	On string `json:"on,omitempty"`
+	// If is a filter expression such as tag:bill
+	If       string `json:"if"`
+	Due      string `json:"due,omitempty"`      // Sets the due date, such as end-of-month or +3d
+	Priority string `json:"priority,omitempty"` // Sets the priority, such as high
+	Tag      string `json:"tag,omitempty"`      // Adds a tag
+	Project  string `json:"project,omitempty"`  // Moves the action to a project
+	Flag     bool   `json:"flag,omitempty"`     // Flags the action for today
+}
+
+// DefaultHookTimeout is how long a hook command may run, in seconds, unless
+// configured otherwise
+const DefaultHookTimeout = 10
+
+// Hook runs a shell command on an event, such as action.created, with the
+// event as JSON on stdin
+type Hook struct {
+	// Event is the event the command runs on, * for every event
+	Event   string `json:"event"`
+	Command string `json:"command"`
+	// TimeoutSeconds is how long the command may run before it is killed
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Timeout returns the configured time the command may run, or the default
+func (h Hook) Timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return DefaultHookTimeout * time.Second
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// Limits of the API server unless configured otherwise
+const (
+	DefaultRequestTimeoutSeconds = 30
+	DefaultMaxBodyMB             = 10
+)
+
+// Server configures the API server
+type Server struct {
+	// CrashReportDir is the directory a report is written to when a request
+	// panics, none are written by default
+	CrashReportDir string `json:"crash_report_dir,omitempty"`
+	// RequestTimeoutSeconds is how long a request may take, backups and
+	// remote sync get longer
+	RequestTimeoutSeconds int `json:"request_timeout_seconds,omitempty"`
+	// MaxBodyMB is the largest request body the server accepts
+	MaxBodyMB int `json:"max_body_mb,omitempty"`
+}
+
+// RequestTimeout returns the configured request timeout, or the default
+func (s Server) RequestTimeout() time.Duration {
+	if s.RequestTimeoutSeconds <= 0 {
+		return DefaultRequestTimeoutSeconds * time.Second
+	}
+	return time.Duration(s.RequestTimeoutSeconds) * time.Second
+}
+
+// MaxBodySize returns the configured largest request body in bytes, or the default
+func (s Server) MaxBodySize() int64 {
+	if s.MaxBodyMB <= 0 {
+		return DefaultMaxBodyMB << 20
+	}
+	return int64(s.MaxBodyMB) << 20
+}
+
+// Remote configures the API server that add, list, done and delete talk to
+// instead of the local database, so that several machines share one server
+type Remote struct {
+	URL   string `json:"url"`
+	Token string `json:"token,omitempty"` // API token for a server with user accounts
+}
+
+// DefaultMailInterval is how often the server checks the mailbox, in minutes,
+// unless configured otherwise
+const DefaultMailInterval = 5
+
+// Mail configures an IMAP mailbox whose unread emails become actions, so
+// that emails can be forwarded to projector. Processed emails are marked read.
+type Mail struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"` // 993 with implicit TLS by default, other ports use STARTTLS
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Folder is the mailbox folder to check, INBOX by default
+	Folder string `json:"folder,omitempty"`
+	// Project is the project of the created actions, none by default
+	Project string `json:"project,omitempty"`
+	// IntervalMinutes is how often the server checks the folder
+	IntervalMinutes int `json:"interval_minutes,omitempty"`
+}
+
+// Enabled reports whether a mailbox is configured
+func (m Mail) Enabled() bool {
+	return m.Host != ""
+}
+
+// Interval returns the configured interval between mailbox checks, or the default
+func (m Mail) Interval() time.Duration {
+	if m.IntervalMinutes <= 0 {
+		return DefaultMailInterval * time.Minute
+	}
+	return time.Duration(m.IntervalMinutes) * time.Minute
+}
+
+// Workflow configures the status of new actions and the status changes that
+// are allowed. Statuses it names are created by 'projector migrate'.
+type Workflow struct {
+	// DefaultStatus is the status new actions get, todo by default
+	DefaultStatus string `json:"default_status"`
+	// Transitions lists for a status the statuses an action may change to.
+	// A status without an entry may change to any status.
+	Transitions map[string][]string `json:"transitions"`
+	// Limits caps the number of actions in a status, such as
+	// {"in-progress": 3}
+	Limits map[string]int `json:"limits"`
+	// ProjectLimits caps the number of actions in a status per project, by
+	// project name, such as {"Website": {"in-progress": 2}}
+	ProjectLimits map[string]map[string]int `json:"project_limits"`
+}
+
+// DefaultTrashRetentionDays is how long deleted actions and projects are kept
+// in the trash unless configured otherwise
+const DefaultTrashRetentionDays = 30
+
+// Trash configures how long deleted actions and projects can be restored
+type Trash struct {
+	// RetentionDays is the number of days the server keeps deleted items
+	RetentionDays int `json:"retention_days"`
+}
+
+// Retention returns the configured retention in days, or the default
+func (t Trash) Retention() int {
+	if t.RetentionDays <= 0 {
+		return DefaultTrashRetentionDays
+	}
+	return t.RetentionDays
+}
+
+// Archive configures when done actions move out of the action table
+type Archive struct {
+	// DoneAfterDays is the number of days after which the server archives
+	// done actions. Done actions are never archived automatically when zero.
+	DoneAfterDays int `json:"done_after_days"`
+}
+
+// Display configures how dates and times are shown in command output, the
+// TUI and digests. JSON output and the API always use ISO dates.
+type Display struct {
+	// DateFormat is iso (2026-10-15), european (15-10-2026), us (10/15/2026)
+	// or relative, which shows dates within a week as "tomorrow" or "in 3
+	// days". Without it the format follows the locale, ISO without one.
+	DateFormat string `json:"date_format,omitempty"`
+	// RelativeDays shows dates up to this many days from today relatively in
+	// any date format, 7 for the relative format unless set
+	RelativeDays int `json:"relative_days,omitempty"`
+	// TimeFormat is 24h or 12h, following the locale when empty
+	TimeFormat string `json:"time_format,omitempty"`
+	// Locale, such as en_US or nl_NL, picks the date and time format when
+	// they are not set, and the language when that is not set
+	Locale string `json:"locale,omitempty"`
+	// Language of messages, en or nl. Without it the language follows the
+	// locale, then the LC_ALL, LC_MESSAGES and LANG environment variables.
+	Language string `json:"language,omitempty"`
+	// Plain selects screen-reader friendly output, like the --plain flag
+	Plain bool `json:"plain,omitempty"`
+}
+
+// Escalation acts on open actions that are overdue by a number of days,
+// optionally limited to a single project. The server evaluates escalations
+// every hour.
+type Escalation struct {
+	// Name identifies the escalation, it acts on an action once per due date
+	Name     string `json:"name"`
+	Days     int    `json:"days"`
+	Project  string `json:"project,omitempty"`
+	Priority string `json:"priority,omitempty"` // Sets the priority, such as high
+	Tag      string `json:"tag,omitempty"`      // Adds a tag
+	Channel  string `json:"channel,omitempty"`  // Notifies a notification channel
+}
+
+// Maintenance configures database maintenance by the server
+type Maintenance struct {
+	// Weekly makes the server vacuum and analyze the database once a week
+	Weekly bool `json:"weekly"`
+}
+
+// Notifications configures where notifications are sent
+type Notifications struct {
+	// Channels are the named notification sinks
+	Channels map[string]Channel `json:"channels"`
+	// Rules route notification events to channels
+	Rules []Rule `json:"rules"`
+	// DigestTime is the local time (HH:MM) the daily digest is sent by the server
+	DigestTime string `json:"digest_time"`
+	// Preferences apply to every rule that is not limited to a user in Users
+	Preferences
+	// Users holds preferences by username, for the rules limited to that user
+	Users map[string]Preferences `json:"users,omitempty"`
+}
+
+// Preferences limit when and which notifications are sent
+type Preferences struct {
+	// QuietHours holds notifications back during a period of every day
+	QuietHours *QuietHours `json:"quiet_hours,omitempty"`
+	// DigestOnly sends the daily digest and nothing else
+	DigestOnly bool `json:"digest_only,omitempty"`
+}
+
+// QuietHours is a period of every day (HH:MM, local time) in which no
+// notifications are sent. It wraps past midnight when End is before Start,
+// such as 22:00 to 07:00.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Channel configures a single notification sink
+type Channel struct {
+	Type       string `json:"type"`
+	WebhookURL string `json:"webhook_url,omitempty"`
+
+	// Disabled keeps the channel configured, but no rule sends to it
+	Disabled bool `json:"disabled,omitempty"`
+
+	// SMTP settings for email channels
+	SMTPHost string   `json:"smtp_host,omitempty"`
+	SMTPPort int      `json:"smtp_port,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       []string `json:"to,omitempty"`
+
+	// Push settings for ntfy and Pushover channels
+	Server  string `json:"server,omitempty"`
+	Topic   string `json:"topic,omitempty"`
+	Token   string `json:"token,omitempty"`
+	UserKey string `json:"user_key,omitempty"`
+}
+
+// Rule sends an event to a channel, optionally limited to a single project
+// or to the actions assigned to a single user
+type Rule struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel"`
+	Project string `json:"project,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
+// GetConfigPath returns the config file path in ~/.config/projector/
+func GetConfigPath() string {
+	// Check for environment variable override
+	if envPath := os.Getenv("PROJECTOR_CONFIG_PATH"); envPath != "" {
+		return envPath
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		// Fallback to current directory
+		return ConfigName
+	}
+
+	return filepath.Join(homeDir, ".config", "projector", ConfigName)
+}
+
+// Load reads the config file. A missing file results in an empty config.
+func Load() (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(GetConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", GetConfigPath(), err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joelgrimberg/projector/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+// isDryRun reports whether the global --dry-run flag is set, in which case
+// destructive commands only print what they would affect
+func isDryRun(cmd *cobra.Command) bool {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	return dryRun
+}
+
+// confirm asks whether to go ahead with a destructive operation, unless the
+// global --yes flag is set. The answer is read as a line from stdin, so
+// scripts can pipe it or pass --yes.
+func confirm(cmd *cobra.Command, question string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+
+	fmt.Print(i18n.T("ConfirmPrompt", i18n.Data{"Question": question}))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Println()
+		fmt.Println(i18n.T("ConfirmNoAnswer"))
+		return false
+	}
+
+	// The answers that agree differ per language, such as j for Dutch
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	for _, yes := range strings.Split(i18n.T("ConfirmYes"), ",") {
+		if answer == yes {
+			return true
+		}
+	}
+	fmt.Println(i18n.T("Cancelled"))
+	return false
+}
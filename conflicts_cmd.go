@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// conflictRecord is the JSON form of a sync conflict in command output
+type conflictRecord struct {
+	ID         uint     `json:"id"`
+	ActionID   uint     `json:"action_id,omitempty"`
+	Name       string   `json:"name"`
+	Peer       string   `json:"peer"`
+	Fields     []string `json:"fields"`
+	Kept       string   `json:"kept"`
+	DetectedAt string   `json:"detected_at"`
+}
+
+// conflictFieldRecord is the JSON form of a field of a sync conflict
+type conflictFieldRecord struct {
+	Field   string `json:"field"`
+	Mine    string `json:"mine"`
+	Theirs  string `json:"theirs"`
+	Differs bool   `json:"differs"`
+}
+
+func conflictsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "List and resolve actions changed on both sides of a sync",
+		Long: `When 'projector sync --remote' finds an action that was changed both here
+and on the other instance since the previous sync, the latest change is kept
+and the other one is recorded as a conflict. Resolving a conflict picks mine,
+theirs, or a mix of their fields; the result is sent with the next sync.`,
+	}
+
+	cmd.AddCommand(conflictsListCmd())
+	cmd.AddCommand(conflictsShowCmd())
+	cmd.AddCommand(conflictsResolveCmd())
+	cmd.AddCommand(conflictsDropCmd())
+	return cmd
+}
+
+// lookupConflict parses a conflict ID and retrieves the conflict, printing
+// an error when it does not exist
+func lookupConflict(arg string) (*database.Conflict, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return nil, false
+	}
+
+	conflictID, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil {
+		fmt.Println(i18n.T("InvalidConflictID", i18n.Data{"ID": arg}))
+		return nil, false
+	}
+
+	conflict, err := database.GetConflict(database.GetDatabasePath(), uint(conflictID))
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingConflict", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if conflict == nil {
+		fmt.Println(i18n.T("ConflictNotFound", i18n.Data{"ID": conflictID}))
+		return nil, false
+	}
+	return conflict, true
+}
+
+func conflictsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the unresolved sync conflicts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			conflicts, err := database.GetConflicts(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingConflicts", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []conflictRecord{}
+			table := output.Table{Headers: []string{"ID", "ACTION", "NAME", "FIELDS", "KEPT", "PEER"}}
+			for _, conflict := range conflicts {
+				record := conflictRecord{
+					ID:         conflict.ID,
+					ActionID:   conflict.ActionID,
+					Name:       conflict.Mine.Name,
+					Peer:       conflict.Peer,
+					Fields:     conflict.Differences(),
+					Kept:       conflict.Kept(),
+					DetectedAt: conflict.DetectedAt,
+				}
+				records = append(records, record)
+
+				action := "deleted"
+				if record.ActionID != 0 {
+					action = fmt.Sprintf("%d", record.ActionID)
+				}
+				table.AddRow(fmt.Sprintf("%d", record.ID), action, record.Name, strings.Join(record.Fields, ","), record.Kept, record.Peer)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoSyncConflicts"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintConflicts", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func conflictsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <conflict-id>",
+		Short: "Show both versions of a conflicting action side by side",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			conflict, ok := lookupConflict(args[0])
+			if !ok {
+				return
+			}
+
+			records := []conflictFieldRecord{}
+			table := output.Table{Headers: []string{"", "FIELD", "MINE", "THEIRS"}}
+			for _, field := range database.ConflictFields {
+				record := conflictFieldRecord{
+					Field:  field,
+					Mine:   conflict.Value(conflict.Mine, field),
+					Theirs: conflict.Value(conflict.Theirs, field),
+				}
+				record.Differs = record.Mine != record.Theirs
+				records = append(records, record)
+
+				marker := ""
+				if record.Differs {
+					marker = "≠"
+				}
+				table.AddRow(marker, record.Field, record.Mine, record.Theirs)
+			}
+
+			if !output.IsJSON() {
+				fmt.Println(i18n.T("ConflictKept", i18n.Data{"ID": conflict.ID, "Peer": conflict.Peer, "Kept": conflict.Kept()}))
+			}
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintConflict", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func conflictsResolveCmd() *cobra.Command {
+	var use string
+	var theirs []string
+
+	cmd := &cobra.Command{
+		Use:   "resolve <conflict-id>",
+		Short: "Resolve a sync conflict with mine, theirs, or a mix of their fields",
+		Long: `Resolves a sync conflict. --use mine or --use theirs keeps one version as
+a whole; --theirs name,due takes those fields from theirs and the rest from
+mine. Without either, a terminal shows both versions side by side to choose
+a side for every field that differs.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			conflict, ok := lookupConflict(args[0])
+			if !ok {
+				return
+			}
+			if conflict.ActionID == 0 {
+				fmt.Println(i18n.T("ConflictActionDeleted", i18n.Data{"ID": conflict.ID}))
+				return
+			}
+
+			switch {
+			case use != "" && len(theirs) > 0:
+				fmt.Println(i18n.T("UseOrTheirs"))
+				return
+			case use == "mine":
+				theirs = nil
+			case use == "theirs":
+				theirs = database.ConflictFields
+			case use != "":
+				fmt.Println(i18n.T("InvalidUse", i18n.Data{"Use": use}))
+				return
+			case len(theirs) == 0:
+				var ok bool
+				if theirs, ok = pickConflictFields(conflict); !ok {
+					return
+				}
+			}
+
+			resolved, err := conflict.Merge(theirs)
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+
+			summary := "mine"
+			switch {
+			case len(theirs) == len(database.ConflictFields):
+				summary = "theirs"
+			case len(theirs) > 0:
+				summary = "mine with " + strings.Join(theirs, ", ") + " from theirs"
+			}
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldResolveConflict", i18n.Data{"ID": conflict.ActionID, "Summary": summary}))
+				return
+			}
+			if cmd.Flags().Changed("use") || cmd.Flags().Changed("theirs") {
+				if !confirm(cmd, i18n.T("ResolveConflictConfirm", i18n.Data{"ID": conflict.ActionID, "Summary": summary})) {
+					return
+				}
+			}
+
+			if err := database.ResolveConflict(database.GetDatabasePath(), conflict.ID, resolved); err != nil {
+				fmt.Println(i18n.T("FailedToResolveConflict", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("ResolvedConflict", i18n.Data{"ID": conflict.ActionID, "Summary": summary}))
+		},
+	}
+
+	cmd.Flags().StringVar(&use, "use", "", "Keep a version as a whole: mine or theirs")
+	cmd.Flags().StringSliceVar(&theirs, "theirs", nil, "Fields to take from theirs: "+strings.Join(database.ConflictFields, ", "))
+	return cmd
+}
+
+// pickConflictFields shows both versions of a conflict side by side and
+// returns the fields chosen from theirs. It returns false when the choice is
+// cancelled or stdin is not a terminal.
+func pickConflictFields(conflict *database.Conflict) ([]string, bool) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Println(i18n.T("NoResolutionGiven"))
+		return nil, false
+	}
+
+	// Fields that are the same on both sides need no choice
+	var fields []ui.ConflictField
+	for _, field := range conflict.Differences() {
+		fields = append(fields, ui.ConflictField{
+			Name:   field,
+			Mine:   conflict.Value(conflict.Mine, field),
+			Theirs: conflict.Value(conflict.Theirs, field),
+		})
+	}
+
+	title := i18n.T("ConflictTitle", i18n.Data{"ID": conflict.ActionID, "Peer": conflict.Peer})
+	theirs, ok, err := ui.ResolveConflict(title, fields, conflict.Kept() == "theirs")
+	if err != nil {
+		fmt.Println(i18n.T("ConflictResolutionFailed", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	return theirs, ok
+}
+
+func conflictsDropCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drop <conflict-id>",
+		Short: "Drop a sync conflict, keeping the action as it is",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			conflict, ok := lookupConflict(args[0])
+			if !ok {
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldDropConflict", i18n.Data{"ID": conflict.ID, "Name": conflict.Mine.Name}))
+				return
+			}
+			if !confirm(cmd, i18n.T("DropConflictConfirm", i18n.Data{"ID": conflict.ID, "Name": conflict.Mine.Name})) {
+				return
+			}
+
+			if err := database.DropConflict(database.GetDatabasePath(), conflict.ID); err != nil {
+				fmt.Println(i18n.T("FailedToDropConflict", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("DroppedConflict", i18n.Data{"ID": conflict.ID, "Kept": conflict.Kept()}))
+		},
+	}
+}
@@ -0,0 +1,218 @@
+// Package core embeds the projector task engine in other Go programs. A
+// Service works on a projector database the way the CLI and the API server
+// do: new actions get the default status of the workflow, completing a
+// repeating action creates its next occurrence, and deleted actions and
+// projects go to the trash. Every change is published on the event bus of
+// the service.
+//
+//	service, err := core.Open(database.GetDatabasePath())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	service.Events().Subscribe(func(event core.Event) {
+//		fmt.Println(event.Type, event.ActionID)
+//	})
+//	action, err := service.AddAction("Call the bank #calls due:fri")
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// ErrNotFound is returned for an action or project that does not exist
+var ErrNotFound = errors.New("not found")
+
+// Service is the task engine on a single projector database
+type Service struct {
+	dbPath string
+	bus    *Bus
+}
+
+// Open returns the service for the database at dbPath, creating the
+// database when it does not exist yet. An existing database must have been
+// migrated to the current schema with `projector migrate`.
+func Open(dbPath string) (*Service, error) {
+	if !database.DatabaseExists(dbPath) {
+		if err := database.InitSchema(dbPath); err != nil {
+			return nil, err
+		}
+	}
+
+	health, err := database.CheckHealth(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	if !health.SchemaUpToDate {
+		return nil, fmt.Errorf("database schema version %d, expected %d: run 'projector migrate'", health.SchemaVersion, health.ExpectedSchemaVersion)
+	}
+
+	return &Service{dbPath: dbPath, bus: &Bus{}}, nil
+}
+
+// Events returns the bus the service publishes its changes on
+func (s *Service) Events() *Bus {
+	return s.bus
+}
+
+// Actions returns all actions, including the done ones
+func (s *Service) Actions() ([]Action, error) {
+	actions, err := database.GetAllActions(s.dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Action, 0, len(actions))
+	for i := range actions {
+		converted = append(converted, *toAction(&actions[i]))
+	}
+	return converted, nil
+}
+
+// Action returns the action with the given ID, or ErrNotFound
+func (s *Service) Action(actionID uint) (*Action, error) {
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil {
+		return nil, err
+	}
+	if action == nil {
+		return nil, ErrNotFound
+	}
+	return toAction(action), nil
+}
+
+// AddAction creates an action from a line in the quick-add syntax of
+// `projector add`, such as "Call the bank #calls due:fri +Finance"
+func (s *Service) AddAction(line string) (*Action, error) {
+	entry, err := quickadd.Parse(strings.TrimSpace(line), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := entry.Resolve(s.dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	actionIDs, err := database.CreateActions(s.dbPath, []database.NewAction{resolved})
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := s.Action(actionIDs[0])
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(actionEvent(ActionCreated, action))
+	return action, nil
+}
+
+// UpdateAction applies the changes to an action
+func (s *Service) UpdateAction(actionID uint, update ActionUpdate) (*Action, error) {
+	if _, err := s.Action(actionID); err != nil {
+		return nil, err
+	}
+	if err := database.UpdateAction(s.dbPath, actionID, update.toDatabase()); err != nil {
+		return nil, err
+	}
+
+	action, err := s.Action(actionID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(actionEvent(ActionUpdated, action))
+	return action, nil
+}
+
+// CompleteAction marks an action as done, creating the next occurrence of a
+// repeating action
+func (s *Service) CompleteAction(actionID uint) (*Action, error) {
+	if _, err := s.Action(actionID); err != nil {
+		return nil, err
+	}
+	if err := database.MarkActionAsDone(s.dbPath, actionID); err != nil {
+		return nil, err
+	}
+
+	action, err := s.Action(actionID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(actionEvent(ActionDone, action))
+	return action, nil
+}
+
+// DeleteAction moves an action to the trash
+func (s *Service) DeleteAction(actionID uint) error {
+	action, err := s.Action(actionID)
+	if err != nil {
+		return err
+	}
+	if err := database.TrashAction(s.dbPath, actionID); err != nil {
+		return err
+	}
+	s.bus.Publish(actionEvent(ActionDeleted, action))
+	return nil
+}
+
+// Projects returns all projects, including the archived ones
+func (s *Service) Projects() ([]Project, error) {
+	projects, err := database.GetAllProjects(s.dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Project, 0, len(projects))
+	for i := range projects {
+		converted = append(converted, *toProject(&projects[i]))
+	}
+	return converted, nil
+}
+
+// Project returns the project with the given ID, or ErrNotFound
+func (s *Service) Project(projectID uint) (*Project, error) {
+	project, err := database.GetProjectByID(s.dbPath, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, ErrNotFound
+	}
+	return toProject(project), nil
+}
+
+// AddProject creates a project, a zero due date leaves it without one
+func (s *Service) AddProject(name string, dueDate time.Time) (*Project, error) {
+	date := ""
+	if !dueDate.IsZero() {
+		date = dueDate.Format(dateLayout)
+	}
+	projectID, err := database.CreateProject(s.dbPath, name, date)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.Project(projectID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(Event{Type: ProjectCreated, ProjectID: project.ID, Project: project})
+	return project, nil
+}
+
+// DeleteProject moves a project with its actions to the trash
+func (s *Service) DeleteProject(projectID uint) error {
+	project, err := s.Project(projectID)
+	if err != nil {
+		return err
+	}
+	if err := database.TrashProject(s.dbPath, projectID); err != nil {
+		return err
+	}
+	s.bus.Publish(Event{Type: ProjectDeleted, ProjectID: projectID, Project: project})
+	return nil
+}
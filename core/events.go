@@ -0,0 +1,78 @@
+package core
+
+import (
+	"slices"
+	"sync"
+)
+
+// Event types published by a Service
+const (
+	ActionCreated  = "action.created"
+	ActionUpdated  = "action.updated"
+	ActionDone     = "action.done"
+	ActionDeleted  = "action.deleted"
+	ProjectCreated = "project.created"
+	ProjectDeleted = "project.deleted"
+)
+
+// Event is a change made through a Service. Action or Project holds the row
+// after the change, or before it for a deletion.
+type Event struct {
+	Type      string
+	ActionID  uint
+	ProjectID uint
+	Action    *Action
+	Project   *Project
+}
+
+// actionEvent returns the event of a change to an action, with the ID of its
+// project when it has one
+func actionEvent(eventType string, action *Action) Event {
+	event := Event{Type: eventType, ActionID: action.ID, Action: action}
+	if action.ProjectID != nil {
+		event.ProjectID = *action.ProjectID
+	}
+	return event
+}
+
+// Bus delivers the events of a Service to its subscribers. Handlers run
+// synchronously in the order they subscribed, after the change is stored.
+type Bus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers []subscriber
+}
+
+// subscriber is a handler on a Bus, with the ID to unsubscribe it by
+type subscriber struct {
+	id      int
+	handler func(Event)
+}
+
+// Subscribe calls the handler for every event published from now on, until
+// the returned function is called
+func (b *Bus) Subscribe(handler func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers = append(b.subscribers, subscriber{id: id, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subscribers = slices.DeleteFunc(b.subscribers, func(s subscriber) bool { return s.id == id })
+	}
+}
+
+// Publish calls the subscribed handlers with the event
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	subscribers := slices.Clone(b.subscribers)
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.handler(event)
+	}
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// dateLayout is the layout of dates handed to the database
+const dateLayout = "2006-01-02"
+
+// Action is an action as returned by a Service. Optional fields are nil when
+// they are not set.
+type Action struct {
+	ID             uint
+	ProjectID      *uint
+	ProjectName    *string
+	Name           string
+	Note           *string
+	DueDate        *time.Time
+	StartDate      *time.Time // The action is left out of active lists until this date
+	StatusID       uint
+	StatusName     string
+	RepeatMode     *string // One of the database.RepeatMode constants
+	RepeatCount    uint
+	RepeatInterval *string
+	RepeatPattern  *string
+	RepeatUntil    *time.Time
+	ParentActionID *uint // The previous occurrence of a repeating action
+	OwnerID        *uint
+	AssigneeID     *uint
+	AssigneeName   *string
+	Flagged        bool // Picked for today, independent of the due date
+	Location       *string
+	Latitude       *float64
+	Longitude      *float64
+	Energy         *string // low, medium or high
+	WaitingOn      *string // Person or system the action waits on
+	FollowUp       *time.Time
+	Tags           []string
+	Reminders      []string // Offsets before the due date such as -1d
+	Version        uint     // Increases on every change
+}
+
+// Project is a project as returned by a Service
+type Project struct {
+	ID              uint
+	Name            string
+	DueDate         *time.Time
+	OwnerID         *uint
+	ArchivedAt      *time.Time // Set when the project was merged into another
+	RepeatInterval  *string
+	ParentProjectID *uint // The project of the previous period
+	ReviewInterval  *string
+	LastReviewedAt  *time.Time
+	Version         uint
+}
+
+// Coordinates is a point on the earth in decimal degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// ActionUpdate holds the changes to an action, nil fields are left as they
+// are. An empty string or a zero time clears the field.
+type ActionUpdate struct {
+	Name           *string
+	Note           *string
+	ProjectID      *uint
+	DueDate        *time.Time
+	StatusID       *uint
+	RepeatMode     *string
+	RepeatCount    *uint
+	RepeatInterval *string
+	RepeatPattern  *string
+	RepeatUntil    *time.Time
+	AssigneeID     *uint // 0 removes the assignee
+	Flagged        *bool
+	Location       *string // Empty removes the location and its coordinates
+	Coordinates    *Coordinates
+	Energy         *string
+	WaitingOn      *string // Empty removes who the action waits on and the follow-up date
+	FollowUp       *time.Time
+	StartDate      *time.Time
+	// Version is the version of the action the changes are based on. The
+	// update fails when the action has changed since.
+	Version *uint
+}
+
+// toAction converts an action read from the database
+func toAction(action *database.Action) *Action {
+	return &Action{
+		ID:             action.ID,
+		ProjectID:      optionalID(action.ProjectID),
+		ProjectName:    optionalString(action.ProjectName),
+		Name:           action.Name,
+		Note:           optionalString(action.Note),
+		DueDate:        optionalTime(action.DueDate),
+		StartDate:      optionalTime(action.StartDate),
+		StatusID:       action.StatusID,
+		StatusName:     action.StatusName,
+		RepeatMode:     optionalString(action.RepeatMode),
+		RepeatCount:    action.RepeatCount,
+		RepeatInterval: optionalString(action.RepeatInterval),
+		RepeatPattern:  optionalString(action.RepeatPattern),
+		RepeatUntil:    optionalTime(action.RepeatUntil),
+		ParentActionID: optionalID(action.ParentActionID),
+		OwnerID:        optionalID(action.OwnerID),
+		AssigneeID:     optionalID(action.AssigneeID),
+		AssigneeName:   optionalString(action.AssigneeName),
+		Flagged:        action.Flagged,
+		Location:       optionalString(action.Location),
+		Latitude:       optionalFloat(action.Latitude),
+		Longitude:      optionalFloat(action.Longitude),
+		Energy:         optionalString(action.Energy),
+		WaitingOn:      optionalString(action.WaitingOn),
+		FollowUp:       optionalTime(action.FollowUp),
+		Tags:           action.Tags,
+		Reminders:      action.Reminders,
+		Version:        action.Version,
+	}
+}
+
+// toProject converts a project read from the database
+func toProject(project *database.Project) *Project {
+	return &Project{
+		ID:              project.ID,
+		Name:            project.Name,
+		DueDate:         optionalTime(project.DueDate),
+		OwnerID:         optionalID(project.OwnerID),
+		ArchivedAt:      optionalTime(project.ArchivedAt),
+		RepeatInterval:  optionalString(project.RepeatInterval),
+		ParentProjectID: optionalID(project.ParentProjectID),
+		ReviewInterval:  optionalString(project.ReviewInterval),
+		LastReviewedAt:  optionalTime(project.LastReviewedAt),
+		Version:         project.Version,
+	}
+}
+
+// toDatabase converts the changes to the form the database applies
+func (u ActionUpdate) toDatabase() database.ActionUpdate {
+	update := database.ActionUpdate{
+		Name:           u.Name,
+		Note:           u.Note,
+		ProjectID:      u.ProjectID,
+		DueDate:        dateString(u.DueDate),
+		StatusID:       u.StatusID,
+		RepeatMode:     u.RepeatMode,
+		RepeatCount:    u.RepeatCount,
+		RepeatInterval: u.RepeatInterval,
+		RepeatPattern:  u.RepeatPattern,
+		RepeatUntil:    dateString(u.RepeatUntil),
+		AssigneeID:     u.AssigneeID,
+		Flagged:        u.Flagged,
+		Location:       u.Location,
+		Energy:         u.Energy,
+		WaitingOn:      u.WaitingOn,
+		FollowUp:       dateString(u.FollowUp),
+		StartDate:      dateString(u.StartDate),
+		Version:        u.Version,
+	}
+	if u.Coordinates != nil {
+		update.Coordinates = &database.Coordinates{Latitude: u.Coordinates.Latitude, Longitude: u.Coordinates.Longitude}
+	}
+	return update
+}
+
+// optionalString returns nil for a NULL or empty string
+func optionalString(value sql.NullString) *string {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	return &value.String
+}
+
+// optionalTime parses a stored date or timestamp, nil when there is none
+func optionalTime(value sql.NullString) *time.Time {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05"} {
+		if parsed, err := time.Parse(layout, value.String); err == nil {
+			return &parsed
+		}
+	}
+	parsed, err := database.ParseStoredDate(value.String)
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// optionalFloat returns nil for a NULL number
+func optionalFloat(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+// optionalID returns nil for a NULL ID
+func optionalID(value sql.NullInt64) *uint {
+	if !value.Valid {
+		return nil
+	}
+	id := uint(value.Int64)
+	return &id
+}
+
+// dateString formats a date for the database, a zero time becomes the empty
+// string that clears the field
+func dateString(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	date := ""
+	if !value.IsZero() {
+		date = value.Format(dateLayout)
+	}
+	return &date
+}
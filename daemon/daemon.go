@@ -0,0 +1,159 @@
+// Package daemon runs the projector server as a background service: it
+// manages the PID file, detaches the server from the terminal and reports
+// readiness to systemd.
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// GetPIDFilePath returns the default PID file path in the data directory
+func GetPIDFilePath(dataDir string) string {
+	return filepath.Join(dataDir, "projector.pid")
+}
+
+// GetLogFilePath returns the default log file path of a detached server in the data directory
+func GetLogFilePath(dataDir string) string {
+	return filepath.Join(dataDir, "projector.log")
+}
+
+// WritePIDFile writes the PID of the current process. It fails when the file
+// belongs to another process that is still running; a stale file is replaced.
+func WritePIDFile(path string) error {
+	pid, err := ReadPIDFile(path)
+	if err != nil {
+		return err
+	}
+	if pid != 0 && pid != os.Getpid() && Running(pid) {
+		return fmt.Errorf("projector is already running (PID %d)", pid)
+	}
+
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write PID file: %v", err)
+	}
+	return nil
+}
+
+// ReadPIDFile returns the PID in a PID file, or 0 when the file does not exist
+func ReadPIDFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read PID file: %v", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid PID file: %s", path)
+	}
+	return pid, nil
+}
+
+// RemovePIDFile removes the PID file if it belongs to the current process
+func RemovePIDFile(path string) {
+	if pid, err := ReadPIDFile(path); err == nil && pid == os.Getpid() {
+		os.Remove(path)
+	}
+}
+
+// Running reports whether a process with the PID exists
+func Running(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Stop asks the process to shut down and waits for it to exit
+func Stop(pid int, timeout time.Duration) error {
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop PID %d: %v", pid, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for Running(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("PID %d did not exit within %s", pid, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// Detach starts the projector executable with the arguments in a new session,
+// without a terminal and with its output appended to the log file. It waits
+// until the new process has written the PID file, or returns an error when it
+// exits before that.
+func Detach(args []string, pidPath, logPath string, timeout time.Duration) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the projector executable: %v", err)
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start projector: %v", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	deadline := time.After(timeout)
+	for {
+		if pid, _ := ReadPIDFile(pidPath); pid == cmd.Process.Pid {
+			return pid, nil
+		}
+
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("projector exited during startup, see %s", logPath)
+		case <-deadline:
+			return cmd.Process.Pid, fmt.Errorf("projector did not start within %s, see %s", timeout, logPath)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Notify sends a state such as READY=1 or STOPPING=1 to systemd. It does
+// nothing when the process was not started by systemd with Type=notify.
+func Notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// Abstract sockets are given with a leading @
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to systemd: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify systemd: %v", err)
+	}
+	return nil
+}
@@ -23,40 +23,200 @@ type Action struct {
 	RepeatPattern  sql.NullString
 	RepeatUntil    sql.NullString
 	ParentActionID sql.NullInt64
+	RepeatMode     sql.NullString
+	OwnerID        sql.NullInt64
+	AssigneeID     sql.NullInt64
+	Flagged        bool // Picked for today, independent of the due date
+	Location       sql.NullString
+	Latitude       sql.NullFloat64
+	Longitude      sql.NullFloat64
+	Energy         sql.NullString // low, medium or high
+	WaitingOn      sql.NullString // Person or system the action waits on
+	FollowUp       sql.NullString // Date to follow up on what the action waits on
+	StartDate      sql.NullString // The action is deferred and left out of active lists until this date
+	Version        uint           // Increases on every change, see VersionConflictError
+	AssigneeName   sql.NullString
 	ProjectName    sql.NullString
 	StatusName     string
+	Tags           []string
+	Reminders      []string // Offsets before the due date such as -1d, see ParseReminderOffset
 }
 
-// GetAllActions retrieves all actions with their project and status information
-func GetAllActions(dbPath string) ([]Action, error) {
+// Repeat modes control when a repeating action stops creating new occurrences
+const (
+	RepeatModeNone    = "none"    // The action does not repeat
+	RepeatModeForever = "forever" // The action repeats indefinitely
+	RepeatModeCount   = "count"   // The action repeats RepeatCount more times
+	RepeatModeUntil   = "until"   // The action repeats until the RepeatUntil date
+)
+
+// EffectiveRepeatMode returns the repeat mode of the action, deriving it from
+// the legacy repeat_count/repeat_until fields when no mode has been stored
+func (a *Action) EffectiveRepeatMode() string {
+	if a.RepeatMode.Valid && a.RepeatMode.String != "" {
+		return a.RepeatMode.String
+	}
+	if !a.RepeatInterval.Valid || a.RepeatInterval.String == "" {
+		return RepeatModeNone
+	}
+	if a.RepeatUntil.Valid && a.RepeatUntil.String != "" {
+		return RepeatModeUntil
+	}
+	if a.RepeatCount > 0 {
+		return RepeatModeCount
+	}
+	return RepeatModeNone
+}
+
+// IsRepeating reports whether completing the action should create a next occurrence
+func (a *Action) IsRepeating() bool {
+	if !a.RepeatInterval.Valid || a.RepeatInterval.String == "" {
+		return false
+	}
+
+	switch a.EffectiveRepeatMode() {
+	case RepeatModeForever:
+		return true
+	case RepeatModeCount:
+		return a.RepeatCount > 0
+	case RepeatModeUntil:
+		return a.RepeatUntil.Valid && a.RepeatUntil.String != ""
+	default:
+		return false
+	}
+}
+
+// IsToday reports whether an open action belongs on the list for today
+// (YYYY-MM-DD): it is flagged, due today or overdue, and not deferred
+func (a *Action) IsToday(today string) bool {
+	if a.StatusName == "done" || a.IsDeferred(today) {
+		return false
+	}
+	dueDate := StoredDate(a.DueDate.String)
+	return a.Flagged || (dueDate != "" && dueDate <= today)
+}
+
+// RepeatDescription returns a human readable description of the repeat schedule
+func (a *Action) RepeatDescription() string {
+	if !a.RepeatInterval.Valid || a.RepeatInterval.String == "" {
+		return ""
+	}
+
+	description := fmt.Sprintf("every %s", a.RepeatInterval.String)
+	if a.RepeatPattern.Valid && a.RepeatPattern.String != "" {
+		description += fmt.Sprintf(" on %s", a.RepeatPattern.String)
+	}
+
+	switch a.EffectiveRepeatMode() {
+	case RepeatModeForever:
+		description += ", forever"
+	case RepeatModeCount:
+		if a.RepeatCount == 1 {
+			description += ", 1 more time"
+		} else {
+			description += fmt.Sprintf(", %d more times", a.RepeatCount)
+		}
+	case RepeatModeUntil:
+		description += fmt.Sprintf(", until %s", StoredDate(a.RepeatUntil.String))
+	default:
+		return ""
+	}
+
+	return description
+}
+
+// actionSelectQuery selects all action columns with their project, status and
+// assignee names. The tag names and reminder offsets are aggregated per action
+// in the same query, looked up through the action_tag primary key and the
+// reminder unique index, so listing actions never needs a query per action.
+const actionSelectQuery = `
+	SELECT 
+		a.id, 
+		a.project_id, 
+		a.name, 
+		a.note,
+		a.due_date, 
+		a.status_id,
+		a.repeat_count,
+		a.repeat_interval,
+		a.repeat_pattern,
+		a.repeat_until,
+		a.parent_action_id,
+		a.repeat_mode,
+		a.owner_id,
+		a.assignee_id,
+		a.flagged,
+		a.location,
+		a.latitude,
+		a.longitude,
+		a.energy,
+		a.waiting_on,
+		a.follow_up,
+		a.start_date,
+		a.version,
+		u.username as assignee_name,
+		p.name as project_name,
+		s.name as status_name,
+		(
+			SELECT json_group_array(t.name ORDER BY t.name)
+			FROM action_tag at
+			JOIN tag t ON at.tag_id = t.id
+			WHERE at.action_id = a.id
+		) as tag_names,
+		(
+			SELECT json_group_array(r.due_offset ORDER BY r.id)
+			FROM reminder r
+			WHERE r.action_id = a.id
+		) as reminders
+	FROM action a
+	LEFT JOIN project p ON a.project_id = p.id
+	LEFT JOIN status s ON a.status_id = s.id
+	LEFT JOIN user u ON a.assignee_id = u.id
+`
+
+// actionScanFields returns the scan destinations matching actionSelectQuery
+func actionScanFields(action *Action) []interface{} {
+	return []interface{}{
+		&action.ID,
+		&action.ProjectID,
+		&action.Name,
+		&action.Note,
+		&action.DueDate,
+		&action.StatusID,
+		&action.RepeatCount,
+		&action.RepeatInterval,
+		&action.RepeatPattern,
+		&action.RepeatUntil,
+		&action.ParentActionID,
+		&action.RepeatMode,
+		&action.OwnerID,
+		&action.AssigneeID,
+		&action.Flagged,
+		&action.Location,
+		&action.Latitude,
+		&action.Longitude,
+		&action.Energy,
+		&action.WaitingOn,
+		&action.FollowUp,
+		&action.StartDate,
+		&action.Version,
+		&action.AssigneeName,
+		&action.ProjectName,
+		&action.StatusName,
+		(*tagNames)(&action.Tags),
+		(*reminderOffsets)(&action.Reminders),
+	}
+}
+
+// queryActions retrieves the actions matching the given WHERE and ORDER BY clauses
+func queryActions(dbPath, clauses string, args ...interface{}) ([]Action, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	query := `
-		SELECT 
-			a.id, 
-			a.project_id, 
-			a.name, 
-			a.note,
-			a.due_date, 
-			a.status_id,
-			a.repeat_count,
-			a.repeat_interval,
-			a.repeat_pattern,
-			a.repeat_until,
-			a.parent_action_id,
-			p.name as project_name,
-			s.name as status_name
-		FROM action a
-		LEFT JOIN project p ON a.project_id = p.id
-		LEFT JOIN status s ON a.status_id = s.id
-		ORDER BY a.id DESC
-	`
-
-	rows, err := db.Query(query)
+	rows, err := db.Query(actionSelectQuery+clauses, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -65,22 +225,7 @@ func GetAllActions(dbPath string) ([]Action, error) {
 	var actions []Action
 	for rows.Next() {
 		var action Action
-		err := rows.Scan(
-			&action.ID,
-			&action.ProjectID,
-			&action.Name,
-			&action.Note,
-			&action.DueDate,
-			&action.StatusID,
-			&action.RepeatCount,
-			&action.RepeatInterval,
-			&action.RepeatPattern,
-			&action.RepeatUntil,
-			&action.ParentActionID,
-			&action.ProjectName,
-			&action.StatusName,
-		)
-		if err != nil {
+		if err := rows.Scan(actionScanFields(&action)...); err != nil {
 			return nil, err
 		}
 		actions = append(actions, action)
@@ -89,6 +234,45 @@ func GetAllActions(dbPath string) ([]Action, error) {
 	return actions, nil
 }
 
+// GetAllActions retrieves all actions with their project and status information
+func GetAllActions(dbPath string) ([]Action, error) {
+	return queryActions(dbPath, "ORDER BY a.id DESC")
+}
+
+// GetActionsForUser retrieves the actions owned by or assigned to a user, and
+// the actions in projects the user owns or is a member of
+func GetActionsForUser(dbPath string, userID uint) ([]Action, error) {
+	clauses := `
+		WHERE a.owner_id = ? OR a.assignee_id = ? OR p.owner_id = ?
+			OR a.project_id IN (SELECT project_id FROM project_member WHERE user_id = ?)
+		ORDER BY a.id DESC
+	`
+	return queryActions(dbPath, clauses, userID, userID, userID, userID)
+}
+
+// GetOverdueActions retrieves the open actions with a due date before today
+func GetOverdueActions(dbPath string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND date(a.due_date) < date('now', 'localtime') ORDER BY a.due_date, a.id")
+}
+
+// GetActionsDueOn retrieves the open actions due on the given date (YYYY-MM-DD)
+func GetActionsDueOn(dbPath, date string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND date(a.due_date) = date(?) ORDER BY a.id", date)
+}
+
+// GetActionsDueBetween retrieves the open actions due from one date to
+// another (YYYY-MM-DD), both included
+func GetActionsDueBetween(dbPath, from, to string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND date(a.due_date) BETWEEN date(?) AND date(?) ORDER BY a.due_date, a.id", from, to)
+}
+
+// GetActionsCompletedOn retrieves the actions marked done on the given local
+// date (YYYY-MM-DD). Actions have no completion time of their own, the last
+// change of a done action is taken as the moment it was completed.
+func GetActionsCompletedOn(dbPath, date string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id = 2 AND date(a.updated_at, 'localtime') = date(?) ORDER BY a.updated_at, a.id", date)
+}
+
 // GetActionByID retrieves an action by its ID
 func GetActionByID(dbPath string, actionID uint) (*Action, error) {
 	db, err := sql.Open("sqlite3", dbPath)
@@ -97,43 +281,8 @@ func GetActionByID(dbPath string, actionID uint) (*Action, error) {
 	}
 	defer db.Close()
 
-	query := `
-		SELECT 
-			a.id, 
-			a.project_id, 
-			a.name, 
-			a.note,
-			a.due_date, 
-			a.status_id,
-			a.repeat_count,
-			a.repeat_interval,
-			a.repeat_pattern,
-			a.repeat_until,
-			a.parent_action_id,
-			p.name as project_name,
-			s.name as status_name
-		FROM action a
-		LEFT JOIN project p ON a.project_id = p.id
-		LEFT JOIN status s ON a.status_id = s.id
-		WHERE a.id = ?
-	`
-
 	var action Action
-	err = db.QueryRow(query, actionID).Scan(
-		&action.ID,
-		&action.ProjectID,
-		&action.Name,
-		&action.Note,
-		&action.DueDate,
-		&action.StatusID,
-		&action.RepeatCount,
-		&action.RepeatInterval,
-		&action.RepeatPattern,
-		&action.RepeatUntil,
-		&action.ParentActionID,
-		&action.ProjectName,
-		&action.StatusName,
-	)
+	err = db.QueryRow(actionSelectQuery+"WHERE a.id = ?", actionID).Scan(actionScanFields(&action)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Action not found
@@ -144,18 +293,38 @@ func GetActionByID(dbPath string, actionID uint) (*Action, error) {
 	return &action, nil
 }
 
-// CreateAction creates a new action in the database
-func CreateAction(dbPath, name, note string, projectID *uint, dueDate string, statusID uint, repeatCount uint, repeatInterval, repeatPattern, repeatUntil string, parentActionID *uint) (uint, error) {
-	// Validate input data
-	if err := ValidateActionInput(name, projectID, dueDate, statusID); err != nil {
-		return 0, err
+// FindOpenDuplicate retrieves an open action with the given name in the same
+// project, or outside any project when projectID is nil. It returns nil when
+// there is none.
+func FindOpenDuplicate(dbPath, name string, projectID *uint) (*Action, error) {
+	actions, err := queryActions(dbPath, "WHERE a.name = ? AND a.project_id IS ? AND a.status_id != 2 ORDER BY a.id LIMIT 1", name, projectID)
+	if err != nil {
+		return nil, err
 	}
+	if len(actions) == 0 {
+		return nil, nil
+	}
+	return &actions[0], nil
+}
 
-	// Validate and format due date
-	validatedDueDate, err := ValidateDate(dueDate)
-	if err != nil {
+// CreateAction creates a new action in the database
+func CreateAction(dbPath, name, note string, projectID *uint, dueDate string, statusID uint, repeatMode string, repeatCount uint, repeatInterval, repeatPattern, repeatUntil string, parentActionID *uint) (uint, error) {
+	// Validate input data, resolving the repeat mode and formatting the due date
+	input := ActionInput{
+		Name:           name,
+		ProjectID:      projectID,
+		StatusID:       statusID,
+		DueDate:        dueDate,
+		RepeatMode:     repeatMode,
+		RepeatCount:    repeatCount,
+		RepeatInterval: repeatInterval,
+		RepeatUntil:    repeatUntil,
+		ParentActionID: parentActionID,
+	}
+	if err := ValidateAction(dbPath, &input); err != nil {
 		return 0, err
 	}
+	repeatMode, validatedDueDate := input.RepeatMode, input.DueDate
 
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
@@ -164,15 +333,15 @@ func CreateAction(dbPath, name, note string, projectID *uint, dueDate string, st
 	defer db.Close()
 
 	query := `
-		INSERT INTO action (name, note, project_id, due_date, status_id, repeat_count, repeat_interval, repeat_pattern, repeat_until, parent_action_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until, parent_action_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var result sql.Result
 	if projectID != nil {
-		result, err = db.Exec(query, name, note, *projectID, validatedDueDate, statusID, repeatCount, repeatInterval, repeatPattern, repeatUntil, parentActionID)
+		result, err = db.Exec(query, name, note, *projectID, nullIfEmpty(validatedDueDate), statusID, repeatMode, repeatCount, repeatInterval, repeatPattern, nullIfEmpty(repeatUntil), parentActionID)
 	} else {
-		result, err = db.Exec(query, name, note, nil, validatedDueDate, statusID, repeatCount, repeatInterval, repeatPattern, repeatUntil, parentActionID)
+		result, err = db.Exec(query, name, note, nil, nullIfEmpty(validatedDueDate), statusID, repeatMode, repeatCount, repeatInterval, repeatPattern, nullIfEmpty(repeatUntil), parentActionID)
 	}
 
 	if err != nil {
@@ -187,9 +356,100 @@ func CreateAction(dbPath, name, note string, projectID *uint, dueDate string, st
 	return uint(actionID), nil
 }
 
+// NewAction is an action to create with CreateActions
+type NewAction struct {
+	Name           string
+	Note           string
+	ProjectID      *uint
+	DueDate        string
+	Tags           []string
+	RepeatMode     string
+	RepeatInterval string
+	RepeatPattern  string
+	Location       string
+	Energy         string
+	StartDate      string
+	Reminders      []string
+	OwnerID        *uint // The user creating the action, nil in single-user mode
+}
+
+// Input returns the fields of the action checked by ValidateAction, with the
+// given status
+func (a NewAction) Input(statusID uint) ActionInput {
+	return ActionInput{
+		Name:           a.Name,
+		ProjectID:      a.ProjectID,
+		StatusID:       statusID,
+		DueDate:        a.DueDate,
+		StartDate:      a.StartDate,
+		RepeatMode:     a.RepeatMode,
+		RepeatInterval: a.RepeatInterval,
+		Energy:         a.Energy,
+		Reminders:      a.Reminders,
+	}
+}
+
+// CreateActions creates actions with the default status and their tags in a
+// single transaction, so either all of them are created or none. It returns
+// the IDs in the order of the actions.
+func CreateActions(dbPath string, actions []NewAction) ([]uint, error) {
+	statusID, err := DefaultStatusID(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]ActionInput, len(actions))
+	for i, action := range actions {
+		inputs[i] = action.Input(statusID)
+		if err := ValidateAction(dbPath, &inputs[i]); err != nil {
+			return nil, fmt.Errorf("%s: %w", action.Name, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var actionIDs []uint
+	for i, action := range actions {
+		result, err := tx.Exec(`
+			INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_interval, repeat_pattern, location, energy, start_date, owner_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			action.Name, action.Note, action.ProjectID, nullIfEmpty(inputs[i].DueDate), statusID, inputs[i].RepeatMode, action.RepeatInterval, action.RepeatPattern, nullIfEmpty(action.Location), nullIfEmpty(inputs[i].Energy), nullIfEmpty(action.StartDate), action.OwnerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create action %s: %v", action.Name, err)
+		}
+		actionID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if err := setActionTagsTx(tx, uint(actionID), action.Tags); err != nil {
+			return nil, fmt.Errorf("failed to tag action %s: %v", action.Name, err)
+		}
+		if err := setRemindersTx(tx, uint(actionID), action.Reminders); err != nil {
+			return nil, fmt.Errorf("%s: %v", action.Name, err)
+		}
+		actionIDs = append(actionIDs, uint(actionID))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return actionIDs, nil
+}
+
 // CreateNextRepeatedAction creates the next occurrence of a repeating action
 func CreateNextRepeatedAction(dbPath string, originalAction *Action) (uint, error) {
-	if originalAction.RepeatCount <= 0 || originalAction.RepeatInterval.String == "" {
+	if !originalAction.IsRepeating() {
 		return 0, fmt.Errorf("action is not configured for repetition")
 	}
 
@@ -199,40 +459,107 @@ func CreateNextRepeatedAction(dbPath string, originalAction *Action) (uint, erro
 		return 0, err
 	}
 
-	// Check if we've reached the repeat until date
-	if originalAction.RepeatUntil.Valid && originalAction.RepeatUntil.String != "" {
-		untilDate, err := time.Parse("2006-01-02", originalAction.RepeatUntil.String)
+	repeatMode := originalAction.EffectiveRepeatMode()
+	repeatCount := originalAction.RepeatCount
+
+	switch repeatMode {
+	case RepeatModeCount:
+		// Each occurrence consumes one of the remaining repetitions
+		repeatCount--
+	case RepeatModeUntil:
+		// Check if we've reached the repeat until date
+		untilDate, err := ParseStoredDate(originalAction.RepeatUntil.String)
 		if err == nil && nextDueDate.After(untilDate) {
 			return 0, fmt.Errorf("repetition limit reached")
 		}
 	}
 
-	// Create the next action
+	// The last occurrence of a counted series no longer repeats
+	if repeatMode == RepeatModeCount && repeatCount == 0 {
+		repeatMode = RepeatModeNone
+	}
+
 	var projectID *uint
 	if originalAction.ProjectID.Valid {
 		projectIDUint := uint(originalAction.ProjectID.Int64)
 		projectID = &projectIDUint
 	}
 
-	nextActionID, err := CreateAction(
-		dbPath,
-		originalAction.Name,
-		originalAction.Note.String,
-		projectID,
-		nextDueDate.Format("2006-01-02"),
-		originalAction.StatusID,
-		originalAction.RepeatCount-1, // Decrease repeat count
-		originalAction.RepeatInterval.String,
-		originalAction.RepeatPattern.String,
-		originalAction.RepeatUntil.String,
-		&originalAction.ID, // Set this as the parent action
-	)
+	// The next occurrence starts over in the default status, like a new
+	// action, rather than in the status the series was completed from
+	statusID, err := DefaultStatusID(dbPath)
+	if err != nil {
+		return 0, err
+	}
+
+	input := ActionInput{
+		Name:           originalAction.Name,
+		ProjectID:      projectID,
+		StatusID:       statusID,
+		DueDate:        nextDueDate.Format("2006-01-02"),
+		RepeatMode:     repeatMode,
+		RepeatCount:    repeatCount,
+		RepeatInterval: originalAction.RepeatInterval.String,
+		RepeatUntil:    originalAction.RepeatUntil.String,
+		ParentActionID: &originalAction.ID,
+	}
+	if err := ValidateAction(dbPath, &input); err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
 
+	// The next occurrence belongs to the owner and assignee of the series,
+	// and keeps its tags, reminders, location, energy and start date
+	result, err := tx.Exec(`
+		INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until, parent_action_id,
+			owner_id, assignee_id, location, latitude, longitude, energy, start_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		originalAction.Name, originalAction.Note.String, projectID, nullIfEmpty(input.DueDate), statusID, input.RepeatMode, repeatCount,
+		originalAction.RepeatInterval.String, originalAction.RepeatPattern.String, nullIfEmpty(originalAction.RepeatUntil.String), originalAction.ID,
+		originalAction.OwnerID, originalAction.AssigneeID, originalAction.Location, originalAction.Latitude, originalAction.Longitude,
+		originalAction.Energy, nullIfEmpty(originalAction.nextStartDate(nextDueDate)))
 	if err != nil {
+		return 0, fmt.Errorf("failed to create next occurrence: %v", err)
+	}
+	nextActionID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := setActionTagsTx(tx, uint(nextActionID), originalAction.Tags); err != nil {
+		return 0, fmt.Errorf("failed to tag next occurrence: %v", err)
+	}
+	if err := setRemindersTx(tx, uint(nextActionID), originalAction.Reminders); err != nil {
 		return 0, err
 	}
 
-	return nextActionID, nil
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return uint(nextActionID), nil
+}
+
+// SetActionOwner sets the user owning an action
+func SetActionOwner(dbPath string, actionID, ownerID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE action SET owner_id = ? WHERE id = ?", ownerID, actionID)
+	return err
 }
 
 // calculateNextDueDate calculates the next due date based on the interval and pattern
@@ -241,7 +568,7 @@ func calculateNextDueDate(currentDueDate, interval, pattern string) (time.Time,
 		return time.Now(), fmt.Errorf("no current due date")
 	}
 
-	date, err := time.Parse("2006-01-02", currentDueDate)
+	date, err := ParseStoredDate(currentDueDate)
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -264,6 +591,29 @@ func calculateNextDueDate(currentDueDate, interval, pattern string) (time.Time,
 	}
 }
 
+// nullIfEmpty stores empty dates as NULL, the sqlite driver reads an empty
+// DATE value back as the zero time
+func nullIfEmpty(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// StoredDate returns the date part of a date read from the database. The sqlite
+// driver returns DATE columns as full timestamps.
+func StoredDate(value string) string {
+	if len(value) > 10 {
+		return value[:10]
+	}
+	return value
+}
+
+// ParseStoredDate parses a date read from the database
+func ParseStoredDate(value string) (time.Time, error) {
+	return time.Parse("2006-01-02", StoredDate(value))
+}
+
 // calculateNextWeeklyDate calculates the next weekly date based on the pattern
 func calculateNextWeeklyDate(currentDate time.Time, pattern string) (time.Time, error) {
 	if pattern == "" {
@@ -272,7 +622,7 @@ func calculateNextWeeklyDate(currentDate time.Time, pattern string) (time.Time,
 	}
 
 	// Parse pattern like "mon,tue,wed,thu,fri" or "monday,tuesday,wednesday,thursday,friday"
-	days := parseWeeklyPattern(pattern)
+	days := ParseWeeklyPattern(pattern)
 	if len(days) == 0 {
 		return currentDate.AddDate(0, 0, 7), nil
 	}
@@ -299,8 +649,8 @@ func calculateNextWeeklyDate(currentDate time.Time, pattern string) (time.Time,
 	return nextWeek.AddDate(0, 0, daysToAdd), nil
 }
 
-// parseWeeklyPattern parses weekly pattern string into weekday numbers
-func parseWeeklyPattern(pattern string) []int {
+// ParseWeeklyPattern parses weekly pattern string into weekday numbers
+func ParseWeeklyPattern(pattern string) []int {
 	var days []int
 	parts := strings.Split(strings.ToLower(pattern), ",")
 
@@ -342,6 +692,9 @@ func MarkActionAsDone(dbPath string, actionID uint) error {
 	if action == nil {
 		return fmt.Errorf("action not found")
 	}
+	if err := CheckStatusTransition(dbPath, action.StatusID, 2); err != nil {
+		return err
+	}
 
 	// Update status to done (assuming status ID 2 is 'done')
 	_, err = db.Exec("UPDATE action SET status_id = 2 WHERE id = ?", actionID)
@@ -350,7 +703,7 @@ func MarkActionAsDone(dbPath string, actionID uint) error {
 	}
 
 	// If action has repetition configured, create the next occurrence
-	if action.RepeatCount > 0 && action.RepeatInterval.Valid {
+	if action.IsRepeating() {
 		_, err = CreateNextRepeatedAction(dbPath, action)
 		if err != nil {
 			// Log the error but don't fail the operation
@@ -358,18 +711,321 @@ func MarkActionAsDone(dbPath string, actionID uint) error {
 		}
 	}
 
+	// Finishing the last action of a recurring project starts its next period
+	if action.ProjectID.Valid {
+		_, err = CreateNextProjectPeriod(dbPath, uint(action.ProjectID.Int64))
+		if err != nil {
+			fmt.Printf("Warning: Failed to create next project period: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
-// DeleteAction deletes an action from the database
-func DeleteAction(dbPath string, actionID uint) error {
+// ActionUpdate holds the changes to apply to an action. Nil fields are left
+// untouched; a ProjectID of 0 removes the action from its project and an empty
+// DueDate or RepeatUntil clears the date.
+type ActionUpdate struct {
+	Name           *string
+	Note           *string
+	ProjectID      *uint
+	DueDate        *string
+	StatusID       *uint
+	RepeatMode     *string
+	RepeatCount    *uint
+	RepeatInterval *string
+	RepeatPattern  *string
+	RepeatUntil    *string
+	AssigneeID     *uint // 0 removes the assignee
+	Flagged        *bool
+	Location       *string // Empty removes the location and its coordinates
+	Coordinates    *Coordinates
+	Energy         *string // Empty removes the energy level
+	WaitingOn      *string // Empty removes who the action waits on and the follow-up date
+	FollowUp       *string // Empty clears the follow-up date
+	StartDate      *string // Empty clears the start date
+	// Version is the version of the action the changes are based on. The
+	// update fails with a VersionConflictError when the action has changed
+	// since.
+	Version *uint
+}
+
+// UpdateAction applies the given changes to an existing action
+func UpdateAction(dbPath string, actionID uint, update ActionUpdate) error {
+	args, err := prepareActionUpdate(dbPath, actionID, update)
+	if err != nil {
+		return err
+	}
+
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %v", err)
 	}
 	defer db.Close()
 
-	// Check if action exists
+	result, err := db.Exec(updateActionQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update action: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 && update.Version != nil {
+		return versionConflict(db, "action", actionID, *update.Version)
+	}
+
+	return nil
+}
+
+// updateActionQuery writes the fields of an action, when its version is the
+// one the changes are based on, if any
+const updateActionQuery = `
+	UPDATE action
+	SET name = ?, note = ?, project_id = ?, due_date = ?, status_id = ?,
+		repeat_mode = ?, repeat_count = ?, repeat_interval = ?, repeat_pattern = ?, repeat_until = ?,
+		assignee_id = ?, flagged = ?, location = ?, latitude = ?, longitude = ?, energy = ?,
+		waiting_on = ?, follow_up = ?, start_date = ?
+	WHERE id = ? AND (? IS NULL OR version = ?)
+`
+
+// prepareActionUpdate merges the changes into an action and validates the
+// result, returning the arguments of updateActionQuery
+func prepareActionUpdate(dbPath string, actionID uint, update ActionUpdate) ([]interface{}, error) {
+	action, err := GetActionByID(dbPath, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("error checking action existence: %v", err)
+	}
+	if action == nil {
+		return nil, fmt.Errorf("action not found")
+	}
+
+	if update.Name != nil {
+		action.Name = *update.Name
+	}
+	if update.Note != nil {
+		action.Note = sql.NullString{String: *update.Note, Valid: true}
+	}
+	if update.ProjectID != nil {
+		action.ProjectID = sql.NullInt64{Int64: int64(*update.ProjectID), Valid: *update.ProjectID != 0}
+	}
+	if update.StatusID != nil {
+		if err := CheckStatusTransition(dbPath, action.StatusID, *update.StatusID); err != nil {
+			return nil, err
+		}
+		action.StatusID = *update.StatusID
+	}
+	if update.AssigneeID != nil {
+		action.AssigneeID = sql.NullInt64{Int64: int64(*update.AssigneeID), Valid: *update.AssigneeID != 0}
+	}
+	if update.Flagged != nil {
+		action.Flagged = *update.Flagged
+	}
+	if update.Location != nil {
+		action.Location = sql.NullString{String: *update.Location, Valid: *update.Location != ""}
+		if *update.Location == "" {
+			action.Latitude, action.Longitude = sql.NullFloat64{}, sql.NullFloat64{}
+		}
+	}
+	if update.Coordinates != nil {
+		if err := update.Coordinates.Validate(); err != nil {
+			return nil, err
+		}
+		action.Latitude = sql.NullFloat64{Float64: update.Coordinates.Latitude, Valid: true}
+		action.Longitude = sql.NullFloat64{Float64: update.Coordinates.Longitude, Valid: true}
+	}
+	if update.Energy != nil {
+		action.Energy = sql.NullString{String: *update.Energy, Valid: *update.Energy != ""}
+	}
+	if update.WaitingOn != nil {
+		action.WaitingOn = sql.NullString{String: *update.WaitingOn, Valid: *update.WaitingOn != ""}
+		if *update.WaitingOn == "" {
+			action.FollowUp = sql.NullString{}
+		}
+	}
+	if update.FollowUp != nil {
+		action.FollowUp = sql.NullString{String: *update.FollowUp, Valid: *update.FollowUp != ""}
+	}
+	if update.StartDate != nil {
+		action.StartDate = sql.NullString{String: *update.StartDate, Valid: *update.StartDate != ""}
+	}
+	if update.DueDate != nil {
+		action.DueDate = sql.NullString{String: *update.DueDate, Valid: *update.DueDate != ""}
+	}
+
+	// Merge the repeat configuration before validating it as a whole
+	repeatMode := action.EffectiveRepeatMode()
+	if update.RepeatMode != nil {
+		repeatMode = *update.RepeatMode
+	}
+	if update.RepeatCount != nil {
+		action.RepeatCount = *update.RepeatCount
+	}
+	if update.RepeatInterval != nil {
+		action.RepeatInterval = sql.NullString{String: *update.RepeatInterval, Valid: true}
+	}
+	if update.RepeatPattern != nil {
+		action.RepeatPattern = sql.NullString{String: *update.RepeatPattern, Valid: true}
+	}
+	if update.RepeatUntil != nil {
+		action.RepeatUntil = sql.NullString{String: *update.RepeatUntil, Valid: *update.RepeatUntil != ""}
+	}
+
+	// Only the dates that change are validated, existing dates may lie in the
+	// past. The references are checked when they change as well.
+	input := ActionInput{
+		Name:           action.Name,
+		StatusID:       action.StatusID,
+		DueDate:        action.DueDate.String,
+		StartDate:      action.StartDate.String,
+		FollowUp:       action.FollowUp.String,
+		RepeatMode:     repeatMode,
+		RepeatCount:    action.RepeatCount,
+		RepeatInterval: action.RepeatInterval.String,
+		RepeatUntil:    action.RepeatUntil.String,
+		Energy:         action.Energy.String,
+		ProjectID:      update.ProjectID,
+		AssigneeID:     update.AssigneeID,
+		KeepDueDate:    update.DueDate == nil,
+		KeepStartDate:  update.StartDate == nil,
+		KeepFollowUp:   update.FollowUp == nil,
+	}
+	if err := ValidateAction(dbPath, &input); err != nil {
+		return nil, err
+	}
+	repeatMode = input.RepeatMode
+	action.DueDate.String = input.DueDate
+	action.Energy.String = input.Energy
+
+	return []interface{}{
+		action.Name,
+		action.Note,
+		action.ProjectID,
+		action.DueDate,
+		action.StatusID,
+		repeatMode,
+		action.RepeatCount,
+		action.RepeatInterval,
+		action.RepeatPattern,
+		action.RepeatUntil,
+		action.AssigneeID,
+		action.Flagged,
+		action.Location,
+		action.Latitude,
+		action.Longitude,
+		action.Energy,
+		action.WaitingOn,
+		action.FollowUp,
+		action.StartDate,
+		actionID,
+		update.Version,
+		update.Version,
+	}, nil
+}
+
+// GetSeriesOccurrenceIDs returns the ID of the given action followed by the IDs
+// of all later occurrences that were created from it by repetition
+func GetSeriesOccurrenceIDs(dbPath string, actionID uint) ([]uint, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		WITH RECURSIVE series(id) AS (
+			SELECT id FROM action WHERE id = ?
+			UNION ALL
+			SELECT a.id FROM action a JOIN series s ON a.parent_action_id = s.id
+		)
+		SELECT id FROM series ORDER BY id
+	`
+
+	rows, err := db.Query(query, actionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// UpdateActionSeries applies the given changes to an action and all of its later
+// occurrences. The due date is only changed on the given action, later
+// occurrences keep their own schedule. It returns the number of updated actions.
+func UpdateActionSeries(dbPath string, actionID uint, update ActionUpdate) (int, error) {
+	ids, err := GetSeriesOccurrenceIDs(dbPath, actionID)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("action not found")
+	}
+
+	// Every occurrence is validated before any is written, and all are
+	// written in one transaction, so the series is never left half-edited
+	args, err := prepareActionUpdate(dbPath, actionID, update)
+	if err != nil {
+		return 0, err
+	}
+	targets, updates := []uint{actionID}, [][]interface{}{args}
+
+	// The version is that of the given action, later occurrences have their own
+	futureUpdate := update
+	futureUpdate.DueDate = nil
+	futureUpdate.Flagged = nil
+	futureUpdate.Version = nil
+
+	for _, id := range ids {
+		if id == actionID {
+			continue
+		}
+		args, err := prepareActionUpdate(dbPath, id, futureUpdate)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update occurrence %d: %v", id, err)
+		}
+		targets, updates = append(targets, id), append(updates, args)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for i, args := range updates {
+		result, err := tx.Exec(updateActionQuery, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update occurrence %d, no occurrence was changed: %v", targets[i], err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 && i == 0 && update.Version != nil {
+			tx.Rollback()
+			return 0, versionConflict(db, "action", actionID, *update.Version)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return len(ids), nil
+}
+
+// DetachAction removes a single occurrence from its repeat series. Later
+// occurrences are linked to the previous occurrence so the series stays intact,
+// and the detached action no longer repeats.
+func DetachAction(dbPath string, actionID uint) error {
 	action, err := GetActionByID(dbPath, actionID)
 	if err != nil {
 		return fmt.Errorf("error checking action existence: %v", err)
@@ -378,12 +1034,38 @@ func DeleteAction(dbPath string, actionID uint) error {
 		return fmt.Errorf("action not found")
 	}
 
-	// Delete the action
-	query := "DELETE FROM action WHERE id = ?"
-	_, err = db.Exec(query, actionID)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Link later occurrences to the previous occurrence
+	_, err = tx.Exec("UPDATE action SET parent_action_id = ? WHERE parent_action_id = ?", action.ParentActionID, actionID)
 	if err != nil {
-		return fmt.Errorf("failed to delete action: %v", err)
+		return fmt.Errorf("failed to relink series: %v", err)
+	}
+
+	_, err = tx.Exec("UPDATE action SET parent_action_id = NULL, repeat_mode = ? WHERE id = ?", RepeatModeNone, actionID)
+	if err != nil {
+		return fmt.Errorf("failed to detach action: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
 	return nil
 }
+
+// DeleteAction deletes an action by moving it to the trash, from where it
+// can be restored until the trash is emptied
+func DeleteAction(dbPath string, actionID uint) error {
+	return TrashAction(dbPath, actionID)
+}
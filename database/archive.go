@@ -0,0 +1,248 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ArchivedAction is a done action moved out of the action table, so that it
+// no longer weighs on listings. It can be restored with RestoreArchived.
+type ArchivedAction struct {
+	ID          uint
+	ActionID    uint
+	Name        string
+	ProjectName sql.NullString
+	CompletedAt string
+	ArchivedAt  string
+}
+
+// GetArchivableActions retrieves the done actions that were completed before
+// a moment, the oldest first. As in GetActionsCompletedOn, the last change of
+// a done action is taken as the moment it was completed. Occurrences of a
+// series that still repeats are kept, since GetHabits reads the history of
+// the habit from them.
+func GetArchivableActions(dbPath string, before time.Time) ([]Action, error) {
+	actions, err := queryActions(dbPath, "WHERE a.status_id = 2 AND a.updated_at < ? ORDER BY a.updated_at, a.id", before.UTC().Format("2006-01-02T15:04:05.000Z"))
+	if err != nil || len(actions) == 0 {
+		return actions, err
+	}
+
+	habits, err := GetHabits(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	inHabit := make(map[uint]bool)
+	for _, habit := range habits {
+		for _, occurrence := range habit.Occurrences {
+			inHabit[occurrence.ActionID] = true
+		}
+	}
+
+	var archivable []Action
+	for _, action := range actions {
+		if !inHabit[action.ID] {
+			archivable = append(archivable, action)
+		}
+	}
+	return archivable, nil
+}
+
+// ArchiveDoneActions moves the done actions completed before a moment to the
+// archive, with their tags, attachments and reminders, in a single
+// transaction. Series that still repeat stay out of the archive; later
+// occurrences of an archived series that ended become the start of their own. Archiving is local: no tombstone is recorded, so sync
+// peers keep their copy. It returns the number of actions archived.
+func ArchiveDoneActions(dbPath string, before time.Time) (int, error) {
+	actions, err := GetArchivableActions(dbPath, before)
+	if err != nil {
+		return 0, err
+	}
+	if len(actions) == 0 {
+		return 0, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, action := range actions {
+		if err := archiveAction(tx, &action); err != nil {
+			return 0, fmt.Errorf("action %d: %v", action.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return len(actions), nil
+}
+
+// archiveAction snapshots an action into the archive and deletes it
+func archiveAction(tx *sql.Tx, action *Action) error {
+	data, err := snapshotAction(tx, action.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := snapshotRows(tx, "action", "id = ?", action.ID)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("action not found")
+	}
+	data.Row = rows[0]
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode action: %v", err)
+	}
+
+	var uid sql.NullString
+	var completedAt string
+	if err := tx.QueryRow("SELECT uid, updated_at FROM action WHERE id = ?", action.ID).Scan(&uid, &completedAt); err != nil {
+		return fmt.Errorf("failed to read action: %v", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO action_archive (action_id, name, project_name, data, completed_at, archived_at) VALUES (?, ?, ?, ?, ?, "+sqlNow+")",
+		action.ID, action.Name, action.ProjectName, string(encoded), completedAt)
+	if err != nil {
+		return fmt.Errorf("failed to archive action: %v", err)
+	}
+
+	if _, err := tx.Exec("UPDATE action SET parent_action_id = NULL WHERE parent_action_id = ?", action.ID); err != nil {
+		return fmt.Errorf("failed to detach later occurrences: %v", err)
+	}
+	if err := deleteActionRows(tx, action.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM action WHERE id = ?", action.ID); err != nil {
+		return fmt.Errorf("failed to delete action: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tombstone WHERE entity = 'action' AND uid = ?", uid); err != nil {
+		return fmt.Errorf("failed to clear tombstone: %v", err)
+	}
+	return nil
+}
+
+// GetArchive retrieves the archived actions, the most recently completed first
+func GetArchive(dbPath string) ([]ArchivedAction, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, action_id, name, project_name, completed_at, archived_at FROM action_archive ORDER BY completed_at DESC, id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archive: %v", err)
+	}
+	defer rows.Close()
+
+	var archived []ArchivedAction
+	for rows.Next() {
+		var action ArchivedAction
+		if err := rows.Scan(&action.ID, &action.ActionID, &action.Name, &action.ProjectName, &action.CompletedAt, &action.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan archived action: %v", err)
+		}
+		archived = append(archived, action)
+	}
+	return archived, rows.Err()
+}
+
+// RestoreArchived puts an archived action back with its original ID, tags,
+// attachments and reminders. A project or earlier occurrence that no longer
+// exists is left out.
+func RestoreArchived(dbPath string, archiveID uint) (uint, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var actionID uint
+	var encoded string
+	err = tx.QueryRow("SELECT action_id, data FROM action_archive WHERE id = ?", archiveID).Scan(&actionID, &encoded)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("archived action %d not found", archiveID)
+		}
+		return 0, fmt.Errorf("failed to read archive: %v", err)
+	}
+
+	var data trashData
+	if err := json.Unmarshal([]byte(encoded), &data); err != nil {
+		return 0, fmt.Errorf("invalid archive data: %v", err)
+	}
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM action WHERE id = ?", actionID).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to check action: %v", err)
+	}
+	if exists > 0 {
+		return 0, fmt.Errorf("action %d already exists", actionID)
+	}
+
+	if err := restoreRow(tx, "action", data.Row); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`
+		UPDATE action SET
+			project_id = CASE WHEN project_id IN (SELECT id FROM project) THEN project_id END,
+			parent_action_id = CASE WHEN parent_action_id IN (SELECT id FROM action) THEN parent_action_id END
+		WHERE id = ?`, actionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check references: %v", err)
+	}
+	if err := restoreActionRows(tx, actionID, data); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM action_archive WHERE id = ?", archiveID); err != nil {
+		return 0, fmt.Errorf("failed to remove from archive: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return actionID, nil
+}
+
+// RunArchive archives the done actions completed more than afterDays days
+// ago, checking every hour until stop is closed
+func RunArchive(dbPath string, afterDays int, stop <-chan struct{}, onArchived func(int), onError func(error)) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		archived, err := ArchiveDoneActions(dbPath, time.Now().AddDate(0, 0, -afterDays))
+		if err != nil {
+			onError(err)
+		} else if archived > 0 {
+			onArchived(archived)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Attachment is a link attached to an action, such as the page an action was
+// captured from
+type Attachment struct {
+	ID        uint
+	ActionID  uint
+	URL       string
+	Title     sql.NullString
+	CreatedAt string
+}
+
+// ValidateAttachmentURL checks that an attachment is an absolute http or
+// https URL
+func ValidateAttachmentURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid URL: %s. Expected an http or https URL", rawURL)
+	}
+	return nil
+}
+
+// AddAttachment attaches a URL with an optional title to an action
+func AddAttachment(dbPath string, actionID uint, rawURL, title string) (uint, error) {
+	if err := ValidateAttachmentURL(rawURL); err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("INSERT INTO attachment (action_id, url, title, created_at) VALUES (?, ?, ?, "+sqlNow+")", actionID, rawURL, nullIfEmpty(title))
+	if err != nil {
+		return 0, fmt.Errorf("failed to add attachment: %v", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+// GetAttachments retrieves the attachments of an action in the order they
+// were added
+func GetAttachments(dbPath string, actionID uint) ([]Attachment, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, action_id, url, title, created_at FROM attachment WHERE action_id = ? ORDER BY id", actionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %v", err)
+	}
+	defer rows.Close()
+
+	var attachments []Attachment
+	for rows.Next() {
+		var attachment Attachment
+		if err := rows.Scan(&attachment.ID, &attachment.ActionID, &attachment.URL, &attachment.Title, &attachment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %v", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+// GetAllAttachments retrieves the attachments of all actions by action ID,
+// in the order they were added
+func GetAllAttachments(dbPath string) (map[uint][]Attachment, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, action_id, url, title, created_at FROM attachment ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %v", err)
+	}
+	defer rows.Close()
+
+	attachments := make(map[uint][]Attachment)
+	for rows.Next() {
+		var attachment Attachment
+		if err := rows.Scan(&attachment.ID, &attachment.ActionID, &attachment.URL, &attachment.Title, &attachment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %v", err)
+		}
+		attachments[attachment.ActionID] = append(attachments[attachment.ActionID], attachment)
+	}
+
+	return attachments, rows.Err()
+}
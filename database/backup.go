@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// BackupDatabase writes a consistent copy of the database into dir, named
+// after the current time, and returns its path. The copy is made with VACUUM
+// INTO, so it is safe while the database is in use.
+func BackupDatabase(dbPath, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	backupPath := filepath.Join(dir, "projector-"+time.Now().Format("20060102-150405")+".db")
+	if _, err := os.Stat(backupPath); err == nil {
+		return "", fmt.Errorf("backup already exists: %s", backupPath)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return "", fmt.Errorf("failed to back up database: %v", err)
+	}
+
+	return backupPath, nil
+}
+
+// GetBackupDir returns the directory backups are written to, next to the database
+func GetBackupDir(dbPath string) string {
+	return filepath.Join(GetDataDir(dbPath), "backups")
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// MarkActionsAsDone marks several actions as done in one transaction, so
+// either all of them or none are marked. The next occurrences of repeating
+// actions and the next periods of recurring projects are created afterwards,
+// like MarkActionAsDone does.
+func MarkActionsAsDone(dbPath string, actionIDs []uint) error {
+	var actions []*Action
+	for _, actionID := range actionIDs {
+		action, err := GetActionByID(dbPath, actionID)
+		if err != nil {
+			return err
+		}
+		if action == nil {
+			return fmt.Errorf("action %d not found", actionID)
+		}
+		if err := CheckStatusTransition(dbPath, action.StatusID, 2); err != nil {
+			return fmt.Errorf("action %d: %v", actionID, err)
+		}
+		actions = append(actions, action)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, action := range actions {
+		if _, err := tx.Exec("UPDATE action SET status_id = 2 WHERE id = ?", action.ID); err != nil {
+			return fmt.Errorf("failed to mark action %d as done: %v", action.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	projectIDs := make(map[uint]bool)
+	for _, action := range actions {
+		if action.IsRepeating() {
+			if _, err := CreateNextRepeatedAction(dbPath, action); err != nil {
+				fmt.Printf("Warning: Failed to create next repeated action: %v\n", err)
+			}
+		}
+		if action.ProjectID.Valid {
+			projectIDs[uint(action.ProjectID.Int64)] = true
+		}
+	}
+
+	// Finishing the last actions of a recurring project starts its next period
+	for projectID := range projectIDs {
+		if _, err := CreateNextProjectPeriod(dbPath, projectID); err != nil {
+			fmt.Printf("Warning: Failed to create next project period: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// RetagActions adds and removes tags on several actions in one transaction,
+// creating the added tags when they do not exist yet
+func RetagActions(dbPath string, actionIDs []uint, add, remove []string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range add {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tag (name) VALUES (?)", name); err != nil {
+			return fmt.Errorf("failed to create tag %s: %v", name, err)
+		}
+	}
+
+	for _, actionID := range actionIDs {
+		for _, name := range remove {
+			_, err := tx.Exec("DELETE FROM action_tag WHERE action_id = ? AND tag_id = (SELECT id FROM tag WHERE name = ?)", actionID, name)
+			if err != nil {
+				return fmt.Errorf("failed to remove tag %s: %v", name, err)
+			}
+		}
+		for _, name := range add {
+			_, err := tx.Exec("INSERT OR IGNORE INTO action_tag (action_id, tag_id) SELECT ?, id FROM tag WHERE name = ?", actionID, name)
+			if err != nil {
+				return fmt.Errorf("failed to add tag %s: %v", name, err)
+			}
+		}
+		if len(add) > 0 || len(remove) > 0 {
+			if _, err := tx.Exec(bumpActionVersion, actionID); err != nil {
+				return fmt.Errorf("failed to update action version: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// RescheduleActions moves several actions to a new due date (YYYY-MM-DD) in
+// one transaction. A start date after the new due date moves along to it, so
+// that the actions stay valid.
+func RescheduleActions(dbPath string, actionIDs []uint, dueDate string) error {
+	if _, err := ValidateDate(dueDate); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, actionID := range actionIDs {
+		result, err := tx.Exec(`
+			UPDATE action
+			SET due_date = ?1, start_date = CASE WHEN date(start_date) > date(?1) THEN ?1 ELSE start_date END
+			WHERE id = ?2
+		`, dueDate, actionID)
+		if err != nil {
+			return fmt.Errorf("failed to reschedule action %d: %v", actionID, err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return fmt.Errorf("action %d not found", actionID)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,561 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/wire"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ChangeTimeFormat is the format of the sync timestamps. It sorts lexically, so
+// timestamps can be compared as strings in SQL.
+//
+// updated_at and deleted_at hold the time of the original write and decide
+// which write wins. changed_at holds the time the row last changed on this
+// instance, including changes applied from elsewhere, and is what the sync
+// cursors compare against, so changes relayed by a shared server are not missed.
+const ChangeTimeFormat = "2006-01-02T15:04:05.000Z"
+
+// sqlNow is the SQL expression for the current time in ChangeTimeFormat
+const sqlNow = "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')"
+
+// syncTriggers keep the uid, updated_at and changed_at columns up to date and
+// record a tombstone for deleted rows, so every write is picked up by remote
+// sync. The triggers are disabled while ApplyChanges holds a row in sync_apply.
+var syncTriggers = map[string][]string{
+	"project": {
+		`CREATE TABLE IF NOT EXISTS sync_apply (id INTEGER PRIMARY KEY)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_project_uid ON project (uid)`,
+		`CREATE TRIGGER IF NOT EXISTS project_sync_insert AFTER INSERT ON project
+		WHEN (NEW.uid IS NULL OR NEW.updated_at IS NULL) AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE project SET uid = COALESCE(NEW.uid, lower(hex(randomblob(16)))), updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS project_sync_update AFTER UPDATE ON project
+		WHEN NEW.updated_at IS OLD.updated_at AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE project SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS project_sync_delete AFTER DELETE ON project
+		WHEN OLD.uid IS NOT NULL AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			INSERT OR REPLACE INTO tombstone (entity, uid, deleted_at, changed_at) VALUES ('project', OLD.uid, ` + sqlNow + `, ` + sqlNow + `);
+		END`,
+	},
+	"action": {
+		`CREATE TABLE IF NOT EXISTS sync_apply (id INTEGER PRIMARY KEY)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_action_uid ON action (uid)`,
+		`CREATE TRIGGER IF NOT EXISTS action_sync_insert AFTER INSERT ON action
+		WHEN (NEW.uid IS NULL OR NEW.updated_at IS NULL) AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET uid = COALESCE(NEW.uid, lower(hex(randomblob(16)))), updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS action_sync_update AFTER UPDATE ON action
+		WHEN NEW.updated_at IS OLD.updated_at AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS action_sync_delete AFTER DELETE ON action
+		WHEN OLD.uid IS NOT NULL AND NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			INSERT OR REPLACE INTO tombstone (entity, uid, deleted_at, changed_at) VALUES ('action', OLD.uid, ` + sqlNow + `, ` + sqlNow + `);
+		END`,
+	},
+	"action_tag": {
+		`CREATE TRIGGER IF NOT EXISTS action_tag_sync_insert AFTER INSERT ON action_tag
+		WHEN NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.action_id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS action_tag_sync_delete AFTER DELETE ON action_tag
+		WHEN NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = OLD.action_id;
+		END`,
+	},
+	"reminder": {
+		`CREATE TRIGGER IF NOT EXISTS reminder_sync_insert AFTER INSERT ON reminder
+		WHEN NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = NEW.action_id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS reminder_sync_delete AFTER DELETE ON reminder
+		WHEN NOT EXISTS (SELECT 1 FROM sync_apply)
+		BEGIN
+			UPDATE action SET updated_at = ` + sqlNow + `, changed_at = ` + sqlNow + ` WHERE id = OLD.action_id;
+		END`,
+	},
+}
+
+// ProjectChange is a project as exchanged during remote sync
+type ProjectChange = wire.ProjectChange
+
+// ActionChange is an action as exchanged during remote sync
+type ActionChange = wire.ActionChange
+
+// Tombstone records the deletion of a project or action
+type Tombstone = wire.Tombstone
+
+// Changes holds everything that changed since a point in time
+type Changes = wire.Changes
+
+// ApplyResult summarizes the outcome of ApplyChanges
+type ApplyResult = wire.ApplyResult
+
+// CreateSyncSchema creates the sync triggers and gives existing rows a uid
+// and timestamps
+func CreateSyncSchema(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, table := range []string{"project", "action", "action_tag", "reminder"} {
+		for _, statement := range syncTriggers[table] {
+			if _, err := db.Exec(statement); err != nil {
+				return fmt.Errorf("failed to create sync triggers for %s: %v", table, err)
+			}
+		}
+	}
+
+	for _, table := range []string{"project", "action"} {
+		_, err := db.Exec(fmt.Sprintf("UPDATE %s SET uid = lower(hex(randomblob(16))) WHERE uid IS NULL", table))
+		if err != nil {
+			return fmt.Errorf("failed to assign uids to %s: %v", table, err)
+		}
+		_, err = db.Exec(fmt.Sprintf("UPDATE %s SET updated_at = %s, changed_at = %s WHERE updated_at IS NULL", table, sqlNow, sqlNow))
+		if err != nil {
+			return fmt.Errorf("failed to set updated_at on %s: %v", table, err)
+		}
+	}
+
+	return nil
+}
+
+// GetChangesSince retrieves all projects, actions and tombstones that changed
+// on this instance at or after since (ChangeTimeFormat). An empty since
+// returns everything. A non-zero ownerID limits the projects and actions to
+// those owned by that user.
+func GetChangesSince(dbPath, since string, ownerID uint) (*Changes, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// Read the clock first, so changes made while reading are picked up next time
+	changes := &Changes{Until: time.Now().UTC().Format(ChangeTimeFormat)}
+
+	rows, err := db.Query(`
+		SELECT uid, name, COALESCE(due_date, ''), COALESCE(repeat_interval, ''), COALESCE(review_interval, ''),
+			COALESCE(last_reviewed_at, ''), updated_at
+		FROM project
+		WHERE uid IS NOT NULL AND changed_at >= ? AND (? = 0 OR owner_id = ?)
+		ORDER BY id`, since, ownerID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read projects: %v", err)
+	}
+	for rows.Next() {
+		var project ProjectChange
+		err := rows.Scan(&project.UID, &project.Name, &project.DueDate, &project.RepeatInterval, &project.ReviewInterval,
+			&project.LastReviewedAt, &project.UpdatedAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		project.DueDate = StoredDate(project.DueDate)
+		changes.Projects = append(changes.Projects, project)
+	}
+	rows.Close()
+
+	tags, err := GetAllActionTags(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %v", err)
+	}
+
+	// Parents are created before their children, so ordering by ID lets
+	// ApplyChanges link them in a single pass
+	changes.Actions, err = queryActionChanges(db, tags, "a.uid IS NOT NULL AND a.changed_at >= ? AND (? = 0 OR a.owner_id = ?) ORDER BY a.id", since, ownerID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT entity, uid, deleted_at
+		FROM tombstone
+		WHERE changed_at >= ?
+		ORDER BY changed_at`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tombstones: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tombstone Tombstone
+		if err := rows.Scan(&tombstone.Entity, &tombstone.UID, &tombstone.DeletedAt); err != nil {
+			return nil, err
+		}
+		changes.Tombstones = append(changes.Tombstones, tombstone)
+	}
+
+	return changes, nil
+}
+
+// ApplyChanges merges changes from another instance into the database. The
+// most recent write wins: a change is only applied when it is newer than the
+// local row, and a tombstone only deletes a row that was not changed after the
+// deletion. A row changed after it was deleted elsewhere is recreated.
+//
+// A non-zero ownerID applies the changes on behalf of that user: new rows are
+// owned by the user and rows owned by anyone else are left untouched.
+func ApplyChanges(dbPath string, changes *Changes, ownerID uint) (*ApplyResult, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Keep the remote timestamps instead of letting the triggers stamp the rows
+	if _, err := tx.Exec("INSERT INTO sync_apply (id) VALUES (1)"); err != nil {
+		return nil, fmt.Errorf("failed to disable sync triggers: %v", err)
+	}
+
+	result := &ApplyResult{}
+	changedAt := time.Now().UTC().Format(ChangeTimeFormat)
+
+	var owner sql.NullInt64
+	if ownerID != 0 {
+		owner = sql.NullInt64{Int64: int64(ownerID), Valid: true}
+	}
+
+	for _, project := range changes.Projects {
+		apply, id, err := shouldApply(tx, "project", project.UID, project.UpdatedAt, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if !apply {
+			result.Skipped++
+			continue
+		}
+
+		if id == 0 {
+			_, err = tx.Exec(`
+				INSERT INTO project (name, due_date, repeat_interval, review_interval, last_reviewed_at, uid, updated_at, changed_at, owner_id)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				project.Name, nullIfEmpty(project.DueDate), nullIfEmpty(project.RepeatInterval), nullIfEmpty(project.ReviewInterval),
+				nullIfEmpty(project.LastReviewedAt), project.UID, project.UpdatedAt, changedAt, owner)
+		} else {
+			_, err = tx.Exec(`
+				UPDATE project
+				SET name = ?, due_date = ?, repeat_interval = ?, review_interval = ?, last_reviewed_at = ?, updated_at = ?, changed_at = ?
+				WHERE id = ?`,
+				project.Name, nullIfEmpty(project.DueDate), nullIfEmpty(project.RepeatInterval), nullIfEmpty(project.ReviewInterval),
+				nullIfEmpty(project.LastReviewedAt), project.UpdatedAt, changedAt, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply project %s: %v", project.UID, err)
+		}
+		if _, err := tx.Exec("DELETE FROM tombstone WHERE entity = 'project' AND uid = ?", project.UID); err != nil {
+			return nil, err
+		}
+		result.Applied++
+	}
+
+	for _, action := range changes.Actions {
+		apply, id, err := shouldApply(tx, "action", action.UID, action.UpdatedAt, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if !apply {
+			result.Skipped++
+			continue
+		}
+
+		projectID, err := lookupID(tx, "SELECT id FROM project WHERE uid = ? AND (? = 0 OR owner_id = ?)", action.ProjectUID, ownerID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		parentID, err := lookupID(tx, "SELECT id FROM action WHERE uid = ? AND (? = 0 OR owner_id = ?)", action.ParentUID, ownerID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		statusID, err := lookupID(tx, "SELECT id FROM status WHERE name = ?", action.Status)
+		if err != nil {
+			return nil, err
+		}
+		if !statusID.Valid {
+			statusID = sql.NullInt64{Int64: 1, Valid: true}
+		}
+
+		if id == 0 {
+			res, err := tx.Exec("INSERT INTO action (name, status_id, uid, updated_at, owner_id) VALUES (?, ?, ?, ?, ?)",
+				action.Name, statusID, action.UID, action.UpdatedAt, owner)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply action %s: %v", action.UID, err)
+			}
+			newID, err := res.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			id = uint(newID)
+		}
+
+		_, err = tx.Exec(`
+			UPDATE action
+			SET project_id = ?, name = ?, note = ?, due_date = ?, status_id = ?, repeat_mode = ?,
+				repeat_count = ?, repeat_interval = ?, repeat_pattern = ?, repeat_until = ?,
+				parent_action_id = ?, flagged = ?, location = ?, latitude = ?, longitude = ?, energy = ?,
+				waiting_on = ?, follow_up = ?, start_date = ?, updated_at = ?, changed_at = ?
+			WHERE id = ?`,
+			projectID, action.Name, nullIfEmpty(action.Note), nullIfEmpty(action.DueDate), statusID,
+			nullIfEmpty(action.RepeatMode), action.RepeatCount, nullIfEmpty(action.RepeatInterval),
+			nullIfEmpty(action.RepeatPattern), nullIfEmpty(action.RepeatUntil), parentID, action.Flagged,
+			nullIfEmpty(action.Location), action.Latitude, action.Longitude, nullIfEmpty(action.Energy),
+			nullIfEmpty(action.WaitingOn), nullIfEmpty(action.FollowUp), nullIfEmpty(action.StartDate),
+			action.UpdatedAt, changedAt, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply action %s: %v", action.UID, err)
+		}
+
+		if err := setActionTagsTx(tx, id, action.Tags); err != nil {
+			return nil, fmt.Errorf("failed to apply tags of action %s: %v", action.UID, err)
+		}
+		if err := setRemindersTx(tx, id, action.Reminders); err != nil {
+			return nil, fmt.Errorf("failed to apply reminders of action %s: %v", action.UID, err)
+		}
+		if _, err := tx.Exec("DELETE FROM tombstone WHERE entity = 'action' AND uid = ?", action.UID); err != nil {
+			return nil, err
+		}
+		result.Applied++
+	}
+
+	for _, tombstone := range changes.Tombstones {
+		if tombstone.Entity != "project" && tombstone.Entity != "action" {
+			continue
+		}
+
+		var id uint
+		var updatedAt string
+		var rowOwner sql.NullInt64
+		query := fmt.Sprintf("SELECT id, COALESCE(updated_at, ''), owner_id FROM %s WHERE uid = ?", tombstone.Entity)
+		err := tx.QueryRow(query, tombstone.UID).Scan(&id, &updatedAt, &rowOwner)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		if err == nil {
+			if ownerID != 0 && rowOwner != owner {
+				result.Skipped++
+				continue
+			}
+			if updatedAt > tombstone.DeletedAt {
+				// Changed after the deletion, the row will be recreated on the other side
+				result.Skipped++
+				continue
+			}
+
+			if tombstone.Entity == "project" {
+				_, err = tx.Exec("UPDATE action SET project_id = NULL WHERE project_id = ?", id)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				_, err = tx.Exec("UPDATE action SET parent_action_id = NULL WHERE parent_action_id = ?", id)
+				if err != nil {
+					return nil, err
+				}
+				_, err = tx.Exec("DELETE FROM action_tag WHERE action_id = ?", id)
+				if err != nil {
+					return nil, err
+				}
+				_, err = tx.Exec("DELETE FROM attachment WHERE action_id = ?", id)
+				if err != nil {
+					return nil, err
+				}
+				_, err = tx.Exec("DELETE FROM reminder WHERE action_id = ?", id)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", tombstone.Entity), id); err != nil {
+				return nil, fmt.Errorf("failed to delete %s %s: %v", tombstone.Entity, tombstone.UID, err)
+			}
+			result.Deleted++
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO tombstone (entity, uid, deleted_at, changed_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (entity, uid) DO UPDATE SET deleted_at = MAX(deleted_at, excluded.deleted_at), changed_at = excluded.changed_at`,
+			tombstone.Entity, tombstone.UID, tombstone.DeletedAt, changedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record tombstone: %v", err)
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM sync_apply"); err != nil {
+		return nil, fmt.Errorf("failed to enable sync triggers: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return result, nil
+}
+
+// queryActionChanges reads the actions matching a condition on the action
+// table, aliased a, as exchanged during remote sync. Tags holds the tag names
+// by action ID, see GetAllActionTags.
+func queryActionChanges(db *sql.DB, tags map[uint][]string, condition string, args ...interface{}) ([]ActionChange, error) {
+	rows, err := db.Query(`
+		SELECT a.id, a.uid, COALESCE(p.uid, ''), a.name, COALESCE(a.note, ''), COALESCE(a.due_date, ''),
+			s.name, COALESCE(a.repeat_mode, ''), COALESCE(a.repeat_count, 0), COALESCE(a.repeat_interval, ''),
+			COALESCE(a.repeat_pattern, ''), COALESCE(a.repeat_until, ''), COALESCE(parent.uid, ''), a.flagged,
+			COALESCE(a.location, ''), a.latitude, a.longitude, COALESCE(a.energy, ''), COALESCE(a.waiting_on, ''),
+			COALESCE(a.follow_up, ''), COALESCE(a.start_date, ''),
+			(SELECT json_group_array(r.due_offset ORDER BY r.id) FROM reminder r WHERE r.action_id = a.id),
+			a.updated_at
+		FROM action a
+		JOIN status s ON s.id = a.status_id
+		LEFT JOIN project p ON p.id = a.project_id
+		LEFT JOIN action parent ON parent.id = a.parent_action_id
+		WHERE `+condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actions: %v", err)
+	}
+	defer rows.Close()
+
+	var actions []ActionChange
+	for rows.Next() {
+		var id uint
+		var action ActionChange
+		var latitude, longitude sql.NullFloat64
+		err := rows.Scan(&id, &action.UID, &action.ProjectUID, &action.Name, &action.Note, &action.DueDate,
+			&action.Status, &action.RepeatMode, &action.RepeatCount, &action.RepeatInterval,
+			&action.RepeatPattern, &action.RepeatUntil, &action.ParentUID, &action.Flagged,
+			&action.Location, &latitude, &longitude, &action.Energy, &action.WaitingOn,
+			&action.FollowUp, &action.StartDate, (*reminderOffsets)(&action.Reminders), &action.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if latitude.Valid && longitude.Valid {
+			action.Latitude, action.Longitude = &latitude.Float64, &longitude.Float64
+		}
+		action.DueDate = StoredDate(action.DueDate)
+		action.RepeatUntil = StoredDate(action.RepeatUntil)
+		action.FollowUp = StoredDate(action.FollowUp)
+		action.StartDate = StoredDate(action.StartDate)
+		action.Tags = tags[id]
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// GetSyncPeer retrieves the pull and push cursors for a remote instance. Both
+// are empty when the instance has never been synced.
+func GetSyncPeer(dbPath, url string) (pulledAt, pushedAt string, err error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return "", "", err
+	}
+	defer db.Close()
+
+	err = db.QueryRow("SELECT COALESCE(pulled_at, ''), COALESCE(pushed_at, '') FROM sync_peer WHERE url = ?", url).Scan(&pulledAt, &pushedAt)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return pulledAt, pushedAt, err
+}
+
+// SaveSyncPeer stores the pull and push cursors for a remote instance
+func SaveSyncPeer(dbPath, url, pulledAt, pushedAt string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT OR REPLACE INTO sync_peer (url, pulled_at, pushed_at) VALUES (?, ?, ?)", url, pulledAt, pushedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sync peer: %v", err)
+	}
+
+	return nil
+}
+
+// shouldApply reports whether a remote change is newer than both the local
+// row and any local tombstone, and returns the local row ID (0 if missing).
+// With a non-zero ownerID, rows owned by someone else are never changed.
+func shouldApply(tx *sql.Tx, entity, uid, updatedAt string, ownerID uint) (bool, uint, error) {
+	if uid == "" || updatedAt == "" {
+		return false, 0, nil
+	}
+
+	var deletedAt string
+	err := tx.QueryRow("SELECT deleted_at FROM tombstone WHERE entity = ? AND uid = ?", entity, uid).Scan(&deletedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return false, 0, err
+	}
+	if err == nil && deletedAt >= updatedAt {
+		return false, 0, nil
+	}
+
+	var id uint
+	var localUpdatedAt string
+	var localOwner sql.NullInt64
+	query := fmt.Sprintf("SELECT id, COALESCE(updated_at, ''), owner_id FROM %s WHERE uid = ?", entity)
+	err = tx.QueryRow(query, uid).Scan(&id, &localUpdatedAt, &localOwner)
+	if err == sql.ErrNoRows {
+		return true, 0, nil
+	}
+	if err != nil {
+		return false, 0, err
+	}
+	if ownerID != 0 && (!localOwner.Valid || uint(localOwner.Int64) != ownerID) {
+		return false, 0, nil
+	}
+
+	return updatedAt > localUpdatedAt, id, nil
+}
+
+// lookupID runs a query returning a single ID, resulting in NULL when the key
+// is empty or nothing matches
+func lookupID(tx *sql.Tx, query, key string, args ...interface{}) (sql.NullInt64, error) {
+	var id sql.NullInt64
+	if key == "" {
+		return id, nil
+	}
+
+	err := tx.QueryRow(query, append([]interface{}{key}, args...)...).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return id, err
+	}
+	return id, nil
+}
+
+// setActionTagsTx replaces the tags of an action within a transaction
+func setActionTagsTx(tx *sql.Tx, actionID uint, names []string) error {
+	if _, err := tx.Exec("DELETE FROM action_tag WHERE action_id = ?", actionID); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tag (name) VALUES (?)", name); err != nil {
+			return err
+		}
+		_, err := tx.Exec("INSERT OR IGNORE INTO action_tag (action_id, tag_id) SELECT ?, id FROM tag WHERE name = ?", actionID, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// CloneOptions selects what is copied when cloning an action or project
+type CloneOptions struct {
+	Name    string // Name of the copy, defaults to the original name with " (copy)"
+	Actions bool   // Copy the actions of a project
+	Tags    bool
+	Notes   bool
+	Shift   string // Moves the due dates, such as 3d, 2w, 1m or -1d
+}
+
+// ShiftDate moves a date (YYYY-MM-DD) by a shift such as 3d, 2w, 1m or -1d.
+// An empty date or shift leaves the date as is.
+func ShiftDate(date, shift string) (string, error) {
+	if date == "" || shift == "" {
+		return date, nil
+	}
+
+	parsed, err := ParseStoredDate(date)
+	if err != nil {
+		return "", fmt.Errorf("invalid date: %s", date)
+	}
+	if !strings.HasPrefix(shift, "-") && !strings.HasPrefix(shift, "+") {
+		shift = "+" + shift
+	}
+
+	shifted, err := ResolveDateOffset("start"+shift, parsed)
+	if err != nil {
+		return "", fmt.Errorf("invalid shift: %s. Expected a number of days, weeks or months such as 3d, 2w, 1m or -1d", strings.TrimPrefix(shift, "+"))
+	}
+	return shifted, nil
+}
+
+// CloneAction copies an action as a new action with the default status in the
+// same project,
+// keeping its repeat settings and reminders. It returns the ID of the copy.
+func CloneAction(dbPath string, actionID uint, options CloneOptions) (uint, error) {
+	action, err := GetActionByID(dbPath, actionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get action: %v", err)
+	}
+	if action == nil {
+		return 0, fmt.Errorf("action %d not found", actionID)
+	}
+
+	name := options.Name
+	if name == "" {
+		name = action.Name + " (copy)"
+	}
+	var note string
+	if options.Notes {
+		note = action.Note.String
+	}
+	var tags []string
+	if options.Tags {
+		tags = action.Tags
+	}
+
+	dueDate, err := ShiftDate(StoredDate(action.DueDate.String), options.Shift)
+	if err != nil {
+		return 0, err
+	}
+	repeatUntil, err := ShiftDate(StoredDate(action.RepeatUntil.String), options.Shift)
+	if err != nil {
+		return 0, err
+	}
+	startDate, err := ShiftDate(StoredDate(action.StartDate.String), options.Shift)
+	if err != nil {
+		return 0, err
+	}
+
+	statusID, err := DefaultStatusID(dbPath)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until, location, latitude, longitude, energy, start_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		name, nullIfEmpty(note), action.ProjectID, nullIfEmpty(dueDate), statusID, action.RepeatMode, action.RepeatCount,
+		action.RepeatInterval, action.RepeatPattern, nullIfEmpty(repeatUntil), action.Location, action.Latitude, action.Longitude, action.Energy, nullIfEmpty(startDate))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create action: %v", err)
+	}
+	cloneID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := setActionTagsTx(tx, uint(cloneID), tags); err != nil {
+		return 0, fmt.Errorf("failed to tag action: %v", err)
+	}
+	if err := setRemindersTx(tx, uint(cloneID), action.Reminders); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return uint(cloneID), nil
+}
+
+// CloneProject copies a project and, with options.Actions, its actions as new
+// actions with the default status. Like a template, repeating actions are copied once, as the
+// first occurrence of their series. It returns the ID of the copy.
+func CloneProject(dbPath string, projectID uint, options CloneOptions) (uint, error) {
+	today := time.Now().Format("2006-01-02")
+	template, err := NewProjectTemplate(dbPath, projectID, "", today)
+	if err != nil {
+		return 0, err
+	}
+
+	if !options.Actions {
+		template.Actions = nil
+	}
+	for i := range template.Actions {
+		if !options.Tags {
+			template.Actions[i].Tags = nil
+		}
+		if !options.Notes {
+			template.Actions[i].Note = ""
+		}
+	}
+
+	name := options.Name
+	if name == "" {
+		project, err := GetProjectByID(dbPath, projectID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get project: %v", err)
+		}
+		name = project.Name + " (copy)"
+	}
+
+	// The dates are relative to today, starting the copy later shifts them
+	start, err := ShiftDate(today, options.Shift)
+	if err != nil {
+		return 0, err
+	}
+	return CreateProjectFromTemplate(dbPath, template, name, start)
+}
@@ -0,0 +1,378 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ConflictFields are the fields of an action compared when looking for sync
+// conflicts, in the order they are shown
+var ConflictFields = []string{"name", "project", "status", "due", "start", "note", "tags", "repeat", "flagged",
+	"location", "energy", "waiting", "reminders"}
+
+// Conflict is an action written both here and on a sync peer since they last
+// synced. The most recent write was kept, as for any change; the conflict
+// holds both versions so that the fields of the other one can be picked
+// with ResolveConflict.
+type Conflict struct {
+	ID         uint         `json:"id"`
+	ActionID   uint         `json:"action_id"` // Zero when the action was deleted since
+	Peer       string       `json:"peer"`
+	Mine       ActionChange `json:"mine"`
+	Theirs     ActionChange `json:"theirs"`
+	DetectedAt string       `json:"detected_at"`
+	// projects holds the names of the projects the versions refer to, by uid
+	projects map[string]string
+}
+
+// Kept returns the version that was kept when the changes were applied:
+// mine or theirs
+func (c *Conflict) Kept() string {
+	if c.Theirs.UpdatedAt > c.Mine.UpdatedAt {
+		return "theirs"
+	}
+	return "mine"
+}
+
+// Value returns a field of a version as text, with the project by name
+func (c *Conflict) Value(version ActionChange, field string) string {
+	switch field {
+	case "name":
+		return version.Name
+	case "project":
+		if name, ok := c.projects[version.ProjectUID]; ok {
+			return name
+		}
+		return version.ProjectUID
+	case "status":
+		return version.Status
+	case "due":
+		return version.DueDate
+	case "note":
+		return version.Note
+	case "tags":
+		tags := slices.Clone(version.Tags)
+		slices.Sort(tags)
+		return strings.Join(tags, ", ")
+	case "repeat":
+		if version.RepeatInterval == "" {
+			return ""
+		}
+		repeat := "every " + version.RepeatInterval
+		if version.RepeatPattern != "" {
+			repeat += " on " + version.RepeatPattern
+		}
+		switch version.RepeatMode {
+		case RepeatModeCount:
+			repeat += fmt.Sprintf(", %d times", version.RepeatCount)
+		case RepeatModeUntil:
+			repeat += " until " + version.RepeatUntil
+		}
+		return repeat
+	case "start":
+		return version.StartDate
+	case "flagged":
+		if version.Flagged {
+			return "yes"
+		}
+		return ""
+	case "location":
+		if version.Latitude == nil || version.Longitude == nil {
+			return version.Location
+		}
+		return strings.TrimSpace(fmt.Sprintf("%s (%.6f, %.6f)", version.Location, *version.Latitude, *version.Longitude))
+	case "energy":
+		return version.Energy
+	case "waiting":
+		if version.WaitingOn == "" || version.FollowUp == "" {
+			return version.WaitingOn
+		}
+		return version.WaitingOn + ", follow up " + version.FollowUp
+	case "reminders":
+		return strings.Join(version.Reminders, ", ")
+	}
+	return ""
+}
+
+// Differences returns the fields that differ between the two versions
+func (c *Conflict) Differences() []string {
+	var fields []string
+	for _, field := range ConflictFields {
+		if c.Value(c.Mine, field) != c.Value(c.Theirs, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Merge returns my version with the given fields taken from theirs
+func (c *Conflict) Merge(theirs []string) (ActionChange, error) {
+	merged := c.Mine
+	for _, field := range theirs {
+		switch field {
+		case "name":
+			merged.Name = c.Theirs.Name
+		case "project":
+			merged.ProjectUID = c.Theirs.ProjectUID
+		case "status":
+			merged.Status = c.Theirs.Status
+		case "due":
+			merged.DueDate = c.Theirs.DueDate
+		case "note":
+			merged.Note = c.Theirs.Note
+		case "tags":
+			merged.Tags = c.Theirs.Tags
+		case "repeat":
+			merged.RepeatMode = c.Theirs.RepeatMode
+			merged.RepeatCount = c.Theirs.RepeatCount
+			merged.RepeatInterval = c.Theirs.RepeatInterval
+			merged.RepeatPattern = c.Theirs.RepeatPattern
+			merged.RepeatUntil = c.Theirs.RepeatUntil
+		case "start":
+			merged.StartDate = c.Theirs.StartDate
+		case "flagged":
+			merged.Flagged = c.Theirs.Flagged
+		case "location":
+			merged.Location = c.Theirs.Location
+			merged.Latitude = c.Theirs.Latitude
+			merged.Longitude = c.Theirs.Longitude
+		case "energy":
+			merged.Energy = c.Theirs.Energy
+		case "waiting":
+			merged.WaitingOn = c.Theirs.WaitingOn
+			merged.FollowUp = c.Theirs.FollowUp
+		case "reminders":
+			merged.Reminders = c.Theirs.Reminders
+		default:
+			return merged, fmt.Errorf("unknown field: %s. Expected one of %s", field, strings.Join(ConflictFields, ", "))
+		}
+	}
+	return merged, nil
+}
+
+// RecordConflicts compares the actions received from a sync peer with the
+// local ones before they are applied. An action both sides wrote at or after
+// since (ChangeTimeFormat), the time of the last sync, is recorded as a
+// conflict when the versions differ. An unresolved conflict of the same
+// action is replaced. It returns the number of conflicts recorded.
+func RecordConflicts(dbPath, peer, since string, actions []ActionChange) (int, error) {
+	// Actions never synced before cannot have been changed on both sides
+	if since == "" || len(actions) == 0 {
+		return 0, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tags, err := GetAllActionTags(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tags: %v", err)
+	}
+
+	recorded := 0
+	for _, theirs := range actions {
+		if theirs.UpdatedAt < since {
+			// Written before the last sync, such as a change of ours relayed back
+			continue
+		}
+		local, err := queryActionChanges(db, tags, "a.uid = ? AND a.updated_at >= ?", theirs.UID, since)
+		if err != nil {
+			return recorded, err
+		}
+		if len(local) == 0 {
+			continue
+		}
+
+		conflict := Conflict{Mine: local[0], Theirs: theirs}
+		if len(conflict.Differences()) == 0 {
+			continue
+		}
+
+		mine, err := json.Marshal(conflict.Mine)
+		if err != nil {
+			return recorded, err
+		}
+		theirsJSON, err := json.Marshal(conflict.Theirs)
+		if err != nil {
+			return recorded, err
+		}
+		_, err = db.Exec(`
+			INSERT INTO sync_conflict (action_uid, peer, mine, theirs, detected_at) VALUES (?, ?, ?, ?, `+sqlNow+`)
+			ON CONFLICT (action_uid) DO UPDATE SET peer = excluded.peer, mine = excluded.mine, theirs = excluded.theirs, detected_at = excluded.detected_at`,
+			theirs.UID, peer, string(mine), string(theirsJSON))
+		if err != nil {
+			return recorded, fmt.Errorf("failed to record conflict: %v", err)
+		}
+		recorded++
+	}
+	return recorded, nil
+}
+
+// GetConflicts retrieves the unresolved sync conflicts, the oldest first
+func GetConflicts(dbPath string) ([]Conflict, error) {
+	return queryConflicts(dbPath, "ORDER BY c.id")
+}
+
+// GetConflict retrieves an unresolved sync conflict, or nil when it does not exist
+func GetConflict(dbPath string, conflictID uint) (*Conflict, error) {
+	conflicts, err := queryConflicts(dbPath, "WHERE c.id = ?", conflictID)
+	if err != nil || len(conflicts) == 0 {
+		return nil, err
+	}
+	return &conflicts[0], nil
+}
+
+// queryConflicts retrieves the conflicts matching a condition on the
+// sync_conflict table, aliased c
+func queryConflicts(dbPath, condition string, args ...interface{}) ([]Conflict, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT c.id, COALESCE(a.id, 0), c.peer, c.mine, c.theirs, c.detected_at
+		FROM sync_conflict c
+		LEFT JOIN action a ON a.uid = c.action_uid
+		`+condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conflicts: %v", err)
+	}
+	defer rows.Close()
+
+	var conflicts []Conflict
+	for rows.Next() {
+		var conflict Conflict
+		var mine, theirs string
+		if err := rows.Scan(&conflict.ID, &conflict.ActionID, &conflict.Peer, &mine, &theirs, &conflict.DetectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conflict: %v", err)
+		}
+		if err := json.Unmarshal([]byte(mine), &conflict.Mine); err != nil {
+			return nil, fmt.Errorf("invalid conflict %d: %v", conflict.ID, err)
+		}
+		if err := json.Unmarshal([]byte(theirs), &conflict.Theirs); err != nil {
+			return nil, fmt.Errorf("invalid conflict %d: %v", conflict.ID, err)
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	projects, err := db.Query("SELECT uid, name FROM project WHERE uid IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %v", err)
+	}
+	defer projects.Close()
+	names := map[string]string{}
+	for projects.Next() {
+		var uid, name string
+		if err := projects.Scan(&uid, &name); err != nil {
+			return nil, err
+		}
+		names[uid] = name
+	}
+	for i := range conflicts {
+		conflicts[i].projects = names
+	}
+	return conflicts, projects.Err()
+}
+
+// ResolveConflict writes the resolved version of the action of a conflict
+// and removes the conflict. The resolution is a local change, so it is sent
+// to the peers with the next sync and wins over both versions.
+func ResolveConflict(dbPath string, conflictID uint, resolved ActionChange) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var actionID sql.NullInt64
+	err = tx.QueryRow("SELECT a.id FROM sync_conflict c LEFT JOIN action a ON a.uid = c.action_uid WHERE c.id = ?", conflictID).Scan(&actionID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("conflict %d not found", conflictID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read conflict: %v", err)
+	}
+	if !actionID.Valid {
+		return fmt.Errorf("the action of conflict %d was deleted, drop the conflict instead", conflictID)
+	}
+
+	projectID, err := lookupID(tx, "SELECT id FROM project WHERE uid = ?", resolved.ProjectUID)
+	if err != nil {
+		return err
+	}
+	statusID, err := lookupID(tx, "SELECT id FROM status WHERE name = ?", resolved.Status)
+	if err != nil {
+		return err
+	}
+	if !statusID.Valid {
+		return fmt.Errorf("unknown status: %s", resolved.Status)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE action
+		SET project_id = ?, name = ?, note = ?, due_date = ?, status_id = ?, repeat_mode = ?,
+			repeat_count = ?, repeat_interval = ?, repeat_pattern = ?, repeat_until = ?, flagged = ?,
+			location = ?, latitude = ?, longitude = ?, energy = ?, waiting_on = ?, follow_up = ?, start_date = ?
+		WHERE id = ?`,
+		projectID, resolved.Name, nullIfEmpty(resolved.Note), nullIfEmpty(resolved.DueDate), statusID,
+		nullIfEmpty(resolved.RepeatMode), resolved.RepeatCount, nullIfEmpty(resolved.RepeatInterval),
+		nullIfEmpty(resolved.RepeatPattern), nullIfEmpty(resolved.RepeatUntil), resolved.Flagged,
+		nullIfEmpty(resolved.Location), resolved.Latitude, resolved.Longitude, nullIfEmpty(resolved.Energy),
+		nullIfEmpty(resolved.WaitingOn), nullIfEmpty(resolved.FollowUp), nullIfEmpty(resolved.StartDate), actionID.Int64)
+	if err != nil {
+		return fmt.Errorf("failed to update action: %v", err)
+	}
+	if err := setActionTagsTx(tx, uint(actionID.Int64), resolved.Tags); err != nil {
+		return fmt.Errorf("failed to update tags: %v", err)
+	}
+	if err := setRemindersTx(tx, uint(actionID.Int64), resolved.Reminders); err != nil {
+		return fmt.Errorf("failed to update reminders: %v", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM sync_conflict WHERE id = ?", conflictID); err != nil {
+		return fmt.Errorf("failed to remove conflict: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// DropConflict removes a conflict without changing the action, keeping the
+// version that won
+func DropConflict(dbPath string, conflictID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM sync_conflict WHERE id = ?", conflictID)
+	if err != nil {
+		return fmt.Errorf("failed to remove conflict: %v", err)
+	}
+	if removed, _ := result.RowsAffected(); removed == 0 {
+		return fmt.Errorf("conflict %d not found", conflictID)
+	}
+	return nil
+}
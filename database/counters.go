@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// countedTables are the tables whose writes are counted in change_counter
+var countedTables = []string{"project", "action", "tag", "action_tag", "status", "user", "project_member", "saved_filter"}
+
+// changeCounterTriggers returns the triggers that count the inserts, updates
+// and deletes of a table. Unlike the sync triggers they also count the
+// changes applied by remote sync.
+func changeCounterTriggers(table string) []string {
+	var triggers []string
+	for _, event := range []string{"INSERT", "UPDATE", "DELETE"} {
+		triggers = append(triggers, fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %s_count_%s AFTER %s ON %s
+		BEGIN
+			INSERT INTO change_counter (table_name, counter) VALUES ('%s', 1)
+			ON CONFLICT (table_name) DO UPDATE SET counter = counter + 1;
+		END`, table, strings.ToLower(event), event, table, table))
+	}
+	return triggers
+}
+
+// CreateChangeCounters creates the triggers that maintain change_counter
+func CreateChangeCounters(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, table := range countedTables {
+		for _, statement := range changeCounterTriggers(table) {
+			if _, err := db.Exec(statement); err != nil {
+				return fmt.Errorf("failed to create change counter for %s: %v", table, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetChangeCount returns the number of writes to the given tables. The count
+// only grows, so an unchanged count means the tables are unchanged.
+func GetChangeCount(dbPath string, tables ...string) (int64, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tables)), ", ")
+	args := make([]interface{}, len(tables))
+	for i, table := range tables {
+		args[i] = table
+	}
+
+	var count int64
+	query := "SELECT COALESCE(SUM(counter), 0) FROM change_counter WHERE table_name IN (" + placeholders + ")"
+	if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to read change counter: %v", err)
+	}
+	return count, nil
+}
@@ -13,9 +13,16 @@ const DatabaseName = "projector.db"
 
 // GetDatabasePath returns the proper database path in ~/.local/share/projector/
 func GetDatabasePath() string {
-	// Check for environment variable override
-	if envPath := os.Getenv("PROJECTOR_DB_PATH"); envPath != "" {
-		return envPath
+	// Check for a path set by a flag, then for the environment variable override
+	path := pathOverride
+	if path == "" {
+		path = os.Getenv("PROJECTOR_DB_PATH")
+	}
+	if path == MemoryPath || path == TempPath {
+		return temporaryDatabasePath(path)
+	}
+	if path != "" {
+		return path
 	}
 
 	homeDir, err := os.UserHomeDir()
@@ -79,7 +86,19 @@ func CreateTable(dbPath, tableName string) error {
 		CREATE TABLE IF NOT EXISTS project (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			name TEXT NOT NULL,
-			due_date DATE
+			due_date DATE,
+			uid TEXT,
+			updated_at TEXT,
+			changed_at TEXT,
+			owner_id INTEGER,
+			archived_at TEXT,
+			repeat_interval TEXT,
+			parent_project_id INTEGER,
+			review_interval TEXT,
+			last_reviewed_at TEXT,
+			version INTEGER NOT NULL DEFAULT 1,
+			FOREIGN KEY (owner_id) REFERENCES user (id) ON DELETE SET NULL,
+			FOREIGN KEY (parent_project_id) REFERENCES project (id) ON DELETE SET NULL
 		);`
 	case "action":
 		createTableSQL = `
@@ -95,9 +114,26 @@ func CreateTable(dbPath, tableName string) error {
 			repeat_pattern TEXT,
 			repeat_until DATE,
 			parent_action_id INTEGER,
+			repeat_mode TEXT,
+			uid TEXT,
+			updated_at TEXT,
+			changed_at TEXT,
+			owner_id INTEGER,
+			assignee_id INTEGER,
+			flagged INTEGER NOT NULL DEFAULT 0,
+			location TEXT,
+			latitude REAL,
+			longitude REAL,
+			energy TEXT,
+			waiting_on TEXT,
+			follow_up DATE,
+			start_date DATE,
+			version INTEGER NOT NULL DEFAULT 1,
 			FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE SET NULL,
 			FOREIGN KEY (status_id) REFERENCES status (id),
-			FOREIGN KEY (parent_action_id) REFERENCES action (id) ON DELETE SET NULL
+			FOREIGN KEY (parent_action_id) REFERENCES action (id) ON DELETE SET NULL,
+			FOREIGN KEY (owner_id) REFERENCES user (id) ON DELETE SET NULL,
+			FOREIGN KEY (assignee_id) REFERENCES user (id) ON DELETE SET NULL
 		);`
 	case "tag":
 		createTableSQL = `
@@ -118,7 +154,206 @@ func CreateTable(dbPath, tableName string) error {
 		createTableSQL = `
 		CREATE TABLE IF NOT EXISTS status (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			name TEXT NOT NULL UNIQUE
+			name TEXT NOT NULL UNIQUE,
+			color TEXT,
+			icon TEXT
+		);`
+	case "sync_state":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS sync_state (
+			source TEXT NOT NULL,
+			action_id INTEGER NOT NULL,
+			remote_id TEXT NOT NULL,
+			etag TEXT,
+			local_hash TEXT,
+			synced_at DATETIME,
+			PRIMARY KEY (source, action_id),
+			FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE
+		);`
+	case "notification_log":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS notification_log (
+			event TEXT NOT NULL,
+			channel TEXT NOT NULL,
+			key TEXT NOT NULL,
+			sent_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (event, channel, key)
+		);`
+	case "tombstone":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS tombstone (
+			entity TEXT NOT NULL,
+			uid TEXT NOT NULL,
+			deleted_at TEXT NOT NULL,
+			changed_at TEXT,
+			PRIMARY KEY (entity, uid)
+		);`
+	case "sync_peer":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS sync_peer (
+			url TEXT PRIMARY KEY,
+			pulled_at TEXT,
+			pushed_at TEXT
+		);`
+	case "user":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS user (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT NOT NULL UNIQUE,
+			password_hash TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			is_admin INTEGER NOT NULL DEFAULT 0,
+			disabled INTEGER NOT NULL DEFAULT 0
+		);`
+	case "user_token":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS user_token (
+			token_hash TEXT PRIMARY KEY,
+			user_id INTEGER NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE
+		);`
+	case "project_member":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS project_member (
+			project_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (project_id, user_id),
+			FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE
+		);`
+	case "maintenance_log":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS maintenance_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ran_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			size_before INTEGER NOT NULL,
+			size_after INTEGER NOT NULL
+		);`
+	case "change_counter":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS change_counter (
+			table_name TEXT PRIMARY KEY,
+			counter INTEGER NOT NULL DEFAULT 0
+		);`
+	case "template":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS template (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			project_due TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`
+	case "template_action":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS template_action (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			template_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			note TEXT,
+			due TEXT,
+			tags TEXT,
+			repeat_mode TEXT,
+			repeat_count INTEGER DEFAULT 0,
+			repeat_interval TEXT,
+			repeat_pattern TEXT,
+			repeat_until TEXT,
+			FOREIGN KEY (template_id) REFERENCES template (id) ON DELETE CASCADE
+		);`
+	case "saved_filter":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS saved_filter (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			query TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);`
+	case "action_history":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS action_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id INTEGER NOT NULL,
+			project_id INTEGER,
+			status_id INTEGER,
+			changed_at TEXT NOT NULL,
+			skipped_date TEXT
+		);`
+	case "attachment":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS attachment (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			title TEXT,
+			created_at TEXT NOT NULL,
+			FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE
+		);`
+	case "idempotency_key":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS idempotency_key (
+			user_id INTEGER NOT NULL DEFAULT 0,
+			key TEXT NOT NULL,
+			method TEXT NOT NULL,
+			path TEXT NOT NULL,
+			status INTEGER NOT NULL,
+			content_type TEXT,
+			body TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (user_id, key)
+		);`
+	case "change_event":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS change_event (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			operation TEXT NOT NULL,
+			owner_id INTEGER,
+			data TEXT NOT NULL,
+			changed_at TEXT NOT NULL
+		);`
+	case "reminder":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS reminder (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id INTEGER NOT NULL,
+			due_offset TEXT NOT NULL,
+			UNIQUE (action_id, due_offset),
+			FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE
+		);`
+	case "action_archive":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS action_archive (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			project_name TEXT,
+			data TEXT NOT NULL,
+			completed_at TEXT NOT NULL,
+			archived_at TEXT NOT NULL
+		);`
+	case "sync_conflict":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS sync_conflict (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			action_uid TEXT NOT NULL UNIQUE,
+			peer TEXT NOT NULL,
+			mine TEXT NOT NULL,
+			theirs TEXT NOT NULL,
+			detected_at TEXT NOT NULL
+		);`
+	case "trash":
+		createTableSQL = `
+		CREATE TABLE IF NOT EXISTS trash (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			data TEXT NOT NULL,
+			deleted_at TEXT NOT NULL
 		);`
 	default:
 		return fmt.Errorf("unknown table: %s", tableName)
@@ -129,6 +364,22 @@ func CreateTable(dbPath, tableName string) error {
 		return err
 	}
 
+	// Keep the uid, updated_at and tombstones used by remote sync up to date
+	for _, statement := range syncTriggers[tableName] {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	// Record the changes of the actions from now on
+	if tableName == "action_history" {
+		for _, statement := range historyTriggers {
+			if _, err := db.Exec(statement); err != nil {
+				return err
+			}
+		}
+	}
+
 	// If this is the status table, insert the default statuses
 	if tableName == "status" {
 		insertStatusSQL := `
@@ -190,6 +441,16 @@ func CheckTableSchema(dbPath, tableName string) error {
 			"id INTEGER",
 			"name TEXT",
 			"due_date DATE",
+			"uid TEXT",
+			"updated_at TEXT",
+			"changed_at TEXT",
+			"owner_id INTEGER",
+			"archived_at TEXT",
+			"repeat_interval TEXT",
+			"parent_project_id INTEGER",
+			"review_interval TEXT",
+			"last_reviewed_at TEXT",
+			"version INTEGER",
 		},
 		"action": {
 			"id INTEGER",
@@ -203,6 +464,21 @@ func CheckTableSchema(dbPath, tableName string) error {
 			"repeat_pattern TEXT",
 			"repeat_until DATE",
 			"parent_action_id INTEGER",
+			"repeat_mode TEXT",
+			"uid TEXT",
+			"updated_at TEXT",
+			"changed_at TEXT",
+			"owner_id INTEGER",
+			"assignee_id INTEGER",
+			"flagged INTEGER",
+			"location TEXT",
+			"latitude REAL",
+			"longitude REAL",
+			"energy TEXT",
+			"waiting_on TEXT",
+			"follow_up DATE",
+			"start_date DATE",
+			"version INTEGER",
 		},
 		"tag": {
 			"id INTEGER",
@@ -215,6 +491,152 @@ func CheckTableSchema(dbPath, tableName string) error {
 		"status": {
 			"id INTEGER",
 			"name TEXT",
+			"color TEXT",
+			"icon TEXT",
+		},
+		"sync_state": {
+			"source TEXT",
+			"action_id INTEGER",
+			"remote_id TEXT",
+			"etag TEXT",
+			"local_hash TEXT",
+			"synced_at DATETIME",
+		},
+		"notification_log": {
+			"event TEXT",
+			"channel TEXT",
+			"key TEXT",
+			"sent_at DATETIME",
+		},
+		"tombstone": {
+			"entity TEXT",
+			"uid TEXT",
+			"deleted_at TEXT",
+			"changed_at TEXT",
+		},
+		"sync_peer": {
+			"url TEXT",
+			"pulled_at TEXT",
+			"pushed_at TEXT",
+		},
+		"user": {
+			"id INTEGER",
+			"username TEXT",
+			"password_hash TEXT",
+			"created_at DATETIME",
+			"is_admin INTEGER",
+			"disabled INTEGER",
+		},
+		"user_token": {
+			"token_hash TEXT",
+			"user_id INTEGER",
+			"created_at DATETIME",
+		},
+		"project_member": {
+			"project_id INTEGER",
+			"user_id INTEGER",
+			"role TEXT",
+			"created_at DATETIME",
+		},
+		"maintenance_log": {
+			"id INTEGER",
+			"ran_at DATETIME",
+			"size_before INTEGER",
+			"size_after INTEGER",
+		},
+		"change_counter": {
+			"table_name TEXT",
+			"counter INTEGER",
+		},
+		"template": {
+			"id INTEGER",
+			"name TEXT",
+			"project_due TEXT",
+			"created_at DATETIME",
+		},
+		"template_action": {
+			"id INTEGER",
+			"template_id INTEGER",
+			"position INTEGER",
+			"name TEXT",
+			"note TEXT",
+			"due TEXT",
+			"tags TEXT",
+			"repeat_mode TEXT",
+			"repeat_count INTEGER",
+			"repeat_interval TEXT",
+			"repeat_pattern TEXT",
+			"repeat_until TEXT",
+		},
+		"saved_filter": {
+			"id INTEGER",
+			"name TEXT",
+			"query TEXT",
+			"created_at DATETIME",
+		},
+		"action_history": {
+			"id INTEGER",
+			"action_id INTEGER",
+			"project_id INTEGER",
+			"status_id INTEGER",
+			"changed_at TEXT",
+			"skipped_date TEXT",
+		},
+		"trash": {
+			"id INTEGER",
+			"entity TEXT",
+			"entity_id INTEGER",
+			"name TEXT",
+			"data TEXT",
+			"deleted_at TEXT",
+		},
+		"attachment": {
+			"id INTEGER",
+			"action_id INTEGER",
+			"url TEXT",
+			"title TEXT",
+			"created_at TEXT",
+		},
+		"idempotency_key": {
+			"user_id INTEGER",
+			"key TEXT",
+			"method TEXT",
+			"path TEXT",
+			"status INTEGER",
+			"content_type TEXT",
+			"body TEXT",
+			"created_at TEXT",
+		},
+		"change_event": {
+			"id INTEGER",
+			"entity TEXT",
+			"entity_id INTEGER",
+			"operation TEXT",
+			"owner_id INTEGER",
+			"data TEXT",
+			"changed_at TEXT",
+		},
+		"reminder": {
+			"id INTEGER",
+			"action_id INTEGER",
+			"due_offset TEXT",
+		},
+		"action_archive": {
+			"id INTEGER",
+			"action_id INTEGER",
+			"name TEXT",
+			"project_name TEXT",
+			"data TEXT",
+			"completed_at TEXT",
+			"archived_at TEXT",
+		},
+		"sync_conflict": {
+			"id INTEGER",
+			"action_uid TEXT",
+			"peer TEXT",
+			"mine TEXT",
+			"theirs TEXT",
+			"detected_at TEXT",
 		},
 	}
 
@@ -236,11 +658,31 @@ func CheckTableSchema(dbPath, tableName string) error {
 // GetExpectedSchema returns the expected schema string for a table
 func GetExpectedSchema(tableName string) string {
 	expectedSchemas := map[string]string{
-		"project":  "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, due_date DATE",
-		"action":     "id INTEGER PRIMARY KEY AUTOINCREMENT, project_id INTEGER, name TEXT NOT NULL, note TEXT, due_date DATE, status_id INTEGER NOT NULL, repeat_count INTEGER DEFAULT 0, repeat_interval TEXT, repeat_pattern TEXT, repeat_until DATE, parent_action_id INTEGER",
+		"project":  "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, due_date DATE, uid TEXT, updated_at TEXT, changed_at TEXT, owner_id INTEGER, archived_at TEXT, repeat_interval TEXT, parent_project_id INTEGER, review_interval TEXT, last_reviewed_at TEXT, version INTEGER NOT NULL DEFAULT 1",
+		"action":     "id INTEGER PRIMARY KEY AUTOINCREMENT, project_id INTEGER, name TEXT NOT NULL, note TEXT, due_date DATE, status_id INTEGER NOT NULL, repeat_count INTEGER DEFAULT 0, repeat_interval TEXT, repeat_pattern TEXT, repeat_until DATE, parent_action_id INTEGER, repeat_mode TEXT, uid TEXT, updated_at TEXT, changed_at TEXT, owner_id INTEGER, assignee_id INTEGER, flagged INTEGER NOT NULL DEFAULT 0, location TEXT, latitude REAL, longitude REAL, energy TEXT, waiting_on TEXT, follow_up DATE, start_date DATE, version INTEGER NOT NULL DEFAULT 1",
 		"tag":      "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE",
 		"action_tag": "action_id INTEGER NOT NULL, tag_id INTEGER NOT NULL, PRIMARY KEY (action_id, tag_id), FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE, FOREIGN KEY (tag_id) REFERENCES tag (id) ON DELETE CASCADE",
-		"status":   "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE",
+		"status":   "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE, color TEXT, icon TEXT",
+		"sync_state": "source TEXT NOT NULL, action_id INTEGER NOT NULL, remote_id TEXT NOT NULL, etag TEXT, local_hash TEXT, synced_at DATETIME, PRIMARY KEY (source, action_id), FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE",
+		"notification_log": "event TEXT NOT NULL, channel TEXT NOT NULL, key TEXT NOT NULL, sent_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (event, channel, key)",
+		"tombstone": "entity TEXT NOT NULL, uid TEXT NOT NULL, deleted_at TEXT NOT NULL, changed_at TEXT, PRIMARY KEY (entity, uid)",
+		"sync_peer": "url TEXT PRIMARY KEY, pulled_at TEXT, pushed_at TEXT",
+		"user": "id INTEGER PRIMARY KEY AUTOINCREMENT, username TEXT NOT NULL UNIQUE, password_hash TEXT NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, is_admin INTEGER NOT NULL DEFAULT 0, disabled INTEGER NOT NULL DEFAULT 0",
+		"user_token": "token_hash TEXT PRIMARY KEY, user_id INTEGER NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE",
+		"project_member": "project_id INTEGER NOT NULL, user_id INTEGER NOT NULL, role TEXT NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (project_id, user_id), FOREIGN KEY (project_id) REFERENCES project (id) ON DELETE CASCADE, FOREIGN KEY (user_id) REFERENCES user (id) ON DELETE CASCADE",
+		"maintenance_log": "id INTEGER PRIMARY KEY AUTOINCREMENT, ran_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, size_before INTEGER NOT NULL, size_after INTEGER NOT NULL",
+		"change_counter": "table_name TEXT PRIMARY KEY, counter INTEGER NOT NULL DEFAULT 0",
+		"template": "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE, project_due TEXT, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		"template_action": "id INTEGER PRIMARY KEY AUTOINCREMENT, template_id INTEGER NOT NULL, position INTEGER NOT NULL, name TEXT NOT NULL, note TEXT, due TEXT, tags TEXT, repeat_mode TEXT, repeat_count INTEGER DEFAULT 0, repeat_interval TEXT, repeat_pattern TEXT, repeat_until TEXT, FOREIGN KEY (template_id) REFERENCES template (id) ON DELETE CASCADE",
+		"saved_filter": "id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL UNIQUE, query TEXT NOT NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP",
+		"action_history": "id INTEGER PRIMARY KEY AUTOINCREMENT, action_id INTEGER NOT NULL, project_id INTEGER, status_id INTEGER, changed_at TEXT NOT NULL, skipped_date TEXT",
+		"trash": "id INTEGER PRIMARY KEY AUTOINCREMENT, entity TEXT NOT NULL, entity_id INTEGER NOT NULL, name TEXT NOT NULL, data TEXT NOT NULL, deleted_at TEXT NOT NULL",
+		"attachment": "id INTEGER PRIMARY KEY AUTOINCREMENT, action_id INTEGER NOT NULL, url TEXT NOT NULL, title TEXT, created_at TEXT NOT NULL, FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE",
+		"idempotency_key": "user_id INTEGER NOT NULL DEFAULT 0, key TEXT NOT NULL, method TEXT NOT NULL, path TEXT NOT NULL, status INTEGER NOT NULL, content_type TEXT, body TEXT NOT NULL, created_at TEXT NOT NULL, PRIMARY KEY (user_id, key)",
+		"change_event": "id INTEGER PRIMARY KEY AUTOINCREMENT, entity TEXT NOT NULL, entity_id INTEGER NOT NULL, operation TEXT NOT NULL, owner_id INTEGER, data TEXT NOT NULL, changed_at TEXT NOT NULL",
+		"reminder": "id INTEGER PRIMARY KEY AUTOINCREMENT, action_id INTEGER NOT NULL, due_offset TEXT NOT NULL, UNIQUE (action_id, due_offset), FOREIGN KEY (action_id) REFERENCES action (id) ON DELETE CASCADE",
+		"action_archive": "id INTEGER PRIMARY KEY AUTOINCREMENT, action_id INTEGER NOT NULL, name TEXT NOT NULL, project_name TEXT, data TEXT NOT NULL, completed_at TEXT NOT NULL, archived_at TEXT NOT NULL",
+		"sync_conflict": "id INTEGER PRIMARY KEY AUTOINCREMENT, action_uid TEXT NOT NULL UNIQUE, peer TEXT NOT NULL, mine TEXT NOT NULL, theirs TEXT NOT NULL, detected_at TEXT NOT NULL",
 	}
 
 	if schema, exists := expectedSchemas[tableName]; exists {
@@ -306,35 +748,17 @@ func GetActualSchema(dbPath, tableName string) string {
 
 // DatabaseExists checks if the database file exists
 func DatabaseExists(dbPath string) bool {
+	if IsMemoryPath(dbPath) {
+		return true
+	}
 	_, err := os.Stat(dbPath)
 	return err == nil
 }
 
-// DeleteProject deletes a project from the database
+// DeleteProject deletes a project by moving it to the trash, from where it
+// can be restored until the trash is emptied
 func DeleteProject(dbPath string, projectID uint) error {
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return fmt.Errorf("failed to open database: %v", err)
-	}
-	defer db.Close()
-
-	// Check if project exists
-	project, err := GetProjectByID(dbPath, projectID)
-	if err != nil {
-		return fmt.Errorf("error checking project existence: %v", err)
-	}
-	if project == nil {
-		return fmt.Errorf("project not found")
-	}
-
-	// Delete the project
-	query := "DELETE FROM project WHERE id = ?"
-	_, err = db.Exec(query, projectID)
-	if err != nil {
-		return fmt.Errorf("failed to delete project: %v", err)
-	}
-
-	return nil
+	return TrashProject(dbPath, projectID)
 }
 
 // VerifyStatusTableData checks if the status table contains the expected initial data
@@ -0,0 +1,238 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Problem is an inconsistency in the database found by CheckIntegrity
+type Problem struct {
+	Check       string // The check that found the problem
+	Description string
+	Fix         string // Description of the repair, empty when it cannot be fixed automatically
+	fixQuery    string
+	fixArgs     []interface{}
+}
+
+// Fixable reports whether FixProblems can repair the problem
+func (p Problem) Fixable() bool {
+	return p.fixQuery != ""
+}
+
+// referenceCheck finds rows referring to a row that does not exist. The
+// query selects the row ID and the dangling reference.
+type referenceCheck struct {
+	check    string
+	query    string
+	describe string // Format for the row ID and the reference
+	fix      string
+	fixQuery string // Takes the row ID and the reference
+}
+
+var referenceChecks = []referenceCheck{
+	{
+		check:    "action tags",
+		query:    "SELECT action_id, tag_id FROM action_tag WHERE action_id NOT IN (SELECT id FROM action) OR tag_id NOT IN (SELECT id FROM tag)",
+		describe: "tag link of action %d to tag %d refers to a deleted action or tag",
+		fix:      "remove the tag link",
+		fixQuery: "DELETE FROM action_tag WHERE action_id = ? AND tag_id = ?",
+	},
+	{
+		check:    "attachments",
+		query:    "SELECT id, action_id FROM attachment WHERE action_id NOT IN (SELECT id FROM action)",
+		describe: "attachment %d belongs to action %d, which does not exist",
+		fix:      "remove the attachment",
+		fixQuery: "DELETE FROM attachment WHERE id = ? AND action_id = ?",
+	},
+	{
+		check:    "reminders",
+		query:    "SELECT id, action_id FROM reminder WHERE action_id NOT IN (SELECT id FROM action)",
+		describe: "reminder %d belongs to action %d, which does not exist",
+		fix:      "remove the reminder",
+		fixQuery: "DELETE FROM reminder WHERE id = ? AND action_id = ?",
+	},
+	{
+		check:    "projects",
+		query:    "SELECT id, project_id FROM action WHERE project_id IS NOT NULL AND project_id NOT IN (SELECT id FROM project)",
+		describe: "action %d belongs to project %d, which does not exist",
+		fix:      "remove the action from the project",
+		fixQuery: "UPDATE action SET project_id = NULL WHERE id = ? AND project_id = ?",
+	},
+	{
+		check:    "repeat series",
+		query:    "SELECT id, parent_action_id FROM action WHERE parent_action_id IS NOT NULL AND parent_action_id NOT IN (SELECT id FROM action)",
+		describe: "action %d repeats action %d, which does not exist",
+		fix:      "make the action the start of its series",
+		fixQuery: "UPDATE action SET parent_action_id = NULL WHERE id = ? AND parent_action_id = ?",
+	},
+	{
+		check:    "statuses",
+		query:    "SELECT id, status_id FROM action WHERE status_id NOT IN (SELECT id FROM status)",
+		describe: "action %d has status %d, which does not exist",
+		fix:      "set the status to todo",
+		fixQuery: "UPDATE action SET status_id = 1 WHERE id = ? AND status_id = ?",
+	},
+	{
+		check:    "assignees",
+		query:    "SELECT id, assignee_id FROM action WHERE assignee_id IS NOT NULL AND assignee_id NOT IN (SELECT id FROM user)",
+		describe: "action %d is assigned to user %d, who does not exist",
+		fix:      "unassign the action",
+		fixQuery: "UPDATE action SET assignee_id = NULL WHERE id = ? AND assignee_id = ?",
+	},
+	{
+		check:    "project members",
+		query:    "SELECT project_id, user_id FROM project_member WHERE project_id NOT IN (SELECT id FROM project) OR user_id NOT IN (SELECT id FROM user)",
+		describe: "membership of project %d for user %d refers to a deleted project or user",
+		fix:      "remove the membership",
+		fixQuery: "DELETE FROM project_member WHERE project_id = ? AND user_id = ?",
+	},
+}
+
+// CheckIntegrity runs SQLite's integrity check and looks for references to
+// deleted rows and invalid repeat configurations
+func CheckIntegrity(dbPath string) ([]Problem, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var problems []Problem
+
+	// Corruption of the database file itself cannot be repaired here
+	rows, err := db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run integrity check: %v", err)
+	}
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read integrity check: %v", err)
+		}
+		if result != "ok" {
+			problems = append(problems, Problem{Check: "integrity", Description: result})
+		}
+	}
+	rows.Close()
+
+	for _, check := range referenceChecks {
+		found, err := findDanglingReferences(db, check)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, found...)
+	}
+
+	// Read the repeat columns directly, the action queries fail on the
+	// dangling references found above
+	rows, err = db.Query("SELECT id, due_date, repeat_mode, repeat_count, repeat_interval, repeat_until FROM action")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check repeat configurations: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var action Action
+		var repeatCount sql.NullInt64
+		if err := rows.Scan(&action.ID, &action.DueDate, &action.RepeatMode, &repeatCount, &action.RepeatInterval, &action.RepeatUntil); err != nil {
+			return nil, fmt.Errorf("failed to check repeat configurations: %v", err)
+		}
+		action.RepeatCount = uint(repeatCount.Int64)
+		if problem := checkRepeat(action); problem != nil {
+			problems = append(problems, *problem)
+		}
+	}
+
+	return problems, nil
+}
+
+// findDanglingReferences runs a reference check
+func findDanglingReferences(db *sql.DB, check referenceCheck) ([]Problem, error) {
+	rows, err := db.Query(check.query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %v", check.check, err)
+	}
+	defer rows.Close()
+
+	var problems []Problem
+	for rows.Next() {
+		var id, reference int64
+		if err := rows.Scan(&id, &reference); err != nil {
+			return nil, fmt.Errorf("failed to check %s: %v", check.check, err)
+		}
+		problems = append(problems, Problem{
+			Check:       check.check,
+			Description: fmt.Sprintf(check.describe, id, reference),
+			Fix:         check.fix,
+			fixQuery:    check.fixQuery,
+			fixArgs:     []interface{}{id, reference},
+		})
+	}
+
+	return problems, nil
+}
+
+// checkRepeat validates the repeat configuration of an action. Invalid
+// configurations are fixed by no longer repeating the action.
+func checkRepeat(action Action) *Problem {
+	mode := action.EffectiveRepeatMode()
+	if mode == RepeatModeNone {
+		return nil
+	}
+
+	_, err := ValidateRepeatInput(mode, action.RepeatCount, action.RepeatInterval.String, StoredDate(action.RepeatUntil.String))
+	if err != nil {
+		return &Problem{
+			Check:       "repeat",
+			Description: fmt.Sprintf("action %d: %v", action.ID, err),
+			Fix:         "stop repeating the action",
+			fixQuery:    "UPDATE action SET repeat_mode = 'none' WHERE id = ?",
+			fixArgs:     []interface{}{action.ID},
+		}
+	}
+
+	// The next occurrence is due one interval after the current due date
+	if action.IsRepeating() && (!action.DueDate.Valid || action.DueDate.String == "") {
+		return &Problem{
+			Check:       "repeat",
+			Description: fmt.Sprintf("action %d repeats but has no due date, so no next occurrence can be created", action.ID),
+		}
+	}
+
+	return nil
+}
+
+// FixProblems repairs the fixable problems in a single transaction and
+// returns how many were fixed
+func FixProblems(dbPath string, problems []Problem) (int, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	fixed := 0
+	for _, problem := range problems {
+		if !problem.Fixable() {
+			continue
+		}
+		if _, err := tx.Exec(problem.fixQuery, problem.fixArgs...); err != nil {
+			return 0, fmt.Errorf("failed to fix %q: %v", problem.Description, err)
+		}
+		fixed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit fixes: %v", err)
+	}
+
+	return fixed, nil
+}
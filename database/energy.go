@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Energy levels say how much focus an action takes, from least to most
+const (
+	EnergyLow    = "low"
+	EnergyMedium = "medium"
+	EnergyHigh   = "high"
+)
+
+// EnergyLevels are the energy levels in increasing order
+var EnergyLevels = []string{EnergyLow, EnergyMedium, EnergyHigh}
+
+// ValidateEnergy checks that an energy level is low, medium or high and
+// returns it in lowercase. An empty level is valid and removes the level.
+func ValidateEnergy(energy string) (string, error) {
+	energy = strings.ToLower(strings.TrimSpace(energy))
+	if energy == "" || slices.Contains(EnergyLevels, energy) {
+		return energy, nil
+	}
+	return "", fmt.Errorf("invalid energy level: %s (expected %s)", energy, strings.Join(EnergyLevels, ", "))
+}
+
+// EnergyRank returns the position of an energy level, from 1 for low to 3
+// for high, or 0 for an empty or unknown level
+func EnergyRank(energy string) int {
+	return slices.Index(EnergyLevels, strings.ToLower(energy)) + 1
+}
+
+// NeedsAtMost reports whether an action has an energy level no higher than
+// the given one. Actions without an energy level never match.
+func (a *Action) NeedsAtMost(energy string) bool {
+	rank := EnergyRank(a.Energy.String)
+	return rank > 0 && rank <= EnergyRank(energy)
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ChangeEvent is a write to a project or action recorded in the event log.
+// Data holds the row as it was after the write, or its id, uid and name for a
+// deletion, so that a client can replay the log without reading the rows.
+type ChangeEvent struct {
+	Cursor    int64           `json:"cursor"`
+	Entity    string          `json:"entity"` // project or action
+	EntityID  uint            `json:"id"`
+	Operation string          `json:"operation"` // insert, update or delete
+	Data      json.RawMessage `json:"data"`
+	ChangedAt string          `json:"changed_at"`
+}
+
+// eventData are the JSON snapshots of a project and an action stored with an
+// event, by the alias the row is selected under
+var eventData = map[string]string{
+	"project": `json_object('id', p.id, 'uid', p.uid, 'name', p.name, 'due_date', p.due_date,
+		'parent_project_id', p.parent_project_id, 'archived_at', p.archived_at,
+		'repeat_interval', p.repeat_interval, 'review_interval', p.review_interval,
+		'owner_id', p.owner_id, 'version', p.version, 'updated_at', p.updated_at)`,
+	"action": `json_object('id', a.id, 'uid', a.uid, 'project_id', a.project_id, 'name', a.name,
+		'note', a.note, 'due_date', a.due_date, 'start_date', a.start_date,
+		'status', (SELECT name FROM status WHERE id = a.status_id), 'flagged', a.flagged,
+		'repeat_mode', a.repeat_mode, 'repeat_count', a.repeat_count, 'repeat_interval', a.repeat_interval,
+		'repeat_pattern', a.repeat_pattern, 'repeat_until', a.repeat_until, 'parent_action_id', a.parent_action_id,
+		'owner_id', a.owner_id, 'assignee_id', a.assignee_id, 'location', a.location,
+		'latitude', a.latitude, 'longitude', a.longitude, 'energy', a.energy,
+		'waiting_on', a.waiting_on, 'follow_up', a.follow_up, 'version', a.version, 'updated_at', a.updated_at,
+		'tags', json((SELECT json_group_array(t.name) FROM action_tag at JOIN tag t ON t.id = at.tag_id WHERE at.action_id = a.id)))`,
+}
+
+// eventAliases are the aliases eventData selects the rows under
+var eventAliases = map[string]string{"project": "p", "action": "a"}
+
+// recordEvent returns the trigger statements that record an insert or update
+// of the row with the given ID. A single write fires the sync, version and
+// counter triggers, which update the row again, so the events of the same
+// statement are folded into the last one, keeping an insert an insert.
+func recordEvent(table, operation, id string) string {
+	alias := eventAliases[table]
+	return fmt.Sprintf(`INSERT INTO change_event (entity, entity_id, operation, owner_id, data, changed_at)
+			SELECT '%[1]s', %[2]s.id,
+				CASE WHEN EXISTS (SELECT 1 FROM change_event WHERE entity = '%[1]s' AND entity_id = %[2]s.id AND changed_at = %[5]s AND operation = 'insert') THEN 'insert' ELSE '%[3]s' END,
+				%[2]s.owner_id, %[6]s, %[5]s
+			FROM %[1]s %[2]s WHERE %[2]s.id = %[4]s;
+			DELETE FROM change_event WHERE entity = '%[1]s' AND entity_id = %[4]s AND changed_at = %[5]s AND id < (SELECT MAX(id) FROM change_event);`,
+		table, alias, operation, id, sqlNow, eventData[table])
+}
+
+// eventTriggers returns the triggers that record the writes to projects,
+// actions and their tags in change_event. Unlike the sync triggers they also
+// record the changes applied by remote sync.
+func eventTriggers() []string {
+	var triggers []string
+	for _, table := range []string{"project", "action"} {
+		triggers = append(triggers,
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_event_insert AFTER INSERT ON %[1]s
+		BEGIN
+			%[2]s
+		END`, table, recordEvent(table, "insert", "NEW.id")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_event_update AFTER UPDATE ON %[1]s
+		BEGIN
+			%[2]s
+		END`, table, recordEvent(table, "update", "NEW.id")),
+			fmt.Sprintf(`CREATE TRIGGER IF NOT EXISTS %[1]s_event_delete AFTER DELETE ON %[1]s
+		BEGIN
+			INSERT INTO change_event (entity, entity_id, operation, owner_id, data, changed_at)
+			VALUES ('%[1]s', OLD.id, 'delete', OLD.owner_id, json_object('id', OLD.id, 'uid', OLD.uid, 'name', OLD.name), %[2]s);
+			DELETE FROM change_event WHERE entity = '%[1]s' AND entity_id = OLD.id AND changed_at = %[2]s AND id < (SELECT MAX(id) FROM change_event);
+		END`, table, sqlNow))
+	}
+
+	// Tags are part of the action, tagging is recorded as an update of it
+	triggers = append(triggers,
+		`CREATE TRIGGER IF NOT EXISTS action_tag_event_insert AFTER INSERT ON action_tag
+		BEGIN
+			`+recordEvent("action", "update", "NEW.action_id")+`
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS action_tag_event_delete AFTER DELETE ON action_tag
+		BEGIN
+			`+recordEvent("action", "update", "OLD.action_id")+`
+		END`,
+		`CREATE INDEX IF NOT EXISTS idx_change_event_entity ON change_event (entity, entity_id)`,
+	)
+	return triggers
+}
+
+// CreateEventLog creates the triggers that maintain change_event. When the
+// log is empty, an insert event is recorded for every existing project and
+// action, so that replaying the log from the start yields the whole database.
+func CreateEventLog(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range eventTriggers() {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create event triggers: %v", err)
+		}
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM change_event").Scan(&count); err != nil {
+		return fmt.Errorf("failed to read event log: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	for _, table := range []string{"project", "action"} {
+		alias := eventAliases[table]
+		_, err := db.Exec(fmt.Sprintf(`
+			INSERT INTO change_event (entity, entity_id, operation, owner_id, data, changed_at)
+			SELECT '%[1]s', %[2]s.id, 'insert', %[2]s.owner_id, %[3]s, COALESCE(%[2]s.changed_at, %[4]s)
+			FROM %[1]s %[2]s
+			ORDER BY %[2]s.id`, table, alias, eventData[table], sqlNow))
+		if err != nil {
+			return fmt.Errorf("failed to record existing %s rows: %v", table, err)
+		}
+	}
+
+	return nil
+}
+
+// GetChangeEvents retrieves up to limit events after the given cursor, oldest
+// first. A cursor of 0 starts at the beginning of the log. A non-zero ownerID
+// limits the events to the projects and actions owned by that user.
+func GetChangeEvents(dbPath string, cursor int64, limit int, ownerID uint) ([]ChangeEvent, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT id, entity, entity_id, operation, data, changed_at
+		FROM change_event
+		WHERE id > ? AND (? = 0 OR owner_id = ?)
+		ORDER BY id
+		LIMIT ?`, cursor, ownerID, ownerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []ChangeEvent{}
+	for rows.Next() {
+		var event ChangeEvent
+		var data string
+		if err := rows.Scan(&event.Cursor, &event.Entity, &event.EntityID, &event.Operation, &data, &event.ChangedAt); err != nil {
+			return nil, err
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SavedFilter is a filter expression saved under a name
+type SavedFilter struct {
+	ID        uint
+	Name      string
+	Query     string
+	CreatedAt string
+}
+
+// SaveFilter saves a filter expression under a name, replacing the
+// expression of an existing filter with that name. The expression is not
+// validated here, see the filter package.
+func SaveFilter(dbPath, name, query string) error {
+	if name == "" {
+		return fmt.Errorf("filter name is required")
+	}
+	if query == "" {
+		return fmt.Errorf("filter query is required")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		INSERT INTO saved_filter (name, query) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET query = excluded.query
+	`, name, query)
+	if err != nil {
+		return fmt.Errorf("failed to save filter: %v", err)
+	}
+	return nil
+}
+
+// GetAllSavedFilters retrieves all saved filters ordered by name
+func GetAllSavedFilters(dbPath string) ([]SavedFilter, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name, query, created_at FROM saved_filter ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []SavedFilter
+	for rows.Next() {
+		var filter SavedFilter
+		if err := rows.Scan(&filter.ID, &filter.Name, &filter.Query, &filter.CreatedAt); err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, rows.Err()
+}
+
+// GetSavedFilter retrieves a saved filter by name
+func GetSavedFilter(dbPath, name string) (*SavedFilter, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var filter SavedFilter
+	err = db.QueryRow("SELECT id, name, query, created_at FROM saved_filter WHERE name = ?", name).
+		Scan(&filter.ID, &filter.Name, &filter.Query, &filter.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Filter not found
+		}
+		return nil, err
+	}
+
+	return &filter, nil
+}
+
+// DeleteSavedFilter deletes a saved filter by name
+func DeleteSavedFilter(dbPath, name string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM saved_filter WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete filter: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("filter %s not found", name)
+	}
+	return nil
+}
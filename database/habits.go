@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// HabitOccurrence is an occurrence of a repeating action that was done,
+// skipped, or is still open
+type HabitOccurrence struct {
+	ActionID    uint
+	DueDate     string // YYYY-MM-DD
+	CompletedOn string // Local date the occurrence was done, empty while it is open
+	Skipped     bool
+}
+
+// Habit is a series of a repeating action: the occurrences created from the
+// first one by repetition, linked through parent_action_id
+type Habit struct {
+	Action      Action            // The latest occurrence
+	Occurrences []HabitOccurrence // By due date
+}
+
+// GetHabits returns the series of the actions that still repeat, with their
+// occurrences and the occurrences skipped, ordered by name. Completion dates
+// come from action_history; occurrences done before it was recorded count as
+// done on their due date.
+func GetHabits(dbPath string) ([]Habit, error) {
+	actions, err := GetAllActions(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	completed := make(map[uint]string)
+	rows, err := db.Query(`
+		SELECT action_id, MAX(date(changed_at, 'localtime'))
+		FROM action_history WHERE status_id = 2 AND skipped_date IS NULL
+		GROUP BY action_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completions: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var actionID uint
+		var date string
+		if err := rows.Scan(&actionID, &date); err != nil {
+			return nil, fmt.Errorf("failed to scan completion: %v", err)
+		}
+		completed[actionID] = date
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[uint][]string)
+	skipRows, err := db.Query("SELECT action_id, skipped_date FROM action_history WHERE skipped_date IS NOT NULL ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query skipped occurrences: %v", err)
+	}
+	defer skipRows.Close()
+	for skipRows.Next() {
+		var actionID uint
+		var date string
+		if err := skipRows.Scan(&actionID, &date); err != nil {
+			return nil, fmt.Errorf("failed to scan skipped occurrence: %v", err)
+		}
+		skipped[actionID] = append(skipped[actionID], StoredDate(date))
+	}
+	if err := skipRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Every occurrence belongs to the series of its first occurrence. A
+	// detached occurrence, or one whose parent was deleted, starts a series.
+	byID := make(map[uint]Action, len(actions))
+	for _, action := range actions {
+		byID[action.ID] = action
+	}
+	first := func(action Action) uint {
+		for action.ParentActionID.Valid {
+			parent, ok := byID[uint(action.ParentActionID.Int64)]
+			if !ok {
+				break
+			}
+			action = parent
+		}
+		return action.ID
+	}
+	series := make(map[uint][]Action)
+	for _, action := range actions {
+		id := first(action)
+		series[id] = append(series[id], action)
+	}
+
+	var habits []Habit
+	for _, occurrences := range series {
+		// Actions are ordered by ID descending, so the latest comes first
+		habit := Habit{Action: occurrences[0]}
+		if !habit.Action.IsRepeating() {
+			continue
+		}
+
+		for _, action := range occurrences {
+			occurrence := HabitOccurrence{ActionID: action.ID, DueDate: StoredDate(action.DueDate.String)}
+			if action.StatusName == "done" {
+				occurrence.CompletedOn = completed[action.ID]
+				if occurrence.CompletedOn == "" {
+					occurrence.CompletedOn = occurrence.DueDate
+				}
+			}
+			habit.Occurrences = append(habit.Occurrences, occurrence)
+			for _, date := range skipped[action.ID] {
+				habit.Occurrences = append(habit.Occurrences, HabitOccurrence{ActionID: action.ID, DueDate: date, Skipped: true})
+			}
+		}
+		sort.SliceStable(habit.Occurrences, func(i, j int) bool {
+			a, b := habit.Occurrences[i], habit.Occurrences[j]
+			if a.DueDate != b.DueDate {
+				return a.DueDate < b.DueDate
+			}
+			return a.ActionID < b.ActionID
+		})
+		habits = append(habits, habit)
+	}
+
+	sort.Slice(habits, func(i, j int) bool {
+		if habits[i].Action.Name != habits[j].Action.Name {
+			return habits[i].Action.Name < habits[j].Action.Name
+		}
+		return habits[i].Action.ID < habits[j].Action.ID
+	})
+	return habits, nil
+}
+
+// Missed reports whether an occurrence is open past its due date on a day
+// (YYYY-MM-DD)
+func (o HabitOccurrence) Missed(today string) bool {
+	return !o.Skipped && o.CompletedOn == "" && o.DueDate != "" && o.DueDate < today
+}
+
+// Streak returns the number of occurrences done in a row up to a day
+// (YYYY-MM-DD). Skipped occurrences and the open occurrence that is not due
+// yet leave the streak as it is; an occurrence that was missed ends it.
+func (h Habit) Streak(today string) int {
+	streak := 0
+	for i := len(h.Occurrences) - 1; i >= 0; i-- {
+		occurrence := h.Occurrences[i]
+		switch {
+		case occurrence.CompletedOn != "":
+			streak++
+		case occurrence.Missed(today):
+			return streak
+		}
+	}
+	return streak
+}
+
+// BestStreak returns the largest number of occurrences ever done in a row
+func (h Habit) BestStreak(today string) int {
+	best, streak := 0, 0
+	for _, occurrence := range h.Occurrences {
+		switch {
+		case occurrence.CompletedOn != "":
+			streak++
+			best = max(best, streak)
+		case occurrence.Missed(today):
+			streak = 0
+		}
+	}
+	return best
+}
+
+// CompletionRate counts the occurrences due in the given number of days up to
+// a day (YYYY-MM-DD) that were done, and those that were done or missed.
+// Skipped occurrences and the open occurrence that is not due yet are left
+// out.
+func (h Habit) CompletionRate(today string, days int) (done, due int) {
+	end, err := time.Parse("2006-01-02", today)
+	if err != nil {
+		return 0, 0
+	}
+	from := end.AddDate(0, 0, 1-days).Format("2006-01-02")
+
+	for _, occurrence := range h.Occurrences {
+		if occurrence.DueDate < from || occurrence.DueDate > today {
+			continue
+		}
+		switch {
+		case occurrence.CompletedOn != "":
+			done++
+			due++
+		case occurrence.Missed(today):
+			due++
+		}
+	}
+	return done, due
+}
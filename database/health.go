@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SchemaVersion is the version of the schema created by CreateTable and the
+// migrations. Bump it whenever a table, column or index is added, so that
+// health checks can tell whether a database has been migrated.
+const SchemaVersion = 29
+
+// Health describes the state of the database for health checks
+type Health struct {
+	SchemaVersion         int            `json:"schema_version"`
+	ExpectedSchemaVersion int            `json:"expected_schema_version"`
+	SchemaUpToDate        bool           `json:"schema_up_to_date"`
+	Counts                map[string]int `json:"counts"`
+	SizeBytes             int64          `json:"size_bytes"`
+}
+
+// SetSchemaVersion records that the database schema matches SchemaVersion
+func SetSchemaVersion(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// PRAGMA statements do not take parameters
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to set schema version: %v", err)
+	}
+	return nil
+}
+
+// CheckHealth verifies that the database file exists and can be queried, and
+// reports its schema version and row counts. Unlike the other functions it
+// never creates a missing database file, and it gives up quickly when the
+// database is locked.
+func CheckHealth(dbPath string) (*Health, error) {
+	health := Health{
+		ExpectedSchemaVersion: SchemaVersion,
+		Counts:                map[string]int{},
+	}
+
+	dsn := dbPath
+	if !IsMemoryPath(dbPath) {
+		info, err := os.Stat(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("database file not accessible: %v", err)
+		}
+		health.SizeBytes = info.Size()
+		dsn = "file:" + dbPath + "?mode=rw&_busy_timeout=1000"
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.QueryRow("PRAGMA user_version").Scan(&health.SchemaVersion); err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	health.SchemaUpToDate = health.SchemaVersion >= SchemaVersion
+
+	for _, table := range []string{"project", "action", "tag", "user"} {
+		var count int
+		if err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", table)).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count %s rows: %v", table, err)
+		}
+		health.Counts[table] = count
+	}
+
+	return &health, nil
+}
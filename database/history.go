@@ -0,0 +1,200 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// historyTriggers record the state of an action in action_history whenever it
+// is created, deleted, or its status or project changes. A NULL status_id
+// records the deletion. Unlike the sync triggers they also record the changes
+// applied by remote sync.
+var historyTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS action_history_insert AFTER INSERT ON action
+	BEGIN
+		INSERT INTO action_history (action_id, project_id, status_id, changed_at) VALUES (NEW.id, NEW.project_id, NEW.status_id, ` + sqlNow + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS action_history_update AFTER UPDATE OF status_id, project_id ON action
+	WHEN NEW.status_id IS NOT OLD.status_id OR NEW.project_id IS NOT OLD.project_id
+	BEGIN
+		INSERT INTO action_history (action_id, project_id, status_id, changed_at) VALUES (NEW.id, NEW.project_id, NEW.status_id, ` + sqlNow + `);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS action_history_delete AFTER DELETE ON action
+	BEGIN
+		INSERT INTO action_history (action_id, project_id, status_id, changed_at) VALUES (OLD.id, OLD.project_id, NULL, ` + sqlNow + `);
+	END`,
+	`CREATE INDEX IF NOT EXISTS idx_action_history_project_id ON action_history (project_id)`,
+}
+
+// BurndownDay is the number of open and closed actions of a project at the
+// end of a day
+type BurndownDay struct {
+	Date   string // YYYY-MM-DD
+	Open   int
+	Closed int
+}
+
+// CreateHistory creates the triggers that maintain action_history and
+// records the current state of actions that have no history yet. Their
+// history starts at their last change, the time they were created or
+// completed before is unknown.
+func CreateHistory(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range historyTriggers {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create history triggers: %v", err)
+		}
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO action_history (action_id, project_id, status_id, changed_at)
+		SELECT a.id, a.project_id, a.status_id, COALESCE(a.updated_at, ` + sqlNow + `)
+		FROM action a
+		WHERE NOT EXISTS (SELECT 1 FROM action_history h WHERE h.action_id = a.id)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to record action history: %v", err)
+	}
+
+	return nil
+}
+
+// GetProjectBurndown returns the number of open and closed actions of a
+// project at the end of every day from one date to another (YYYY-MM-DD),
+// derived from action_history. An empty from starts at the first day the
+// project had actions, an empty to ends today. Days are local dates.
+func GetProjectBurndown(dbPath string, projectID uint, from, to string) ([]BurndownDay, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	// Actions that moved out of the project still need their later history
+	rows, err := db.Query(`
+		SELECT action_id, project_id, status_id, date(changed_at, 'localtime')
+		FROM action_history
+		WHERE action_id IN (SELECT action_id FROM action_history WHERE project_id = ?)
+		ORDER BY changed_at, id
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type historyEntry struct {
+		actionID  uint
+		projectID sql.NullInt64
+		statusID  sql.NullInt64
+		date      string
+	}
+	var history []historyEntry
+	for rows.Next() {
+		var entry historyEntry
+		if err := rows.Scan(&entry.actionID, &entry.projectID, &entry.statusID, &entry.date); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if to == "" {
+		to = time.Now().Format("2006-01-02")
+	}
+	if from == "" {
+		if len(history) == 0 {
+			return []BurndownDay{}, nil
+		}
+		from = history[0].date
+	}
+	start, err := ParseStoredDate(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date: %s", from)
+	}
+	end, err := ParseStoredDate(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date: %s", to)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("from date %s is after to date %s", from, to)
+	}
+
+	// Replay the history day by day, keeping the latest state of every action
+	days := []BurndownDay{}
+	latest := map[uint]historyEntry{}
+	next := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		for next < len(history) && history[next].date <= date {
+			latest[history[next].actionID] = history[next]
+			next++
+		}
+
+		burndown := BurndownDay{Date: date}
+		for _, entry := range latest {
+			if !entry.projectID.Valid || uint(entry.projectID.Int64) != projectID || !entry.statusID.Valid {
+				continue
+			}
+			if entry.statusID.Int64 == 2 {
+				burndown.Closed++
+			} else {
+				burndown.Open++
+			}
+		}
+		days = append(days, burndown)
+	}
+
+	return days, nil
+}
+
+// HistoryEntry is a recorded state of an action: its status and project
+// after a change, or a skipped occurrence of a repeating action
+type HistoryEntry struct {
+	ChangedAt   string         // Timestamp of the change, in UTC
+	Status      sql.NullString // NULL when the action was deleted
+	ProjectID   sql.NullInt64
+	ProjectName sql.NullString
+	SkippedDate sql.NullString // Due date that was skipped, see SkipOccurrence
+}
+
+// GetActionHistory returns the recorded states of an action, oldest first
+func GetActionHistory(dbPath string, actionID uint) ([]HistoryEntry, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT h.changed_at, s.name, h.project_id, p.name, h.skipped_date
+		FROM action_history h
+		LEFT JOIN status s ON s.id = h.status_id
+		LEFT JOIN project p ON p.id = h.project_id
+		WHERE h.action_id = ?
+		ORDER BY h.changed_at, h.id
+	`, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action history: %v", err)
+	}
+	defer rows.Close()
+
+	history := []HistoryEntry{}
+	for rows.Next() {
+		var entry HistoryEntry
+		if err := rows.Scan(&entry.ChangedAt, &entry.Status, &entry.ProjectID, &entry.ProjectName, &entry.SkippedDate); err != nil {
+			return nil, fmt.Errorf("failed to scan action history: %v", err)
+		}
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// IdempotencyKeyRetention is how long the response to a request with an
+// idempotency key is kept, and so how long a client may take to retry it
+const IdempotencyKeyRetention = 30 * 24 * time.Hour
+
+// IdempotencyKeyTimeout is how long a key stays reserved for a request that
+// has not finished. A reservation older than this was left by a server that
+// stopped during the request, and the key may be used again.
+const IdempotencyKeyTimeout = 5 * time.Minute
+
+// IdempotentResponse is the response stored for an idempotency key, returned
+// again when a request with the same key is retried. Keys belong to a user,
+// 0 in single-user mode, so one user cannot replay the response of another.
+// A Status of 0 means the request with the key is still running.
+type IdempotentResponse struct {
+	UserID      uint
+	Key         string
+	Method      string
+	Path        string
+	Status      int
+	ContentType string
+	Body        string
+}
+
+// ReserveIdempotencyKey reserves an idempotency key of a user for a request,
+// so a retry that arrives while the request runs does not make the change
+// again. It returns nil when the key was reserved, and the stored response,
+// which may still be pending, when the key was already used.
+func ReserveIdempotencyKey(dbPath string, userID uint, key, method, path string) (*IdempotentResponse, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	expired := time.Now().Add(-IdempotencyKeyRetention).UTC().Format(ChangeTimeFormat)
+	if _, err := db.Exec("DELETE FROM idempotency_key WHERE created_at < ?", expired); err != nil {
+		return nil, fmt.Errorf("failed to remove expired idempotency keys: %v", err)
+	}
+	abandoned := time.Now().Add(-IdempotencyKeyTimeout).UTC().Format(ChangeTimeFormat)
+	if _, err := db.Exec("DELETE FROM idempotency_key WHERE status = 0 AND created_at < ?", abandoned); err != nil {
+		return nil, fmt.Errorf("failed to remove abandoned idempotency keys: %v", err)
+	}
+
+	result, err := db.Exec("INSERT OR IGNORE INTO idempotency_key (user_id, key, method, path, status, body, created_at) VALUES (?, ?, ?, ?, 0, '', "+sqlNow+")",
+		userID, key, method, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %v", err)
+	}
+	if reserved, _ := result.RowsAffected(); reserved == 1 {
+		return nil, nil
+	}
+
+	response := IdempotentResponse{UserID: userID}
+	var contentType sql.NullString
+	err = db.QueryRow("SELECT key, method, path, status, content_type, body FROM idempotency_key WHERE user_id = ? AND key = ?", userID, key).Scan(
+		&response.Key, &response.Method, &response.Path, &response.Status, &contentType, &response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query idempotency key: %v", err)
+	}
+	response.ContentType = contentType.String
+
+	return &response, nil
+}
+
+// SaveIdempotentResponse stores the response to the request that reserved
+// an idempotency key
+func SaveIdempotentResponse(dbPath string, response IdempotentResponse) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE idempotency_key SET status = ?, content_type = ?, body = ? WHERE user_id = ? AND key = ? AND status = 0",
+		response.Status, nullIfEmpty(response.ContentType), response.Body, response.UserID, response.Key)
+	if err != nil {
+		return fmt.Errorf("failed to save idempotency key: %v", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes the reservation of an idempotency key whose
+// request failed, so the request may be retried with the same key
+func ReleaseIdempotencyKey(dbPath string, userID uint, key string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("DELETE FROM idempotency_key WHERE user_id = ? AND key = ? AND status = 0", userID, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package database
+
+import "github.com/joelgrimberg/projector/wire"
+
+// ImportFailure is a row of an import file that was not imported
+type ImportFailure = wire.ImportFailure
+
+// ImportProgress is called by the importers after every row, with the
+// number of rows done and the total number of rows
+type ImportProgress func(done, total int)
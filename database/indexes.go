@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// indexes speed up the joins and filters of the action queries. The due date
+// index is on date(due_date), as the queries compare dates through date().
+var indexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_action_project_id ON action (project_id)",
+	"CREATE INDEX IF NOT EXISTS idx_action_status_id ON action (status_id)",
+	"CREATE INDEX IF NOT EXISTS idx_action_due_date ON action (date(due_date))",
+	"CREATE INDEX IF NOT EXISTS idx_action_parent_action_id ON action (parent_action_id)",
+	"CREATE INDEX IF NOT EXISTS idx_action_tag_tag_id ON action_tag (tag_id)",
+	"CREATE INDEX IF NOT EXISTS idx_attachment_action_id ON attachment (action_id)",
+}
+
+// QueryPlan is the plan SQLite chose for one of the frequent queries
+type QueryPlan struct {
+	Name  string   `json:"name"`
+	Query string   `json:"query"`
+	Plan  []string `json:"plan"`
+}
+
+// explainedQueries are the frequent queries shown by ExplainQueries
+var explainedQueries = []struct {
+	name  string
+	query string
+}{
+	{"all actions", actionSelectQuery + "ORDER BY a.id DESC"},
+	{"actions of a project", actionSelectQuery + "WHERE a.project_id = 1"},
+	{"open actions", actionSelectQuery + "WHERE a.status_id = 1"},
+	{"actions due on a date", actionSelectQuery + "WHERE a.status_id != 2 AND date(a.due_date) = date('2030-01-01') ORDER BY a.id"},
+	{"overdue actions", actionSelectQuery + "WHERE a.status_id != 2 AND date(a.due_date) < date('now', 'localtime') ORDER BY a.due_date, a.id"},
+	{"actions with a tag", "SELECT action_id FROM action_tag WHERE tag_id = 1"},
+	{"next occurrences", "SELECT id FROM action WHERE parent_action_id = 1"},
+}
+
+// CreateIndexes creates the indexes of the frequent queries
+func CreateIndexes(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range indexes {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create index: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ExplainQueries returns the query plans of the frequent queries, to check
+// that they use the indexes
+func ExplainQueries(dbPath string) ([]QueryPlan, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var plans []QueryPlan
+	for _, explained := range explainedQueries {
+		rows, err := db.Query("EXPLAIN QUERY PLAN " + explained.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain %s: %v", explained.name, err)
+		}
+
+		plan := QueryPlan{Name: explained.name, Query: explained.query}
+		for rows.Next() {
+			var id, parent, unused int
+			var detail string
+			if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to explain %s: %v", explained.name, err)
+			}
+			plan.Plan = append(plan.Plan, detail)
+		}
+		rows.Close()
+
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultNearRadius is the distance in kilometers within which actions are
+// near a place, when no radius is given
+const DefaultNearRadius = 1.0
+
+// earthRadius is the mean radius of the earth in kilometers
+const earthRadius = 6371.0
+
+// Coordinates are a latitude and longitude in degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Validate checks that the coordinates lie on the earth
+func (c Coordinates) Validate() error {
+	if c.Latitude < -90 || c.Latitude > 90 {
+		return fmt.Errorf("invalid latitude %g: expected a value between -90 and 90", c.Latitude)
+	}
+	if c.Longitude < -180 || c.Longitude > 180 {
+		return fmt.Errorf("invalid longitude %g: expected a value between -180 and 180", c.Longitude)
+	}
+	return nil
+}
+
+// ParseCoordinates parses coordinates written as "latitude,longitude", such
+// as 52.37,4.89
+func ParseCoordinates(value string) (Coordinates, error) {
+	latitude, longitude, found := strings.Cut(value, ",")
+	if !found {
+		return Coordinates{}, fmt.Errorf("invalid coordinates %s: expected latitude,longitude", value)
+	}
+
+	var c Coordinates
+	var err error
+	if c.Latitude, err = strconv.ParseFloat(strings.TrimSpace(latitude), 64); err != nil {
+		return Coordinates{}, fmt.Errorf("invalid coordinates %s: expected latitude,longitude", value)
+	}
+	if c.Longitude, err = strconv.ParseFloat(strings.TrimSpace(longitude), 64); err != nil {
+		return Coordinates{}, fmt.Errorf("invalid coordinates %s: expected latitude,longitude", value)
+	}
+	return c, c.Validate()
+}
+
+// Distance returns the great-circle distance in kilometers between two
+// coordinates
+func (c Coordinates) Distance(other Coordinates) float64 {
+	lat1, lat2 := c.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - c.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// Coordinates returns the coordinates of an action, or nil when it has none
+func (a *Action) Coordinates() *Coordinates {
+	if !a.Latitude.Valid || !a.Longitude.Valid {
+		return nil
+	}
+	return &Coordinates{Latitude: a.Latitude.Float64, Longitude: a.Longitude.Float64}
+}
+
+// Place is where to look for actions with IsNear
+type Place struct {
+	Name        string
+	Coordinates *Coordinates
+}
+
+// ResolvePlace resolves "latitude,longitude" or the name of a location. A
+// name gets the coordinates of the first action at that location that has
+// them, so actions without a location name are found by distance too.
+func ResolvePlace(actions []Action, near string) Place {
+	if c, err := ParseCoordinates(near); err == nil {
+		return Place{Coordinates: &c}
+	}
+
+	place := Place{Name: near}
+	for i := range actions {
+		if strings.EqualFold(actions[i].Location.String, near) {
+			if c := actions[i].Coordinates(); c != nil {
+				place.Coordinates = c
+				break
+			}
+		}
+	}
+	return place
+}
+
+// IsNear reports whether an action is at a place: its location contains the
+// place name, or it lies within radius kilometers of the place coordinates
+func (a *Action) IsNear(place Place, radius float64) bool {
+	if place.Name != "" && strings.Contains(strings.ToLower(a.Location.String), strings.ToLower(place.Name)) {
+		return true
+	}
+	if c := a.Coordinates(); c != nil && place.Coordinates != nil {
+		return c.Distance(*place.Coordinates) <= radius
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// MaintenanceInterval is how often the server maintains the database when
+// automatic maintenance is enabled
+const MaintenanceInterval = 7 * 24 * time.Hour
+
+// StorageInfo describes the size of the database and how much of it is unused
+type StorageInfo struct {
+	SizeBytes     int64   `json:"size_bytes"`
+	PageSize      int64   `json:"page_size"`
+	PageCount     int64   `json:"page_count"`
+	FreePages     int64   `json:"free_pages"`
+	Fragmentation float64 `json:"fragmentation"` // Fraction of the pages that are unused
+}
+
+// MaintenanceResult reports the storage before and after maintenance
+type MaintenanceResult struct {
+	Before   StorageInfo   `json:"before"`
+	After    StorageInfo   `json:"after"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// GetStorageInfo reads the page counts of the database
+func GetStorageInfo(dbPath string) (*StorageInfo, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	return storageInfo(db)
+}
+
+// storageInfo reads the page counts over an open database
+func storageInfo(db *sql.DB) (*StorageInfo, error) {
+	var info StorageInfo
+	pragmas := []struct {
+		name string
+		dest *int64
+	}{
+		{"page_size", &info.PageSize},
+		{"page_count", &info.PageCount},
+		{"freelist_count", &info.FreePages},
+	}
+	for _, pragma := range pragmas {
+		if err := db.QueryRow("PRAGMA " + pragma.name).Scan(pragma.dest); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", pragma.name, err)
+		}
+	}
+
+	info.SizeBytes = info.PageSize * info.PageCount
+	if info.PageCount > 0 {
+		info.Fragmentation = float64(info.FreePages) / float64(info.PageCount)
+	}
+	return &info, nil
+}
+
+// MaintainDatabase rebuilds the database file to reclaim unused pages with
+// VACUUM and refreshes the query planner statistics with ANALYZE. The run is
+// recorded in the maintenance log.
+func MaintainDatabase(dbPath string) (*MaintenanceResult, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Now()
+	before, err := storageInfo(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range []string{"VACUUM", "ANALYZE", "PRAGMA optimize"} {
+		if _, err := db.Exec(statement); err != nil {
+			return nil, fmt.Errorf("failed to run %s: %v", statement, err)
+		}
+	}
+
+	after, err := storageInfo(db)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec("INSERT INTO maintenance_log (size_before, size_after) VALUES (?, ?)", before.SizeBytes, after.SizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record maintenance: %v", err)
+	}
+
+	return &MaintenanceResult{Before: *before, After: *after, Duration: time.Since(start)}, nil
+}
+
+// GetLastMaintenance returns when the database was last maintained, or the
+// zero time when it never was
+func GetLastMaintenance(dbPath string) (time.Time, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var ranAt time.Time
+	err = db.QueryRow("SELECT ran_at FROM maintenance_log ORDER BY ran_at DESC LIMIT 1").Scan(&ranAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil // Never maintained
+		}
+		return time.Time{}, fmt.Errorf("failed to read maintenance log: %v", err)
+	}
+	return ranAt, nil
+}
+
+// RunMaintenance maintains the database whenever the last maintenance is
+// more than MaintenanceInterval ago, checking every hour until stop is closed
+func RunMaintenance(dbPath string, stop <-chan struct{}, onDone func(*MaintenanceResult), onError func(error)) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		lastRun, err := GetLastMaintenance(dbPath)
+		if err != nil {
+			onError(err)
+		} else if time.Since(lastRun) >= MaintenanceInterval {
+			if result, err := MaintainDatabase(dbPath); err != nil {
+				onError(err)
+			} else {
+				onDone(result)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Project roles, in increasing order of permissions. Viewers can read a
+// project and its actions, editors can also add and change its actions, and
+// owners can also delete the project and manage its members.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+// roleLevels orders the project roles by their permissions
+var roleLevels = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ProjectMember is a user a project is shared with
+type ProjectMember struct {
+	ProjectID uint
+	UserID    uint
+	Username  string
+	Role      string
+	CreatedAt string
+}
+
+// ValidRole reports whether a role name is known
+func ValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// HasRole reports whether a role grants at least the permissions of the
+// required role. An empty role grants nothing.
+func HasRole(role, required string) bool {
+	return role != "" && roleLevels[role] >= roleLevels[required]
+}
+
+// GetProjectRole returns the role of a user in a project: owner for the user
+// owning the project, the member role for users it is shared with, and an
+// empty string otherwise
+func GetProjectRole(dbPath string, projectID, userID uint) (string, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT CASE WHEN p.owner_id = ? THEN ? ELSE m.role END
+		FROM project p
+		LEFT JOIN project_member m ON m.project_id = p.id AND m.user_id = ?
+		WHERE p.id = ?
+	`
+
+	var role sql.NullString
+	err = db.QueryRow(query, userID, RoleOwner, userID, projectID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil // Project not found
+		}
+		return "", err
+	}
+
+	return role.String, nil
+}
+
+// GetProjectMembers retrieves the users a project is shared with
+func GetProjectMembers(dbPath string, projectID uint) ([]ProjectMember, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT m.project_id, m.user_id, u.username, m.role, m.created_at
+		FROM project_member m
+		JOIN user u ON u.id = m.user_id
+		WHERE m.project_id = ?
+		ORDER BY u.username
+	`
+
+	rows, err := db.Query(query, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []ProjectMember
+	for rows.Next() {
+		var member ProjectMember
+		if err := rows.Scan(&member.ProjectID, &member.UserID, &member.Username, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// SetProjectMember shares a project with a user, or changes the role of an
+// existing member
+func SetProjectMember(dbPath string, projectID, userID uint, role string) error {
+	if !ValidRole(role) {
+		return fmt.Errorf("invalid role: %s (expected owner, editor or viewer)", role)
+	}
+
+	project, err := GetProjectByID(dbPath, projectID)
+	if err != nil {
+		return fmt.Errorf("error checking project existence: %v", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project not found")
+	}
+	if project.OwnerID.Valid && uint(project.OwnerID.Int64) == userID {
+		return fmt.Errorf("user already owns the project")
+	}
+
+	user, err := GetUserByID(dbPath, userID)
+	if err != nil {
+		return fmt.Errorf("error checking user existence: %v", err)
+	}
+	if user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	query := `
+		INSERT INTO project_member (project_id, user_id, role) VALUES (?, ?, ?)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = excluded.role
+	`
+
+	if _, err := db.Exec(query, projectID, userID, role); err != nil {
+		return fmt.Errorf("failed to add project member: %v", err)
+	}
+
+	return nil
+}
+
+// RemoveProjectMember stops sharing a project with a user, returning false
+// when the user was not a member
+func RemoveProjectMember(dbPath string, projectID, userID uint) (bool, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM project_member WHERE project_id = ? AND user_id = ?", projectID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove project member: %v", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
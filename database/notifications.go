@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NotificationSent checks if a notification was already sent to a channel.
+// The key identifies what the notification was about, e.g. an action ID or a date.
+func NotificationSent(dbPath, event, channel, key string) (bool, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM notification_log WHERE event = ? AND channel = ? AND key = ?", event, channel, key).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// RecordNotification remembers that a notification was sent to a channel
+func RecordNotification(dbPath, event, channel, key string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT OR REPLACE INTO notification_log (event, channel, key) VALUES (?, ?, ?)", event, channel, key)
+	if err != nil {
+		return fmt.Errorf("failed to record notification: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SetProjectRepeat makes a project recur with the given interval, or stops it
+// from recurring when the interval is empty. A recurring project needs a due
+// date, the deadline of its first period.
+func SetProjectRepeat(dbPath string, projectID uint, interval string) error {
+	project, err := GetProjectByID(dbPath, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %v", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", projectID)
+	}
+
+	if interval != "" {
+		if err := ValidateProjectRepeatInterval(interval); err != nil {
+			return err
+		}
+		if !project.DueDate.Valid || project.DueDate.String == "" {
+			return fmt.Errorf("project %s needs a due date to repeat", project.Name)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE project SET repeat_interval = ? WHERE id = ?", nullIfEmpty(interval), projectID)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %v", err)
+	}
+	return nil
+}
+
+// nextProjectDueDate returns the deadline of the period after the one ending
+// on the given due date
+func nextProjectDueDate(dueDate time.Time, interval string) (time.Time, error) {
+	switch interval {
+	case "week":
+		return dueDate.AddDate(0, 0, 7), nil
+	case "month":
+		return dueDate.AddDate(0, 1, 0), nil
+	case "quarter":
+		return dueDate.AddDate(0, 3, 0), nil
+	case "year":
+		return dueDate.AddDate(1, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unsupported project repeat interval: %s", interval)
+}
+
+// CreateNextProjectPeriod creates the next period of a recurring project once
+// all of its actions due by the project deadline are done. The new project gets the same actions as new
+// todo actions, with their due dates moved by the repeat interval, and the
+// same owner, members and review schedule. The finished period is archived. It returns the ID of the new project, or 0 when the
+// project does not recur, still has open actions or already has a next period.
+func CreateNextProjectPeriod(dbPath string, projectID uint) (uint, error) {
+	project, err := GetProjectByID(dbPath, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get project: %v", err)
+	}
+	if project == nil || !project.RepeatInterval.Valid || project.RepeatInterval.String == "" || project.IsArchived() {
+		return 0, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// Occurrences of repeating actions after the deadline belong to a later
+	// period and do not keep this one open
+	var openActions, nextPeriods int
+	query := "SELECT COUNT(*) FROM action WHERE project_id = ? AND status_id != 2 AND (due_date IS NULL OR date(due_date) <= date(?))"
+	if err := db.QueryRow(query, projectID, project.DueDate.String).Scan(&openActions); err != nil {
+		return 0, fmt.Errorf("failed to count open actions: %v", err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM project WHERE parent_project_id = ?", projectID).Scan(&nextPeriods); err != nil {
+		return 0, fmt.Errorf("failed to check next period: %v", err)
+	}
+	if openActions > 0 || nextPeriods > 0 {
+		return 0, nil
+	}
+
+	dueDate, err := ParseStoredDate(project.DueDate.String)
+	if err != nil {
+		return 0, fmt.Errorf("project %s has no valid due date to repeat from", project.Name)
+	}
+	nextDueDate, err := nextProjectDueDate(dueDate, project.RepeatInterval.String)
+	if err != nil {
+		return 0, err
+	}
+
+	// The dates of the actions are kept relative to the project deadline
+	template, err := NewProjectTemplate(dbPath, projectID, project.Name, dueDate.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	nextID, err := CreateProjectFromTemplate(dbPath, template, project.Name, nextDueDate.Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.Exec("UPDATE project SET repeat_interval = ?, parent_project_id = ?, owner_id = ?, review_interval = ?, last_reviewed_at = ? WHERE id = ?",
+		project.RepeatInterval.String, projectID, project.OwnerID, project.ReviewInterval, project.LastReviewedAt, nextID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to link next period: %v", err)
+	}
+	_, err = db.Exec("INSERT INTO project_member (project_id, user_id, role) SELECT ?, user_id, role FROM project_member WHERE project_id = ?", nextID, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to share next period: %v", err)
+	}
+	_, err = db.Exec("UPDATE project SET archived_at = "+sqlNow+" WHERE id = ?", projectID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive finished period: %v", err)
+	}
+
+	return nextID, nil
+}
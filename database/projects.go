@@ -2,19 +2,51 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"slices"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 // Project represents a project in the database
 type Project struct {
-	ID      uint
-	Name    string
-	DueDate sql.NullString
+	ID         uint
+	Name       string
+	DueDate    sql.NullString
+	OwnerID    sql.NullInt64
+	ArchivedAt sql.NullString // Set when the project was merged into another
+
+	// RepeatInterval makes the project recur: once all its actions are done,
+	// the project is created again for the next period. ParentProjectID is
+	// the project of the previous period.
+	RepeatInterval  sql.NullString
+	ParentProjectID sql.NullInt64
+
+	// ReviewInterval is how often the project is due for a review, such as
+	// 1w or 2m, counted from LastReviewedAt
+	ReviewInterval sql.NullString
+	LastReviewedAt sql.NullString
+
+	Version uint // Increases on every change, see VersionConflictError
+}
+
+// IsArchived reports whether the project was archived
+func (p *Project) IsArchived() bool {
+	return p.ArchivedAt.Valid
 }
 
 // GetAllProjects retrieves all projects
 func GetAllProjects(dbPath string) ([]Project, error) {
+	return queryProjects(dbPath, "ORDER BY id DESC")
+}
+
+// GetProjectsForUser retrieves the projects owned by or shared with a user
+func GetProjectsForUser(dbPath string, userID uint) ([]Project, error) {
+	return queryProjects(dbPath, "WHERE owner_id = ? OR id IN (SELECT project_id FROM project_member WHERE user_id = ?) ORDER BY id DESC", userID, userID)
+}
+
+// queryProjects retrieves the projects matching the given WHERE and ORDER BY clauses
+func queryProjects(dbPath, clauses string, args ...interface{}) ([]Project, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, err
@@ -22,12 +54,11 @@ func GetAllProjects(dbPath string) ([]Project, error) {
 	defer db.Close()
 
 	query := `
-		SELECT id, name, due_date
+		SELECT id, name, due_date, owner_id, archived_at, repeat_interval, parent_project_id, review_interval, last_reviewed_at, version
 		FROM project
-		ORDER BY id DESC
-	`
+	` + clauses
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +67,7 @@ func GetAllProjects(dbPath string) ([]Project, error) {
 	var projects []Project
 	for rows.Next() {
 		var project Project
-		err := rows.Scan(&project.ID, &project.Name, &project.DueDate)
+		err := rows.Scan(&project.ID, &project.Name, &project.DueDate, &project.OwnerID, &project.ArchivedAt, &project.RepeatInterval, &project.ParentProjectID, &project.ReviewInterval, &project.LastReviewedAt, &project.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -55,13 +86,42 @@ func GetProjectByID(dbPath string, projectID uint) (*Project, error) {
 	defer db.Close()
 
 	query := `
-		SELECT id, name, due_date
+		SELECT id, name, due_date, owner_id, archived_at, repeat_interval, parent_project_id, review_interval, last_reviewed_at, version
 		FROM project
 		WHERE id = ?
 	`
 
 	var project Project
-	err = db.QueryRow(query, projectID).Scan(&project.ID, &project.Name, &project.DueDate)
+	err = db.QueryRow(query, projectID).Scan(&project.ID, &project.Name, &project.DueDate, &project.OwnerID, &project.ArchivedAt, &project.RepeatInterval, &project.ParentProjectID, &project.ReviewInterval, &project.LastReviewedAt, &project.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Project not found
+		}
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+// GetProjectByName retrieves a project by its name, preferring projects that
+// are not archived
+func GetProjectByName(dbPath, name string) (*Project, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT id, name, due_date, owner_id, archived_at, repeat_interval, parent_project_id, review_interval, last_reviewed_at, version
+		FROM project
+		WHERE name = ?
+		ORDER BY archived_at IS NOT NULL, id
+		LIMIT 1
+	`
+
+	var project Project
+	err = db.QueryRow(query, name).Scan(&project.ID, &project.Name, &project.DueDate, &project.OwnerID, &project.ArchivedAt, &project.RepeatInterval, &project.ParentProjectID, &project.ReviewInterval, &project.LastReviewedAt, &project.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // Project not found
@@ -96,7 +156,7 @@ func CreateProject(dbPath, name, dueDate string) (uint, error) {
 		VALUES (?, ?)
 	`
 
-	result, err := db.Exec(query, name, validatedDueDate)
+	result, err := db.Exec(query, name, nullIfEmpty(validatedDueDate))
 	if err != nil {
 		return 0, err
 	}
@@ -108,3 +168,177 @@ func CreateProject(dbPath, name, dueDate string) (uint, error) {
 
 	return uint(projectID), nil
 }
+
+// GetOrCreateProject returns the ID of the project with the given name, creating it if needed
+func GetOrCreateProject(dbPath, name string) (uint, error) {
+	project, err := GetProjectByName(dbPath, name)
+	if err != nil {
+		return 0, err
+	}
+	if project != nil {
+		return project.ID, nil
+	}
+
+	return CreateProject(dbPath, name, "")
+}
+
+// SetProjectOwner sets the user owning a project
+func SetProjectOwner(dbPath string, projectID, ownerID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("UPDATE project SET owner_id = ? WHERE id = ?", ownerID, projectID)
+	return err
+}
+
+// ProjectUpdate holds the settings of a project to change. Nil fields are
+// left unchanged, an empty interval stops the project from recurring or
+// being reviewed. A non-nil Version makes the update apply only when the
+// project is still at that version.
+type ProjectUpdate struct {
+	RepeatInterval *string
+	ReviewInterval *string
+	Version        *uint
+}
+
+// UpdateProject changes the settings of a project in a single write, which
+// increases its version once
+func UpdateProject(dbPath string, projectID uint, update ProjectUpdate) error {
+	project, err := GetProjectByID(dbPath, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %v", err)
+	}
+	if project == nil {
+		return fmt.Errorf("project %d not found", projectID)
+	}
+
+	var repeatInterval, reviewInterval string
+	if update.RepeatInterval != nil && *update.RepeatInterval != "" {
+		repeatInterval = *update.RepeatInterval
+		if err := ValidateProjectRepeatInterval(repeatInterval); err != nil {
+			return err
+		}
+		if !project.DueDate.Valid || project.DueDate.String == "" {
+			return fmt.Errorf("project %s needs a due date to repeat", project.Name)
+		}
+	}
+	if update.ReviewInterval != nil && *update.ReviewInterval != "" {
+		reviewInterval = *update.ReviewInterval
+		if err := ValidateReviewInterval(reviewInterval); err != nil {
+			return err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var version interface{}
+	if update.Version != nil {
+		version = *update.Version
+	}
+	result, err := db.Exec(`UPDATE project SET
+		repeat_interval = CASE WHEN ? THEN ? ELSE repeat_interval END,
+		review_interval = CASE WHEN ? THEN ? ELSE review_interval END
+		WHERE id = ? AND (? IS NULL OR version = ?)`,
+		update.RepeatInterval != nil, nullIfEmpty(repeatInterval),
+		update.ReviewInterval != nil, nullIfEmpty(reviewInterval),
+		projectID, version, version)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		if update.Version != nil {
+			return versionConflict(db, "project", projectID, *update.Version)
+		}
+		return fmt.Errorf("project %d not found", projectID)
+	}
+	return nil
+}
+
+// MergeResult describes the actions that are, or with a dry run would be,
+// moved by MergeProjects
+type MergeResult struct {
+	Actions []Action
+	Tags    []string // The tags of the moved actions
+}
+
+// MergeProjects moves all actions of the source project, with their tags, to
+// the target project and archives the source project. With dryRun nothing is
+// changed and the result describes what would be moved.
+func MergeProjects(dbPath string, sourceID, targetID uint, dryRun bool) (*MergeResult, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("cannot merge a project into itself")
+	}
+
+	source, err := GetProjectByID(dbPath, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("project %d not found", sourceID)
+	}
+	target, err := GetProjectByID(dbPath, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("project %d not found", targetID)
+	}
+	if target.IsArchived() {
+		return nil, fmt.Errorf("cannot merge into archived project %s", target.Name)
+	}
+
+	actions, err := queryActions(dbPath, "WHERE a.project_id = ? ORDER BY a.id", sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions: %v", err)
+	}
+
+	result := &MergeResult{Actions: actions, Tags: []string{}}
+	for _, action := range actions {
+		for _, tag := range action.Tags {
+			if !slices.Contains(result.Tags, tag) {
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+	slices.Sort(result.Tags)
+
+	if dryRun {
+		return result, nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE action SET project_id = ? WHERE project_id = ?", targetID, sourceID); err != nil {
+		return nil, fmt.Errorf("failed to move actions: %v", err)
+	}
+	if _, err := tx.Exec("UPDATE project SET archived_at = "+sqlNow+" WHERE id = ?", sourceID); err != nil {
+		return nil, fmt.Errorf("failed to archive project: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	for i := range result.Actions {
+		result.Actions[i].ProjectID = sql.NullInt64{Int64: int64(targetID), Valid: true}
+		result.Actions[i].ProjectName = sql.NullString{String: target.Name, Valid: true}
+	}
+	return result, nil
+}
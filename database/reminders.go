@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// reminderOffsetPattern matches a reminder offset before the due time, such
+// as -15m, -2h, -1d or -1w
+var reminderOffsetPattern = regexp.MustCompile(`^-(\d+)([mhdw])$`)
+
+// reminderOffsets scans the JSON array of reminder offsets aggregated by
+// actionSelectQuery
+type reminderOffsets []string
+
+// Scan implements sql.Scanner
+func (r *reminderOffsets) Scan(value interface{}) error {
+	return (*tagNames)(r).Scan(value)
+}
+
+// ParseReminderOffset parses a reminder offset: a number of minutes, hours,
+// days or weeks before the due time such as -2h or -1d, or 0 for the due time
+// itself. It returns how long before the due time the reminder fires.
+func ParseReminderOffset(offset string) (time.Duration, error) {
+	offset = strings.ToLower(strings.TrimSpace(offset))
+	if offset == "0" {
+		return 0, nil
+	}
+
+	matches := reminderOffsetPattern.FindStringSubmatch(offset)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid reminder: %s. Expected an offset before the due date such as -15m, -2h, -1d or -1w, or 0", offset)
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid reminder: %s", offset)
+	}
+
+	unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[matches[2]]
+	return time.Duration(n) * unit, nil
+}
+
+// normalizeReminders validates reminder offsets and returns them without
+// duplicates, the earliest reminder first
+func normalizeReminders(offsets []string) ([]string, error) {
+	before := map[string]time.Duration{}
+	var normalized []string
+	for _, offset := range offsets {
+		offset = strings.ToLower(strings.TrimSpace(offset))
+		if offset == "" {
+			continue
+		}
+		duration, err := ParseReminderOffset(offset)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := before[offset]; exists {
+			continue
+		}
+		before[offset] = duration
+		normalized = append(normalized, offset)
+	}
+
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return before[normalized[i]] > before[normalized[j]]
+	})
+	return normalized, nil
+}
+
+// ValidateReminders checks a list of reminder offsets, see ParseReminderOffset
+func ValidateReminders(offsets []string) error {
+	_, err := normalizeReminders(offsets)
+	return err
+}
+
+// SetReminders replaces the reminders of an action. An empty list removes
+// them, so the action gets the default reminder on its due date again.
+func SetReminders(dbPath string, actionID uint, offsets []string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := setRemindersTx(tx, actionID, offsets); err != nil {
+		return err
+	}
+	// Touch the action, so its version moves on like for any other change
+	if _, err := tx.Exec("UPDATE action SET updated_at = "+sqlNow+" WHERE id = ?", actionID); err != nil {
+		return fmt.Errorf("failed to update action: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// setRemindersTx replaces the reminders of an action within a transaction
+func setRemindersTx(tx *sql.Tx, actionID uint, offsets []string) error {
+	normalized, err := normalizeReminders(offsets)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM reminder WHERE action_id = ?", actionID); err != nil {
+		return fmt.Errorf("failed to clear reminders: %v", err)
+	}
+	for _, offset := range normalized {
+		if _, err := tx.Exec("INSERT INTO reminder (action_id, due_offset) VALUES (?, ?)", actionID, offset); err != nil {
+			return fmt.Errorf("failed to add reminder: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetActionsWithReminders retrieves the open actions with reminders that are
+// due on or after a date (YYYY-MM-DD), ordered by due date
+func GetActionsWithReminders(dbPath, from string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND date(a.due_date) >= date(?) AND EXISTS (SELECT 1 FROM reminder r WHERE r.action_id = a.id) ORDER BY a.due_date, a.id", from)
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ValidateReviewInterval checks that a review interval is a positive number
+// of days, weeks or months, such as 10d, 1w or 2m
+func ValidateReviewInterval(interval string) error {
+	if len(interval) < 2 {
+		return fmt.Errorf("invalid review interval: %s. Expected a number of days, weeks or months such as 10d, 1w or 2m", interval)
+	}
+	amount, err := strconv.Atoi(interval[:len(interval)-1])
+	if err != nil || amount <= 0 {
+		return fmt.Errorf("invalid review interval: %s. Expected a number of days, weeks or months such as 10d, 1w or 2m", interval)
+	}
+	if _, err := ResolveDateOffset("start+"+interval, time.Now()); err != nil {
+		return fmt.Errorf("invalid review interval: %s. Expected a number of days, weeks or months such as 10d, 1w or 2m", interval)
+	}
+	return nil
+}
+
+// NextReview returns the date (YYYY-MM-DD) the project is due for its next
+// review. It is empty when the project has no review interval or was never
+// reviewed, in which case a review is due right away.
+func (p *Project) NextReview() string {
+	if !p.ReviewInterval.Valid || !p.LastReviewedAt.Valid {
+		return ""
+	}
+	reviewed, err := time.Parse(ChangeTimeFormat, p.LastReviewedAt.String)
+	if err != nil {
+		return ""
+	}
+	reviewed = reviewed.Local()
+	start := time.Date(reviewed.Year(), reviewed.Month(), reviewed.Day(), 0, 0, 0, 0, time.Local)
+	next, err := ResolveDateOffset("start+"+p.ReviewInterval.String, start)
+	if err != nil {
+		return ""
+	}
+	return next
+}
+
+// ReviewDue reports whether a project with a review interval is due for a
+// review on the given date (YYYY-MM-DD). Archived projects are never due.
+func (p *Project) ReviewDue(date string) bool {
+	if !p.ReviewInterval.Valid || p.IsArchived() {
+		return false
+	}
+	next := p.NextReview()
+	return next == "" || next <= date
+}
+
+// GetProjectsDueForReview retrieves the projects due for a review on the
+// given date (YYYY-MM-DD), the ones never reviewed first and then by the date
+// their review became due
+func GetProjectsDueForReview(dbPath, date string) ([]Project, error) {
+	projects, err := queryProjects(dbPath, "WHERE review_interval IS NOT NULL AND archived_at IS NULL ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %v", err)
+	}
+
+	var due []Project
+	for _, project := range projects {
+		if project.ReviewDue(date) {
+			due = append(due, project)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextReview() < due[j].NextReview()
+	})
+	return due, nil
+}
+
+// SetProjectReviewInterval sets how often a project is due for a review, or
+// stops reviewing it when the interval is empty
+func SetProjectReviewInterval(dbPath string, projectID uint, interval string) error {
+	if interval != "" {
+		if err := ValidateReviewInterval(interval); err != nil {
+			return err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("UPDATE project SET review_interval = ? WHERE id = ?", nullIfEmpty(interval), projectID)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("project %d not found", projectID)
+	}
+	return nil
+}
+
+// MarkProjectReviewed records that a project was reviewed now, which starts
+// its next review interval
+func MarkProjectReviewed(dbPath string, projectID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("UPDATE project SET last_reviewed_at = "+sqlNow+" WHERE id = ?", projectID)
+	if err != nil {
+		return fmt.Errorf("failed to mark project reviewed: %v", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("project %d not found", projectID)
+	}
+	return nil
+}
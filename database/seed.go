@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// demoStatuses are the statuses of the demo actions, created when they do
+// not exist yet, so that boards and workflows have more than two columns
+var demoStatuses = []string{"todo", "in-progress", "waiting", "done"}
+
+// demoAction is an action of the demo data. Dates are given in days from
+// today, so the demo always has overdue, current and upcoming actions.
+type demoAction struct {
+	name           string
+	note           string
+	project        string
+	due            *int
+	status         string // todo when empty
+	waitingOn      string
+	followUp       *int
+	repeatMode     string
+	repeatCount    uint
+	repeatInterval string
+	repeatPattern  string
+	repeatUntil    *int
+	tags           []string
+}
+
+// SeedResult counts the rows created by SeedDemoData
+type SeedResult struct {
+	Projects int
+	Actions  int
+	Tags     int
+}
+
+// SeedDemoData fills the database with sample projects and actions spread
+// over the todo, in-progress, waiting and done statuses, with various tags and repeat configurations. Unlike CreateAction it
+// also creates actions that are overdue.
+func SeedDemoData(dbPath string) (*SeedResult, error) {
+	days := func(n int) *int { return &n }
+
+	projects := []struct {
+		name string
+		due  *int
+	}{
+		{"Home Renovation", days(60)},
+		{"Website Launch", days(21)},
+		{"Learn Go", nil},
+	}
+
+	actions := []demoAction{
+		{name: "Get quotes from contractors", project: "Home Renovation", due: days(-3), status: "waiting", waitingOn: "Contractors", followUp: days(-1), tags: []string{"urgent", "calls"}},
+		{name: "Choose paint colors", project: "Home Renovation", due: days(7), status: "in-progress", note: "Living room and hallway"},
+		{name: "Order kitchen tiles", project: "Home Renovation", due: days(14), tags: []string{"errands"}},
+		{name: "Measure the living room", project: "Home Renovation", status: "done"},
+		{name: "Write launch announcement", project: "Website Launch", due: days(0), status: "in-progress", tags: []string{"writing"}},
+		{name: "Fix broken links", project: "Website Launch", due: days(-1), tags: []string{"urgent"}},
+		{name: "Set up analytics", project: "Website Launch", due: days(10), status: "waiting", waitingOn: "Marketing team", followUp: days(3)},
+		{name: "Buy domain name", project: "Website Launch", status: "done"},
+		{name: "Team sync", project: "Website Launch", due: days(1), repeatMode: RepeatModeUntil, repeatInterval: "week", repeatPattern: "mon,thu", repeatUntil: days(21), tags: []string{"meetings"}},
+		{name: "Finish the Go tour", project: "Learn Go", due: days(5), status: "in-progress", note: "https://go.dev/tour", tags: []string{"reading"}},
+		{name: "Read Effective Go", project: "Learn Go", tags: []string{"reading"}},
+		{name: "Practice exercise", project: "Learn Go", due: days(1), repeatMode: RepeatModeCount, repeatCount: 10, repeatInterval: "day"},
+		{name: "Water the plants", due: days(0), repeatMode: RepeatModeForever, repeatInterval: "day"},
+		{name: "Weekly review", due: days(4), repeatMode: RepeatModeForever, repeatInterval: "week", tags: []string{"review"}},
+		{name: "Pay rent", due: days(12), repeatMode: RepeatModeForever, repeatInterval: "month", tags: []string{"finance"}},
+		{name: "Call the dentist", tags: []string{"calls"}},
+		{name: "Renew passport", due: days(-10), status: "waiting", waitingOn: "Passport office", followUp: days(5), tags: []string{"errands", "urgent"}},
+		{name: "Return library books", status: "done", tags: []string{"errands"}},
+	}
+
+	today := time.Now()
+	date := func(n *int) interface{} {
+		if n == nil {
+			return nil
+		}
+		return today.AddDate(0, 0, *n).Format("2006-01-02")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var tagsBefore int
+	if err := db.QueryRow("SELECT COUNT(*) FROM tag").Scan(&tagsBefore); err != nil {
+		return nil, fmt.Errorf("failed to count tags: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	statusIDs := map[string]int64{}
+	for _, name := range demoStatuses {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO status (name) VALUES (?)", name); err != nil {
+			return nil, fmt.Errorf("failed to create status %s: %v", name, err)
+		}
+		var id int64
+		if err := tx.QueryRow("SELECT id FROM status WHERE name = ?", name).Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to read status %s: %v", name, err)
+		}
+		statusIDs[name] = id
+	}
+
+	result := &SeedResult{}
+	projectIDs := map[string]int64{}
+	for _, project := range projects {
+		res, err := tx.Exec("INSERT INTO project (name, due_date) VALUES (?, ?)", project.name, date(project.due))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project %s: %v", project.name, err)
+		}
+		if projectIDs[project.name], err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		result.Projects++
+	}
+
+	for _, action := range actions {
+		var projectID interface{}
+		if action.project != "" {
+			projectID = projectIDs[action.project]
+		}
+		status := action.status
+		if status == "" {
+			status = "todo"
+		}
+		repeatMode := action.repeatMode
+		if repeatMode == "" {
+			repeatMode = RepeatModeNone
+		}
+
+		res, err := tx.Exec(`
+			INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until, waiting_on, follow_up)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			action.name, action.note, projectID, date(action.due), statusIDs[status], repeatMode, action.repeatCount,
+			action.repeatInterval, action.repeatPattern, date(action.repeatUntil), nullIfEmpty(action.waitingOn), date(action.followUp))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create action %s: %v", action.name, err)
+		}
+		actionID, err := res.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		if err := setActionTagsTx(tx, uint(actionID), action.tags); err != nil {
+			return nil, fmt.Errorf("failed to tag action %s: %v", action.name, err)
+		}
+		result.Actions++
+	}
+
+	var tagsAfter int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM tag").Scan(&tagsAfter); err != nil {
+		return nil, fmt.Errorf("failed to count tags: %v", err)
+	}
+	result.Tags = tagsAfter - tagsBefore
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit demo data: %v", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SkipResult describes a skipped occurrence of a repeating action
+type SkipResult struct {
+	ActionID    uint   `json:"action_id"`
+	SkippedDate string `json:"skipped_date"` // YYYY-MM-DD
+	NextDueDate string `json:"next_due_date"`
+}
+
+// SkipOccurrence skips the open occurrence of a repeating action without
+// completing it: the action moves on to the next due date of its schedule,
+// and the skipped date is recorded in action_history. A skipped occurrence
+// counts towards the repetitions of a counted series. It fails when the
+// series has no later occurrence.
+func SkipOccurrence(dbPath string, actionID uint) (*SkipResult, error) {
+	action, err := GetActionByID(dbPath, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving action: %v", err)
+	}
+	if action == nil {
+		return nil, fmt.Errorf("action not found")
+	}
+	if action.StatusName == "done" {
+		return nil, fmt.Errorf("action %d is already done", actionID)
+	}
+	if !action.IsRepeating() {
+		return nil, fmt.Errorf("action %d does not repeat", actionID)
+	}
+	if !action.DueDate.Valid || action.DueDate.String == "" {
+		return nil, fmt.Errorf("action %d has no due date to skip", actionID)
+	}
+
+	nextDueDate, err := calculateNextDueDate(action.DueDate.String, action.RepeatInterval.String, action.RepeatPattern.String)
+	if err != nil {
+		return nil, err
+	}
+
+	dueDate := nextDueDate.Format("2006-01-02")
+	update := ActionUpdate{DueDate: &dueDate}
+	switch action.EffectiveRepeatMode() {
+	case RepeatModeCount:
+		// The skipped occurrence uses up one of the remaining repetitions
+		repeatCount := action.RepeatCount - 1
+		update.RepeatCount = &repeatCount
+		if repeatCount == 0 {
+			repeatMode := RepeatModeNone
+			update.RepeatMode = &repeatMode
+		}
+	case RepeatModeUntil:
+		untilDate, err := ParseStoredDate(action.RepeatUntil.String)
+		if err == nil && nextDueDate.After(untilDate) {
+			return nil, fmt.Errorf("action %d has no occurrence after %s", actionID, StoredDate(action.DueDate.String))
+		}
+	}
+	if startDate := action.nextStartDate(nextDueDate); startDate != "" {
+		update.StartDate = &startDate
+	}
+
+	if err := UpdateAction(dbPath, actionID, update); err != nil {
+		return nil, err
+	}
+
+	skipped := StoredDate(action.DueDate.String)
+	if err := recordSkip(dbPath, action, skipped); err != nil {
+		return nil, err
+	}
+
+	return &SkipResult{ActionID: actionID, SkippedDate: skipped, NextDueDate: dueDate}, nil
+}
+
+// recordSkip records the skipped due date of an action in action_history
+func recordSkip(dbPath string, action *Action, skippedDate string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`
+		INSERT INTO action_history (action_id, project_id, status_id, changed_at, skipped_date)
+		VALUES (?, ?, ?, `+sqlNow+`, ?)
+	`, action.ID, action.ProjectID, action.StatusID, skippedDate)
+	if err != nil {
+		return fmt.Errorf("failed to record skipped occurrence: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// ValidateStartDate checks that a start date (YYYY-MM-DD) is valid and does
+// not lie after the due date. Start dates may lie in the past, the action is
+// simply active already. An empty start date is valid.
+func ValidateStartDate(startDate, dueDate string) error {
+	if startDate == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", startDate); err != nil {
+		return fmt.Errorf("invalid start date format: %s. Expected format: YYYY-MM-DD", startDate)
+	}
+	if dueDate != "" && startDate > dueDate {
+		return fmt.Errorf("start date %s is after the due date %s", startDate, dueDate)
+	}
+	return nil
+}
+
+// IsDeferred reports whether an action has a start date after the given date
+// (YYYY-MM-DD), so it is not active yet
+func (a *Action) IsDeferred(date string) bool {
+	return a.StartDate.Valid && StoredDate(a.StartDate.String) > date
+}
+
+// GetActionsStartingOn retrieves the open actions whose start date is the
+// given date (YYYY-MM-DD)
+func GetActionsStartingOn(dbPath, date string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND date(a.start_date) = date(?) ORDER BY a.id", date)
+}
+
+// nextStartDate returns the start date of the occurrence after this one, due
+// on nextDueDate, keeping the same number of days between the start and due
+// dates. It is empty when the action has no start or due date.
+func (a *Action) nextStartDate(nextDueDate time.Time) string {
+	if !a.StartDate.Valid {
+		return ""
+	}
+	startDate, err := ParseStoredDate(a.StartDate.String)
+	if err != nil {
+		return ""
+	}
+	dueDate, err := ParseStoredDate(a.DueDate.String)
+	if err != nil {
+		return ""
+	}
+	leadDays := int(dueDate.Sub(startDate).Hours() / 24)
+	return nextDueDate.AddDate(0, 0, -leadDays).Format("2006-01-02")
+}
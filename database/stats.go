@@ -0,0 +1,151 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/joelgrimberg/projector/wire"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Stats summarizes the contents of the whole database
+type Stats = wire.Stats
+
+// GetStats counts the users, projects, actions and tags in the database
+func GetStats(dbPath string) (*Stats, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var stats Stats
+	counts := []struct {
+		query string
+		dest  *int
+	}{
+		{"SELECT COUNT(*) FROM user", &stats.Users},
+		{"SELECT COUNT(*) FROM project", &stats.Projects},
+		{"SELECT COUNT(*) FROM action", &stats.Actions},
+		{"SELECT COUNT(*) FROM action WHERE status_id != 2", &stats.OpenActions},
+		{"SELECT COUNT(*) FROM action WHERE status_id = 2", &stats.DoneActions},
+		{"SELECT COUNT(*) FROM action WHERE status_id != 2 AND date(due_date) < date('now', 'localtime')", &stats.OverdueActions},
+		{"SELECT COUNT(*) FROM tag", &stats.Tags},
+	}
+
+	for _, count := range counts {
+		if err := db.QueryRow(count.query).Scan(count.dest); err != nil {
+			return nil, fmt.Errorf("failed to count: %v", err)
+		}
+	}
+
+	if info, err := os.Stat(dbPath); err == nil {
+		stats.SizeBytes = info.Size()
+	}
+
+	return &stats, nil
+}
+
+// ProjectStats counts the actions of a project. ProjectID 0 and an empty
+// Project stand for the actions without a project.
+type ProjectStats struct {
+	ProjectID uint   `json:"project_id"`
+	Project   string `json:"project"`
+	Actions   int    `json:"actions"`
+	Open      int    `json:"open"`
+	Done      int    `json:"done"`
+	Overdue   int    `json:"overdue"`
+	Completed int    `json:"completed"` // Completed on or after the since date
+}
+
+// CompletionCount is the number of actions completed on a day
+type CompletionCount struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Completed int    `json:"completed"`
+}
+
+// completionsQuery selects the project and local date of every completion in
+// action_history, the moments an action changed to done. An empty ?1 includes
+// all completions.
+const completionsQuery = `
+	SELECT h.project_id, date(h.changed_at, 'localtime') AS completed_on
+	FROM (
+		SELECT project_id, status_id, changed_at,
+			LAG(status_id) OVER (PARTITION BY action_id ORDER BY changed_at, id) AS previous_status_id
+		FROM action_history
+	) h
+	WHERE h.status_id = 2 AND (h.previous_status_id IS NULL OR h.previous_status_id != 2)
+		AND (?1 = '' OR date(h.changed_at, 'localtime') >= date(?1))
+`
+
+// GetProjectStats counts the actions of every project, ordered by project
+// name with the actions without a project last. Completed counts the
+// completions since a date (YYYY-MM-DD), or all completions when since is empty.
+func GetProjectStats(dbPath, since string) ([]ProjectStats, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		WITH completions AS (`+completionsQuery+`)
+		SELECT COALESCE(p.id, 0), COALESCE(p.name, ''),
+			COUNT(a.id),
+			COUNT(CASE WHEN a.status_id != 2 THEN 1 END),
+			COUNT(CASE WHEN a.status_id = 2 THEN 1 END),
+			COUNT(CASE WHEN a.status_id != 2 AND date(a.due_date) < date('now', 'localtime') THEN 1 END),
+			(SELECT COUNT(*) FROM completions c WHERE c.project_id IS p.id)
+		FROM (SELECT id, name FROM project UNION ALL SELECT NULL, NULL) p
+		LEFT JOIN action a ON a.project_id IS p.id
+		GROUP BY p.id
+		ORDER BY p.id IS NULL, p.name COLLATE NOCASE, p.id
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count project actions: %v", err)
+	}
+	defer rows.Close()
+
+	var stats []ProjectStats
+	for rows.Next() {
+		var project ProjectStats
+		if err := rows.Scan(&project.ProjectID, &project.Project, &project.Actions, &project.Open, &project.Done, &project.Overdue, &project.Completed); err != nil {
+			return nil, err
+		}
+		stats = append(stats, project)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetCompletionCounts counts the actions completed per day since a date
+// (YYYY-MM-DD), or over all time when since is empty. Days without
+// completions are left out.
+func GetCompletionCounts(dbPath, since string) ([]CompletionCount, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		WITH completions AS (`+completionsQuery+`)
+		SELECT completed_on, COUNT(*) FROM completions GROUP BY completed_on ORDER BY completed_on
+	`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count completions: %v", err)
+	}
+	defer rows.Close()
+
+	var counts []CompletionCount
+	for rows.Next() {
+		var count CompletionCount
+		if err := rows.Scan(&count.Date, &count.Completed); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
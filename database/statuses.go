@@ -0,0 +1,137 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// StatusColors are the color names a status can have. They are understood
+// by terminals and are valid CSS colors, so the CLI and the web interface
+// show a status the same way.
+var StatusColors = []string{"red", "green", "yellow", "blue", "magenta", "cyan", "gray", "white"}
+
+// maxStatusIcon is the longest icon in characters, enough for an emoji with
+// a variation selector
+const maxStatusIcon = 4
+
+// hexColor matches a #rrggbb color
+var hexColor = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Status represents a status in the database, with the color and icon it is
+// shown with
+type Status struct {
+	ID    uint
+	Name  string
+	Color sql.NullString
+	Icon  sql.NullString
+}
+
+// ValidateStatusColor checks that a color is one of StatusColors or a #rrggbb
+// color and returns it in lowercase. An empty color is valid and removes the
+// color of a status.
+func ValidateStatusColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" || slices.Contains(StatusColors, color) || hexColor.MatchString(color) {
+		return color, nil
+	}
+	return "", fmt.Errorf("invalid color: %s (expected %s or #rrggbb)", color, strings.Join(StatusColors, ", "))
+}
+
+// ValidateStatusIcon checks that an icon is short enough to fit before a
+// status name. An empty icon is valid and removes the icon of a status.
+func ValidateStatusIcon(icon string) (string, error) {
+	icon = strings.TrimSpace(icon)
+	if utf8.RuneCountInString(icon) > maxStatusIcon {
+		return "", fmt.Errorf("icon is too long (max %d characters)", maxStatusIcon)
+	}
+	return icon, nil
+}
+
+// GetAllStatuses retrieves all statuses ordered by ID
+func GetAllStatuses(dbPath string) ([]Status, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name, color, icon FROM status ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statuses: %v", err)
+	}
+	defer rows.Close()
+
+	var statuses []Status
+	for rows.Next() {
+		var status Status
+		if err := rows.Scan(&status.ID, &status.Name, &status.Color, &status.Icon); err != nil {
+			return nil, fmt.Errorf("failed to scan status: %v", err)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// GetStatus retrieves a status by ID or name
+func GetStatus(dbPath, idOrName string) (*Status, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var status Status
+	err = db.QueryRow("SELECT id, name, color, icon FROM status WHERE CAST(id AS TEXT) = ? OR name = ?", idOrName, idOrName).
+		Scan(&status.ID, &status.Name, &status.Color, &status.Icon)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status %s: %v", idOrName, err)
+	}
+	return &status, nil
+}
+
+// UpdateStatusStyle sets the color and icon of a status. A nil value keeps
+// the current one, an empty value removes it.
+func UpdateStatusStyle(dbPath string, statusID uint, color, icon *string) error {
+	if color != nil {
+		value, err := ValidateStatusColor(*color)
+		if err != nil {
+			return err
+		}
+		color = &value
+	}
+	if icon != nil {
+		value, err := ValidateStatusIcon(*icon)
+		if err != nil {
+			return err
+		}
+		icon = &value
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// A nil pointer is passed as NULL and keeps the column, an empty string
+	// sets it to NULL
+	_, err = db.Exec(`UPDATE status SET
+		color = CASE WHEN ? IS NULL THEN color ELSE NULLIF(?, '') END,
+		icon = CASE WHEN ? IS NULL THEN icon ELSE NULLIF(?, '') END
+		WHERE id = ?`, color, color, icon, icon, statusID)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %v", err)
+	}
+
+	return nil
+}
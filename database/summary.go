@@ -0,0 +1,47 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Summary counts the open actions that need attention on a date, for status
+// lines and prompts. Actions deferred to a later start date are left out.
+type Summary struct {
+	Open      int `json:"open"`
+	Due       int `json:"due"`     // Due on the date
+	Overdue   int `json:"overdue"` // Due before the date
+	Today     int `json:"today"`   // Flagged, due or overdue, like `projector today`
+	Flagged   int `json:"flagged"`
+	FollowUps int `json:"follow_ups"` // Waiting actions whose follow-up date has arrived
+}
+
+// GetSummary counts the open actions that need attention on the given date
+// (YYYY-MM-DD) in a single query
+func GetSummary(dbPath, date string) (*Summary, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var summary Summary
+	err = db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(date(due_date) = date(?1)), 0),
+			COALESCE(SUM(date(due_date) < date(?1)), 0),
+			COALESCE(SUM(flagged = 1 OR date(due_date) <= date(?1)), 0),
+			COALESCE(SUM(flagged = 1), 0),
+			COALESCE(SUM(waiting_on IS NOT NULL AND date(follow_up) <= date(?1)), 0)
+		FROM action
+		WHERE status_id != 2 AND (start_date IS NULL OR date(start_date) <= date(?1))`, date).Scan(
+		&summary.Open, &summary.Due, &summary.Overdue, &summary.Today, &summary.Flagged, &summary.FollowUps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize actions: %v", err)
+	}
+
+	return &summary, nil
+}
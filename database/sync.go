@@ -0,0 +1,111 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SyncState links a local action to its copy on a remote sync source
+type SyncState struct {
+	Source    string
+	ActionID  uint
+	RemoteID  string
+	ETag      string
+	LocalHash string
+	SyncedAt  sql.NullString
+}
+
+// GetSyncStates retrieves all sync states for a sync source
+func GetSyncStates(dbPath, source string) ([]SyncState, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT source, action_id, remote_id, COALESCE(etag, ''), COALESCE(local_hash, ''), synced_at
+		FROM sync_state
+		WHERE source = ?
+		ORDER BY action_id
+	`
+
+	rows, err := db.Query(query, source)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []SyncState
+	for rows.Next() {
+		var state SyncState
+		err := rows.Scan(&state.Source, &state.ActionID, &state.RemoteID, &state.ETag, &state.LocalHash, &state.SyncedAt)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// SaveSyncState creates or replaces the sync state of an action
+func SaveSyncState(dbPath string, state SyncState) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	query := `
+		INSERT OR REPLACE INTO sync_state (source, action_id, remote_id, etag, local_hash, synced_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`
+
+	_, err = db.Exec(query, state.Source, state.ActionID, state.RemoteID, state.ETag, state.LocalHash)
+	if err != nil {
+		return fmt.Errorf("failed to save sync state: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteSyncState removes the sync state of an action
+func DeleteSyncState(dbPath, source string, actionID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM sync_state WHERE source = ? AND action_id = ?", source, actionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sync state: %v", err)
+	}
+
+	return nil
+}
+
+// ActionHash returns a hash of the synchronized fields of an action, used to
+// detect local changes since the last sync
+func ActionHash(action *Action) string {
+	fields := []string{
+		action.Name,
+		action.Note.String,
+		StoredDate(action.DueDate.String),
+		action.StatusName,
+		action.EffectiveRepeatMode(),
+		fmt.Sprint(action.RepeatCount),
+		action.RepeatInterval.String,
+		action.RepeatPattern.String,
+		StoredDate(action.RepeatUntil.String),
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,430 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Tag represents a tag in the database
+type Tag struct {
+	ID   uint
+	Name string
+}
+
+// tagNames scans the JSON array of tag names aggregated by actionSelectQuery
+type tagNames []string
+
+// Scan implements sql.Scanner
+func (t *tagNames) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unexpected tag names type %T", value)
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// GetAllTags retrieves all tags ordered by name
+func GetAllTags(dbPath string) ([]Tag, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name FROM tag ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// GetOrCreateTag returns the ID of the tag with the given name, creating it if needed
+func GetOrCreateTag(dbPath, name string) (uint, error) {
+	if name == "" {
+		return 0, fmt.Errorf("tag name is required")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT OR IGNORE INTO tag (name) VALUES (?)", name)
+	if err != nil {
+		return 0, err
+	}
+
+	var tagID uint
+	err = db.QueryRow("SELECT id FROM tag WHERE name = ?", name).Scan(&tagID)
+	if err != nil {
+		return 0, err
+	}
+
+	return tagID, nil
+}
+
+// GetActionTags retrieves the tag names of an action
+func GetActionTags(dbPath string, actionID uint) ([]string, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT t.name
+		FROM tag t
+		JOIN action_tag at ON at.tag_id = t.id
+		WHERE at.action_id = ?
+		ORDER BY t.name
+	`
+
+	rows, err := db.Query(query, actionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+
+	return tags, nil
+}
+
+// GetAllActionTags retrieves the tag names of all actions, keyed by action ID
+func GetAllActionTags(dbPath string) (map[uint][]string, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT at.action_id, t.name
+		FROM tag t
+		JOIN action_tag at ON at.tag_id = t.id
+		ORDER BY at.action_id, t.name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[uint][]string)
+	for rows.Next() {
+		var actionID uint
+		var name string
+		if err := rows.Scan(&actionID, &name); err != nil {
+			return nil, err
+		}
+		tags[actionID] = append(tags[actionID], name)
+	}
+
+	return tags, nil
+}
+
+// SetActionTags replaces the tags of an action, creating missing tags
+func SetActionTags(dbPath string, actionID uint, names []string) error {
+	var tagIDs []uint
+	for _, name := range names {
+		tagID, err := GetOrCreateTag(dbPath, name)
+		if err != nil {
+			return fmt.Errorf("failed to create tag %s: %v", name, err)
+		}
+		tagIDs = append(tagIDs, tagID)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM action_tag WHERE action_id = ?", actionID)
+	if err != nil {
+		return fmt.Errorf("failed to clear tags: %v", err)
+	}
+
+	for _, tagID := range tagIDs {
+		_, err = tx.Exec("INSERT OR IGNORE INTO action_tag (action_id, tag_id) VALUES (?, ?)", actionID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to add tag: %v", err)
+		}
+	}
+
+	if _, err := tx.Exec(bumpActionVersion, actionID); err != nil {
+		return fmt.Errorf("failed to update action version: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// GetTagByName retrieves a tag by name
+func GetTagByName(dbPath, name string) (*Tag, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var tag Tag
+	err = db.QueryRow("SELECT id, name FROM tag WHERE name = ?", name).Scan(&tag.ID, &tag.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag %s: %v", name, err)
+	}
+	return &tag, nil
+}
+
+// RenameTag renames a tag on all its actions. The new name must not be in
+// use, merge the tags with MergeTags instead. It returns the number of
+// actions with the tag.
+func RenameTag(dbPath, oldName, newName string) (int, error) {
+	if newName == "" {
+		return 0, fmt.Errorf("tag name is required")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var tagID uint
+	if err := tx.QueryRow("SELECT id FROM tag WHERE name = ?", oldName).Scan(&tagID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("tag %s not found", oldName)
+		}
+		return 0, fmt.Errorf("failed to read tag %s: %v", oldName, err)
+	}
+
+	var taken int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM tag WHERE name = ?", newName).Scan(&taken); err != nil {
+		return 0, fmt.Errorf("failed to read tag %s: %v", newName, err)
+	}
+	if taken > 0 {
+		return 0, fmt.Errorf("tag %s already exists, merge the tags instead", newName)
+	}
+
+	if _, err := tx.Exec("UPDATE tag SET name = ? WHERE id = ?", newName, tagID); err != nil {
+		return 0, fmt.Errorf("failed to rename tag: %v", err)
+	}
+
+	// Actions carry their tag names in remote sync, so the renamed tag
+	// changes them
+	result, err := tx.Exec("UPDATE action SET updated_at = "+sqlNow+", changed_at = "+sqlNow+", version = version + 1 WHERE id IN (SELECT action_id FROM action_tag WHERE tag_id = ?)", tagID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update tagged actions: %v", err)
+	}
+	actions, _ := result.RowsAffected()
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return int(actions), nil
+}
+
+// MergeTags moves a tag to the actions of another one and deletes it. The
+// target tag is created when it does not exist yet. It returns the number of
+// actions that had the merged tag.
+func MergeTags(dbPath, source, target string) (int, error) {
+	if target == "" {
+		return 0, fmt.Errorf("tag name is required")
+	}
+	if source == target {
+		return 0, fmt.Errorf("cannot merge tag %s into itself", source)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sourceID uint
+	if err := tx.QueryRow("SELECT id FROM tag WHERE name = ?", source).Scan(&sourceID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("tag %s not found", source)
+		}
+		return 0, fmt.Errorf("failed to read tag %s: %v", source, err)
+	}
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO tag (name) VALUES (?)", target); err != nil {
+		return 0, fmt.Errorf("failed to create tag %s: %v", target, err)
+	}
+
+	_, err = tx.Exec("INSERT OR IGNORE INTO action_tag (action_id, tag_id) SELECT action_id, (SELECT id FROM tag WHERE name = ?) FROM action_tag WHERE tag_id = ?", target, sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to move tag %s: %v", source, err)
+	}
+
+	_, err = tx.Exec("UPDATE action SET version = version + 1 WHERE id IN (SELECT action_id FROM action_tag WHERE tag_id = ?)", sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update action versions: %v", err)
+	}
+
+	result, err := tx.Exec("DELETE FROM action_tag WHERE tag_id = ?", sourceID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove tag %s: %v", source, err)
+	}
+	actions, _ := result.RowsAffected()
+
+	if _, err := tx.Exec("DELETE FROM tag WHERE id = ?", sourceID); err != nil {
+		return 0, fmt.Errorf("failed to delete tag %s: %v", source, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return int(actions), nil
+}
+
+// TagUsage is the number of open and done actions carrying a tag
+type TagUsage struct {
+	Tag
+	Open int
+	Done int
+}
+
+// orphanTagCondition matches the tags no action carries. The tags of
+// actions in the trash are kept, so restoring the actions restores them.
+const orphanTagCondition = `id NOT IN (SELECT tag_id FROM action_tag)
+	AND id NOT IN (SELECT value FROM trash, json_each(trash.data, '$.tags'))`
+
+// GetTagUsage retrieves all tags ordered by name with the number of open and
+// done actions carrying them
+func GetTagUsage(dbPath string) ([]TagUsage, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	query := `
+		SELECT t.id, t.name,
+			COUNT(a.id) - COUNT(CASE WHEN a.status_id = 2 THEN 1 END),
+			COUNT(CASE WHEN a.status_id = 2 THEN 1 END)
+		FROM tag t
+		LEFT JOIN action_tag at ON at.tag_id = t.id
+		LEFT JOIN action a ON a.id = at.action_id
+		GROUP BY t.id
+		ORDER BY t.name
+	`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %v", err)
+	}
+	defer rows.Close()
+
+	var usage []TagUsage
+	for rows.Next() {
+		var tag TagUsage
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Open, &tag.Done); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		usage = append(usage, tag)
+	}
+
+	return usage, nil
+}
+
+// GetOrphanTags retrieves the tags no action carries, ordered by name
+func GetOrphanTags(dbPath string) ([]Tag, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name FROM tag WHERE " + orphanTagCondition + " ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %v", err)
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// DeleteOrphanTags deletes the tags no action carries and returns how many
+// were deleted
+func DeleteOrphanTags(dbPath string) (int, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("DELETE FROM tag WHERE " + orphanTagCondition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tags: %v", err)
+	}
+	deleted, _ := result.RowsAffected()
+	return int(deleted), nil
+}
@@ -0,0 +1,410 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Template is a project saved for reuse. Its dates are offsets from the start
+// date given when a project is created from it, such as start+3d.
+type Template struct {
+	ID         uint
+	Name       string
+	ProjectDue string // Offset of the project deadline, empty without one
+	CreatedAt  string
+	Actions    []TemplateAction
+}
+
+// TemplateAction is an action of a template
+type TemplateAction struct {
+	Name           string
+	Note           string
+	Due            string // Offset of the due date, empty without one
+	Tags           []string
+	RepeatMode     string
+	RepeatCount    uint
+	RepeatInterval string
+	RepeatPattern  string
+	RepeatUntil    string // Offset of the last date for the until mode
+}
+
+// FormatDateOffset describes a date as an offset in days from the start date:
+// start, start+3d or start-2d
+func FormatDateOffset(start, date time.Time) string {
+	days := int(date.Sub(start).Hours() / 24)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("start+%dd", days)
+	case days < 0:
+		return fmt.Sprintf("start%dd", days)
+	}
+	return "start"
+}
+
+// ResolveDateOffset returns the date (YYYY-MM-DD) an offset such as start+3d,
+// start+2w or start-1m refers to for the given start date. An empty offset
+// resolves to no date.
+func ResolveDateOffset(offset string, start time.Time) (string, error) {
+	if offset == "" {
+		return "", nil
+	}
+
+	rest, ok := strings.CutPrefix(offset, "start")
+	if !ok {
+		return "", fmt.Errorf("invalid date offset: %s. Expected start, start+3d, start+2w or start+1m", offset)
+	}
+	if rest == "" {
+		return start.Format("2006-01-02"), nil
+	}
+
+	if len(rest) < 3 || (rest[0] != '+' && rest[0] != '-') {
+		return "", fmt.Errorf("invalid date offset: %s. Expected start, start+3d, start+2w or start+1m", offset)
+	}
+	amount, err := strconv.Atoi(rest[:len(rest)-1])
+	if err != nil {
+		return "", fmt.Errorf("invalid date offset: %s. Expected start, start+3d, start+2w or start+1m", offset)
+	}
+
+	switch rest[len(rest)-1] {
+	case 'd':
+		return start.AddDate(0, 0, amount).Format("2006-01-02"), nil
+	case 'w':
+		return start.AddDate(0, 0, amount*7).Format("2006-01-02"), nil
+	case 'm':
+		return start.AddDate(0, amount, 0).Format("2006-01-02"), nil
+	}
+	return "", fmt.Errorf("invalid date offset: %s. Expected start, start+3d, start+2w or start+1m", offset)
+}
+
+// NewProjectTemplate builds a template from a project and its actions. Later
+// occurrences of repeating actions are left out, the series is represented by
+// its first occurrence. Dates are stored relative to start (YYYY-MM-DD), which
+// defaults to the earliest date of the project.
+func NewProjectTemplate(dbPath string, projectID uint, name, start string) (*Template, error) {
+	project, err := GetProjectByID(dbPath, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %v", err)
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %d not found", projectID)
+	}
+
+	actions, err := queryActions(dbPath, "WHERE a.project_id = ? AND a.parent_action_id IS NULL ORDER BY a.id", projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actions: %v", err)
+	}
+
+	if start == "" {
+		start = earliestDate(project, actions)
+	}
+	var startDate time.Time
+	if start != "" {
+		startDate, err = time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start date: %s. Expected format: YYYY-MM-DD", start)
+		}
+	}
+
+	// offset describes a stored date relative to the start date
+	offset := func(date sql.NullString) string {
+		parsed, err := ParseStoredDate(date.String)
+		if !date.Valid || err != nil {
+			return ""
+		}
+		return FormatDateOffset(startDate, parsed)
+	}
+
+	template := &Template{Name: name, ProjectDue: offset(project.DueDate)}
+	for _, action := range actions {
+		repeatMode := action.EffectiveRepeatMode()
+		if repeatMode == RepeatModeNone {
+			repeatMode = ""
+		}
+
+		template.Actions = append(template.Actions, TemplateAction{
+			Name:           action.Name,
+			Note:           action.Note.String,
+			Due:            offset(action.DueDate),
+			Tags:           action.Tags,
+			RepeatMode:     repeatMode,
+			RepeatCount:    action.RepeatCount,
+			RepeatInterval: action.RepeatInterval.String,
+			RepeatPattern:  action.RepeatPattern.String,
+			RepeatUntil:    offset(action.RepeatUntil),
+		})
+	}
+
+	return template, nil
+}
+
+// earliestDate returns the earliest due date of a project and its actions, or
+// an empty string when none of them has a due date
+func earliestDate(project *Project, actions []Action) string {
+	var earliest string
+	consider := func(date sql.NullString) {
+		if date.Valid && date.String != "" && (earliest == "" || StoredDate(date.String) < earliest) {
+			earliest = StoredDate(date.String)
+		}
+	}
+
+	consider(project.DueDate)
+	for _, action := range actions {
+		consider(action.DueDate)
+	}
+	return earliest
+}
+
+// SaveTemplate stores a new template with its actions
+func SaveTemplate(dbPath string, template *Template) (uint, error) {
+	if template.Name == "" {
+		return 0, fmt.Errorf("template name is required")
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM template WHERE name = ?", template.Name).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to check template: %v", err)
+	}
+	if exists > 0 {
+		return 0, fmt.Errorf("template %s already exists", template.Name)
+	}
+
+	result, err := tx.Exec("INSERT INTO template (name, project_due) VALUES (?, ?)", template.Name, nullIfEmpty(template.ProjectDue))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create template: %v", err)
+	}
+	templateID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for position, action := range template.Actions {
+		tags, err := json.Marshal(action.Tags)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO template_action (template_id, position, name, note, due, tags, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			templateID, position, action.Name, nullIfEmpty(action.Note), nullIfEmpty(action.Due), string(tags),
+			nullIfEmpty(action.RepeatMode), action.RepeatCount, nullIfEmpty(action.RepeatInterval), nullIfEmpty(action.RepeatPattern), nullIfEmpty(action.RepeatUntil))
+		if err != nil {
+			return 0, fmt.Errorf("failed to add action %s to template: %v", action.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return uint(templateID), nil
+}
+
+// GetAllTemplates retrieves all templates with their actions ordered by name
+func GetAllTemplates(dbPath string) ([]Template, error) {
+	return queryTemplates(dbPath, "ORDER BY name")
+}
+
+// GetTemplateByName retrieves a template with its actions by name
+func GetTemplateByName(dbPath, name string) (*Template, error) {
+	templates, err := queryTemplates(dbPath, "WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, nil // Template not found
+	}
+	return &templates[0], nil
+}
+
+// queryTemplates retrieves the templates matching the given clauses
+func queryTemplates(dbPath, clauses string, args ...interface{}) ([]Template, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id, name, project_due, created_at FROM template "+clauses, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []Template
+	for rows.Next() {
+		var template Template
+		var projectDue sql.NullString
+		if err := rows.Scan(&template.ID, &template.Name, &projectDue, &template.CreatedAt); err != nil {
+			return nil, err
+		}
+		template.ProjectDue = projectDue.String
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range templates {
+		templates[i].Actions, err = getTemplateActions(db, templates[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
+
+// getTemplateActions retrieves the actions of a template in their saved order
+func getTemplateActions(db *sql.DB, templateID uint) ([]TemplateAction, error) {
+	rows, err := db.Query(`
+		SELECT name, note, due, tags, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until
+		FROM template_action
+		WHERE template_id = ?
+		ORDER BY position
+	`, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []TemplateAction
+	for rows.Next() {
+		var action TemplateAction
+		var note, due, repeatMode, repeatInterval, repeatPattern, repeatUntil sql.NullString
+		if err := rows.Scan(&action.Name, &note, &due, (*tagNames)(&action.Tags), &repeatMode, &action.RepeatCount, &repeatInterval, &repeatPattern, &repeatUntil); err != nil {
+			return nil, err
+		}
+		action.Note = note.String
+		action.Due = due.String
+		action.RepeatMode = repeatMode.String
+		action.RepeatInterval = repeatInterval.String
+		action.RepeatPattern = repeatPattern.String
+		action.RepeatUntil = repeatUntil.String
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+// DeleteTemplate deletes a template and its actions
+func DeleteTemplate(dbPath string, templateID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM template_action WHERE template_id = ?", templateID); err != nil {
+		return fmt.Errorf("failed to delete template actions: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM template WHERE id = ?", templateID); err != nil {
+		return fmt.Errorf("failed to delete template: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return nil
+}
+
+// CreateProjectFromTemplate creates a project with the actions of a template
+// in a single transaction. The dates of the template are resolved against
+// start (YYYY-MM-DD). It returns the ID of the new project.
+func CreateProjectFromTemplate(dbPath string, template *Template, name, start string) (uint, error) {
+	if err := ValidateProjectInput(name, ""); err != nil {
+		return 0, err
+	}
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return 0, fmt.Errorf("invalid start date: %s. Expected format: YYYY-MM-DD", start)
+	}
+
+	projectDue, err := ResolveDateOffset(template.ProjectDue, startDate)
+	if err != nil {
+		return 0, err
+	}
+	statusID, err := DefaultStatusID(dbPath)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO project (name, due_date) VALUES (?, ?)", name, nullIfEmpty(projectDue))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create project: %v", err)
+	}
+	projectID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, action := range template.Actions {
+		due, err := ResolveDateOffset(action.Due, startDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", action.Name, err)
+		}
+		until, err := ResolveDateOffset(action.RepeatUntil, startDate)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %v", action.Name, err)
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO action (name, note, project_id, due_date, status_id, repeat_mode, repeat_count, repeat_interval, repeat_pattern, repeat_until)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			action.Name, action.Note, projectID, nullIfEmpty(due), statusID, nullIfEmpty(action.RepeatMode), action.RepeatCount,
+			nullIfEmpty(action.RepeatInterval), nullIfEmpty(action.RepeatPattern), nullIfEmpty(until))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create action %s: %v", action.Name, err)
+		}
+		actionID, err := result.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		if err := setActionTagsTx(tx, uint(actionID), action.Tags); err != nil {
+			return 0, fmt.Errorf("failed to tag action %s: %v", action.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return uint(projectID), nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Special database paths for throwaway databases, accepted by SetDatabasePath
+// and PROJECTOR_DB_PATH
+const (
+	MemoryPath = ":memory:" // An in-memory database that is gone when the process exits
+	TempPath   = ":temp:"   // A database file in a temporary directory, removed by CloseTemporary
+)
+
+// Tables lists all tables of the schema, in the order they are created
+var Tables = []string{"project", "status", "action", "tag", "action_tag", "sync_state", "notification_log", "tombstone", "sync_peer", "user", "user_token", "project_member", "maintenance_log", "change_counter", "template", "template_action", "saved_filter", "action_history", "trash", "attachment", "idempotency_key", "change_event", "reminder", "action_archive", "sync_conflict"}
+
+var (
+	pathOverride string
+
+	temporaryOnce sync.Once
+	temporaryPath string
+	temporaryErr  error
+	temporaryDir  string
+	memoryConn    *sql.Conn
+)
+
+// SetDatabasePath overrides the database path for the rest of the process,
+// taking precedence over PROJECTOR_DB_PATH
+func SetDatabasePath(path string) {
+	pathOverride = path
+}
+
+// IsMemoryPath reports whether a database path refers to an in-memory database
+func IsMemoryPath(dbPath string) bool {
+	return strings.HasPrefix(dbPath, "file:") && strings.Contains(dbPath, "mode=memory")
+}
+
+// temporaryDatabasePath creates the throwaway database the first time it is
+// needed, and returns the path to pass to the other functions
+func temporaryDatabasePath(kind string) string {
+	temporaryOnce.Do(func() {
+		temporaryPath, temporaryErr = createTemporary(kind)
+	})
+	if temporaryErr != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to create %s database: %v\n", kind, temporaryErr)
+		os.Exit(1)
+	}
+	return temporaryPath
+}
+
+// createTemporary creates and initializes an in-memory or temporary file database
+func createTemporary(kind string) (string, error) {
+	if kind == MemoryPath {
+		// Every connection to a shared-cache memory database sees the same
+		// data, as long as one connection stays open
+		dbPath := fmt.Sprintf("file:projector-%d?mode=memory&cache=shared", os.Getpid())
+		db, err := sql.Open("sqlite3", dbPath)
+		if err != nil {
+			return "", err
+		}
+		memoryConn, err = db.Conn(context.Background())
+		if err != nil {
+			return "", err
+		}
+		return dbPath, InitSchema(dbPath)
+	}
+
+	dir, err := os.MkdirTemp("", "projector-")
+	if err != nil {
+		return "", err
+	}
+	temporaryDir = dir
+	dbPath := filepath.Join(dir, DatabaseName)
+	return dbPath, InitSchema(dbPath)
+}
+
+// GetDataDir returns the directory for files kept next to the database, such
+// as backups and the PID file. In-memory databases use the temporary directory.
+func GetDataDir(dbPath string) string {
+	if IsMemoryPath(dbPath) {
+		return os.TempDir()
+	}
+	return filepath.Dir(dbPath)
+}
+
+// CloseTemporary releases the in-memory database or removes the temporary
+// database file, if one was created
+func CloseTemporary() {
+	if memoryConn != nil {
+		memoryConn.Close()
+		memoryConn = nil
+	}
+	if temporaryDir != "" {
+		os.RemoveAll(temporaryDir)
+		temporaryDir = ""
+	}
+}
+
+// InitSchema creates a database with all tables and the status rows, and
+// records the current schema version, as `projector init` followed by a
+// migration does
+func InitSchema(dbPath string) error {
+	if err := CreateDatabase(dbPath); err != nil {
+		return fmt.Errorf("failed to create database: %v", err)
+	}
+	for _, table := range Tables {
+		if err := CreateTable(dbPath, table); err != nil {
+			return fmt.Errorf("failed to create table %s: %v", table, err)
+		}
+	}
+	if err := CreateSyncSchema(dbPath); err != nil {
+		return fmt.Errorf("failed to set up remote sync: %v", err)
+	}
+	if err := CreateIndexes(dbPath); err != nil {
+		return err
+	}
+	if err := CreateChangeCounters(dbPath); err != nil {
+		return err
+	}
+	if err := CreateVersionTriggers(dbPath); err != nil {
+		return err
+	}
+	if err := CreateHistory(dbPath); err != nil {
+		return err
+	}
+	if err := CreateEventLog(dbPath); err != nil {
+		return err
+	}
+	return SetSchemaVersion(dbPath)
+}
@@ -0,0 +1,477 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TrashItem is a deleted action or project that can still be restored
+type TrashItem struct {
+	ID        uint
+	Entity    string // action or project
+	EntityID  uint
+	Name      string
+	OwnerID   sql.NullInt64
+	DeletedAt string
+}
+
+// ExpiresAt returns when the item is purged for a retention period
+func (t *TrashItem) ExpiresAt(retentionDays int) time.Time {
+	deletedAt, _ := time.Parse(time.RFC3339Nano, t.DeletedAt)
+	return deletedAt.AddDate(0, 0, retentionDays)
+}
+
+// trashData is the snapshot of a deleted row and the rows that belong to it.
+// The rows hold the stored column values, so restoring them does not depend
+// on the Go models.
+type trashData struct {
+	Row         json.RawMessage   `json:"row"`
+	Tags        []int64           `json:"tags,omitempty"`
+	Members     []json.RawMessage `json:"members,omitempty"`
+	Attachments []json.RawMessage `json:"attachments,omitempty"`
+	Reminders   []json.RawMessage `json:"reminders,omitempty"`
+}
+
+// restoreSkipped are the columns left out when a row is restored, so that the
+// sync triggers mark it as changed
+var restoreSkipped = map[string]bool{"updated_at": true, "changed_at": true}
+
+// tableColumns returns the column names of a table
+func tableColumns(tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %v", err)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// snapshotRows returns the matching rows of a table as JSON objects of their
+// stored column values
+func snapshotRows(tx *sql.Tx, table, where string, args ...interface{}) ([]json.RawMessage, error) {
+	columns, err := tableColumns(tx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make([]string, len(columns))
+	for i, column := range columns {
+		pairs[i] = fmt.Sprintf(`'%s', "%s"`, column, column)
+	}
+
+	rows, err := tx.Query(fmt.Sprintf("SELECT json_object(%s) FROM %s WHERE %s", strings.Join(pairs, ", "), table, where), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", table, err)
+	}
+	defer rows.Close()
+
+	var snapshots []json.RawMessage
+	for rows.Next() {
+		var snapshot string
+		if err := rows.Scan(&snapshot); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %v", table, err)
+		}
+		snapshots = append(snapshots, json.RawMessage(snapshot))
+	}
+	return snapshots, rows.Err()
+}
+
+// restoreRow inserts a row from its snapshot. Columns that no longer exist
+// are ignored and columns added since the deletion get their default.
+func restoreRow(tx *sql.Tx, table string, snapshot json.RawMessage) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(snapshot, &values); err != nil {
+		return fmt.Errorf("invalid %s snapshot: %v", table, err)
+	}
+
+	columns, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+
+	var names, placeholders []string
+	var args []interface{}
+	for _, column := range columns {
+		if _, ok := values[column]; !ok || restoreSkipped[column] {
+			continue
+		}
+		names = append(names, fmt.Sprintf(`"%s"`, column))
+		placeholders = append(placeholders, "json_extract(?, ?)")
+		args = append(args, string(snapshot), fmt.Sprintf(`$."%s"`, column))
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(placeholders, ", "))
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to restore %s: %v", table, err)
+	}
+	return nil
+}
+
+// moveToTrash snapshots a row into the trash and deletes it
+func moveToTrash(tx *sql.Tx, entity string, id uint, name string, data trashData) error {
+	rows, err := snapshotRows(tx, entity, "id = ?", id)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%s not found", entity)
+	}
+	data.Row = rows[0]
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", entity, err)
+	}
+
+	_, err = tx.Exec("INSERT INTO trash (entity, entity_id, name, data, deleted_at) VALUES (?, ?, ?, ?, "+sqlNow+")", entity, id, name, string(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to move %s to trash: %v", entity, err)
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", entity), id)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %v", entity, err)
+	}
+	return nil
+}
+
+// TrashAction moves an action, its tags, attachments and reminders to the trash
+func TrashAction(dbPath string, actionID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRow("SELECT name FROM action WHERE id = ?", actionID).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("action not found")
+		}
+		return fmt.Errorf("failed to read action: %v", err)
+	}
+
+	data, err := snapshotAction(tx, actionID)
+	if err != nil {
+		return err
+	}
+
+	if err := moveToTrash(tx, "action", actionID, name, data); err != nil {
+		return err
+	}
+
+	if err := deleteActionRows(tx, actionID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// snapshotAction returns the tags, attachments and reminders of an action,
+// without the action row itself
+func snapshotAction(tx *sql.Tx, actionID uint) (trashData, error) {
+	var data trashData
+	rows, err := tx.Query("SELECT tag_id FROM action_tag WHERE action_id = ? ORDER BY tag_id", actionID)
+	if err != nil {
+		return data, fmt.Errorf("failed to read tags: %v", err)
+	}
+	for rows.Next() {
+		var tagID int64
+		if err := rows.Scan(&tagID); err != nil {
+			rows.Close()
+			return data, fmt.Errorf("failed to scan tag: %v", err)
+		}
+		data.Tags = append(data.Tags, tagID)
+	}
+	rows.Close()
+
+	data.Attachments, err = snapshotRows(tx, "attachment", "action_id = ?", actionID)
+	if err != nil {
+		return data, err
+	}
+	data.Reminders, err = snapshotRows(tx, "reminder", "action_id = ?", actionID)
+	if err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// deleteActionRows deletes the tags, attachments and reminders of an action
+func deleteActionRows(tx *sql.Tx, actionID uint) error {
+	_, err := tx.Exec("DELETE FROM action_tag WHERE action_id = ?", actionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tags: %v", err)
+	}
+	_, err = tx.Exec("DELETE FROM attachment WHERE action_id = ?", actionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete attachments: %v", err)
+	}
+	_, err = tx.Exec("DELETE FROM reminder WHERE action_id = ?", actionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reminders: %v", err)
+	}
+	return nil
+}
+
+// restoreActionRows puts back the tags, attachments and reminders of an
+// action from its snapshot. Tags that were deleted in the meantime are left
+// out.
+func restoreActionRows(tx *sql.Tx, actionID uint, data trashData) error {
+	for _, tagID := range data.Tags {
+		_, err := tx.Exec("INSERT OR IGNORE INTO action_tag (action_id, tag_id) SELECT ?, id FROM tag WHERE id = ?", actionID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to restore tag: %v", err)
+		}
+	}
+	for _, attachment := range data.Attachments {
+		if err := restoreRow(tx, "attachment", attachment); err != nil {
+			return err
+		}
+	}
+	for _, reminder := range data.Reminders {
+		if err := restoreRow(tx, "reminder", reminder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TrashProject moves a project and its members to the trash. The actions of
+// the project are kept and belong to it again when it is restored.
+func TrashProject(dbPath string, projectID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRow("SELECT name FROM project WHERE id = ?", projectID).Scan(&name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("project not found")
+		}
+		return fmt.Errorf("failed to read project: %v", err)
+	}
+
+	members, err := snapshotRows(tx, "project_member", "project_id = ?", projectID)
+	if err != nil {
+		return err
+	}
+
+	if err := moveToTrash(tx, "project", projectID, name, trashData{Members: members}); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("DELETE FROM project_member WHERE project_id = ?", projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project members: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// trashColumns selects a TrashItem
+const trashColumns = "id, entity, entity_id, name, json_extract(data, '$.row.owner_id'), deleted_at"
+
+// scanTrashItem scans a row selected with trashColumns
+func scanTrashItem(scanner interface{ Scan(...interface{}) error }) (*TrashItem, error) {
+	var item TrashItem
+	err := scanner.Scan(&item.ID, &item.Entity, &item.EntityID, &item.Name, &item.OwnerID, &item.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetTrash retrieves the items in the trash, most recently deleted first
+func GetTrash(dbPath string) ([]TrashItem, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT " + trashColumns + " FROM trash ORDER BY deleted_at DESC, id DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %v", err)
+	}
+	defer rows.Close()
+
+	var items []TrashItem
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trash: %v", err)
+		}
+		items = append(items, *item)
+	}
+
+	return items, nil
+}
+
+// GetTrashItem retrieves an item in the trash by its ID
+func GetTrashItem(dbPath string, trashID uint) (*TrashItem, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	item, err := scanTrashItem(db.QueryRow("SELECT "+trashColumns+" FROM trash WHERE id = ?", trashID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Item not found
+		}
+		return nil, fmt.Errorf("failed to query trash: %v", err)
+	}
+	return item, nil
+}
+
+// RestoreTrash puts an item in the trash back with its original ID, along
+// with the tags of an action or the members of a project. The restored row
+// is synced to remote peers as a new change.
+func RestoreTrash(dbPath string, trashID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var entity, encoded string
+	var entityID uint
+	err = tx.QueryRow("SELECT entity, entity_id, data FROM trash WHERE id = ?", trashID).Scan(&entity, &entityID, &encoded)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("trash item not found")
+		}
+		return fmt.Errorf("failed to read trash: %v", err)
+	}
+	if entity != "action" && entity != "project" {
+		return fmt.Errorf("unknown trash entity: %s", entity)
+	}
+
+	var data trashData
+	if err := json.Unmarshal([]byte(encoded), &data); err != nil {
+		return fmt.Errorf("invalid trash data: %v", err)
+	}
+
+	var exists int
+	err = tx.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE id = ?", entity), entityID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check %s: %v", entity, err)
+	}
+	if exists > 0 {
+		return fmt.Errorf("%s %d already exists", entity, entityID)
+	}
+
+	if err := restoreRow(tx, entity, data.Row); err != nil {
+		return err
+	}
+
+	// Forget the deletion so remote peers do not delete the row again
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM tombstone WHERE entity = ? AND uid = (SELECT uid FROM %s WHERE id = ?)", entity), entity, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to clear tombstone: %v", err)
+	}
+
+	if err := restoreActionRows(tx, entityID, data); err != nil {
+		return err
+	}
+	for _, member := range data.Members {
+		if err := restoreRow(tx, "project_member", member); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec("DELETE FROM trash WHERE id = ?", trashID); err != nil {
+		return fmt.Errorf("failed to remove from trash: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// EmptyTrash permanently removes the items deleted before a time, or all
+// items when before is the zero time. It returns the number of items removed.
+func EmptyTrash(dbPath string, before time.Time) (int64, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var result sql.Result
+	if before.IsZero() {
+		result, err = db.Exec("DELETE FROM trash")
+	} else {
+		result, err = db.Exec("DELETE FROM trash WHERE deleted_at < ?", before.UTC().Format("2006-01-02T15:04:05.000Z"))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %v", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// RunTrashPurge removes the items that have been in the trash for more than
+// the retention period, checking every hour until stop is closed
+func RunTrashPurge(dbPath string, retentionDays int, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := EmptyTrash(dbPath, time.Now().AddDate(0, 0, -retentionDays)); err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package database
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// passwordIterations is the PBKDF2 iteration count for new password hashes
+const passwordIterations = 600000
+
+// User is an account that owns projects and actions. Admins can manage all
+// accounts, disabled accounts cannot log in.
+type User struct {
+	ID        uint
+	Username  string
+	CreatedAt string
+	IsAdmin   bool
+	Disabled  bool
+}
+
+// userColumns are the columns scanned by scanUser
+const userColumns = "u.id, u.username, u.created_at, u.is_admin, u.disabled"
+
+// scanUser scans a row of userColumns
+func scanUser(row interface{ Scan(...interface{}) error }, user *User) error {
+	return row.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.IsAdmin, &user.Disabled)
+}
+
+// CountUsers returns the number of user accounts. Without any accounts the
+// API runs in single-user mode and does not require a login.
+func CountUsers(dbPath string) (int, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM user").Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetAllUsers retrieves all user accounts
+func GetAllUsers(dbPath string) ([]User, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT " + userColumns + " FROM user u ORDER BY u.username")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// GetUserByID retrieves a user by ID
+func GetUserByID(dbPath string, userID uint) (*User, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var user User
+	err = scanUser(db.QueryRow("SELECT "+userColumns+" FROM user u WHERE u.id = ?", userID), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetUserByUsername retrieves a user by username
+func GetUserByUsername(dbPath, username string) (*User, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var user User
+	err = scanUser(db.QueryRow("SELECT "+userColumns+" FROM user u WHERE u.username = ?", username), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CreateUser creates a user account with the given password. The first
+// account is always an admin, so the server can be administered.
+func CreateUser(dbPath, username, password string, isAdmin bool) (uint, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return 0, fmt.Errorf("username is required")
+	}
+	if len(password) < 8 {
+		return 0, fmt.Errorf("password must be at least 8 characters")
+	}
+
+	existing, err := GetUserByUsername(dbPath, username)
+	if err != nil {
+		return 0, err
+	}
+	if existing != nil {
+		return 0, fmt.Errorf("user %s already exists", username)
+	}
+
+	count, err := CountUsers(dbPath)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		isAdmin = true
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	result, err := db.Exec("INSERT INTO user (username, password_hash, is_admin) VALUES (?, ?, ?)", username, passwordHash, isAdmin)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create user: %v", err)
+	}
+
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(userID), nil
+}
+
+// SetUserPassword replaces the password of a user and revokes all its tokens
+func SetUserPassword(dbPath string, userID uint, password string) error {
+	if len(password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE user SET password_hash = ? WHERE id = ?", passwordHash, userID); err != nil {
+		return fmt.Errorf("failed to update password: %v", err)
+	}
+	if _, err := tx.Exec("DELETE FROM user_token WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// SetUserAdmin grants or revokes the admin role of a user
+func SetUserAdmin(dbPath string, userID uint, isAdmin bool) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("UPDATE user SET is_admin = ? WHERE id = ?", isAdmin, userID); err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+
+	return nil
+}
+
+// SetUserDisabled disables or re-enables a user account. Disabling an
+// account also revokes all its tokens.
+func SetUserDisabled(dbPath string, userID uint, disabled bool) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE user SET disabled = ? WHERE id = ?", disabled, userID); err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	if disabled {
+		if _, err := tx.Exec("DELETE FROM user_token WHERE user_id = ?", userID); err != nil {
+			return fmt.Errorf("failed to revoke tokens: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUser deletes a user account, its tokens and memberships. Its projects and actions
+// are kept without an owner.
+func DeleteUser(dbPath string, userID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range []string{
+		"DELETE FROM user_token WHERE user_id = ?",
+		"DELETE FROM project_member WHERE user_id = ?",
+		"UPDATE action SET owner_id = NULL WHERE owner_id = ?",
+		"UPDATE action SET assignee_id = NULL WHERE assignee_id = ?",
+		"UPDATE project SET owner_id = NULL WHERE owner_id = ?",
+		"DELETE FROM user WHERE id = ?",
+	} {
+		if _, err := tx.Exec(statement, userID); err != nil {
+			return fmt.Errorf("failed to delete user: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Authenticate checks a username and password, returning nil for invalid
+// credentials and disabled accounts
+func Authenticate(dbPath, username, password string) (*User, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var user User
+	var passwordHash string
+	err = db.QueryRow("SELECT "+userColumns+", u.password_hash FROM user u WHERE u.username = ?", username).
+		Scan(&user.ID, &user.Username, &user.CreatedAt, &user.IsAdmin, &user.Disabled, &passwordHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if user.Disabled || !checkPassword(passwordHash, password) {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+// CreateToken issues a new API token for a user. Only a hash of the token is
+// stored, so it cannot be retrieved again.
+func CreateToken(dbPath string, userID uint) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %v", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT INTO user_token (token_hash, user_id) VALUES (?, ?)", hashToken(token), userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to store token: %v", err)
+	}
+
+	return token, nil
+}
+
+// GetUserByToken retrieves the user an API token was issued to, returning nil
+// for unknown tokens and disabled accounts
+func GetUserByToken(dbPath, token string) (*User, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM user_token t
+		JOIN user u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND u.disabled = 0
+	`
+
+	var user User
+	err = scanUser(db.QueryRow(query, hashToken(token)), &user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// DeleteToken revokes an API token
+func DeleteToken(dbPath, token string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("DELETE FROM user_token WHERE token_hash = ?", hashToken(token))
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+
+	return nil
+}
+
+// ClaimUnowned gives a user ownership of all projects and actions without an
+// owner, as created before user accounts existed. It returns the number of
+// items claimed.
+func ClaimUnowned(dbPath string, userID uint) (int64, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var claimed int64
+	for _, table := range []string{"project", "action"} {
+		result, err := db.Exec(fmt.Sprintf("UPDATE %s SET owner_id = ? WHERE owner_id IS NULL", table), userID)
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim %s rows: %v", table, err)
+		}
+		rows, _ := result.RowsAffected()
+		claimed += rows
+	}
+
+	return claimed, nil
+}
+
+// hashPassword derives a salted PBKDF2 hash in the form
+// pbkdf2-sha256$iterations$salt$key
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations, hex.EncodeToString(salt), hex.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether a password matches a hash from hashPassword
+func checkPassword(passwordHash, password string) bool {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key, expected) == 1
+}
+
+// hashToken returns the stored form of an API token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
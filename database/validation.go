@@ -1,8 +1,13 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
 // ValidateDate checks if a date string is valid and returns a formatted date string
@@ -27,48 +32,271 @@ func ValidateDate(dateStr string) (string, error) {
 	return date.Format("2006-01-02"), nil
 }
 
-// ValidateActionInput validates action input data
-func ValidateActionInput(name string, projectID *uint, dueDate string, statusID uint) error {
-	if name == "" {
-		return fmt.Errorf("action name is required")
+// maxNameLength is the longest name of an action or project, in characters
+const maxNameLength = 255
+
+// FieldError is an input field that breaks a validation rule. Field is the
+// name of the field in the API.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of an action or project that break the
+// validation rules. The API answers it with 422 Unprocessable Entity and the
+// TUI shows it next to the input.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
 	}
+	return strings.Join(messages, "; ")
+}
+
+// add records a field error
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
 
-	if len(name) > 255 {
-		return fmt.Errorf("action name is too long (max 255 characters)")
+// orNil returns the error when a field broke a rule, or nil
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
 	}
+	return e
+}
+
+// ActionInput holds the fields of an action checked by ValidateAction,
+// either a new action or an existing one with its changes applied
+type ActionInput struct {
+	Name           string
+	ProjectID      *uint
+	StatusID       uint
+	DueDate        string
+	StartDate      string
+	FollowUp       string
+	RepeatMode     string
+	RepeatCount    uint
+	RepeatInterval string
+	RepeatUntil    string
+	Energy         string
+	Reminders      []string
+	AssigneeID     *uint
+	ParentActionID *uint
+	// Dates an update leaves as they are may lie in the past, they are not
+	// checked again
+	KeepDueDate   bool
+	KeepStartDate bool
+	KeepFollowUp  bool
+}
 
-	if statusID <= 0 {
-		return fmt.Errorf("invalid status ID")
+// ValidateAction checks an action against the rules every entry point
+// applies: the API, gRPC, the CLI, the TUI and the importers. It checks the
+// name, the dates, whether the repeat settings fit together, and whether the
+// project, status, assignee and parent it refers to exist. Every field that
+// breaks a rule is listed in the returned *ValidationError. The due date,
+// energy level and repeat mode of the input are normalized in place.
+func ValidateAction(dbPath string, input *ActionInput) error {
+	invalid := &ValidationError{}
+
+	name := strings.TrimSpace(input.Name)
+	switch {
+	case name == "":
+		invalid.add("name", "action name is required")
+	case utf8.RuneCountInString(input.Name) > maxNameLength:
+		invalid.add("name", fmt.Sprintf("action name is too long (max %d characters)", maxNameLength))
 	}
 
-	// Validate due date if provided
-	if dueDate != "" {
-		_, err := ValidateDate(dueDate)
+	if !input.KeepDueDate {
+		dueDate, err := ValidateDate(input.DueDate)
 		if err != nil {
-			return fmt.Errorf("due date validation failed: %v", err)
+			invalid.add("due_date", fmt.Sprintf("due date validation failed: %v", err))
+		} else {
+			input.DueDate = dueDate
+		}
+	}
+	if !input.KeepStartDate || !input.KeepDueDate {
+		// Dates read from the database may carry a time
+		startDate := input.StartDate
+		if input.KeepStartDate {
+			startDate = StoredDate(startDate)
+		}
+		if err := ValidateStartDate(startDate, StoredDate(input.DueDate)); err != nil {
+			invalid.add("start_date", err.Error())
 		}
 	}
+	if !input.KeepFollowUp {
+		if _, err := ValidateDate(input.FollowUp); err != nil {
+			invalid.add("follow_up", fmt.Sprintf("follow-up date validation failed: %v", err))
+		}
+	}
+
+	if energy, err := ValidateEnergy(input.Energy); err != nil {
+		invalid.add("energy", err.Error())
+	} else {
+		input.Energy = energy
+	}
+	if err := ValidateReminders(input.Reminders); err != nil {
+		invalid.add("reminders", err.Error())
+	}
+
+	repeatMode, err := ValidateRepeatInput(input.RepeatMode, input.RepeatCount, input.RepeatInterval, input.RepeatUntil)
+	if err != nil {
+		invalid.add(repeatField(input.RepeatMode, input.RepeatCount, input.RepeatInterval), err.Error())
+	} else {
+		input.RepeatMode = repeatMode
+	}
+
+	if input.StatusID == 0 {
+		invalid.add("status_id", "invalid status ID")
+	}
+
+	if err := checkReferences(dbPath, input, invalid); err != nil {
+		return err
+	}
+	return invalid.orNil()
+}
+
+// repeatField returns the repeat field a failed ValidateRepeatInput refers to
+func repeatField(mode string, count uint, interval string) string {
+	switch {
+	case mode != "" && mode != RepeatModeNone && mode != RepeatModeForever && mode != RepeatModeCount && mode != RepeatModeUntil:
+		return "repeat_mode"
+	case ValidateRepeatInterval(interval) != nil:
+		return "repeat_interval"
+	case mode == RepeatModeCount && count == 0:
+		return "repeat_count"
+	default:
+		return "repeat_until"
+	}
+}
+
+// checkReferences records the project, status, assignee and parent action of
+// an input that do not exist. It only returns an error when the database
+// cannot be read.
+func checkReferences(dbPath string, input *ActionInput, invalid *ValidationError) error {
+	references := []struct {
+		field, query, message string
+		id                    *uint
+	}{
+		{"project_id", "SELECT 1 FROM project WHERE id = ?", "project %d not found", input.ProjectID},
+		{"status_id", "SELECT 1 FROM status WHERE id = ?", "status %d not found", &input.StatusID},
+		{"assignee_id", "SELECT 1 FROM user WHERE id = ?", "assignee %d not found", input.AssigneeID},
+		{"parent_action_id", "SELECT 1 FROM action WHERE id = ?", "parent action %d not found", input.ParentActionID},
+	}
+
+	var db *sql.DB
+	for _, reference := range references {
+		if reference.id == nil || *reference.id == 0 {
+			continue
+		}
+		if db == nil {
+			var err error
+			if db, err = sql.Open("sqlite3", dbPath); err != nil {
+				return fmt.Errorf("failed to open database: %v", err)
+			}
+			defer db.Close()
+		}
 
+		var found int
+		err := db.QueryRow(reference.query, *reference.id).Scan(&found)
+		if err == sql.ErrNoRows {
+			invalid.add(reference.field, fmt.Sprintf(reference.message, *reference.id))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check %s: %v", reference.field, err)
+		}
+	}
 	return nil
 }
 
-// ValidateProjectInput validates project input data
+// ValidateProjectInput validates project input data, returning a
+// *ValidationError with the fields that break a rule
 func ValidateProjectInput(name string, dueDate string) error {
-	if name == "" {
-		return fmt.Errorf("project name is required")
+	invalid := &ValidationError{}
+
+	switch {
+	case strings.TrimSpace(name) == "":
+		invalid.add("name", "project name is required")
+	case utf8.RuneCountInString(name) > maxNameLength:
+		invalid.add("name", fmt.Sprintf("project name is too long (max %d characters)", maxNameLength))
 	}
 
-	if len(name) > 255 {
-		return fmt.Errorf("project name is too long (max 255 characters)")
+	if _, err := ValidateDate(dueDate); err != nil {
+		invalid.add("due_date", fmt.Sprintf("due date validation failed: %v", err))
 	}
 
-	// Validate due date if provided
-	if dueDate != "" {
-		_, err := ValidateDate(dueDate)
-		if err != nil {
-			return fmt.Errorf("due date validation failed: %v", err)
+	return invalid.orNil()
+}
+
+// ValidateRepeatInput validates a repeat configuration and returns the resolved repeat mode.
+// When no mode is given it is derived from the other fields for backwards compatibility.
+func ValidateRepeatInput(mode string, count uint, interval, until string) (string, error) {
+	if mode == "" {
+		switch {
+		case interval == "":
+			mode = RepeatModeNone
+		case until != "":
+			mode = RepeatModeUntil
+		case count > 0:
+			mode = RepeatModeCount
+		default:
+			mode = RepeatModeNone
 		}
 	}
 
-	return nil
+	switch mode {
+	case RepeatModeNone:
+		return mode, nil
+	case RepeatModeForever, RepeatModeCount, RepeatModeUntil:
+	default:
+		return "", fmt.Errorf("invalid repeat mode: %s. Expected one of: none, forever, count, until", mode)
+	}
+
+	if err := ValidateRepeatInterval(interval); err != nil {
+		return "", err
+	}
+
+	if mode == RepeatModeCount && count == 0 {
+		return "", fmt.Errorf("repeat count must be greater than 0 when repeating a number of times")
+	}
+
+	if mode == RepeatModeUntil {
+		if until == "" {
+			return "", fmt.Errorf("repeat until date is required when repeating until a date")
+		}
+		if _, err := ParseStoredDate(until); err != nil {
+			return "", fmt.Errorf("invalid repeat until date: %s. Expected format: YYYY-MM-DD", until)
+		}
+	}
+
+	return mode, nil
+}
+
+// ValidateRepeatInterval checks if a repeat interval is supported
+func ValidateRepeatInterval(interval string) error {
+	switch interval {
+	case "minute", "hour", "day", "week", "month", "year":
+		return nil
+	case "":
+		return fmt.Errorf("repeat interval is required for repeating actions")
+	default:
+		return fmt.Errorf("invalid repeat interval: %s. Expected one of: minute, hour, day, week, month, year", interval)
+	}
+}
+
+// ValidateProjectRepeatInterval checks if a repeat interval is supported for
+// projects. Projects repeat at most weekly.
+func ValidateProjectRepeatInterval(interval string) error {
+	switch interval {
+	case "week", "month", "quarter", "year":
+		return nil
+	default:
+		return fmt.Errorf("invalid project repeat interval: %s. Expected one of: week, month, quarter, year", interval)
+	}
 }
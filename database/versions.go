@@ -0,0 +1,99 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// versionTriggers increase the version of a project or action once for every
+// write, including changes applied by remote sync, so that a client can tell
+// whether a row changed since it read it, and how often. They only fire for
+// the columns a write changes, not for the uid and timestamps that the sync
+// triggers set after an insert or update, which would count it twice, nor for
+// the owner, which is not edited but set when a row is created or its user
+// deleted. Tag changes increase the version in the statements that make them.
+var versionTriggers = []string{
+	`DROP TRIGGER IF EXISTS project_version`,
+	`CREATE TRIGGER project_version
+	AFTER UPDATE OF name, due_date, archived_at, repeat_interval, parent_project_id, review_interval, last_reviewed_at ON project
+	WHEN NEW.version IS OLD.version
+	BEGIN
+		UPDATE project SET version = OLD.version + 1 WHERE id = NEW.id;
+	END`,
+	`DROP TRIGGER IF EXISTS action_version`,
+	`CREATE TRIGGER action_version
+	AFTER UPDATE OF project_id, name, note, due_date, status_id, repeat_count, repeat_interval, repeat_pattern, repeat_until,
+		parent_action_id, repeat_mode, assignee_id, flagged, location, latitude, longitude, energy, waiting_on,
+		follow_up, start_date ON action
+	WHEN NEW.version IS OLD.version
+	BEGIN
+		UPDATE action SET version = OLD.version + 1 WHERE id = NEW.id;
+	END`,
+}
+
+// bumpActionVersion is the statement that increases the version of an action
+// whose tags changed
+const bumpActionVersion = "UPDATE action SET version = version + 1 WHERE id = ?"
+
+// VersionConflictError is returned when an update expects a version of a
+// project or action that is no longer current, because someone else changed
+// it in the meantime
+type VersionConflictError struct {
+	Entity   string
+	ID       uint
+	Expected uint
+	Current  uint
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s %d was changed in the meantime: expected version %d, but it is at version %d", e.Entity, e.ID, e.Expected, e.Current)
+}
+
+// CreateVersionTriggers creates the triggers that maintain the version columns
+func CreateVersionTriggers(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, statement := range versionTriggers {
+		if _, err := db.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create version trigger: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ResetActionVersion makes an action that was just created in several
+// statements, such as the fields the API sets after the insert, report
+// version 1, the single write that created it
+func ResetActionVersion(dbPath string, actionID uint) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("UPDATE action SET version = 1 WHERE id = ?", actionID); err != nil {
+		return fmt.Errorf("failed to reset action version: %v", err)
+	}
+	return nil
+}
+
+// versionConflict returns the error for an update of a row that was not at
+// the expected version, or that does not exist
+func versionConflict(db *sql.DB, table string, id, expected uint) error {
+	var current uint
+	err := db.QueryRow("SELECT version FROM "+table+" WHERE id = ?", id).Scan(&current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s %d not found", table, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s version: %v", table, err)
+	}
+	return &VersionConflictError{Entity: table, ID: id, Expected: expected, Current: current}
+}
@@ -0,0 +1,20 @@
+package database
+
+// GetWaitingActions retrieves the open actions that wait on someone or
+// something, those with the earliest follow-up date first and those without
+// one last
+func GetWaitingActions(dbPath string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND a.waiting_on IS NOT NULL ORDER BY a.follow_up IS NULL, date(a.follow_up), a.id")
+}
+
+// GetFollowUpsDue retrieves the open waiting actions whose follow-up date is
+// on or before the given date (YYYY-MM-DD)
+func GetFollowUpsDue(dbPath, date string) ([]Action, error) {
+	return queryActions(dbPath, "WHERE a.status_id != 2 AND a.waiting_on IS NOT NULL AND date(a.follow_up) <= date(?) ORDER BY date(a.follow_up), a.id", date)
+}
+
+// FollowUpDue reports whether an action waits on someone and its follow-up
+// date is on or before the given date (YYYY-MM-DD)
+func (a *Action) FollowUpDue(date string) bool {
+	return a.WaitingOn.Valid && a.FollowUp.Valid && StoredDate(a.FollowUp.String) <= date
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// WIPLimit returns the work-in-progress limit of a status, within a project
+// when a project name is given, or 0 when the status has no limit
+func WIPLimit(status, project string) int {
+	if project != "" {
+		return workflow.ProjectLimits[project][status]
+	}
+	return workflow.Limits[status]
+}
+
+// WIPLimitError reports a change that would put more actions in a status
+// than its work-in-progress limit allows
+type WIPLimitError struct {
+	Status  string
+	Project string // Empty for the limit across all projects
+	Limit   int
+	Count   int // Number of actions in the status before the change
+}
+
+func (e *WIPLimitError) Error() string {
+	if e.Project != "" {
+		return fmt.Sprintf("%s already has %d action(s) in project %s, the project limit is %d", e.Status, e.Count, e.Project, e.Limit)
+	}
+	return fmt.Sprintf("%s already has %d action(s), the limit is %d", e.Status, e.Count, e.Limit)
+}
+
+// CheckWIPLimit verifies that an update does not move an action into a status
+// that holds as many actions as its work-in-progress limit allows, across the
+// projects of the owner of the action or within its project. It returns a
+// WIPLimitError when it does, which callers may let the user override.
+func CheckWIPLimit(dbPath string, actionID uint, update ActionUpdate) error {
+	if len(workflow.Limits) == 0 && len(workflow.ProjectLimits) == 0 {
+		return nil
+	}
+
+	action, err := GetActionByID(dbPath, actionID)
+	if err != nil {
+		return err
+	}
+	if action == nil {
+		return fmt.Errorf("action not found")
+	}
+
+	statusID := action.StatusID
+	if update.StatusID != nil {
+		statusID = *update.StatusID
+	}
+	projectID := uint(action.ProjectID.Int64)
+	if update.ProjectID != nil {
+		projectID = *update.ProjectID
+	}
+	statusChanged := statusID != action.StatusID
+	if !statusChanged && projectID == uint(action.ProjectID.Int64) {
+		return nil
+	}
+
+	return checkWIPLimit(dbPath, statusID, projectID, action.OwnerID, actionID, statusChanged)
+}
+
+// CheckNewActionWIPLimit verifies that an action about to be created in a
+// status, within a project when projectID is not 0 and owned by ownerID or
+// by no one when it is 0, does not exceed a work-in-progress limit. It
+// returns a WIPLimitError when it does, like CheckWIPLimit.
+func CheckNewActionWIPLimit(dbPath string, statusID, projectID, ownerID uint) error {
+	if len(workflow.Limits) == 0 && len(workflow.ProjectLimits) == 0 {
+		return nil
+	}
+
+	var owner sql.NullInt64
+	if ownerID != 0 {
+		owner = sql.NullInt64{Int64: int64(ownerID), Valid: true}
+	}
+	return checkWIPLimit(dbPath, statusID, projectID, owner, 0, true)
+}
+
+// checkWIPLimit counts the actions other than actionID in a status against
+// the limits of the status, across the projects of the owner when the
+// action enters the status and within the project when it is not 0
+func checkWIPLimit(dbPath string, statusID, projectID uint, ownerID sql.NullInt64, actionID uint, statusChanged bool) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	status, err := statusName(db, statusID)
+	if err != nil {
+		return err
+	}
+
+	// Moving within the status only counts against the limit of the project.
+	// The limit across projects is that of each user, and the actions of
+	// other users do not count against it.
+	if limit := WIPLimit(status, ""); limit > 0 && statusChanged {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM action WHERE status_id = ? AND owner_id IS ? AND id != ?", statusID, ownerID, actionID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count actions: %v", err)
+		}
+		if count >= limit {
+			return &WIPLimitError{Status: status, Limit: limit, Count: count}
+		}
+	}
+
+	if projectID == 0 {
+		return nil
+	}
+	var project string
+	if err := db.QueryRow("SELECT name FROM project WHERE id = ?", projectID).Scan(&project); err != nil {
+		if err == sql.ErrNoRows {
+			return nil // Reported when the update is validated
+		}
+		return fmt.Errorf("failed to read project %d: %v", projectID, err)
+	}
+	if limit := WIPLimit(status, project); limit > 0 {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM action WHERE status_id = ? AND project_id = ? AND id != ?", statusID, projectID, actionID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to count actions: %v", err)
+		}
+		if count >= limit {
+			return &WIPLimitError{Status: status, Project: project, Limit: limit, Count: count}
+		}
+	}
+	return nil
+}
+
+// CountActionsByStatus returns the number of actions per status ID, within a
+// project when projectID is not 0
+func CountActionsByStatus(dbPath string, projectID uint) (map[uint]int, error) {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query("SELECT status_id, COUNT(*) FROM action WHERE ? = 0 OR project_id = ? GROUP BY status_id", projectID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count actions: %v", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[uint]int)
+	for rows.Next() {
+		var statusID uint
+		var count int
+		if err := rows.Scan(&statusID, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan action count: %v", err)
+		}
+		counts[statusID] = count
+	}
+	return counts, rows.Err()
+}
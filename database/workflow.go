@@ -0,0 +1,175 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Workflow configures the status of new actions and the status changes that
+// are allowed
+type Workflow struct {
+	// DefaultStatus is the name of the status new actions get, todo when empty
+	DefaultStatus string
+	// Transitions lists for a status name the statuses an action may change
+	// to. A status without an entry may change to any status.
+	Transitions map[string][]string
+	// Limits caps the number of actions in a status name across all
+	// projects, such as 3 in-progress actions
+	Limits map[string]int
+	// ProjectLimits caps the number of actions in a status name within a
+	// project, by project name
+	ProjectLimits map[string]map[string]int
+}
+
+// workflow is the workflow set with SetWorkflow
+var workflow Workflow
+
+// SetWorkflow sets the workflow applied when actions are created and their
+// status changes
+func SetWorkflow(w Workflow) {
+	workflow = w
+}
+
+// StatusNames returns the names of the statuses the workflow refers to
+func (w Workflow) StatusNames() []string {
+	var names []string
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	add(w.DefaultStatus)
+	for from, to := range w.Transitions {
+		add(from)
+		for _, name := range to {
+			add(name)
+		}
+	}
+	for name := range w.Limits {
+		add(name)
+	}
+	for _, limits := range w.ProjectLimits {
+		for name := range limits {
+			add(name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// TransitionError reports a status change the workflow does not allow
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("cannot change status from %s to %s: %s is a final status", e.From, e.To, e.From)
+	}
+	return fmt.Sprintf("cannot change status from %s to %s: %s can only change to %s", e.From, e.To, e.From, strings.Join(e.Allowed, " or "))
+}
+
+// CreateWorkflowStatuses creates the statuses the workflow refers to that do
+// not exist yet, such as in-progress
+func CreateWorkflowStatuses(dbPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, name := range workflow.StatusNames() {
+		if _, err := db.Exec("INSERT OR IGNORE INTO status (name) VALUES (?)", name); err != nil {
+			return fmt.Errorf("failed to create status %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// statusID returns the ID of a status by name, or 0 when it does not exist
+func statusID(db *sql.DB, name string) (uint, error) {
+	var id uint
+	err := db.QueryRow("SELECT id FROM status WHERE name = ?", name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read status %s: %v", name, err)
+	}
+	return id, nil
+}
+
+// statusName returns the name of a status by ID, or an empty string when it
+// does not exist
+func statusName(db *sql.DB, id uint) (string, error) {
+	var name string
+	err := db.QueryRow("SELECT name FROM status WHERE id = ?", id).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read status %d: %v", id, err)
+	}
+	return name, nil
+}
+
+// DefaultStatusID returns the ID of the status new actions get
+func DefaultStatusID(dbPath string) (uint, error) {
+	if workflow.DefaultStatus == "" {
+		return 1, nil // todo
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	id, err := statusID(db, workflow.DefaultStatus)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("default status %s does not exist, run 'projector migrate' to create it", workflow.DefaultStatus)
+	}
+	return id, nil
+}
+
+// CheckStatusTransition verifies that the workflow allows an action to change
+// from one status to another. Staying in the same status is always allowed.
+func CheckStatusTransition(dbPath string, fromID, toID uint) error {
+	if fromID == toID || len(workflow.Transitions) == 0 {
+		return nil
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	from, err := statusName(db, fromID)
+	if err != nil {
+		return err
+	}
+	to, err := statusName(db, toID)
+	if err != nil {
+		return err
+	}
+	if to == "" {
+		return fmt.Errorf("status %d does not exist", toID)
+	}
+
+	allowed, limited := workflow.Transitions[from]
+	if !limited || slices.Contains(allowed, to) {
+		return nil
+	}
+	return &TransitionError{From: from, To: to, Allowed: allowed}
+}
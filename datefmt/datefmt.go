@@ -0,0 +1,169 @@
+// Package datefmt formats dates and times for people, in the format chosen in
+// the display section of the config: ISO, European or US dates, optionally
+// relative to today for near dates, and a 24 or 12-hour clock
+package datefmt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/i18n"
+)
+
+// Date formats
+const (
+	FormatISO      = "iso"      // 2026-10-15
+	FormatEuropean = "european" // 15-10-2026
+	FormatUS       = "us"       // 10/15/2026
+	FormatRelative = "relative" // tomorrow, in 3 days; near dates only
+)
+
+// DefaultRelativeDays is how many days from today the relative format shows
+// dates relatively, unless configured otherwise
+const DefaultRelativeDays = 7
+
+// dateLayout is the layout of stored dates
+const dateLayout = "2006-01-02"
+
+var layouts = map[string]string{
+	FormatISO:      "2006-01-02",
+	FormatEuropean: "02-01-2006",
+	FormatUS:       "01/02/2006",
+}
+
+// Selected formats, ISO dates and a 24-hour clock until configured
+var (
+	layout       = layouts[FormatISO]
+	relativeDays = 0
+	clock        = "15:04"
+)
+
+// twelveHourRegions are the regions of locales that use a 12-hour clock
+var twelveHourRegions = map[string]bool{"US": true, "CA": true, "AU": true, "NZ": true, "IN": true, "PH": true}
+
+// Configure selects the formats of the display section of the config
+func Configure(display config.Display) error {
+	region := localeRegion(display.Locale)
+
+	format := strings.ToLower(display.DateFormat)
+	days := display.RelativeDays
+	if format == FormatRelative {
+		format = ""
+		if days == 0 {
+			days = DefaultRelativeDays
+		}
+	}
+	if format == "" {
+		switch {
+		case region == "":
+			format = FormatISO
+		case region == "US" || region == "PH":
+			format = FormatUS
+		default:
+			format = FormatEuropean
+		}
+	}
+	selected, ok := layouts[format]
+	if !ok {
+		return fmt.Errorf("unknown date format: %s (expected iso, european, us or relative)", display.DateFormat)
+	}
+	if days < 0 {
+		return fmt.Errorf("relative_days must not be negative")
+	}
+
+	timeFormat := strings.ToLower(display.TimeFormat)
+	if timeFormat == "" {
+		timeFormat = "24h"
+		if twelveHourRegions[region] {
+			timeFormat = "12h"
+		}
+	}
+	var selectedClock string
+	switch timeFormat {
+	case "24h":
+		selectedClock = "15:04"
+	case "12h":
+		selectedClock = "3:04 PM"
+	default:
+		return fmt.Errorf("unknown time format: %s (expected 24h or 12h)", display.TimeFormat)
+	}
+
+	layout, relativeDays, clock = selected, days, selectedClock
+	return nil
+}
+
+// localeRegion returns the region of a locale such as en_US.UTF-8 or en-US,
+// or an empty string when it has none
+func localeRegion(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	_, region, found := strings.Cut(strings.ReplaceAll(locale, "-", "_"), "_")
+	if !found {
+		return ""
+	}
+	return strings.ToUpper(region)
+}
+
+// Date formats a stored date (YYYY-MM-DD, or a timestamp starting with one).
+// An empty value stays empty, and a value that is not a date is returned as
+// it is.
+func Date(value string) string {
+	return DateOn(value, time.Now())
+}
+
+// DateOn formats a stored date like Date, relative to the given day
+func DateOn(value string, now time.Time) string {
+	if len(value) > len(dateLayout) {
+		value = value[:len(dateLayout)]
+	}
+	date, err := time.ParseInLocation(dateLayout, value, now.Location())
+	if err != nil {
+		return value
+	}
+
+	if relativeDays > 0 {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		// Rounding copes with days of 23 or 25 hours around DST changes
+		days := int(date.Sub(today).Round(24*time.Hour) / (24 * time.Hour))
+		if days >= -relativeDays && days <= relativeDays {
+			return relative(days)
+		}
+	}
+	return date.Format(layout)
+}
+
+// Absolute formats a stored date like Date, but never relative to today,
+// for titles that are read later such as the subject of a digest
+func Absolute(value string) string {
+	if len(value) > len(dateLayout) {
+		value = value[:len(dateLayout)]
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return value
+	}
+	return date.Format(layout)
+}
+
+// relative describes a number of days from today
+func relative(days int) string {
+	switch {
+	case days == 0:
+		return i18n.T("Today")
+	case days == 1:
+		return i18n.T("Tomorrow")
+	case days == -1:
+		return i18n.T("Yesterday")
+	case days > 0:
+		return i18n.T("InDays", i18n.Data{"Count": days})
+	default:
+		return i18n.T("DaysAgo", i18n.Data{"Count": -days})
+	}
+}
+
+// Time formats the time of day on the configured clock
+func Time(t time.Time) string {
+	return t.Format(clock)
+}
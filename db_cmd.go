@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Inspect and maintain the database file",
+	}
+
+	cmd.AddCommand(dbInfoCmd())
+	cmd.AddCommand(dbMaintainCmd())
+	cmd.AddCommand(dbExplainCmd())
+	return cmd
+}
+
+func dbInfoCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show the database size, fragmentation and last maintenance",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			info, err := database.GetStorageInfo(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorReadingDatabase", i18n.Data{"Error": err}))
+				return
+			}
+
+			lastRun, err := database.GetLastMaintenance(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorReadingMaintenanceLog", i18n.Data{"Error": err}))
+				return
+			}
+
+			record := struct {
+				*database.StorageInfo
+				Path            string `json:"path"`
+				LastMaintenance string `json:"last_maintenance,omitempty"`
+			}{StorageInfo: info, Path: database.GetDatabasePath()}
+			if !lastRun.IsZero() {
+				record.LastMaintenance = lastRun.Local().Format("2006-01-02 15:04")
+			}
+
+			table := output.Table{Headers: []string{"METRIC", "VALUE"}}
+			table.AddRow("path", record.Path)
+			table.AddRow("size", formatBytes(info.SizeBytes))
+			table.AddRow("pages", fmt.Sprint(info.PageCount))
+			table.AddRow("free_pages", fmt.Sprint(info.FreePages))
+			table.AddRow("fragmentation", fmt.Sprintf("%.1f%%", info.Fragmentation*100))
+			table.AddRow("last_maintenance", orNever(record.LastMaintenance))
+
+			if err := output.Print(record, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintDatabaseInfo", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func dbMaintainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "maintain",
+		Short: "Reclaim unused space with VACUUM and refresh statistics with ANALYZE",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			if !output.IsJSON() {
+				fmt.Println(i18n.T("MaintainingDatabase"))
+			}
+			result, err := database.MaintainDatabase(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("DatabaseMaintenanceFailed", i18n.Data{"Error": err}))
+				return
+			}
+
+			table := output.Table{Headers: []string{"METRIC", "BEFORE", "AFTER"}}
+			table.AddRow("size", formatBytes(result.Before.SizeBytes), formatBytes(result.After.SizeBytes))
+			table.AddRow("pages", fmt.Sprint(result.Before.PageCount), fmt.Sprint(result.After.PageCount))
+			table.AddRow("free_pages", fmt.Sprint(result.Before.FreePages), fmt.Sprint(result.After.FreePages))
+			table.AddRow("fragmentation", fmt.Sprintf("%.1f%%", result.Before.Fragmentation*100), fmt.Sprintf("%.1f%%", result.After.Fragmentation*100))
+
+			if err := output.Print(result, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintMaintenanceResult", i18n.Data{"Error": err}))
+				return
+			}
+			if !output.IsJSON() {
+				fmt.Println(i18n.T("Reclaimed", i18n.Data{"Size": formatBytes(result.Before.SizeBytes - result.After.SizeBytes), "Duration": result.Duration.Round(time.Millisecond)}))
+			}
+		},
+	}
+}
+
+func dbExplainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain",
+		Short: "Show the query plans of the frequent queries and the indexes they use",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			plans, err := database.ExplainQueries(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorExplainingQueries", i18n.Data{"Error": err}))
+				return
+			}
+
+			table := output.Table{Headers: []string{"QUERY", "PLAN"}}
+			for _, plan := range plans {
+				for i, step := range plan.Plan {
+					name := ""
+					if i == 0 {
+						name = plan.Name
+					}
+					table.AddRow(name, step)
+				}
+			}
+
+			if err := output.Print(plans, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintQueryPlans", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+// formatBytes formats a size in bytes for people
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// orNever returns the value, or "never" when it is empty
+func orNever(value string) string {
+	if value == "" {
+		return "never"
+	}
+	return value
+}
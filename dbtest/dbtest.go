@@ -0,0 +1,41 @@
+// Package dbtest provides throwaway projector databases for integration tests
+// of tools built on the projector database and API.
+//
+//	func TestReport(t *testing.T) {
+//		dbPath := dbtest.NewDemo(t)
+//		actions, err := database.GetAllActions(dbPath)
+//		...
+//	}
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// New creates an empty database with the current schema in a temporary
+// directory of the test, which is removed when the test finishes. It returns
+// the path to pass to the database functions or api.NewServer.
+func New(t testing.TB) string {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), database.DatabaseName)
+	if err := database.InitSchema(dbPath); err != nil {
+		t.Fatalf("dbtest: %v", err)
+	}
+	return dbPath
+}
+
+// NewDemo creates a database like New, filled with the demo data of
+// `projector seed --demo`
+func NewDemo(t testing.TB) string {
+	t.Helper()
+
+	dbPath := New(t)
+	if _, err := database.SeedDemoData(dbPath); err != nil {
+		t.Fatalf("dbtest: failed to seed demo data: %v", err)
+	}
+	return dbPath
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+func deleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete [id]",
+		Short:             "Move an action to the trash, picking it interactively when no ID is given",
+		Long:              "Move an action to the trash, from where 'projector trash restore' brings it back until the trash is emptied.",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, ok := remoteClient(cmd)
+			if !ok {
+				return
+			}
+
+			actionID, ok := argOrPickedAction(client, args, i18n.T("PickDelete"))
+			if !ok {
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldTrash", i18n.Data{"ID": actionID}))
+				return
+			}
+
+			var err error
+			var deleted *database.Action
+			if client != nil {
+				err = client.DeleteAction(actionID)
+				if queueOffline(err) {
+					return
+				}
+			} else {
+				// The hooks get the action as it was before it was deleted
+				if hooks.Enabled(hooks.ActionDeleted) {
+					deleted, _ = database.GetActionByID(database.GetDatabasePath(), actionID)
+				}
+				err = database.DeleteAction(database.GetDatabasePath(), actionID)
+			}
+			if err != nil {
+				fmt.Println(i18n.T("DeleteFailed", i18n.Data{"ID": actionID, "Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("MovedToTrash", i18n.Data{"ID": actionID}))
+			if deleted != nil {
+				hooks.Action(hooks.ActionDeleted, api.ToAction(deleted))
+			}
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// digestRecord is the JSON form of the digest in command output
+type digestRecord struct {
+	Date               string         `json:"date"`
+	DueToday           []actionRecord `json:"due_today"`
+	Overdue            []actionRecord `json:"overdue"`
+	CompletedYesterday []actionRecord `json:"completed_yesterday"`
+	UpcomingRepeats    []actionRecord `json:"upcoming_repeats"`
+}
+
+func digestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Show a morning summary of your actions, or email it with --email",
+		Long: fmt.Sprintf(`Show a morning summary: the actions due today, the overdue actions, the
+actions completed yesterday and the repeating actions due in the next %d days.
+
+With --email the summary is sent as plain text to every email channel of the
+config file instead.`, notify.UpcomingRepeatDays),
+		Run: func(cmd *cobra.Command, args []string) {
+			email, _ := cmd.Flags().GetBool("email")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			digest, err := notify.BuildDigest(database.GetDatabasePath(), time.Now())
+			if err != nil {
+				fmt.Println(i18n.T("FailedToBuildDigest", i18n.Data{"Error": err}))
+				return
+			}
+
+			if email {
+				emailDigest(digest)
+				return
+			}
+			printDigest(digest)
+		},
+	}
+
+	cmd.Flags().Bool("email", false, "Send the digest to the email channels of the config file")
+	return cmd
+}
+
+// printDigest prints the digest for the terminal, or in the selected output
+// format with one row per action and section
+func printDigest(digest *notify.Digest) {
+	if output.Format() != output.FormatTable {
+		record := digestRecord{Date: digest.Date}
+		table := output.Table{Headers: []string{"SECTION", "ID", "NAME", "PROJECT", "DUE"}}
+		sections := []struct {
+			name    string
+			actions []database.Action
+			records *[]actionRecord
+		}{
+			{"due_today", digest.DueToday, &record.DueToday},
+			{"overdue", digest.Overdue, &record.Overdue},
+			{"completed_yesterday", digest.CompletedYesterday, &record.CompletedYesterday},
+			{"upcoming_repeats", digest.UpcomingRepeats, &record.UpcomingRepeats},
+		}
+		for _, section := range sections {
+			*section.records = []actionRecord{}
+			for _, action := range section.actions {
+				actionRecord := newActionRecord(action)
+				*section.records = append(*section.records, actionRecord)
+				table.AddRow(section.name, fmt.Sprint(actionRecord.ID), actionRecord.Name, actionRecord.Project, displayDate(actionRecord.DueDate))
+			}
+		}
+
+		if err := output.Print(record, table); err != nil {
+			fmt.Println(i18n.T("FailedToPrintDigest", i18n.Data{"Error": err}))
+		}
+		return
+	}
+
+	fmt.Println(i18n.T("DigestFor", i18n.Data{"Date": datefmt.Absolute(digest.Date)}))
+	if digest.IsEmpty() {
+		fmt.Println()
+		fmt.Println(i18n.T("DigestNothingDue"))
+		return
+	}
+
+	printDigestSection(i18n.T("DigestDueToday"), digest.DueToday, func(action database.Action) string { return "" })
+	printDigestSection(i18n.T("DigestOverdue"), digest.Overdue, func(action database.Action) string {
+		return i18n.T("DigestDue", i18n.Data{"Date": datefmt.Date(action.DueDate.String)})
+	})
+	printDigestSection(i18n.T("DigestCompletedYesterday"), digest.CompletedYesterday, func(action database.Action) string { return "" })
+	printDigestSection(i18n.T("DigestUpcomingRepeats"), digest.UpcomingRepeats, func(action database.Action) string {
+		return i18n.T("DigestDue", i18n.Data{"Date": datefmt.Date(action.DueDate.String)}) + ", " + action.RepeatDescription()
+	})
+}
+
+// printDigestSection prints a section of the digest with an optional detail per action
+func printDigestSection(title string, actions []database.Action, detail func(database.Action) string) {
+	if len(actions) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s (%d)\n", title, len(actions))
+	for _, action := range actions {
+		line := fmt.Sprintf("  %d. %s", action.ID, action.Name)
+		if action.ProjectName.Valid {
+			line += " [" + action.ProjectName.String + "]"
+		}
+		if extra := detail(action); extra != "" {
+			line += " (" + extra + ")"
+		}
+		fmt.Println(line)
+	}
+}
+
+// emailDigest sends the digest to every email channel of the config file
+func emailDigest(digest *notify.Digest) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	var names []string
+	for name, channel := range cfg.Notifications.Channels {
+		if channel.Type == "email" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println(i18n.T("NoEmailChannels"))
+		return
+	}
+	slices.Sort(names)
+
+	n := notify.Notification{
+		Event:   notify.EventDigest,
+		Title:   "Projector digest for " + digest.Date,
+		Message: notify.FormatDigest(digest),
+	}
+	for _, name := range names {
+		sender, err := notify.NewSender(cfg.Notifications.Channels[name])
+		if err != nil {
+			fmt.Println(i18n.T("ChannelError", i18n.Data{"Name": name, "Error": err}))
+			continue
+		}
+		if err := sender.Send(n); err != nil {
+			fmt.Println(i18n.T("FailedToEmailDigest", i18n.Data{"Name": name, "Error": err}))
+			continue
+		}
+		fmt.Println(i18n.T("EmailedDigest", i18n.Data{"Name": name}))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+func doctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the database for corruption and inconsistencies, repairing them with --fix",
+		Run: func(cmd *cobra.Command, args []string) {
+			fix, _ := cmd.Flags().GetBool("fix")
+			runDoctor(fix)
+		},
+	}
+
+	cmd.Flags().Bool("fix", false, "Repair the problems that can be fixed automatically")
+	return cmd
+}
+
+func runDoctor(fix bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	fmt.Println(i18n.T("CheckingDatabase"))
+	problems, err := database.CheckIntegrity(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("FailedToCheckDatabase", i18n.Data{"Error": err}))
+		return
+	}
+
+	if len(problems) == 0 {
+		fmt.Println(i18n.T("NoProblemsFound"))
+		return
+	}
+
+	fixable := 0
+	for _, problem := range problems {
+		if problem.Fixable() {
+			fixable++
+			fmt.Printf("  ⚠️ [%s] %s (fix: %s)\n", problem.Check, problem.Description, problem.Fix)
+		} else {
+			fmt.Printf("  ❌ [%s] %s\n", problem.Check, problem.Description)
+		}
+	}
+	fmt.Println()
+
+	if !fix {
+		fmt.Println(i18n.T("FoundProblems", i18n.Data{"Count": len(problems), "Fixable": fixable}))
+		return
+	}
+
+	if fixable > 0 {
+		// Keep a copy in case a fix removes more than intended
+		backupPath, err := database.BackupDatabase(database.GetDatabasePath(), database.GetBackupDir(database.GetDatabasePath()))
+		if err != nil {
+			fmt.Println(i18n.T("FailedToBackUpBeforeFixing", i18n.Data{"Error": err}))
+			return
+		}
+		fmt.Println(i18n.T("BackedUpDatabase", i18n.Data{"Path": backupPath}))
+	}
+
+	fixed, err := database.FixProblems(database.GetDatabasePath(), problems)
+	if err != nil {
+		fmt.Println(i18n.T("FailedToFixProblems", i18n.Data{"Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("FixedProblems", i18n.Data{"Count": fixed}))
+	if remaining := len(problems) - fixed; remaining > 0 {
+		fmt.Println(i18n.T("ProblemsLeft", i18n.Data{"Count": remaining}))
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/remote"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+func doneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "done [id]",
+		Short:             "Mark an action as done, picking it interactively when no ID is given",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, ok := remoteClient(cmd)
+			if !ok {
+				return
+			}
+
+			actionID, ok := argOrPickedAction(client, args, i18n.T("PickDone"))
+			if !ok {
+				return
+			}
+
+			var err error
+			if client != nil {
+				err = client.CompleteAction(actionID)
+				if queueOffline(err) {
+					return
+				}
+			} else {
+				err = database.MarkActionAsDone(database.GetDatabasePath(), actionID)
+			}
+			if err != nil {
+				fmt.Println(i18n.T("MarkDoneFailed", i18n.Data{"ID": actionID, "Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("MarkedDone", i18n.Data{"ID": actionID}))
+			if client == nil {
+				fireActionHook(hooks.ActionDone, actionID)
+			}
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
+
+// argOrPickedAction returns the action ID given as argument, or lets the user
+// pick an open action of the local database or the API server
+func argOrPickedAction(client *remote.Client, args []string, title string) (uint, bool) {
+	switch {
+	case client == nil && len(args) == 1:
+		return parseActionID(args[0])
+	case client == nil:
+		return pickOpenAction(title)
+	case len(args) == 1:
+		// There may be no local database to check
+		actionID, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			fmt.Println(i18n.T("InvalidActionID", i18n.Data{"ID": args[0]}))
+			return 0, false
+		}
+		return uint(actionID), true
+	default:
+		return pickRemoteAction(client, title)
+	}
+}
+
+// pickOpenAction opens a fuzzy picker over the open actions and returns the
+// ID of the chosen one. It returns false when there is nothing to pick, the
+// picker is cancelled, or stdin is not a terminal.
+func pickOpenAction(title string) (uint, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return 0, false
+	}
+
+	actions, err := database.GetAllActions(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return 0, false
+	}
+	return pickAction(title, actions)
+}
+
+// pickAction opens a fuzzy picker over the open actions of the list and
+// returns the ID of the chosen one
+func pickAction(title string, actions []database.Action) (uint, bool) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		fmt.Println(i18n.T("NoActionIDGiven"))
+		return 0, false
+	}
+
+	// Links are a convenience of the picker, so it opens without attachments
+	// when they cannot be read
+	attachments, _ := database.GetAllAttachments(database.GetDatabasePath())
+
+	var items []ui.PickerItem
+	for _, action := range actions {
+		if action.StatusName == "done" {
+			continue
+		}
+
+		details := []string{fmt.Sprintf("#%d", action.ID)}
+		if action.ProjectName.Valid {
+			details = append(details, action.ProjectName.String)
+		}
+		if action.DueDate.Valid {
+			details = append(details, datefmt.Date(action.DueDate.String))
+		}
+		var links []string
+		for _, link := range actionLinks(action.Note.String, attachments[action.ID]) {
+			links = append(links, link.URL)
+		}
+		items = append(items, ui.PickerItem{ID: action.ID, Label: action.Name, Detail: strings.Join(details, " · "), Note: action.Note.String, Links: links})
+	}
+
+	if len(items) == 0 {
+		fmt.Println(i18n.T("NoOpenActionsFound"))
+		return 0, false
+	}
+
+	chosen, err := ui.Pick(title, items)
+	if err != nil {
+		fmt.Println(i18n.T("PickerFailed", i18n.Data{"Error": err}))
+		return 0, false
+	}
+	if chosen == nil {
+		return 0, false // Cancelled
+	}
+
+	return chosen.ID, true
+}
@@ -0,0 +1,170 @@
+// Package escalate applies the escalation rules of the config file to
+// actions that are overdue by a number of days: it sets their priority, adds
+// a tag or sends a notification.
+package escalate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// logEvent is the event under which escalations are recorded in the
+// notification log, so that each escalation acts on an action only once
+const logEvent = "escalation"
+
+// Validate checks the escalations against the configured notification channels
+func Validate(escalations []config.Escalation, channels map[string]config.Channel) error {
+	names := map[string]bool{}
+	for i, escalation := range escalations {
+		if escalation.Name == "" {
+			return fmt.Errorf("escalation %d requires a name", i+1)
+		}
+		if names[escalation.Name] {
+			return fmt.Errorf("duplicate escalation name: %s", escalation.Name)
+		}
+		names[escalation.Name] = true
+
+		if escalation.Days < 1 {
+			return fmt.Errorf("escalation %s requires days of at least 1", escalation.Name)
+		}
+		if escalation.Priority == "" && escalation.Tag == "" && escalation.Channel == "" {
+			return fmt.Errorf("escalation %s requires a priority, tag or channel", escalation.Name)
+		}
+		if strings.ContainsAny(escalation.Priority+escalation.Tag, " ,") {
+			return fmt.Errorf("escalation %s: priority and tag cannot contain spaces or commas", escalation.Name)
+		}
+		if escalation.Channel != "" {
+			if _, ok := channels[escalation.Channel]; !ok {
+				return fmt.Errorf("escalation %s uses unknown channel: %s", escalation.Name, escalation.Channel)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply escalates the open actions that are overdue by at least the days of
+// an escalation. An escalation acts on an action once for every due date it
+// passed, so postponing an action lets it escalate again. The dispatcher
+// sends the notifications and may be nil when no escalation has a channel.
+// It returns the number of escalations applied.
+func Apply(dbPath string, escalations []config.Escalation, d *notify.Dispatcher, now time.Time) (int, error) {
+	if len(escalations) == 0 {
+		return 0, nil
+	}
+
+	actions, err := database.GetOverdueActions(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving overdue actions: %v", err)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	applied := 0
+	for _, action := range actions {
+		dueDate := database.StoredDate(action.DueDate.String)
+		due, err := database.ParseStoredDate(dueDate)
+		if err != nil {
+			continue
+		}
+		daysOverdue := int(today.Sub(due).Hours() / 24)
+
+		for _, escalation := range escalations {
+			if daysOverdue < escalation.Days {
+				continue
+			}
+			if escalation.Project != "" && escalation.Project != action.ProjectName.String {
+				continue
+			}
+
+			key := fmt.Sprintf("%d/%s", action.ID, dueDate)
+			already, err := database.NotificationSent(dbPath, logEvent, escalation.Name, key)
+			if err != nil {
+				return applied, err
+			}
+			if already {
+				continue
+			}
+
+			if err := escalate(dbPath, &action, escalation, daysOverdue, d); err != nil {
+				return applied, fmt.Errorf("escalation %s: %v", escalation.Name, err)
+			}
+			if err := database.RecordNotification(dbPath, logEvent, escalation.Name, key); err != nil {
+				return applied, err
+			}
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+// escalate applies a single escalation to an action
+func escalate(dbPath string, action *database.Action, escalation config.Escalation, daysOverdue int, d *notify.Dispatcher) error {
+	tags := slices.Clone(action.Tags)
+	if escalation.Priority != "" {
+		// An action has one priority, the escalation replaces it
+		tags = slices.DeleteFunc(tags, func(tag string) bool {
+			return strings.HasPrefix(tag, quickadd.PriorityTagPrefix)
+		})
+		tags = append(tags, quickadd.PriorityTagPrefix+escalation.Priority)
+	}
+	if escalation.Tag != "" && !slices.Contains(tags, escalation.Tag) {
+		tags = append(tags, escalation.Tag)
+	}
+	if !slices.Equal(tags, action.Tags) {
+		if err := database.SetActionTags(dbPath, action.ID, tags); err != nil {
+			return err
+		}
+		// Later escalations of the same run build on these tags
+		action.Tags = tags
+	}
+
+	if escalation.Channel == "" {
+		return nil
+	}
+	if d == nil {
+		return fmt.Errorf("notifications are not configured")
+	}
+	// A disabled channel, digest_only or quiet hours leave the escalation silent
+	if !d.Allows(escalation.Channel, notify.EventEscalated, time.Now()) {
+		return nil
+	}
+
+	message := fmt.Sprintf("#%d %s", action.ID, action.Name)
+	if action.ProjectName.Valid {
+		message += " [" + action.ProjectName.String + "]"
+	}
+	message += fmt.Sprintf(" is %d day(s) overdue, it was due on %s", daysOverdue, datefmt.Date(action.DueDate.String))
+
+	n := notify.Notification{
+		Event:   notify.EventEscalated,
+		Title:   "Overdue action escalated",
+		Message: message,
+	}
+	return d.SendTo(escalation.Channel, n)
+}
+
+// Run applies the escalations every hour until stop is closed
+func Run(dbPath string, escalations []config.Escalation, d *notify.Dispatcher, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if _, err := Apply(dbPath, escalations, d, time.Now()); err != nil {
+			onError(err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/escalate"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/notify"
+
+	"github.com/spf13/cobra"
+)
+
+func escalateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "escalate",
+		Short: "Apply the escalation rules of the config file to overdue actions",
+		Long: `Apply the escalation rules of the config file to the open actions that are
+overdue by their number of days: set a priority, add a tag or send a
+notification. The API server does this every hour; run this command from cron
+instead when the server is not running.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			if len(cfg.Escalations) == 0 {
+				fmt.Println(i18n.T("NoEscalations"))
+				return
+			}
+			if err := escalate.Validate(cfg.Escalations, cfg.Notifications.Channels); err != nil {
+				fmt.Println(i18n.T("InvalidEscalationConfig", i18n.Data{"Error": err}))
+				return
+			}
+
+			dispatcher, err := notify.NewDispatcher(cfg.Notifications)
+			if err != nil {
+				fmt.Println(i18n.T("InvalidNotificationConfig", i18n.Data{"Error": err}))
+				return
+			}
+
+			applied, err := escalate.Apply(database.GetDatabasePath(), cfg.Escalations, dispatcher, time.Now())
+			if err != nil {
+				fmt.Println(i18n.T("FailedToEscalateActions", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("AppliedEscalations", i18n.Data{"Count": applied}))
+		},
+	}
+}
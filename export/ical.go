@@ -0,0 +1,199 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// icalWeekdays maps weekday numbers to their iCalendar BYDAY names
+var icalWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// icalFrequencies maps repeat intervals to their iCalendar FREQ values
+var icalFrequencies = map[string]string{
+	"minute": "MINUTELY",
+	"hour":   "HOURLY",
+	"day":    "DAILY",
+	"week":   "WEEKLY",
+	"month":  "MONTHLY",
+	"year":   "YEARLY",
+}
+
+// WriteICal writes an iCalendar feed with a VTODO for every action with a due
+// date and a VEVENT for every project deadline
+func WriteICal(w io.Writer, actions []database.Action, projects []database.Project) error {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//joelgrimberg//projector//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:Projector",
+	}
+
+	for _, action := range actions {
+		if !action.DueDate.Valid || action.DueDate.String == "" {
+			continue
+		}
+		lines = append(lines, actionTodoLines(action, fmt.Sprintf("action-%d@projector", action.ID), stamp)...)
+	}
+
+	for _, project := range projects {
+		if !project.DueDate.Valid || project.DueDate.String == "" {
+			continue
+		}
+		due, err := database.ParseStoredDate(project.DueDate.String)
+		if err != nil {
+			continue
+		}
+
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:project-%d@projector", project.ID),
+			"DTSTAMP:"+stamp,
+			"SUMMARY:"+escapeICalText("Project deadline: "+project.Name),
+			"DTSTART;VALUE=DATE:"+due.Format("20060102"),
+			"DTEND;VALUE=DATE:"+due.AddDate(0, 0, 1).Format("20060102"),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+	return writeICalLines(w, lines)
+}
+
+// WriteActionICal writes an iCalendar object holding a single VTODO for the
+// action, as stored on CalDAV servers
+func WriteActionICal(w io.Writer, action database.Action, uid string) error {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//joelgrimberg//projector//EN",
+	}
+	lines = append(lines, actionTodoLines(action, uid, stamp)...)
+	lines = append(lines, "END:VCALENDAR")
+
+	return writeICalLines(w, lines)
+}
+
+// actionTodoLines builds the VTODO component lines for an action
+func actionTodoLines(action database.Action, uid, stamp string) []string {
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + uid,
+		"DTSTAMP:" + stamp,
+		"SUMMARY:" + escapeICalText(action.Name),
+	}
+
+	due := ""
+	if action.DueDate.Valid && action.DueDate.String != "" {
+		if date, err := database.ParseStoredDate(action.DueDate.String); err == nil {
+			due = date.Format("20060102")
+			lines = append(lines, "DUE;VALUE=DATE:"+due)
+		}
+	}
+	if action.Note.Valid && action.Note.String != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeICalText(action.Note.String))
+	}
+	if action.ProjectName.Valid && action.ProjectName.String != "" {
+		lines = append(lines, "CATEGORIES:"+escapeICalText(action.ProjectName.String))
+	}
+
+	if action.StatusName == "done" {
+		lines = append(lines, "STATUS:COMPLETED")
+	} else {
+		lines = append(lines, "STATUS:NEEDS-ACTION")
+		// Only the pending occurrence carries the recurrence rule. It needs
+		// a DTSTART to start from, which is the due date of the occurrence.
+		if rule := icalRecurrenceRule(action); rule != "" && due != "" {
+			lines = append(lines, "DTSTART;VALUE=DATE:"+due, "RRULE:"+rule)
+		}
+	}
+
+	return append(lines, "END:VTODO")
+}
+
+// writeICalLines writes folded content lines terminated by CRLF
+func writeICalLines(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := io.WriteString(w, foldICalLine(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// icalRecurrenceRule builds the RRULE value for a repeating action
+func icalRecurrenceRule(action database.Action) string {
+	if !action.IsRepeating() {
+		return ""
+	}
+
+	freq, ok := icalFrequencies[action.RepeatInterval.String]
+	if !ok {
+		return ""
+	}
+
+	rule := "FREQ=" + freq
+
+	if action.RepeatInterval.String == "week" && action.RepeatPattern.Valid {
+		var days []string
+		for _, day := range database.ParseWeeklyPattern(action.RepeatPattern.String) {
+			days = append(days, icalWeekdays[day])
+		}
+		if len(days) > 0 {
+			rule += ";BYDAY=" + strings.Join(days, ",")
+		}
+	}
+
+	switch action.EffectiveRepeatMode() {
+	case database.RepeatModeCount:
+		// The count holds the remaining repetitions, the rule counts this occurrence too
+		rule += fmt.Sprintf(";COUNT=%d", action.RepeatCount+1)
+	case database.RepeatModeUntil:
+		if until, err := database.ParseStoredDate(action.RepeatUntil.String); err == nil {
+			rule += ";UNTIL=" + until.Format("20060102")
+		}
+	}
+
+	return rule
+}
+
+// escapeICalText escapes a TEXT value as described in RFC 5545 section 3.3.11
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// foldICalLine folds lines longer than 75 octets as described in RFC 5545 section 3.1
+func foldICalLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
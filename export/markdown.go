@@ -0,0 +1,88 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// WriteMarkdown writes the projects and their actions as a Markdown document,
+// with a checkbox per action. Actions without a project are listed last.
+func WriteMarkdown(w io.Writer, actions []database.Action, projects []database.Project) error {
+	var b strings.Builder
+	b.WriteString("# Projects\n")
+
+	for _, project := range projects {
+		b.WriteString("\n## " + project.Name + "\n\n")
+		if project.DueDate.Valid && project.DueDate.String != "" {
+			fmt.Fprintf(&b, "Due: %s\n\n", database.StoredDate(project.DueDate.String))
+		}
+
+		count := 0
+		for _, action := range actions {
+			if action.ProjectID.Valid && uint(action.ProjectID.Int64) == project.ID {
+				writeMarkdownAction(&b, action)
+				count++
+			}
+		}
+		if count == 0 {
+			b.WriteString("_No actions_\n")
+		}
+	}
+
+	var unassigned []database.Action
+	for _, action := range actions {
+		if !action.ProjectID.Valid {
+			unassigned = append(unassigned, action)
+		}
+	}
+	if len(unassigned) > 0 {
+		b.WriteString("\n## No project\n\n")
+		for _, action := range unassigned {
+			writeMarkdownAction(&b, action)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownAction writes a single action as a task list item, with its
+// note indented below it
+func writeMarkdownAction(b *strings.Builder, action database.Action) {
+	checkbox := "[ ]"
+	if action.StatusName == "done" {
+		checkbox = "[x]"
+	}
+	fmt.Fprintf(b, "- %s %s", checkbox, escapeMarkdown(action.Name))
+
+	var details []string
+	if action.DueDate.Valid && action.DueDate.String != "" {
+		details = append(details, "due "+database.StoredDate(action.DueDate.String))
+	}
+	if action.IsRepeating() {
+		details = append(details, action.RepeatDescription())
+	}
+	if len(details) > 0 {
+		b.WriteString(" (" + strings.Join(details, ", ") + ")")
+	}
+	for _, tag := range action.Tags {
+		b.WriteString(" #" + strings.ReplaceAll(tag, " ", "-"))
+	}
+	b.WriteString("\n")
+
+	if action.Note.Valid && strings.TrimSpace(action.Note.String) != "" {
+		for _, line := range strings.Split(strings.TrimSpace(action.Note.String), "\n") {
+			b.WriteString("  " + strings.TrimRight(line, "\r") + "\n")
+		}
+	}
+}
+
+// escapeMarkdown escapes characters that would otherwise be rendered as
+// Markdown formatting in an action name
+func escapeMarkdown(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/export"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/taskwarrior"
+	"github.com/joelgrimberg/projector/todotxt"
+
+	"github.com/spf13/cobra"
+)
+
+func exportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export actions and projects to another format",
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			projectID, _ := cmd.Flags().GetUint("project")
+			runExport(format, output, projectID)
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", "ics", "Export format: ics, taskwarrior, todotxt or markdown")
+	cmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	cmd.Flags().UintP("project", "p", 0, "Only export the project with this ID")
+	cmd.RegisterFlagCompletionFunc("project", completeProjectIDs)
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"ics", "taskwarrior", "todotxt", "markdown"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func runExport(format, output string, projectID uint) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	actions, err := database.GetAllActions(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return
+	}
+
+	projects, err := database.GetAllProjects(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingProjects", i18n.Data{"Error": err}))
+		return
+	}
+
+	if projectID != 0 {
+		project, err := database.GetProjectByID(database.GetDatabasePath(), projectID)
+		if err != nil {
+			fmt.Println(i18n.T("ErrorRetrievingProject", i18n.Data{"Error": err}))
+			return
+		}
+		if project == nil {
+			fmt.Println(i18n.T("ProjectNotFound", i18n.Data{"Name": projectID}))
+			return
+		}
+		projects = []database.Project{*project}
+
+		var filtered []database.Action
+		for _, action := range actions {
+			if action.ProjectID.Valid && uint(action.ProjectID.Int64) == projectID {
+				filtered = append(filtered, action)
+			}
+		}
+		actions = filtered
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			fmt.Println(i18n.T("FailedToCreateOutputFile", i18n.Data{"Error": err}))
+			return
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch format {
+	case "ics":
+		err = export.WriteICal(w, actions, projects)
+	case "taskwarrior":
+		err = taskwarrior.Export(w, actions)
+	case "todotxt":
+		err = todotxt.Export(w, actions)
+	case "markdown", "md":
+		err = export.WriteMarkdown(w, actions, projects)
+	default:
+		fmt.Println(i18n.T("UnknownExportFormat", i18n.Data{"Format": format}))
+		return
+	}
+
+	if err != nil {
+		fmt.Println(i18n.T("ExportFailed", i18n.Data{"Error": err}))
+		return
+	}
+
+	if output != "" {
+		fmt.Println(i18n.T("Exported", i18n.Data{"Path": output}))
+	}
+}
@@ -0,0 +1,236 @@
+// Package filter parses and evaluates filter expressions for actions, such as
+//
+//	status:todo AND (tag:home OR project:"House") AND due<=+7d
+//
+// Terms are a field, an operator and a value. Terms next to each other must
+// all match, as if joined by AND. NOT negates a term and parentheses group.
+// A word without a field searches the action names.
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// Fields are the fields that can be filtered on
+var Fields = []string{"status", "tag", "project", "due", "start", "name", "note", "assignee", "location", "energy"}
+
+// Filter is a parsed filter expression
+type Filter struct {
+	query  string
+	root   node
+	fields map[string]bool
+}
+
+// node is a part of a parsed expression
+type node interface {
+	match(action *database.Action) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ node node }
+
+// termNode compares a field of an action with a value. Dates are resolved
+// when the expression is parsed.
+type termNode struct {
+	field string
+	op    string
+	value string
+}
+
+func (n andNode) match(action *database.Action) bool {
+	return n.left.match(action) && n.right.match(action)
+}
+
+func (n orNode) match(action *database.Action) bool {
+	return n.left.match(action) || n.right.match(action)
+}
+
+func (n notNode) match(action *database.Action) bool {
+	return !n.node.match(action)
+}
+
+func (n termNode) match(action *database.Action) bool {
+	switch n.field {
+	case "status":
+		// open matches every status but done
+		equal := strings.EqualFold(action.StatusName, n.value) || (n.value == "open" && action.StatusName != "done")
+		return equal == (n.op != "!=")
+	case "tag":
+		var found bool
+		if n.value == "none" {
+			found = len(action.Tags) == 0
+		} else {
+			found = slices.ContainsFunc(action.Tags, func(tag string) bool { return strings.EqualFold(tag, n.value) })
+		}
+		return found == (n.op != "!=")
+	case "project":
+		return optionalEquals(action.ProjectName.String, n.value) == (n.op != "!=")
+	case "assignee":
+		return optionalEquals(action.AssigneeName.String, n.value) == (n.op != "!=")
+	case "name":
+		return textMatches(action.Name, n.op, n.value)
+	case "note":
+		return textMatches(action.Note.String, n.op, n.value)
+	case "location":
+		if n.value == "none" {
+			return optionalEquals(action.Location.String, n.value) == (n.op != "!=")
+		}
+		return textMatches(action.Location.String, n.op, n.value)
+	case "due":
+		return dateMatches(database.StoredDate(action.DueDate.String), n.op, n.value)
+	case "start":
+		return dateMatches(database.StoredDate(action.StartDate.String), n.op, n.value)
+	case "energy":
+		return energyMatches(action.Energy.String, n.op, n.value)
+	}
+	return false
+}
+
+// optionalEquals compares an optional name, where none matches no name
+func optionalEquals(actual, value string) bool {
+	if value == "none" {
+		return actual == ""
+	}
+	return strings.EqualFold(actual, value)
+}
+
+// textMatches compares a text: : searches it, = and != compare all of it
+func textMatches(text, op, value string) bool {
+	switch op {
+	case "=":
+		return strings.EqualFold(text, value)
+	case "!=":
+		return !strings.EqualFold(text, value)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(value))
+}
+
+// dateMatches compares a due or start date (YYYY-MM-DD or empty) with a
+// resolved date, or with none. Actions without the date only match none.
+func dateMatches(date, op, value string) bool {
+	if value == "none" {
+		return (date == "") == (op != "!=")
+	}
+	if date == "" {
+		return false
+	}
+
+	switch op {
+	case "<":
+		return date < value
+	case "<=":
+		return date <= value
+	case ">":
+		return date > value
+	case ">=":
+		return date >= value
+	case "!=":
+		return date != value
+	}
+	return date == value
+}
+
+// energyMatches compares an energy level with low, medium, high or none.
+// Actions without an energy level only match energy:none.
+func energyMatches(energy, op, value string) bool {
+	if value == "none" {
+		return (energy == "") == (op != "!=")
+	}
+	if energy == "" {
+		return false
+	}
+
+	rank, other := database.EnergyRank(energy), database.EnergyRank(value)
+	switch op {
+	case "<":
+		return rank < other
+	case "<=":
+		return rank <= other
+	case ">":
+		return rank > other
+	case ">=":
+		return rank >= other
+	case "!=":
+		return rank != other
+	}
+	return rank == other
+}
+
+// Parse parses a filter expression. Relative dates such as today or +7d are
+// resolved against now.
+func Parse(query string, now time.Time) (*Filter, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+
+	p := &parser{tokens: tokens, now: now, fields: map[string]bool{}}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s at position %d", p.tokens[p.pos].text, p.tokens[p.pos].offset+1)
+	}
+
+	return &Filter{query: query, root: root, fields: p.fields}, nil
+}
+
+// Match reports whether an action matches the filter
+func (f *Filter) Match(action *database.Action) bool {
+	return f.root.match(action)
+}
+
+// Apply returns the actions that match the filter
+func (f *Filter) Apply(actions []database.Action) []database.Action {
+	matched := []database.Action{}
+	for i := range actions {
+		if f.Match(&actions[i]) {
+			matched = append(matched, actions[i])
+		}
+	}
+	return matched
+}
+
+// Uses reports whether the expression filters on a field
+func (f *Filter) Uses(field string) bool {
+	return f.fields[field]
+}
+
+// String returns the expression the filter was parsed from
+func (f *Filter) String() string {
+	return f.query
+}
+
+// And combines two filters into one that matches when both match. Either
+// filter may be nil.
+func And(a, b *Filter) *Filter {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	fields := map[string]bool{}
+	for field := range a.fields {
+		fields[field] = true
+	}
+	for field := range b.fields {
+		fields[field] = true
+	}
+	return &Filter{
+		query:  "(" + a.query + ") AND (" + b.query + ")",
+		root:   andNode{a.root, b.root},
+		fields: fields,
+	}
+}
@@ -0,0 +1,312 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// tokenKind is the kind of a token of a filter expression
+type tokenKind int
+
+const (
+	tokenLeftParen tokenKind = iota
+	tokenRightParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenTerm // field, operator and value
+	tokenWord // searches the action names
+)
+
+// token is a token of a filter expression. Offset is its byte position in
+// the expression, for error messages.
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+	field  string
+	op     string
+	value  string
+}
+
+// operators are the comparison operators, longest first
+var operators = []string{"!=", "<=", ">=", ":", "=", "<", ">"}
+
+// tokenize splits a filter expression into tokens
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, text: "(", offset: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, text: ")", offset: i})
+			i++
+		case c == '"':
+			value, next, err := readQuoted(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: query[i:next], offset: i, value: value})
+			i = next
+		default:
+			term, next, err := readTerm(query, i)
+			if err != nil {
+				return nil, err
+			}
+			if term != nil {
+				tokens = append(tokens, *term)
+				i = next
+				continue
+			}
+
+			next = wordEnd(query, i)
+			word := query[i:next]
+			kind := tokenWord
+			switch strings.ToUpper(word) {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, offset: i, value: word})
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// readTerm reads a field, operator and value starting at i. It returns nil
+// when the text at i is not a term.
+func readTerm(query string, i int) (*token, int, error) {
+	start := i
+	for i < len(query) && (query[i] >= 'a' && query[i] <= 'z' || query[i] >= 'A' && query[i] <= 'Z' || query[i] == '_') {
+		i++
+	}
+	field := strings.ToLower(query[start:i])
+	if field == "" {
+		return nil, 0, nil
+	}
+
+	var op string
+	for _, candidate := range operators {
+		if strings.HasPrefix(query[i:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, 0, nil
+	}
+	i += len(op)
+
+	var value string
+	if i < len(query) && query[i] == '"' {
+		quoted, next, err := readQuoted(query, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, i = quoted, next
+	} else {
+		next := wordEnd(query, i)
+		value, i = query[i:next], next
+	}
+	if value == "" {
+		return nil, 0, fmt.Errorf("missing value for %s at position %d", field, start+1)
+	}
+
+	return &token{kind: tokenTerm, text: query[start:i], offset: start, field: field, op: op, value: value}, i, nil
+}
+
+// readQuoted reads a double-quoted string starting at i, returning its
+// content and the position after the closing quote
+func readQuoted(query string, i int) (string, int, error) {
+	end := strings.IndexByte(query[i+1:], '"')
+	if end < 0 {
+		return "", 0, fmt.Errorf("unterminated quote at position %d", i+1)
+	}
+	return query[i+1 : i+1+end], i + end + 2, nil
+}
+
+// wordEnd returns the end of the word starting at i
+func wordEnd(query string, i int) int {
+	for i < len(query) && !strings.ContainsRune(" \t\n()", rune(query[i])) {
+		i++
+	}
+	return i
+}
+
+// parser is a recursive descent parser for filter expressions:
+//
+//	or      = and { OR and }
+//	and     = not { [AND] not }
+//	not     = NOT not | primary
+//	primary = ( or ) | term | word
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+	fields map[string]bool
+}
+
+// peek returns the next token, or nil at the end of the expression
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for next := p.peek(); next != nil && next.kind == tokenOr; next = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		next := p.peek()
+		if next == nil || next.kind == tokenOr || next.kind == tokenRightParen {
+			return left, nil
+		}
+		// Terms next to each other are joined by an implicit AND
+		if next.kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if next := p.peek(); next != nil && next.kind == tokenNot {
+		p.pos++
+		negated, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{negated}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	next := p.peek()
+	if next == nil {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	p.pos++
+
+	switch next.kind {
+	case tokenLeftParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != tokenRightParen {
+			return nil, fmt.Errorf("missing ) for ( at position %d", next.offset+1)
+		}
+		p.pos++
+		return inner, nil
+	case tokenTerm:
+		return p.term(next)
+	case tokenWord:
+		p.fields["name"] = true
+		return termNode{field: "name", op: ":", value: next.value}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s at position %d", next.text, next.offset+1)
+}
+
+// term validates a term and resolves its value
+func (p *parser) term(t *token) (node, error) {
+	if !slices.Contains(Fields, t.field) {
+		return nil, fmt.Errorf("unknown field %s at position %d. Expected one of: %s", t.field, t.offset+1, strings.Join(Fields, ", "))
+	}
+	p.fields[t.field] = true
+
+	value := t.value
+	if strings.EqualFold(value, "none") {
+		value = "none"
+	}
+
+	switch t.field {
+	case "due", "start":
+		if value == "none" {
+			if t.op != ":" && t.op != "=" && t.op != "!=" {
+				return nil, fmt.Errorf("%s:none at position %d can only be compared with :, = or !=", t.field, t.offset+1)
+			}
+			break
+		}
+		date, err := resolveDate(value, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("%v at position %d", err, t.offset+1)
+		}
+		value = date
+	case "energy":
+		if value == "none" {
+			if t.op != ":" && t.op != "=" && t.op != "!=" {
+				return nil, fmt.Errorf("energy:none at position %d can only be compared with :, = or !=", t.offset+1)
+			}
+			break
+		}
+		energy, err := database.ValidateEnergy(value)
+		if err != nil {
+			return nil, fmt.Errorf("%v at position %d", err, t.offset+1)
+		}
+		value = energy
+	default:
+		if t.op != ":" && t.op != "=" && t.op != "!=" {
+			return nil, fmt.Errorf("%s at position %d can only be compared with :, = or !=", t.field, t.offset+1)
+		}
+		if t.field == "status" {
+			value = strings.ToLower(value)
+		}
+	}
+
+	return termNode{field: t.field, op: t.op, value: value}, nil
+}
+
+// resolveDate resolves the date of a due or start term: YYYY-MM-DD, today, tomorrow,
+// yesterday, a day name, or an offset from today such as +7d, -2w or +1m
+func resolveDate(value string, now time.Time) (string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch {
+	case strings.EqualFold(value, "yesterday"):
+		return today.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	case strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-"):
+		date, err := database.ResolveDateOffset("start"+strings.ToLower(value), today)
+		if err != nil {
+			return "", fmt.Errorf("invalid date offset: %s. Expected a number of days, weeks or months such as +7d, -2w or +1m", value)
+		}
+		return date, nil
+	}
+	return quickadd.ParseDate(value, now)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// savedFilterRecord is the JSON form of a saved filter in command output
+type savedFilterRecord struct {
+	Name      string `json:"name"`
+	Query     string `json:"query"`
+	CreatedAt string `json:"created_at"`
+}
+
+func filterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "filter",
+		Short: "Save filter queries and list them",
+		Long: `Save filter queries under a name, to list their actions with
+'projector list --saved <name>' or the saved parameter of the HTTP API.
+See 'projector list --help' for the query language.`,
+	}
+
+	cmd.AddCommand(filterSaveCmd())
+	cmd.AddCommand(filterListCmd())
+	cmd.AddCommand(filterDeleteCmd())
+	return cmd
+}
+
+func filterSaveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "save <name> <query>",
+		Short: "Save a filter query under a name, replacing an existing one",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			if _, err := filter.Parse(args[1], time.Now()); err != nil {
+				fmt.Println(i18n.T("InvalidQuery", i18n.Data{"Error": err}))
+				return
+			}
+
+			if err := database.SaveFilter(database.GetDatabasePath(), args[0], args[1]); err != nil {
+				fmt.Println(i18n.T("FailedToSaveFilter", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("SavedFilter", i18n.Data{"Name": args[0]}))
+		},
+	}
+}
+
+func filterListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List saved filters",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			filters, err := database.GetAllSavedFilters(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingFilters", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []savedFilterRecord{}
+			table := output.Table{Headers: []string{"NAME", "QUERY", "CREATED"}}
+			for _, savedFilter := range filters {
+				record := savedFilterRecord{Name: savedFilter.Name, Query: savedFilter.Query, CreatedAt: savedFilter.CreatedAt}
+				records = append(records, record)
+				table.AddRow(record.Name, record.Query, record.CreatedAt)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoSavedFilters"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintFilters", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func filterDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "Delete a saved filter",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFilterNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			savedFilter, ok := lookupSavedFilter(args[0])
+			if !ok {
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldDeleteFilter", i18n.Data{"Name": savedFilter.Name, "Query": savedFilter.Query}))
+				return
+			}
+			if !confirm(cmd, i18n.T("DeleteFilterConfirm", i18n.Data{"Name": savedFilter.Name})) {
+				return
+			}
+
+			if err := database.DeleteSavedFilter(database.GetDatabasePath(), savedFilter.Name); err != nil {
+				fmt.Println(i18n.T("FailedToDeleteFilter", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("DeletedFilter", i18n.Data{"Name": savedFilter.Name}))
+		},
+	}
+}
+
+// lookupSavedFilter retrieves a saved filter by name, printing an error when
+// it cannot be found
+func lookupSavedFilter(name string) (*database.SavedFilter, bool) {
+	savedFilter, err := database.GetSavedFilter(database.GetDatabasePath(), name)
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingFilter", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if savedFilter == nil {
+		fmt.Println(i18n.T("FilterNotFound", i18n.Data{"Name": name}))
+		return nil, false
+	}
+	return savedFilter, true
+}
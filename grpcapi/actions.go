@@ -0,0 +1,223 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/grpcapi/projectorpb"
+	"github.com/joelgrimberg/projector/hooks"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// actionService implements projectorpb.ActionServiceServer
+type actionService struct {
+	projectorpb.UnimplementedActionServiceServer
+	server *Server
+}
+
+func (a *actionService) ListActions(ctx context.Context, req *projectorpb.ListActionsRequest) (*projectorpb.ListActionsResponse, error) {
+	var actions []database.Action
+	var err error
+	if user := currentUser(ctx); user != nil {
+		actions, err = database.GetActionsForUser(a.server.dbPath, user.ID)
+	} else {
+		actions, err = database.GetAllActions(a.server.dbPath)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving actions: %v", err)
+	}
+
+	response := &projectorpb.ListActionsResponse{}
+	for _, action := range actions {
+		if req.ProjectId != nil && uint32(action.ProjectID.Int64) != *req.ProjectId {
+			continue
+		}
+		response.Actions = append(response.Actions, actionToProto(action))
+	}
+	return response, nil
+}
+
+func (a *actionService) GetAction(ctx context.Context, req *projectorpb.GetActionRequest) (*projectorpb.Action, error) {
+	action, err := a.server.accessibleAction(ctx, uint(req.Id), database.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return actionToProto(*action), nil
+}
+
+func (a *actionService) CreateAction(ctx context.Context, req *projectorpb.CreateActionRequest) (*projectorpb.Action, error) {
+	var projectID *uint
+	if req.ProjectId != 0 {
+		if _, err := a.server.accessibleProject(ctx, uint(req.ProjectId), database.RoleEditor); err != nil {
+			return nil, err
+		}
+		id := uint(req.ProjectId)
+		projectID = &id
+	}
+
+	statusID := uint(req.StatusId)
+	if statusID == 0 {
+		defaultID, err := database.DefaultStatusID(a.server.dbPath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error reading default status: %v", err)
+		}
+		statusID = defaultID
+	}
+
+	// The assignee is set after the action is created, so it is validated
+	// with the other fields first
+	input := database.ActionInput{Name: req.Name, ProjectID: projectID, StatusID: statusID, DueDate: req.DueDate, AssigneeID: optionalUint(&req.AssigneeId)}
+	if err := database.ValidateAction(a.server.dbPath, &input); err != nil {
+		return nil, invalidArgument(err, "error creating action")
+	}
+
+	var ownerID uint
+	if user := currentUser(ctx); user != nil {
+		ownerID = user.ID
+	}
+	err := database.CheckNewActionWIPLimit(a.server.dbPath, statusID, uint(req.ProjectId), ownerID)
+	var limitErr *database.WIPLimitError
+	if errors.As(err, &limitErr) {
+		return nil, status.Errorf(codes.FailedPrecondition, "work-in-progress limit reached: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking work-in-progress limits: %v", err)
+	}
+
+	actionID, err := database.CreateAction(a.server.dbPath, req.Name, req.Note, projectID, req.DueDate, statusID, "", 0, "", "", "", nil)
+	if err != nil {
+		return nil, invalidArgument(err, "error creating action")
+	}
+
+	if user := currentUser(ctx); user != nil {
+		if err := database.SetActionOwner(a.server.dbPath, actionID, user.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "error setting action owner: %v", err)
+		}
+	}
+
+	if req.AssigneeId != 0 {
+		assigneeID := uint(req.AssigneeId)
+		if err := database.UpdateAction(a.server.dbPath, actionID, database.ActionUpdate{AssigneeID: &assigneeID}); err != nil {
+			return nil, invalidArgument(err, "error assigning action")
+		}
+		// The request is a single write, with or without an assignee
+		if err := database.ResetActionVersion(a.server.dbPath, actionID); err != nil {
+			return nil, status.Errorf(codes.Internal, "error creating action: %v", err)
+		}
+	}
+
+	a.server.applyRules(nil, actionID)
+	a.server.runActionHooksByID(hooks.ActionCreated, actionID)
+	return a.reload(actionID)
+}
+
+func (a *actionService) UpdateAction(ctx context.Context, req *projectorpb.UpdateActionRequest) (*projectorpb.Action, error) {
+	previous, err := a.server.accessibleAction(ctx, uint(req.Id), database.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	update := database.ActionUpdate{
+		Name:       req.Name,
+		Note:       req.Note,
+		DueDate:    req.DueDate,
+		ProjectID:  optionalUint(req.ProjectId),
+		StatusID:   optionalUint(req.StatusId),
+		AssigneeID: optionalUint(req.AssigneeId),
+	}
+
+	// Moving the action requires editing rights in the new project
+	if update.ProjectID != nil && *update.ProjectID != 0 {
+		if _, err := a.server.accessibleProject(ctx, *update.ProjectID, database.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	// Unlike the REST API, gRPC has no way to exceed a work-in-progress limit
+	err = database.CheckWIPLimit(a.server.dbPath, uint(req.Id), update)
+	var limitErr *database.WIPLimitError
+	if errors.As(err, &limitErr) {
+		return nil, status.Errorf(codes.FailedPrecondition, "work-in-progress limit reached: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking work-in-progress limits: %v", err)
+	}
+
+	if err := database.UpdateAction(a.server.dbPath, uint(req.Id), update); err != nil {
+		return nil, invalidArgument(err, "error updating action")
+	}
+
+	a.server.applyRules(previous, uint(req.Id))
+	a.server.runActionHooksByID(hooks.ActionUpdated, uint(req.Id))
+	return a.reload(uint(req.Id))
+}
+
+func (a *actionService) CompleteAction(ctx context.Context, req *projectorpb.CompleteActionRequest) (*projectorpb.Action, error) {
+	if _, err := a.server.accessibleAction(ctx, uint(req.Id), database.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	err := database.MarkActionAsDone(a.server.dbPath, uint(req.Id))
+	var transitionErr *database.TransitionError
+	if errors.As(err, &transitionErr) {
+		return nil, status.Errorf(codes.FailedPrecondition, "error marking action as done: %v", err)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error marking action as done: %v", err)
+	}
+
+	a.server.runActionHooksByID(hooks.ActionDone, uint(req.Id))
+	return a.reload(uint(req.Id))
+}
+
+func (a *actionService) DeleteAction(ctx context.Context, req *projectorpb.DeleteActionRequest) (*projectorpb.DeleteActionResponse, error) {
+	action, err := a.server.accessibleAction(ctx, uint(req.Id), database.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DeleteAction(a.server.dbPath, uint(req.Id)); err != nil {
+		return nil, status.Errorf(codes.Internal, "error deleting action: %v", err)
+	}
+	a.server.runActionHooks(hooks.ActionDeleted, action)
+	return &projectorpb.DeleteActionResponse{}, nil
+}
+
+// reload retrieves an action after a change and converts it to its protobuf message
+func (a *actionService) reload(actionID uint) (*projectorpb.Action, error) {
+	action, err := database.GetActionByID(a.server.dbPath, actionID)
+	if err != nil || action == nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving action: %v", err)
+	}
+	return actionToProto(*action), nil
+}
+
+// actionToProto converts an action to its protobuf message
+func actionToProto(action database.Action) *projectorpb.Action {
+	return &projectorpb.Action{
+		Id:           uint32(action.ID),
+		Name:         action.Name,
+		Note:         action.Note.String,
+		ProjectId:    uint32(action.ProjectID.Int64),
+		ProjectName:  action.ProjectName.String,
+		DueDate:      database.StoredDate(action.DueDate.String),
+		StatusId:     uint32(action.StatusID),
+		StatusName:   action.StatusName,
+		Repeat:       action.RepeatDescription(),
+		OwnerId:      uint32(action.OwnerID.Int64),
+		AssigneeId:   uint32(action.AssigneeID.Int64),
+		AssigneeName: action.AssigneeName.String,
+	}
+}
+
+// optionalUint converts an optional protobuf ID to the form used by ActionUpdate
+func optionalUint(value *uint32) *uint {
+	if value == nil {
+		return nil
+	}
+	converted := uint(*value)
+	return &converted
+}
@@ -0,0 +1,147 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contextKey is the type of the request context keys set by the server
+type contextKey string
+
+// userContextKey holds the authenticated user in the request context
+const userContextKey contextKey = "user"
+
+// authenticate requires a valid API token in the authorization metadata once
+// user accounts exist. Without accounts all calls are allowed.
+func (s *Server) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	count, err := database.CountUsers(s.dbPath)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking users: %v", err)
+	}
+	if count == 0 {
+		return handler(ctx, req)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	for _, value := range md.Get("authorization") {
+		if strings.HasPrefix(value, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(value, "Bearer "))
+		}
+	}
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	user, err := database.GetUserByToken(s.dbPath, token)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error checking token: %v", err)
+	}
+	if user == nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	return handler(context.WithValue(ctx, userContextKey, user), req)
+}
+
+// rejectWrites fails the calls that could change data with PermissionDenied
+// when the server is read-only. Only the List and Get methods read.
+func (s *Server) rejectWrites(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+	if s.readOnly && !strings.HasPrefix(method, "List") && !strings.HasPrefix(method, "Get") {
+		return nil, status.Error(codes.PermissionDenied, "server is read-only")
+	}
+	return handler(ctx, req)
+}
+
+// currentUser returns the authenticated user, or nil in single-user mode
+func currentUser(ctx context.Context) *database.User {
+	user, _ := ctx.Value(userContextKey).(*database.User)
+	return user
+}
+
+// isUser reports whether an ID refers to the given user. In single-user mode
+// there is no user and every ID matches.
+func isUser(user *database.User, id sql.NullInt64) bool {
+	return user == nil || (id.Valid && uint(id.Int64) == user.ID)
+}
+
+// accessibleProject retrieves a project the caller has at least the required
+// role in. Projects that are not shared with the caller are reported as not found.
+func (s *Server) accessibleProject(ctx context.Context, projectID uint, required string) (*database.Project, error) {
+	project, err := database.GetProjectByID(s.dbPath, projectID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving project: %v", err)
+	}
+	if project == nil {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+
+	role := database.RoleOwner
+	if user := currentUser(ctx); user != nil {
+		role, err = database.GetProjectRole(s.dbPath, projectID, user.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking project role: %v", err)
+		}
+	}
+
+	if role == "" {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+	if err := checkRole(role, required); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// accessibleAction retrieves an action the caller has at least the required
+// role for. The owner of an action owns it and its assignee can edit it,
+// otherwise the role in the project of the action applies.
+func (s *Server) accessibleAction(ctx context.Context, actionID uint, required string) (*database.Action, error) {
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving action: %v", err)
+	}
+	if action == nil {
+		return nil, status.Error(codes.NotFound, "action not found")
+	}
+
+	user := currentUser(ctx)
+	var role string
+	switch {
+	case isUser(user, action.OwnerID):
+		role = database.RoleOwner
+	case isUser(user, action.AssigneeID):
+		role = database.RoleEditor
+	case action.ProjectID.Valid:
+		role, err = database.GetProjectRole(s.dbPath, uint(action.ProjectID.Int64), user.ID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error checking project role: %v", err)
+		}
+	}
+
+	if role == "" {
+		return nil, status.Error(codes.NotFound, "action not found")
+	}
+	if err := checkRole(role, required); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// checkRole returns a PermissionDenied error when the role does not grant the
+// required permissions
+func checkRole(role, required string) error {
+	if !database.HasRole(role, required) {
+		return status.Errorf(codes.PermissionDenied, "requires the %s role", required)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package grpcapi
+
+import (
+	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+)
+
+// runActionHooks runs the hooks of an action event in the background, with
+// the action in the same JSON form as the HTTP API
+func (s *Server) runActionHooks(event string, action *database.Action) {
+	if action == nil || !hooks.Enabled(event) {
+		return
+	}
+	go hooks.Action(event, api.ToAction(action))
+}
+
+// runActionHooksByID runs the hooks of an action event for an action that
+// has not been read yet
+func (s *Server) runActionHooksByID(event string, actionID uint) {
+	if !hooks.Enabled(event) {
+		return
+	}
+	action, err := database.GetActionByID(s.dbPath, actionID)
+	if err != nil {
+		return
+	}
+	s.runActionHooks(event, action)
+}
+
+// runProjectHooks runs the hooks of a project event in the background
+func (s *Server) runProjectHooks(event string, project *database.Project) {
+	if project == nil || !hooks.Enabled(event) {
+		return
+	}
+	go hooks.Project(event, api.ToProject(project))
+}
@@ -0,0 +1,88 @@
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/grpcapi/projectorpb"
+	"github.com/joelgrimberg/projector/hooks"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// projectService implements projectorpb.ProjectServiceServer
+type projectService struct {
+	projectorpb.UnimplementedProjectServiceServer
+	server *Server
+}
+
+func (p *projectService) ListProjects(ctx context.Context, req *projectorpb.ListProjectsRequest) (*projectorpb.ListProjectsResponse, error) {
+	var projects []database.Project
+	var err error
+	if user := currentUser(ctx); user != nil {
+		projects, err = database.GetProjectsForUser(p.server.dbPath, user.ID)
+	} else {
+		projects, err = database.GetAllProjects(p.server.dbPath)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving projects: %v", err)
+	}
+
+	response := &projectorpb.ListProjectsResponse{}
+	for _, project := range projects {
+		response.Projects = append(response.Projects, projectToProto(project))
+	}
+	return response, nil
+}
+
+func (p *projectService) GetProject(ctx context.Context, req *projectorpb.GetProjectRequest) (*projectorpb.Project, error) {
+	project, err := p.server.accessibleProject(ctx, uint(req.Id), database.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return projectToProto(*project), nil
+}
+
+func (p *projectService) CreateProject(ctx context.Context, req *projectorpb.CreateProjectRequest) (*projectorpb.Project, error) {
+	projectID, err := database.CreateProject(p.server.dbPath, req.Name, req.DueDate)
+	if err != nil {
+		return nil, invalidArgument(err, "error creating project")
+	}
+
+	if user := currentUser(ctx); user != nil {
+		if err := database.SetProjectOwner(p.server.dbPath, projectID, user.ID); err != nil {
+			return nil, status.Errorf(codes.Internal, "error setting project owner: %v", err)
+		}
+	}
+
+	project, err := database.GetProjectByID(p.server.dbPath, projectID)
+	if err != nil || project == nil {
+		return nil, status.Errorf(codes.Internal, "error retrieving created project: %v", err)
+	}
+	p.server.runProjectHooks(hooks.ProjectCreated, project)
+	return projectToProto(*project), nil
+}
+
+func (p *projectService) DeleteProject(ctx context.Context, req *projectorpb.DeleteProjectRequest) (*projectorpb.DeleteProjectResponse, error) {
+	project, err := p.server.accessibleProject(ctx, uint(req.Id), database.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DeleteProject(p.server.dbPath, uint(req.Id)); err != nil {
+		return nil, status.Errorf(codes.Internal, "error deleting project: %v", err)
+	}
+	p.server.runProjectHooks(hooks.ProjectDeleted, project)
+	return &projectorpb.DeleteProjectResponse{}, nil
+}
+
+// projectToProto converts a project to its protobuf message
+func projectToProto(project database.Project) *projectorpb.Project {
+	return &projectorpb.Project{
+		Id:      uint32(project.ID),
+		Name:    project.Name,
+		DueDate: database.StoredDate(project.DueDate.String),
+		OwnerId: uint32(project.OwnerID.Int64),
+	}
+}
@@ -0,0 +1,16 @@
+package grpcapi
+
+import (
+	"log"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/rules"
+)
+
+// applyRules runs the action rules on an action that was just created, or
+// updated from previous. A failing rule is logged and does not fail the call.
+func (s *Server) applyRules(previous *database.Action, actionID uint) {
+	if _, err := rules.Apply(s.dbPath, previous, actionID); err != nil {
+		log.Printf("Failed to apply rules to action %d: %v", actionID, err)
+	}
+}
@@ -0,0 +1,64 @@
+// Package grpcapi serves the projects and actions over gRPC, as defined in
+// projectorpb/projector.proto. It applies the same authentication and access
+// rules as the HTTP API.
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative projectorpb/projector.proto
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/joelgrimberg/projector/grpcapi/projectorpb"
+
+	"google.golang.org/grpc"
+)
+
+// Server represents the gRPC server
+type Server struct {
+	port     int
+	dbPath   string
+	listener net.Listener
+	readOnly bool
+}
+
+// NewServer creates a new gRPC server instance
+func NewServer(port int, dbPath string) *Server {
+	return &Server{
+		port:   port,
+		dbPath: dbPath,
+	}
+}
+
+// SetReadOnly makes the server reject every call that could change data
+func (s *Server) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// Listen binds the server port ahead of Start, so that a failure to bind is
+// reported before the server runs in the background
+func (s *Server) Listen() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %v", s.port, err)
+	}
+	s.listener = listener
+	return nil
+}
+
+// Start starts the gRPC server and blocks until it stops, listening first
+// when Listen was not called
+func (s *Server) Start() error {
+	if s.listener == nil {
+		if err := s.Listen(); err != nil {
+			return err
+		}
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(s.rejectWrites, s.authenticate))
+	projectorpb.RegisterProjectServiceServer(server, &projectService{server: s})
+	projectorpb.RegisterActionServiceServer(server, &actionService{server: s})
+
+	fmt.Printf("🚀 gRPC server starting on port %d...\n", s.port)
+	return server.Serve(s.listener)
+}
@@ -0,0 +1,36 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// invalidArgument returns an InvalidArgument status for an error of a create
+// or update. The fields that broke a validation rule are attached as
+// BadRequest field violations, like the errors of a 422 response of the
+// HTTP API.
+func invalidArgument(err error, message string) error {
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("%s: %v", message, err))
+
+	var invalid *database.ValidationError
+	if !errors.As(err, &invalid) {
+		return st.Err()
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range invalid.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	if detailed, detailErr := st.WithDetails(badRequest); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// Marks of the days of the calendar strip of a habit
+const (
+	habitDone    = "✓"
+	habitSkipped = "-"
+	habitMissed  = "✗"
+	habitOpen    = "○" // Due today and not done yet
+	habitNone    = "·" // Nothing due
+)
+
+// habitRecord is the JSON form of a habit in command output
+type habitRecord struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Repeat     string   `json:"repeat"`
+	Streak     int      `json:"streak"`
+	BestStreak int      `json:"best_streak"`
+	Done       int      `json:"done"`
+	Due        int      `json:"due"`
+	Days       []string `json:"days"` // done, skipped, missed, open or empty, oldest first
+}
+
+func habitsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "habits",
+		Short: "Show streaks and completion rates of repeating actions",
+		Long: `Show every repeating action as a habit: the number of occurrences done in a
+row, the best streak, how many of the occurrences due in the last 30 days
+were done, and a calendar strip of those days, oldest first:
+
+  ✓ done   - skipped   ✗ missed   ○ due today   · nothing due
+
+Occurrences are followed from the first one through the occurrences created
+by repetition. Skipped occurrences and the occurrence that is not due yet
+do not break a streak.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			days, _ := cmd.Flags().GetInt("days")
+			if days < 1 {
+				fmt.Println("❌ --days must be at least 1")
+				return
+			}
+
+			habits, err := database.GetHabits(database.GetDatabasePath())
+			if err != nil {
+				fmt.Printf("❌ Error retrieving habits: %v\n", err)
+				return
+			}
+			if len(habits) == 0 && !output.IsJSON() {
+				fmt.Println("📝 No repeating actions found")
+				return
+			}
+
+			today := time.Now().Format("2006-01-02")
+			records := []habitRecord{}
+			table := output.Table{Headers: []string{"ID", "HABIT", "REPEAT", "STREAK", "BEST", "RATE", fmt.Sprintf("LAST %d DAYS", days)}}
+			for _, habit := range habits {
+				done, due := habit.CompletionRate(today, days)
+				record := habitRecord{
+					ID:         habit.Action.ID,
+					Name:       habit.Action.Name,
+					Repeat:     habit.Action.RepeatDescription(),
+					Streak:     habit.Streak(today),
+					BestStreak: habit.BestStreak(today),
+					Done:       done,
+					Due:        due,
+					Days:       habitDays(habit, today, days),
+				}
+				records = append(records, record)
+
+				rate := "-"
+				if due > 0 {
+					rate = fmt.Sprintf("%d%%", done*100/due)
+				}
+				table.AddRow(fmt.Sprint(record.ID), record.Name, record.Repeat, fmt.Sprint(record.Streak), fmt.Sprint(record.BestStreak), rate, habitStrip(record.Days))
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Printf("❌ Failed to print habits: %v\n", err)
+			}
+		},
+	}
+
+	cmd.Flags().Int("days", 30, "Number of days of the completion rate and the calendar strip")
+	return cmd
+}
+
+// habitDays returns the state of a habit on each of the given number of days
+// up to today, oldest first: done, skipped, missed or open when an occurrence
+// was due that day, empty otherwise
+func habitDays(habit database.Habit, today string, days int) []string {
+	end, _ := time.Parse("2006-01-02", today)
+	states := make([]string, days)
+	for i := range states {
+		date := end.AddDate(0, 0, i-days+1).Format("2006-01-02")
+		for _, occurrence := range habit.Occurrences {
+			if occurrence.DueDate != date {
+				continue
+			}
+			// An occurrence that was done shows over others due the same day
+			switch {
+			case occurrence.CompletedOn != "":
+				states[i] = "done"
+			case occurrence.Missed(today) && states[i] != "done":
+				states[i] = "missed"
+			case occurrence.Skipped && states[i] == "":
+				states[i] = "skipped"
+			case states[i] == "":
+				states[i] = "open"
+			}
+		}
+	}
+	return states
+}
+
+// habitStrip renders the states of the days of a habit as a calendar strip.
+// Accessible output counts the states instead, which reads better than a
+// row of symbols.
+func habitStrip(states []string) string {
+	if output.Accessible() {
+		counts := make(map[string]int)
+		for _, state := range states {
+			counts[state]++
+		}
+		var parts []string
+		for _, state := range []string{"done", "skipped", "missed", "open"} {
+			if counts[state] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+			}
+		}
+		if len(parts) == 0 {
+			return "nothing due"
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	var b strings.Builder
+	for _, state := range states {
+		switch state {
+		case "done":
+			b.WriteString(output.Colorize(habitDone, "green"))
+		case "skipped":
+			b.WriteString(output.Colorize(habitSkipped, "gray"))
+		case "missed":
+			b.WriteString(output.Colorize(habitMissed, "red"))
+		case "open":
+			b.WriteString(habitOpen)
+		default:
+			b.WriteString(output.Colorize(habitNone, "gray"))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+func heatmapCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "heatmap",
+		Short: "Show the actions completed per day over the last year",
+		Long: `Show the actions completed per day over the last year as a heatmap, like
+the contribution graph of GitHub. Move between days with the arrow keys to see
+their counts. Completions are taken from the action history, which starts with
+the last change of actions that existed before it was recorded.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			now := time.Now()
+			completions, err := database.GetCompletionCounts(database.GetDatabasePath(), now.AddDate(-1, 0, -7).Format("2006-01-02"))
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingCompletions", i18n.Data{"Error": err}))
+				return
+			}
+
+			counts := make(map[string]int, len(completions))
+			for _, completion := range completions {
+				counts[completion.Date] = completion.Completed
+			}
+
+			if output.Accessible() {
+				fmt.Print(ui.DescribeHeatmap(counts, now))
+				return
+			}
+			if !term.IsTerminal(os.Stdout.Fd()) {
+				fmt.Print(ui.RenderHeatmap(counts, now))
+				return
+			}
+			if err := ui.Heatmap(counts, now); err != nil {
+				fmt.Println(i18n.T("FailedToShowHeatmap", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
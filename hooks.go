@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+)
+
+// fireActionHook runs the hooks of an action event for an action of the
+// local database
+func fireActionHook(event string, actionID uint) {
+	if !hooks.Enabled(event) {
+		return
+	}
+	action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+	if err != nil || action == nil {
+		return
+	}
+	hooks.Action(event, api.ToAction(action))
+}
@@ -0,0 +1,125 @@
+// Package hooks runs external commands on lifecycle events such as a new or
+// completed action, as configured in the config file. The event is passed as
+// JSON on stdin, so that scripts can extend projector without recompiling it.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+)
+
+// Events the hooks can run on
+const (
+	ActionCreated  = "action.created"
+	ActionUpdated  = "action.updated"
+	ActionDone     = "action.done"
+	ActionDeleted  = "action.deleted"
+	ProjectCreated = "project.created"
+	ProjectDeleted = "project.deleted"
+)
+
+// maxOutput bounds the command output kept for the log of a failed hook
+const maxOutput = 1024
+
+var hooks []config.Hook
+
+// SetHooks sets the hooks for the rest of the process
+func SetHooks(configured []config.Hook) {
+	hooks = configured
+}
+
+// Enabled reports whether any hook runs on the event, so that callers can
+// skip preparing its data
+func Enabled(event string) bool {
+	for _, hook := range hooks {
+		if matches(hook, event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fire runs the hooks configured for the event one after another, each with
+// {"event": ..., "time": ..., <data>} on stdin. A hook that fails or runs
+// out of time is logged, and does not stop the other hooks.
+func Fire(event string, data map[string]interface{}) {
+	payload := map[string]interface{}{
+		"event": event,
+		"time":  time.Now().UTC().Format(time.RFC3339),
+	}
+	for key, value := range data {
+		payload[key] = value
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode %s hook payload: %v", event, err)
+		return
+	}
+
+	for _, hook := range hooks {
+		if !matches(hook, event) {
+			continue
+		}
+		if err := run(hook, input); err != nil {
+			log.Printf("Hook %q on %s failed: %v", hook.Command, event, err)
+		}
+	}
+}
+
+// matches reports whether a hook runs on the event, * matching every event
+func matches(hook config.Hook, event string) bool {
+	return hook.Event == event || hook.Event == "*"
+}
+
+// run runs the command of a hook with the shell, with input on stdin
+func run(hook config.Hook, input []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", hook.Command)
+	cmd.Stdin = bytes.NewReader(input)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	// Children of the shell may keep the output open after it is killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", hook.Timeout())
+	}
+	if err != nil {
+		message := strings.TrimSpace(output.String())
+		if len(message) > maxOutput {
+			message = message[:maxOutput] + "..."
+		}
+		if message != "" {
+			return fmt.Errorf("%v: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// Action runs the hooks of an action event, with the action under "action"
+func Action(event string, action interface{}) {
+	if Enabled(event) {
+		Fire(event, map[string]interface{}{"action": action})
+	}
+}
+
+// Project runs the hooks of a project event, with the project under "project"
+func Project(event string, project interface{}) {
+	if Enabled(event) {
+		Fire(event, map[string]interface{}{"project": project})
+	}
+}
@@ -0,0 +1,194 @@
+// Package i18n translates the messages of the CLI, the TUI and the API.
+// Messages are looked up by ID in the message catalogs embedded from
+// locales/<language>.json, English being the source language that every
+// other catalog falls back to.
+//
+// A catalog maps a message ID to its text, or to its "one" and "other" forms
+// for messages about a count. Texts refer to their data as {{.Name}}.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the source language of the messages
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Data is the data a message refers to. The Count entry selects the plural
+// form of a message.
+type Data map[string]interface{}
+
+// message is a catalog entry, a plain text or the plural forms of a text
+type message struct {
+	One   string `json:"one"`
+	Other string `json:"other"`
+}
+
+// UnmarshalJSON reads a text or an object with plural forms
+func (m *message) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		m.One, m.Other = text, text
+		return nil
+	}
+	type forms message
+	return json.Unmarshal(data, (*forms)(m))
+}
+
+// catalogs are the messages of each language
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded message catalogs. A catalog that does not
+// parse is a programming error, so it panics.
+func loadCatalogs() map[string]map[string]message {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]message)
+	for _, file := range files {
+		data, err := locales.ReadFile("locales/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+		var catalog map[string]message
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog %s: %v", file.Name(), err))
+		}
+		result[strings.TrimSuffix(file.Name(), ".json")] = catalog
+	}
+	return result
+}
+
+// Languages returns the languages with a message catalog
+func Languages() []string {
+	var languages []string
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localizer translates messages into one language
+type Localizer struct {
+	language string
+}
+
+// New returns a localizer for a language such as nl or nl_NL.UTF-8,
+// falling back to English for languages without a catalog
+func New(language string) *Localizer {
+	if supported := Match(language); supported != "" {
+		return &Localizer{language: supported}
+	}
+	return &Localizer{language: DefaultLanguage}
+}
+
+// Language returns the language of the localizer
+func (l *Localizer) Language() string {
+	return l.language
+}
+
+// T returns the text of a message with its data filled in. A message missing
+// from the catalog is taken from English, and a message missing altogether is
+// returned as its ID, so a mistake shows rather than an empty line.
+func (l *Localizer) T(id string, data ...Data) string {
+	msg, ok := catalogs[l.language][id]
+	if !ok {
+		if msg, ok = catalogs[DefaultLanguage][id]; !ok {
+			return id
+		}
+	}
+
+	var values Data
+	if len(data) > 0 {
+		values = data[0]
+	}
+	text := msg.Other
+	if count, ok := values["Count"].(int); ok && count == 1 {
+		text = msg.One
+	}
+	for name, value := range values {
+		text = strings.ReplaceAll(text, "{{."+name+"}}", fmt.Sprint(value))
+	}
+	return text
+}
+
+// Match returns the supported language of a language tag or locale, such as
+// nl for nl-BE or nl_NL.UTF-8, or an empty string when it is not supported
+func Match(tag string) string {
+	tag, _, _ = strings.Cut(tag, ".")
+	tag, _, _ = strings.Cut(tag, "@")
+	language, _, _ := strings.Cut(strings.ReplaceAll(tag, "-", "_"), "_")
+	language = strings.ToLower(strings.TrimSpace(language))
+	if _, ok := catalogs[language]; ok {
+		return language
+	}
+	return ""
+}
+
+// FromAcceptLanguage returns the first supported language of an
+// Accept-Language header, English when none is supported. Quality values are
+// not weighed, clients list their languages by preference.
+func FromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if language := Match(tag); language != "" {
+			return language
+		}
+	}
+	return DefaultLanguage
+}
+
+// Detect picks the language of the CLI and the TUI: the configured language,
+// then the language of the configured locale, then the LC_ALL, LC_MESSAGES
+// and LANG environment variables. It returns an error when the configured
+// language has no catalog.
+func Detect(configured, locale string) (string, error) {
+	if configured != "" {
+		language := Match(configured)
+		if language == "" {
+			return DefaultLanguage, fmt.Errorf("unsupported language: %s (expected %s)", configured, strings.Join(Languages(), " or "))
+		}
+		return language, nil
+	}
+
+	for _, tag := range []string{locale, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")} {
+		if tag == "" {
+			continue
+		}
+		// The first locale that is set decides, so LC_ALL=C is English
+		// whatever LANG says
+		if language := Match(tag); language != "" {
+			return language, nil
+		}
+		return DefaultLanguage, nil
+	}
+	return DefaultLanguage, nil
+}
+
+// current is the localizer of the CLI and the TUI
+var current = New(DefaultLanguage)
+
+// SetLanguage selects the language of T
+func SetLanguage(language string) {
+	current = New(language)
+}
+
+// Language returns the language selected for T
+func Language() string {
+	return current.language
+}
+
+// T translates a message into the selected language
+func T(id string, data ...Data) string {
+	return current.T(id, data...)
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/jira"
+	"github.com/joelgrimberg/projector/mailin"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/taskwarrior"
+	"github.com/joelgrimberg/projector/todoist"
+	"github.com/joelgrimberg/projector/todotxt"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+func importCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import actions and projects from other tools",
+	}
+
+	cmd.AddCommand(importTodoistCmd())
+	cmd.AddCommand(importTaskwarriorCmd())
+	cmd.AddCommand(importTodoTxtCmd())
+	cmd.AddCommand(importJiraCmd())
+	cmd.AddCommand(importMailCmd())
+	return cmd
+}
+
+func importMailCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mail",
+		Short: "Turn the unread emails of the configured mailbox into actions",
+		Long: `Turn the unread emails in the IMAP folder of the "mail" config into actions:
+the subject becomes the name and the body the note, below the sender.
+Processed emails are marked read. The API server does this every few minutes;
+run this command from cron instead when the server is not running.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			if !cfg.Mail.Enabled() {
+				fmt.Println(i18n.T("NoMailbox"))
+				return
+			}
+
+			fmt.Println(i18n.T("CheckingMailbox", i18n.Data{"Host": cfg.Mail.Host}))
+
+			result, err := mailin.Poll(database.GetDatabasePath(), cfg.Mail)
+			if result != nil {
+				fmt.Println(i18n.T("ImportedCount", i18n.Data{"Count": result.Imported}))
+				for _, warning := range result.Warnings {
+					fmt.Printf("⚠️ %s\n", warning)
+				}
+			}
+			if err != nil {
+				fmt.Println(i18n.T("MailImportFailed", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func importJiraCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jira",
+		Short: "Import Jira issues matching a JQL query into a project",
+		Run: func(cmd *cobra.Command, args []string) {
+			jql, _ := cmd.Flags().GetString("jql")
+			project, _ := cmd.Flags().GetString("project")
+			url, _ := cmd.Flags().GetString("url")
+			email, _ := cmd.Flags().GetString("email")
+			token, _ := cmd.Flags().GetString("token")
+
+			if url == "" {
+				url = os.Getenv("PROJECTOR_JIRA_URL")
+			}
+			if email == "" {
+				email = os.Getenv("PROJECTOR_JIRA_EMAIL")
+			}
+			if token == "" {
+				token = os.Getenv("PROJECTOR_JIRA_TOKEN")
+			}
+
+			runJiraImport(jql, project, url, email, token)
+		},
+	}
+
+	cmd.Flags().String("jql", "assignee = currentUser() AND sprint in openSprints()", "JQL query selecting the issues to import")
+	cmd.Flags().String("project", "", "Name of the project to import into (created if missing)")
+	cmd.Flags().String("url", "", "Jira base URL, e.g. https://example.atlassian.net (or PROJECTOR_JIRA_URL)")
+	cmd.Flags().String("email", "", "Jira Cloud account email (or PROJECTOR_JIRA_EMAIL)")
+	cmd.Flags().String("token", "", "Jira API or personal access token (or PROJECTOR_JIRA_TOKEN)")
+	cmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	return cmd
+}
+
+func runJiraImport(jql, project, url, email, token string) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	if url == "" || token == "" {
+		fmt.Println(i18n.T("JiraCredentialsRequired"))
+		return
+	}
+
+	var projectID *uint
+	if project != "" {
+		id, err := database.GetOrCreateProject(database.GetDatabasePath(), project)
+		if err != nil {
+			fmt.Println(i18n.T("FailedToCreateProject", i18n.Data{"Error": err}))
+			return
+		}
+		projectID = &id
+	}
+
+	fmt.Println(i18n.T("FetchingJiraIssues"))
+
+	result, err := jira.Import(database.GetDatabasePath(), jira.NewClient(url, email, token), jql, projectID)
+	if err != nil {
+		fmt.Println(i18n.T("JiraImportFailed", i18n.Data{"Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("ImportedCount", i18n.Data{"Count": result.Imported}))
+	fmt.Println(i18n.T("UpdatedCount", i18n.Data{"Count": result.Updated}))
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+}
+
+func importTaskwarriorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taskwarrior [file]",
+		Short: "Import tasks from `task export` JSON (reads stdin without a file)",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runFileImport(cmd, args, "taskwarrior", "Taskwarrior", func(input io.Reader, progress database.ImportProgress) (*api.ImportResult, error) {
+				result, err := taskwarrior.Import(database.GetDatabasePath(), input, progress)
+				if err != nil {
+					return nil, err
+				}
+				return &api.ImportResult{Imported: result.Imported, Skipped: result.Skipped, Failed: result.Failed, Warnings: result.Warnings}, nil
+			})
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
+
+func importTodoTxtCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "todotxt [file]",
+		Short: "Import tasks from a todo.txt file (reads stdin without a file)",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runFileImport(cmd, args, "todotxt", "todo.txt", func(input io.Reader, progress database.ImportProgress) (*api.ImportResult, error) {
+				result, err := todotxt.Import(database.GetDatabasePath(), input, progress)
+				if err != nil {
+					return nil, err
+				}
+				return &api.ImportResult{Imported: result.Imported, Failed: result.Failed, Warnings: result.Warnings}, nil
+			})
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
+
+// runFileImport imports the file given as the first argument, or stdin,
+// into the local database with importLocal or into the API server given by
+// --remote. A progress bar is drawn while it runs, and the rows that failed
+// are listed with the reason.
+func runFileImport(cmd *cobra.Command, args []string, format, label string, importLocal func(io.Reader, database.ImportProgress) (*api.ImportResult, error)) {
+	client, ok := configuredRemote(cmd)
+	if !ok {
+		return
+	}
+	if client == nil && !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	input, ok := openImportInput(args)
+	if !ok {
+		return
+	}
+	defer input.Close()
+
+	bar := newProgressBar("Importing")
+	var result *api.ImportResult
+	var err error
+	if client != nil {
+		// The server streams its progress until the whole file is imported
+		client.SetHTTPClient(&http.Client{Timeout: 5 * time.Minute})
+		result, err = client.ImportActions(format, input, bar.update)
+	} else {
+		result, err = importLocal(input, bar.update)
+	}
+	bar.finish()
+	if err != nil {
+		fmt.Println(i18n.T("ImportFailed", i18n.Data{"Label": label, "Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("ImportedCount", i18n.Data{"Count": result.Imported}))
+	if result.Skipped > 0 {
+		fmt.Println(i18n.T("SkippedTasks", i18n.Data{"Count": result.Skipped}))
+	}
+	if len(result.Failed) > 0 {
+		fmt.Println(i18n.T("FailedCount", i18n.Data{"Count": len(result.Failed)}))
+		for _, failure := range result.Failed {
+			fmt.Printf("   %s: %s\n", failure.Row, failure.Reason)
+		}
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+}
+
+// progressBarWidth is the number of cells of the progress bar
+const progressBarWidth = 30
+
+// progressBar draws the progress of a long import on stderr. It draws
+// nothing when stderr is not a terminal, so that output piped to a file or
+// read by scripts is not cluttered.
+type progressBar struct {
+	label   string
+	enabled bool
+	percent int
+	drawn   bool
+}
+
+func newProgressBar(label string) *progressBar {
+	return &progressBar{label: label, enabled: term.IsTerminal(os.Stderr.Fd()) && !output.Accessible(), percent: -1}
+}
+
+// update redraws the bar when the percentage changed
+func (b *progressBar) update(done, total int) {
+	if !b.enabled || total == 0 {
+		return
+	}
+	percent := done * 100 / total
+	if percent == b.percent {
+		return
+	}
+	b.percent = percent
+	b.drawn = true
+
+	filled := progressBarWidth * done / total
+	fmt.Fprintf(os.Stderr, "\r%s [%s%s] %3d%% %d/%d", b.label,
+		strings.Repeat("█", filled), strings.Repeat("░", progressBarWidth-filled), percent, done, total)
+}
+
+// finish clears the bar, so that the summary starts on a clean line
+func (b *progressBar) finish() {
+	if b.drawn {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
+
+// openImportInput opens the file given as the first argument, or stdin without arguments
+func openImportInput(args []string) (io.ReadCloser, bool) {
+	if len(args) == 0 || args[0] == "-" {
+		return io.NopCloser(os.Stdin), true
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(i18n.T("FailedToOpenFile", i18n.Data{"Path": args[0], "Error": err}))
+		return nil, false
+	}
+	return file, true
+}
+
+func importTodoistCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "todoist",
+		Short: "Import active tasks from Todoist",
+		Run: func(cmd *cobra.Command, args []string) {
+			token, _ := cmd.Flags().GetString("token")
+			runTodoist(token, false)
+		},
+	}
+
+	cmd.Flags().String("token", "", "Todoist API token (or PROJECTOR_TODOIST_TOKEN)")
+	return cmd
+}
+
+func syncTodoistCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "todoist",
+		Short: "Import Todoist tasks and sync completions in both directions",
+		Run: func(cmd *cobra.Command, args []string) {
+			token, _ := cmd.Flags().GetString("token")
+			runTodoist(token, true)
+		},
+	}
+
+	cmd.Flags().String("token", "", "Todoist API token (or PROJECTOR_TODOIST_TOKEN)")
+	return cmd
+}
+
+func runTodoist(token string, sync bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	if token == "" {
+		token = os.Getenv("PROJECTOR_TODOIST_TOKEN")
+	}
+	if token == "" {
+		fmt.Println(i18n.T("TodoistTokenRequired"))
+		return
+	}
+
+	fmt.Println(i18n.T("FetchingTodoistTasks"))
+
+	result, err := todoist.Import(database.GetDatabasePath(), todoist.NewClient(token), sync)
+	if err != nil {
+		fmt.Println(i18n.T("TodoistImportFailed", i18n.Data{"Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("ImportedCount", i18n.Data{"Count": result.Imported}))
+	fmt.Println(i18n.T("UpdatedCount", i18n.Data{"Count": result.Updated}))
+	if sync {
+		fmt.Println(i18n.T("CompletedLocally", i18n.Data{"Count": result.Completed}))
+		fmt.Println(i18n.T("ClosedInTodoist", i18n.Data{"Count": result.Closed}))
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+}
@@ -0,0 +1,105 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the Jira REST API
+type Client struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// Issue is the subset of a Jira issue that is imported
+type Issue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Description json.RawMessage `json:"description"`
+		DueDate     string          `json:"duedate"`
+		Labels      []string        `json:"labels"`
+		Status      struct {
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+// NewClient creates a new Jira client. With an email the token is used as a
+// Jira Cloud API token, otherwise as a personal access token.
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		email:      email,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Search retrieves all issues matching a JQL query
+func (c *Client) Search(jql string) ([]Issue, error) {
+	var issues []Issue
+	pageToken := ""
+
+	for {
+		params := url.Values{}
+		params.Set("jql", jql)
+		params.Set("fields", "summary,description,duedate,labels,status")
+		params.Set("maxResults", "100")
+		if pageToken != "" {
+			params.Set("nextPageToken", pageToken)
+		}
+
+		req, err := http.NewRequest("GET", c.baseURL+"/rest/api/3/search/jql?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if c.email != "" {
+			req.SetBasicAuth(c.email, c.token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query Jira: %v", err)
+		}
+
+		var page struct {
+			Issues        []Issue `json:"issues"`
+			NextPageToken string  `json:"nextPageToken"`
+			IsLast        bool    `json:"isLast"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected response from Jira: %s", resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid response from Jira: %v", err)
+		}
+
+		issues = append(issues, page.Issues...)
+		if page.IsLast || page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+
+	return issues, nil
+}
+
+// IssueURL returns the browser URL of an issue
+func (c *Client) IssueURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
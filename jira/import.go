@@ -0,0 +1,174 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// SyncSource is the sync_state source name used for Jira
+const SyncSource = "jira"
+
+// Result summarizes the changes made by an import
+type Result struct {
+	Imported int
+	Updated  int
+	Warnings []string
+}
+
+// Import pulls the issues matching jql into the given project. Issues that
+// were imported before are updated instead of duplicated.
+func Import(dbPath string, client *Client, jql string, projectID *uint) (*Result, error) {
+	issues, err := client.Search(jql)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := database.GetSyncStates(dbPath, SyncSource)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync state: %v", err)
+	}
+
+	stateByKey := make(map[string]database.SyncState)
+	for _, state := range states {
+		stateByKey[state.RemoteID] = state
+	}
+
+	defaultStatusID, err := database.DefaultStatusID(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, issue := range issues {
+		name := fmt.Sprintf("%s: %s", issue.Key, issue.Fields.Summary)
+		note := strings.TrimSpace(descriptionText(issue.Fields.Description) + "\n\n" + client.IssueURL(issue.Key))
+
+		// A dropped due date, such as one in the past, leaves the due date
+		// of an issue that was imported before as it is
+		dueDate := issue.Fields.DueDate
+		dueDropped := false
+		if _, err := database.ValidateDate(dueDate); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: dropped due date: %v", issue.Key, err))
+			dueDate = ""
+			dueDropped = true
+		}
+
+		done := issue.Fields.Status.StatusCategory.Key == "done"
+
+		var action *database.Action
+		if state, ok := stateByKey[issue.Key]; ok {
+			action, err = database.GetActionByID(dbPath, state.ActionID)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving action: %v", err)
+			}
+		}
+
+		if action != nil {
+			update := database.ActionUpdate{
+				Name:      &name,
+				Note:      &note,
+				ProjectID: projectID,
+			}
+			if !dueDropped && dueDate != database.StoredDate(action.DueDate.String) {
+				update.DueDate = &dueDate
+			}
+			// Only completing or reopening the issue changes the status, so
+			// an action moved to in-progress or waiting locally stays there
+			localDone := action.StatusName == "done"
+			if done && !localDone {
+				doneID := uint(2)
+				update.StatusID = &doneID
+			} else if !done && localDone {
+				update.StatusID = &defaultStatusID
+			}
+			if err := database.UpdateAction(dbPath, action.ID, update); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", issue.Key, err))
+				continue
+			}
+			if err := database.SetActionTags(dbPath, action.ID, issue.Fields.Labels); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", issue.Key, err))
+			}
+			result.Updated++
+			continue
+		}
+
+		statusID := defaultStatusID
+		if done {
+			statusID = 2
+		}
+		actionID, err := database.CreateAction(dbPath, name, note, projectID, dueDate, statusID, "", 0, "", "", "", nil)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", issue.Key, err))
+			continue
+		}
+		if err := database.SetActionTags(dbPath, actionID, issue.Fields.Labels); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", issue.Key, err))
+		}
+		err = database.SaveSyncState(dbPath, database.SyncState{
+			Source:   SyncSource,
+			ActionID: actionID,
+			RemoteID: issue.Key,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// adfNode is a node of an Atlassian Document Format document
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+// descriptionText converts an issue description to plain text. Jira Cloud
+// returns Atlassian Document Format, Jira Server a plain string.
+func descriptionText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var doc adfNode
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	writeADF(&b, doc)
+	return strings.TrimSpace(b.String())
+}
+
+// writeADF appends the text of an ADF node, separating blocks by newlines
+func writeADF(b *strings.Builder, node adfNode) {
+	switch node.Type {
+	case "text":
+		b.WriteString(node.Text)
+		return
+	case "hardBreak":
+		b.WriteString("\n")
+		return
+	case "listItem":
+		b.WriteString("- ")
+	}
+
+	for _, child := range node.Content {
+		writeADF(b, child)
+	}
+
+	switch node.Type {
+	case "paragraph", "heading", "codeBlock", "blockquote":
+		b.WriteString("\n")
+	}
+}
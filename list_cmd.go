@@ -0,0 +1,374 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// watchedTables are the tables whose changes make list --watch list again
+var watchedTables = []string{"project", "action", "tag", "action_tag"}
+
+// actionRecord is the JSON form of an action in command output
+type actionRecord struct {
+	ID        uint     `json:"id"`
+	Name      string   `json:"name"`
+	Note      string   `json:"note,omitempty"`
+	ProjectID uint     `json:"project_id,omitempty"`
+	Project   string   `json:"project,omitempty"`
+	DueDate   string   `json:"due_date,omitempty"`
+	Status    string   `json:"status"`
+	Repeat    string   `json:"repeat,omitempty"`
+	Assignee  string   `json:"assignee,omitempty"`
+	Flagged   bool     `json:"flagged"`
+	Location  string   `json:"location,omitempty"`
+	Energy    string   `json:"energy,omitempty"`
+	WaitingOn string   `json:"waiting_on,omitempty"`
+	FollowUp  string   `json:"follow_up,omitempty"`
+	StartDate string   `json:"start_date,omitempty"`
+	Tags      []string `json:"tags"`
+}
+
+func listCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list [query]",
+		Short: "List open actions, or all actions with --all",
+		Long: `List open actions, or all actions with --all. Actions with a start date in
+the future are deferred and left out until that date, unless --all or
+--deferred is given.
+
+A query filters the actions, such as
+
+  projector list 'status:todo AND (tag:home OR project:"House") AND due<=+7d'
+
+Fields are status, tag, project, due, start, name, note, assignee, location
+and energy. Compare them with :, =, or !=, and dates and energy levels also
+with <, <=, > or >=. Dates are YYYY-MM-DD,
+today, tomorrow, yesterday, a day name or an offset such as +7d, -2w or +1m.
+Combine terms with AND, OR, NOT and parentheses; terms next to each other must
+all match. A word without a field searches the action names. Done actions are
+listed when the query filters on status, deferred actions when it filters on
+start.
+
+--near lists the actions at a place, such as --near office: actions whose
+location contains the name, and actions within --radius kilometers of its
+coordinates. The place may also be given as latitude,longitude.
+
+--energy lists the actions that take at most an energy level, such as
+--energy low when you are tired. Actions without an energy level are left out.
+
+--watch keeps the list on screen and lists the actions again whenever they
+change, from this or any other projector, or the day changes.
+
+--remote lists the actions of a projector API server instead of the local
+database, see 'projector add --help'.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project, _ := cmd.Flags().GetString("project")
+			tag, _ := cmd.Flags().GetString("tag")
+			all, _ := cmd.Flags().GetBool("all")
+			saved, _ := cmd.Flags().GetString("saved")
+			near, _ := cmd.Flags().GetString("near")
+			radius, _ := cmd.Flags().GetFloat64("radius")
+			energy, _ := cmd.Flags().GetString("energy")
+			deferred, _ := cmd.Flags().GetBool("deferred")
+			watch, _ := cmd.Flags().GetBool("watch")
+			interval, _ := cmd.Flags().GetDuration("interval")
+
+			var query string
+			if len(args) > 0 {
+				query = args[0]
+			}
+
+			client, ok := remoteClient(cmd)
+			if !ok {
+				return
+			}
+			if client != nil {
+				if watch {
+					fmt.Println(i18n.T("WatchLocalOnly"))
+					return
+				}
+				runRemoteList(client, project, tag, query, saved, near, radius, energy, all, deferred)
+				return
+			}
+
+			list := func() bool {
+				return runList(project, tag, query, saved, near, radius, energy, all, deferred)
+			}
+			if watch {
+				watchList(interval, list)
+				return
+			}
+			list()
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "", "Only list the actions of this project (name or ID)")
+	cmd.Flags().StringP("tag", "t", "", "Only list the actions with this tag")
+	cmd.Flags().BoolP("all", "a", false, "Include done and deferred actions")
+	cmd.Flags().Bool("deferred", false, "Only list the actions whose start date has not come yet")
+	cmd.Flags().StringP("saved", "s", "", "Only list the actions matching this saved filter")
+	cmd.Flags().String("near", "", "Only list the actions at this location name or latitude,longitude")
+	cmd.Flags().String("energy", "", "Only list the actions that take at most this energy level: low, medium or high")
+	cmd.Flags().Float64("radius", database.DefaultNearRadius, "Distance in kilometers within which actions are near the --near coordinates")
+	cmd.Flags().BoolP("watch", "w", false, "Keep listing the actions whenever they change, until interrupted")
+	cmd.Flags().Duration("interval", 2*time.Second, "How often --watch checks for changes")
+	addRemoteFlags(cmd)
+	cmd.RegisterFlagCompletionFunc("project", completeProjectNames)
+	cmd.RegisterFlagCompletionFunc("tag", completeTagNames)
+	cmd.RegisterFlagCompletionFunc("saved", completeFilterNames)
+	return cmd
+}
+
+// runList prints the matching actions. It reports false when the actions
+// could not be listed, such as for an invalid query.
+func runList(project, tag, query, saved, near string, radius float64, energy string, all, deferred bool) bool {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return false
+	}
+
+	var match *filter.Filter
+	if query != "" {
+		parsed, err := filter.Parse(query, time.Now())
+		if err != nil {
+			fmt.Println(i18n.T("InvalidQuery", i18n.Data{"Error": err}))
+			return false
+		}
+		match = parsed
+	}
+	if saved != "" {
+		savedFilter, ok := lookupSavedFilter(saved)
+		if !ok {
+			return false
+		}
+		parsed, err := filter.Parse(savedFilter.Query, time.Now())
+		if err != nil {
+			fmt.Println(i18n.T("InvalidSavedFilter", i18n.Data{"Name": savedFilter.Name, "Error": err}))
+			return false
+		}
+		match = filter.And(parsed, match)
+	}
+	// Deferred actions are listed with --all, or when the query filters on
+	// the start date
+	showDeferred := all || deferred || match != nil && match.Uses("start")
+
+	// A query on status decides itself whether done actions are listed
+	if match != nil && match.Uses("status") {
+		all = true
+	}
+
+	actions, err := database.GetAllActions(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return false
+	}
+
+	var projectID uint
+	if project != "" {
+		found, ok := lookupProject(project)
+		if !ok {
+			return false
+		}
+		projectID = found.ID
+	}
+
+	if energy != "" {
+		validated, err := database.ValidateEnergy(energy)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return false
+		}
+		energy = validated
+	}
+
+	var place database.Place
+	if near != "" {
+		place = database.ResolvePlace(actions, near)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	var listed []database.Action
+	for _, action := range actions {
+		if !all && action.StatusName == "done" {
+			continue
+		}
+		if deferred && !action.IsDeferred(today) || !showDeferred && action.IsDeferred(today) {
+			continue
+		}
+		if projectID != 0 && uint(action.ProjectID.Int64) != projectID {
+			continue
+		}
+		if tag != "" && !slices.Contains(action.Tags, tag) {
+			continue
+		}
+		if match != nil && !match.Match(&action) {
+			continue
+		}
+		if near != "" && !action.IsNear(place, radius) {
+			continue
+		}
+		if energy != "" && !action.NeedsAtMost(energy) {
+			continue
+		}
+		listed = append(listed, action)
+	}
+
+	return printActions(listed, deferred, near != "", energy != "")
+}
+
+// printActions prints the listed actions, with a start date, location or
+// energy column when the list was filtered on it. The note, assignee and
+// repeat columns are shown with --wide.
+func printActions(actions []database.Action, deferred, near, energy bool) bool {
+	records := []actionRecord{}
+	table := output.Table{Headers: []string{"ID", "NAME", "PROJECT", "DUE", "STATUS", "TAGS"}, Wide: []string{"ASSIGNEE", "REPEAT", "NOTE"}}
+	if deferred {
+		table.Headers = append(table.Headers, "START")
+	}
+	if near {
+		table.Headers = append(table.Headers, "LOCATION")
+	}
+	if energy {
+		table.Headers = append(table.Headers, "ENERGY")
+	}
+	table.Headers = append(table.Headers, table.Wide...)
+	for _, action := range actions {
+		record := newActionRecord(action)
+		records = append(records, record)
+		row := []string{fmt.Sprint(record.ID), record.Name, record.Project, displayDate(record.DueDate), statusLabel(record.Status), strings.Join(record.Tags, ",")}
+		if deferred {
+			row = append(row, displayDate(record.StartDate))
+		}
+		if near {
+			row = append(row, record.Location)
+		}
+		if energy {
+			row = append(row, record.Energy)
+		}
+		// Notes are shown on a single line
+		row = append(row, record.Assignee, record.Repeat, strings.Join(strings.Fields(record.Note), " "))
+		table.AddRow(row...)
+	}
+
+	if len(records) == 0 && !output.IsJSON() {
+		fmt.Println(i18n.T("NoActionsFound"))
+		return true
+	}
+
+	if err := output.Print(records, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+		return false
+	}
+	return true
+}
+
+// watchList lists the actions, and again whenever the change counters of the
+// actions show a write or the day changes, until interrupted. On a terminal
+// the screen is cleared first, otherwise every listing is appended.
+func watchList(interval time.Duration, list func() bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+	if interval <= 0 {
+		fmt.Println(i18n.T("IntervalNotPositive"))
+		return
+	}
+	redraw := term.IsTerminal(os.Stdout.Fd()) && !output.IsJSON() && !output.Accessible()
+
+	var listed string
+	for {
+		count, err := database.GetChangeCount(database.GetDatabasePath(), watchedTables...)
+		if err != nil {
+			fmt.Println(i18n.T("WatchFailed", i18n.Data{"Error": err}))
+			return
+		}
+
+		now := time.Now()
+		if state := fmt.Sprintf("%d %s", count, now.Format("2006-01-02")); state != listed {
+			if redraw {
+				fmt.Print("\033[H\033[2J")
+				fmt.Println(i18n.T("WatchUpdated", i18n.Data{"Time": now.Format("15:04:05")}))
+				fmt.Println()
+			}
+			// Stop on errors such as an invalid query, rather than repeat them
+			if !list() && listed == "" {
+				return
+			}
+			listed = state
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// newActionRecord converts an action for command output
+func newActionRecord(action database.Action) actionRecord {
+	tags := action.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return actionRecord{
+		ID:        action.ID,
+		Name:      action.Name,
+		Note:      action.Note.String,
+		ProjectID: uint(action.ProjectID.Int64),
+		Project:   action.ProjectName.String,
+		DueDate:   database.StoredDate(action.DueDate.String),
+		Status:    action.StatusName,
+		Repeat:    action.RepeatDescription(),
+		Assignee:  action.AssigneeName.String,
+		Flagged:   action.Flagged,
+		Location:  action.Location.String,
+		Energy:    action.Energy.String,
+		WaitingOn: action.WaitingOn.String,
+		FollowUp:  database.StoredDate(action.FollowUp.String),
+		StartDate: database.StoredDate(action.StartDate.String),
+		Tags:      tags,
+	}
+}
+
+// displayDate formats a date of a table cell in the date format of the
+// config. Plain output keeps ISO dates, for scripts.
+func displayDate(value string) string {
+	if output.Format() != output.FormatTable {
+		return database.StoredDate(value)
+	}
+	return datefmt.Date(value)
+}
+
+// lookupProject finds a project by ID or name, printing an error when it does not exist
+func lookupProject(nameOrID string) (*database.Project, bool) {
+	var project *database.Project
+	var err error
+	if id, parseErr := strconv.ParseUint(nameOrID, 10, 32); parseErr == nil {
+		project, err = database.GetProjectByID(database.GetDatabasePath(), uint(id))
+	} else {
+		project, err = database.GetProjectByName(database.GetDatabasePath(), nameOrID)
+	}
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingProject", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if project == nil {
+		fmt.Println(i18n.T("ProjectNotFound", i18n.Data{"Name": nameOrID}))
+		return nil, false
+	}
+
+	return project, true
+}
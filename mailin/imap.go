@@ -0,0 +1,219 @@
+package mailin
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+)
+
+// DefaultPort is the IMAP port with implicit TLS
+const DefaultPort = 993
+
+// sessionTimeout bounds a whole mailbox check, so that a stalled server
+// cannot block the next one
+const sessionTimeout = 2 * time.Minute
+
+// response is an untagged server response, with the literals it contained
+type response struct {
+	line     string
+	literals [][]byte
+}
+
+// client is a minimal IMAP4rev1 client with just the commands needed to read
+// unread messages and mark them read
+type client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+// dial connects and logs in to the mailbox server. Port 993 uses implicit
+// TLS, other ports must support STARTTLS: the password is never sent in the
+// clear.
+func dial(cfg config.Mail) (*client, error) {
+	port := cfg.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	addr := net.JoinHostPort(cfg.Host, fmt.Sprint(port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	var err error
+	if port == DefaultPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to IMAP server: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(sessionTimeout))
+
+	c := &client{conn: conn, reader: bufio.NewReader(conn)}
+	greeting, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to IMAP server: %v", err)
+	}
+	if !strings.HasPrefix(greeting.line, "* OK") {
+		conn.Close()
+		return nil, fmt.Errorf("IMAP server refused the connection: %s", greeting.line)
+	}
+
+	if port != DefaultPort {
+		if _, err := c.command("STARTTLS"); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("IMAP server does not support STARTTLS: %v", err)
+		}
+		c.conn = tls.Client(conn, tlsConfig)
+		c.reader = bufio.NewReader(c.conn)
+	}
+
+	if _, err := c.command("LOGIN " + quote(cfg.Username) + " " + quote(cfg.Password)); err != nil {
+		c.conn.Close()
+		return nil, fmt.Errorf("IMAP login failed: %v", err)
+	}
+	return c, nil
+}
+
+// close logs out and closes the connection
+func (c *client) close() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
+
+// selectFolder opens a folder for reading and flagging messages
+func (c *client) selectFolder(folder string) error {
+	if _, err := c.command("SELECT " + quote(folder)); err != nil {
+		return fmt.Errorf("failed to open folder %s: %v", folder, err)
+	}
+	return nil
+}
+
+// unseen returns the UIDs of the unread messages in the selected folder
+func (c *client) unseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search folder: %v", err)
+	}
+
+	var uids []uint32
+	for _, r := range responses {
+		fields := strings.Fields(r.line)
+		if len(fields) < 2 || !strings.EqualFold(fields[1], "SEARCH") {
+			continue
+		}
+		for _, field := range fields[2:] {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid search response: %s", r.line)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// fetch returns the raw message with a UID without marking it read
+func (c *client) fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch message %d: %v", uid, err)
+	}
+	for _, r := range responses {
+		if strings.Contains(strings.ToUpper(r.line), "FETCH") && len(r.literals) > 0 {
+			return r.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", uid)
+}
+
+// markSeen marks the message with a UID read
+func (c *client) markSeen(uid uint32) error {
+	if _, err := c.command(fmt.Sprintf(`UID STORE %d +FLAGS.SILENT (\Seen)`, uid)); err != nil {
+		return fmt.Errorf("failed to mark message %d read: %v", uid, err)
+	}
+	return nil
+}
+
+// command sends a command and returns its untagged responses, or an error
+// when the server does not complete it with OK
+func (c *client) command(command string) ([]response, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+
+	var responses []response
+	for {
+		r, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(r.line, tag+" ") {
+			responses = append(responses, r)
+			continue
+		}
+
+		status := strings.TrimPrefix(r.line, tag+" ")
+		if !strings.HasPrefix(strings.ToUpper(status), "OK") {
+			return nil, fmt.Errorf("%s", status)
+		}
+		return responses, nil
+	}
+}
+
+// readResponse reads a response line, including the literals ({n} followed
+// by n bytes) that continue it on the next lines
+func (c *client) readResponse() (response, error) {
+	var r response
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return r, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		r.line += line
+
+		size, ok := literalSize(line)
+		if !ok {
+			return r, nil
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return r, err
+		}
+		r.literals = append(r.literals, literal)
+	}
+}
+
+// literalSize returns the size of the literal announced at the end of a line
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	start := strings.LastIndex(line, "{")
+	if start < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(line[start+1 : len(line)-1])
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// quote returns an IMAP quoted string
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,267 @@
+// Package mailin turns the unread emails of an IMAP mailbox into actions, so
+// that emails can be forwarded to projector: the subject becomes the name of
+// the action and the body its note, together with the sender.
+package mailin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+)
+
+// SyncSource is the sync_state source name used for emails, with the
+// Message-ID as remote ID so that an email is never imported twice
+const SyncSource = "email"
+
+// maxNameLength is the maximum length of an action name
+const maxNameLength = 255
+
+// forwardPrefix matches the reply and forward markers in front of a subject
+var forwardPrefix = regexp.MustCompile(`(?i)^\s*((fwd?|re|aw|wg)\s*:\s*)+`)
+
+// Email is the part of an incoming email that becomes an action
+type Email struct {
+	MessageID string
+	From      string
+	Subject   string
+	Body      string
+}
+
+// Result summarizes a mailbox check
+type Result struct {
+	Imported int
+	Warnings []string
+}
+
+// Parse reads the sender, subject and plain text body of a raw email
+func Parse(raw []byte) (*Email, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %v", err)
+	}
+
+	decoder := &mime.WordDecoder{}
+	email := &Email{MessageID: strings.Trim(msg.Header.Get("Message-ID"), "<> ")}
+
+	email.Subject, err = decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		email.Subject = msg.Header.Get("Subject")
+	}
+
+	email.From = msg.Header.Get("From")
+	if address, err := (&mail.AddressParser{WordDecoder: decoder}).Parse(email.From); err == nil {
+		email.From = address.Address
+		if address.Name != "" {
+			email.From = fmt.Sprintf("%s <%s>", address.Name, address.Address)
+		}
+	}
+
+	body, err := plainText(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email body: %v", err)
+	}
+	email.Body = strings.TrimSpace(strings.ReplaceAll(body, "\r\n", "\n"))
+
+	return email, nil
+}
+
+// Action returns the action for the email. Reply and forward markers are
+// removed from the subject, and the sender is recorded at the top of the note.
+func (e *Email) Action(projectID *uint) database.NewAction {
+	name := strings.TrimSpace(forwardPrefix.ReplaceAllString(e.Subject, ""))
+	if name == "" {
+		name = "Email from " + e.From
+	}
+	for len(name) > maxNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+
+	note := "From: " + e.From
+	if e.Body != "" {
+		note += "\n\n" + e.Body
+	}
+
+	return database.NewAction{Name: name, Note: note, ProjectID: projectID}
+}
+
+// Poll creates an action for every unread email in the configured folder and
+// marks the emails read. An email that cannot be read is left unread with a
+// warning.
+func Poll(dbPath string, cfg config.Mail) (*Result, error) {
+	var projectID *uint
+	if cfg.Project != "" {
+		project, err := database.GetProjectByName(dbPath, cfg.Project)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving project: %v", err)
+		}
+		if project == nil {
+			return nil, fmt.Errorf("project %s not found", cfg.Project)
+		}
+		projectID = &project.ID
+	}
+
+	states, err := database.GetSyncStates(dbPath, SyncSource)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync state: %v", err)
+	}
+	imported := make(map[string]bool)
+	for _, state := range states {
+		imported[state.RemoteID] = true
+	}
+
+	folder := cfg.Folder
+	if folder == "" {
+		folder = "INBOX"
+	}
+
+	c, err := dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	if err := c.selectFolder(folder); err != nil {
+		return nil, err
+	}
+	uids, err := c.unseen()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for _, uid := range uids {
+		raw, err := c.fetch(uid)
+		if err != nil {
+			return result, err
+		}
+		email, err := Parse(raw)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("message %d: %v", uid, err))
+			continue
+		}
+
+		// Marking the email read failed after it was imported
+		if email.MessageID != "" && imported[email.MessageID] {
+			if err := c.markSeen(uid); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		actionIDs, err := database.CreateActions(dbPath, []database.NewAction{email.Action(projectID)})
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("message %d: %v", uid, err))
+			continue
+		}
+		if email.MessageID != "" {
+			err := database.SaveSyncState(dbPath, database.SyncState{
+				Source:   SyncSource,
+				ActionID: actionIDs[0],
+				RemoteID: email.MessageID,
+			})
+			if err != nil {
+				return result, err
+			}
+			imported[email.MessageID] = true
+		}
+		result.Imported++
+
+		if err := c.markSeen(uid); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// Run checks the mailbox at the configured interval until stop is closed
+func Run(dbPath string, cfg config.Mail, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(cfg.Interval())
+	defer ticker.Stop()
+
+	for {
+		result, err := Poll(dbPath, cfg)
+		if err != nil {
+			onError(err)
+		}
+		if result != nil {
+			for _, warning := range result.Warnings {
+				onError(fmt.Errorf("%s", warning))
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// plainText returns the text/plain content of a message body, looking into
+// multipart bodies for their first plain text part
+func plainText(contentType, encoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// A missing or broken content type is plain text
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return "", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := plainText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			if text != "" {
+				return text, nil
+			}
+		}
+	}
+	if mediaType != "text/plain" {
+		return "", nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	switch strings.ToLower(params["charset"]) {
+	case "iso-8859-1", "latin1":
+		// The first 256 code points of Unicode are Latin-1
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes), nil
+	}
+	return string(data), nil
+}
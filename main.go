@@ -10,10 +10,21 @@ import (
 	"syscall"
 
 	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/daemon"
 	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/escalate"
+	"github.com/joelgrimberg/projector/grpcapi"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/mailin"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/rules"
+	"github.com/joelgrimberg/projector/theme"
 	"github.com/joelgrimberg/projector/ui"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 )
 
@@ -27,21 +38,220 @@ func main() {
 		Run: func(cmd *cobra.Command, args []string) {
 			// Default behavior when no subcommand is provided
 			verbose, _ := cmd.Flags().GetBool("verbose")
-			startAPIServer(verbose)
+			grpcPort, _ := cmd.Flags().GetInt("grpc-port")
+			startAPIServer(verbose, grpcPort, false)
 		},
 	}
 
 	// Add verbose flag
 	rootCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
 
+	// Add output flag, shared by all commands that list or report data
+	rootCmd.PersistentFlags().StringP("output", "o", output.FormatTable, "Output format: table, plain or json")
+	rootCmd.PersistentFlags().BoolP("yes", "y", false, "Do not ask for confirmation before deleting, restoring, merging or bulk changes")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Show what deleting, restoring, merging or bulk changes would affect without changing anything")
+	rootCmd.PersistentFlags().Bool("plain", false, "Screen-reader friendly output: text labels instead of emoji, and lines instead of spinners, graphs and full-screen prompts")
+	rootCmd.PersistentFlags().Bool("no-emoji", false, "Same as --plain")
+	rootCmd.PersistentFlags().Bool("wide", false, "Show all table columns at full width rather than fitting the terminal")
+	rootCmd.PersistentFlags().Bool("no-color", false, "Show no colors, like setting the NO_COLOR environment variable")
+	rootCmd.PersistentFlags().String("db", "", "Database path, or :memory: or :temp: for a throwaway database (overrides PROJECTOR_DB_PATH)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if dbPath, _ := cmd.Flags().GetString("db"); dbPath != "" {
+			database.SetDatabasePath(dbPath)
+		}
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		noEmoji, _ := cmd.Flags().GetBool("no-emoji")
+
+		// Every command creates and changes actions according to the workflow
+		if cfg, err := config.Load(); err == nil {
+			plain = plain || cfg.Display.Plain
+			database.SetWorkflow(database.Workflow(cfg.Workflow))
+			hooks.SetHooks(cfg.Hooks)
+			if err := rules.SetRules(cfg.Rules); err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("RulesDisabled", i18n.Data{"Error": err}))
+			}
+			if err := datefmt.Configure(cfg.Display); err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("DisplaySettingsIgnored", i18n.Data{"Error": err}))
+			}
+			language, err := i18n.Detect(cfg.Display.Language, cfg.Display.Locale)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("LanguageIgnored", i18n.Data{"Error": err}))
+			}
+			i18n.SetLanguage(language)
+		}
+		if plain || noEmoji {
+			if err := output.SetAccessible(); err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("PlainOutputUnavailable", i18n.Data{"Error": err}))
+			}
+		}
+		// Plain output is read out or shown on dumb terminals, without colors
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		theme.Configure(noColor || output.Accessible())
+
+		wide, _ := cmd.Flags().GetBool("wide")
+		output.SetWide(wide)
+
+		format, _ := cmd.Flags().GetString("output")
+		// The export command has its own --output flag for the output file
+		if cmd.Flags().Lookup("output") != cmd.Root().PersistentFlags().Lookup("output") {
+			return nil
+		}
+		return output.SetFormat(format)
+	}
+
+	// Add gRPC port flag, the gRPC server only runs when it is set
+	rootCmd.Flags().Int("grpc-port", 0, "Also serve the gRPC API on this port")
+
+	// Add the `serve` command
+	rootCmd.AddCommand(serveCmd())
+
+	// Add the `status` command
+	rootCmd.AddCommand(statusCmd())
+
+	// Add the `stop` command
+	rootCmd.AddCommand(stopCmd())
+
 	// Add the `init` command
 	rootCmd.AddCommand(initCmd())
 
 	// Add the `migrate` command
 	rootCmd.AddCommand(migrateCmd())
 
-	// Execute the root command
-	if err := rootCmd.Execute(); err != nil {
+	// Add the `add` command
+	rootCmd.AddCommand(addCmd())
+
+	// Add the `action` command
+	rootCmd.AddCommand(actionCmd())
+
+	// Add the `list` command
+	rootCmd.AddCommand(listCmd())
+
+	// Add the `show` command
+	rootCmd.AddCommand(showCmd())
+
+	// Add the `note` command
+	rootCmd.AddCommand(noteCmd())
+
+	// Add the `open` command
+	rootCmd.AddCommand(openCmd())
+
+	// Add the `project` command
+	rootCmd.AddCommand(projectCmd())
+
+	// Add the `template` command
+	rootCmd.AddCommand(templateCmd())
+
+	// Add the `filter` command
+	rootCmd.AddCommand(filterCmd())
+
+	// Add the `bulk` command
+	rootCmd.AddCommand(bulkCmd())
+
+	// Add the `reschedule` command
+	rootCmd.AddCommand(rescheduleCmd())
+
+	// Add the `trash` command
+	rootCmd.AddCommand(trashCmd())
+
+	// Add the `archive` command
+	rootCmd.AddCommand(archiveCmd())
+
+	// Add the `gc` command
+	rootCmd.AddCommand(gcCmd())
+
+	// Add the `tags` command
+	rootCmd.AddCommand(tagsCmd())
+
+	// Add the `tag` command
+	rootCmd.AddCommand(tagCmd())
+
+	// Add the `statuses` command
+	rootCmd.AddCommand(statusesCmd())
+
+	// Add the `stats` command
+	rootCmd.AddCommand(statsCmd())
+
+	// Add the `heatmap` command
+	rootCmd.AddCommand(heatmapCmd())
+
+	// Add the `habits` command
+	rootCmd.AddCommand(habitsCmd())
+
+	// Add the `export` command
+	rootCmd.AddCommand(exportCmd())
+
+	// Add the `import` command
+	rootCmd.AddCommand(importCmd())
+
+	// Add the `sync` command
+	rootCmd.AddCommand(syncCmd())
+
+	// Add the `conflicts` command
+	rootCmd.AddCommand(conflictsCmd())
+
+	// Add the `notify` command
+	rootCmd.AddCommand(notifyCmd())
+
+	// Add the `digest` command
+	rootCmd.AddCommand(digestCmd())
+
+	// Add the `escalate` command
+	rootCmd.AddCommand(escalateCmd())
+
+	// Add the `rules` command
+	rootCmd.AddCommand(rulesCmd())
+
+	// Add the `user` command
+	rootCmd.AddCommand(userCmd())
+
+	// Add the `admin` command
+	rootCmd.AddCommand(adminCmd())
+
+	// Add the `done` command
+	rootCmd.AddCommand(doneCmd())
+
+	// Add the `skip` command
+	rootCmd.AddCommand(skipCmd())
+
+	// Add the `delete` command
+	rootCmd.AddCommand(deleteCmd())
+
+	// Add the `queue` command
+	rootCmd.AddCommand(queueCmd())
+
+	// Add the `today` command
+	rootCmd.AddCommand(todayCmd())
+
+	// Add the `review` command
+	rootCmd.AddCommand(reviewCmd())
+
+	// Add the `waiting` command
+	rootCmd.AddCommand(waitingCmd())
+
+	// Add the `flag` command
+	rootCmd.AddCommand(flagCmd())
+
+	// Add the `statusline` command
+	rootCmd.AddCommand(statuslineCmd())
+
+	// Add the `seed` command
+	rootCmd.AddCommand(seedCmd())
+
+	// Add the `db` command
+	rootCmd.AddCommand(dbCmd())
+
+	// Add the `doctor` command
+	rootCmd.AddCommand(doctorCmd())
+
+	// Add the `completion` command
+	rootCmd.AddCommand(completionCmd())
+
+	// Execute the root command, then discard a throwaway database
+	err := rootCmd.Execute()
+	database.CloseTemporary()
+	output.Close()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -52,9 +262,9 @@ func initCmd() *cobra.Command {
 		Use:   "init",
 		Short: "Initialize the database and tables",
 		Run: func(cmd *cobra.Command, args []string) {
-			p := tea.NewProgram(ui.NewModel())
-			if _, err := p.Run(); err != nil {
+			if err := ui.RunInit(); err != nil {
 				fmt.Println("Error starting Bubble Tea program:", err)
+				output.Close()
 				os.Exit(1)
 			}
 		},
@@ -64,7 +274,7 @@ func initCmd() *cobra.Command {
 func migrateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "migrate",
-		Short: "Migrate database schema to add note and repeat fields to actions",
+		Short: "Migrate database schema to the latest version",
 		Run: func(cmd *cobra.Command, args []string) {
 			verbose, _ := cmd.Flags().GetBool("verbose")
 			runMigration(verbose)
@@ -76,88 +286,92 @@ func migrateCmd() *cobra.Command {
 	return cmd
 }
 
-func runMigration(verbose bool) {
+// runMigration brings the database schema up to date, reporting whether every
+// step succeeded. Only a complete migration records the current schema version.
+func runMigration(verbose bool) bool {
 	if verbose {
-		fmt.Println("🔄 Starting database migration...")
+		fmt.Println(i18n.T("MigrationStarting"))
 	}
 
 	// Check if database exists
 	if !database.DatabaseExists(database.GetDatabasePath()) {
-		fmt.Println("❌ Database not found. Please run 'projector init' first.")
-		return
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return false
 	}
 
 	// Open database
 	db, err := sql.Open("sqlite3", database.GetDatabasePath())
 	if err != nil {
-		fmt.Printf("❌ Failed to open database: %v\n", err)
-		return
+		fmt.Println(i18n.T("FailedToOpenDatabase", i18n.Data{"Error": err}))
+		return false
 	}
 	defer db.Close()
 
+	failed := false
+
 	// First, check if we need to rename the task table to action table
 	var tableExists int
 	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='task'").Scan(&tableExists)
 	if err != nil {
-		fmt.Printf("❌ Error checking for task table: %v\n", err)
-		return
+		fmt.Println(i18n.T("MigrationTaskTableCheckFailed", i18n.Data{"Error": err}))
+		return false
 	}
 
 	if tableExists > 0 {
 		if verbose {
-			fmt.Println("🔄 Renaming 'task' table to 'action' table...")
+			fmt.Println(i18n.T("MigrationRenamingTaskTable"))
 		}
 		
 		// Rename the task table to action table
 		_, err = db.Exec("ALTER TABLE task RENAME TO action")
 		if err != nil {
-			fmt.Printf("❌ Failed to rename task table: %v\n", err)
-			return
+			fmt.Println(i18n.T("MigrationRenameTaskTableFailed", i18n.Data{"Error": err}))
+			return false
 		}
 		if verbose {
-			fmt.Println("✅ Table renamed successfully")
+			fmt.Println(i18n.T("MigrationTableRenamed"))
 		}
 
 		// Rename the task_tag table to action_tag table
 		err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='task_tag'").Scan(&tableExists)
 		if err == nil && tableExists > 0 {
 			if verbose {
-				fmt.Println("🔄 Renaming 'task_tag' table to 'action_tag' table...")
+				fmt.Println(i18n.T("MigrationRenamingTaskTagTable"))
 			}
 			_, err = db.Exec("ALTER TABLE task_tag RENAME TO action_tag")
 			if err != nil {
-				fmt.Printf("❌ Failed to rename task_tag table: %v\n", err)
-				return
+				fmt.Println(i18n.T("MigrationRenameTaskTagTableFailed", i18n.Data{"Error": err}))
+				return false
 			}
 			if verbose {
-				fmt.Println("✅ task_tag table renamed successfully")
+				fmt.Println(i18n.T("MigrationTaskTagTableRenamed"))
 			}
 			
 			// Rename the task_id column to action_id in the action_tag table
 			if verbose {
-				fmt.Println("🔄 Renaming 'task_id' column to 'action_id' in action_tag table...")
+				fmt.Println(i18n.T("MigrationRenamingTaskIDColumn"))
 			}
 			_, err = db.Exec("ALTER TABLE action_tag RENAME COLUMN task_id TO action_id")
 			if err != nil {
-				fmt.Printf("❌ Failed to rename task_id column: %v\n", err)
-				return
+				fmt.Println(i18n.T("MigrationRenameTaskIDColumnFailed", i18n.Data{"Error": err}))
+				return false
 			}
 			if verbose {
-				fmt.Println("✅ Column renamed successfully")
+				fmt.Println(i18n.T("MigrationColumnRenamed"))
 			}
 		}
 
 		// Rename the parent_task_id column to parent_action_id
 		if verbose {
-			fmt.Println("🔄 Renaming 'parent_task_id' column to 'parent_action_id'...")
+			fmt.Println(i18n.T("MigrationRenamingParentTaskIDColumn"))
 		}
 		_, err = db.Exec("ALTER TABLE action RENAME COLUMN parent_task_id TO parent_action_id")
 		if err != nil {
-			fmt.Printf("❌ Failed to rename parent_task_id column: %v\n", err)
-			return
+			fmt.Println(i18n.T("MigrationRenameParentTaskIDColumnFailed", i18n.Data{"Error": err}))
+			return false
 		}
 		if verbose {
-			fmt.Println("✅ Column renamed successfully")
+			fmt.Println(i18n.T("MigrationColumnRenamed"))
 		}
 	}
 
@@ -169,102 +383,367 @@ func runMigration(verbose bool) {
 		err = db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('action_tag') WHERE name='task_id'").Scan(&columnExists)
 		if err == nil && columnExists > 0 {
 			if verbose {
-				fmt.Println("🔄 Fixing 'task_id' column name to 'action_id' in action_tag table...")
+				fmt.Println(i18n.T("MigrationFixingTaskIDColumn"))
 			}
 			_, err = db.Exec("ALTER TABLE action_tag RENAME COLUMN task_id TO action_id")
 			if err != nil {
-				fmt.Printf("❌ Failed to rename task_id column: %v\n", err)
+				fmt.Println(i18n.T("MigrationRenameTaskIDColumnFailed", i18n.Data{"Error": err}))
+				failed = true
 			} else {
 				if verbose {
-					fmt.Println("✅ Column renamed successfully")
+					fmt.Println(i18n.T("MigrationColumnRenamed"))
 				}
 			}
 		}
 	}
 
+	// Idempotency keys were not kept per user before. The stored responses
+	// only serve retries, so the table is dropped and created again below.
+	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='idempotency_key'").Scan(&tableExists)
+	if err == nil && tableExists > 0 {
+		var columnExists int
+		err = db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('idempotency_key') WHERE name='user_id'").Scan(&columnExists)
+		if err == nil && columnExists == 0 {
+			if verbose {
+				fmt.Println(i18n.T("MigrationRecreatingIdempotencyKeyTable"))
+			}
+			if _, err = db.Exec("DROP TABLE idempotency_key"); err != nil {
+				fmt.Println(i18n.T("MigrationDropIdempotencyKeyTableFailed", i18n.Data{"Error": err}))
+				failed = true
+			}
+		}
+	}
+
+	// Create tables that were added after the initial schema
+	for _, table := range []string{"sync_state", "notification_log", "tombstone", "sync_peer", "user", "user_token", "project_member", "maintenance_log", "change_counter", "template", "template_action", "saved_filter", "action_history", "trash", "attachment", "idempotency_key", "change_event", "reminder", "action_archive", "sync_conflict"} {
+		err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&tableExists)
+		if err != nil {
+			fmt.Println(i18n.T("MigrationTableCheckFailed", i18n.Data{"Table": table, "Error": err}))
+			failed = true
+			continue
+		}
+
+		if tableExists == 0 {
+			if verbose {
+				fmt.Println(i18n.T("MigrationCreatingTable", i18n.Data{"Table": table}))
+			}
+			if err := database.CreateTable(database.GetDatabasePath(), table); err != nil {
+				fmt.Println(i18n.T("MigrationCreateTableFailed", i18n.Data{"Table": table, "Error": err}))
+				failed = true
+				continue
+			}
+			if verbose {
+				fmt.Println(i18n.T("MigrationTableCreated", i18n.Data{"Table": table}))
+			}
+		} else {
+			if verbose {
+				fmt.Println(i18n.T("MigrationTableExists", i18n.Data{"Table": table}))
+			}
+		}
+	}
+
 	// List of columns to add (these will be skipped if they already exist)
 	columns := []struct {
+		table   string
 		name    string
 		sql     string
 		display string
 	}{
-		{"note", "ALTER TABLE action ADD COLUMN note TEXT", "note"},
-		{"repeat_count", "ALTER TABLE action ADD COLUMN repeat_count INTEGER DEFAULT 0", "repeat_count"},
-		{"repeat_interval", "ALTER TABLE action ADD COLUMN repeat_interval TEXT", "repeat_interval"},
-		{"repeat_pattern", "ALTER TABLE action ADD COLUMN repeat_pattern TEXT", "repeat_pattern"},
-		{"repeat_until", "ALTER TABLE action ADD COLUMN repeat_until DATE", "repeat_until"},
-		{"parent_action_id", "ALTER TABLE action ADD COLUMN parent_action_id INTEGER", "parent_action_id"},
+		{"action", "note", "ALTER TABLE action ADD COLUMN note TEXT", "note"},
+		{"action", "repeat_count", "ALTER TABLE action ADD COLUMN repeat_count INTEGER DEFAULT 0", "repeat_count"},
+		{"action", "repeat_interval", "ALTER TABLE action ADD COLUMN repeat_interval TEXT", "repeat_interval"},
+		{"action", "repeat_pattern", "ALTER TABLE action ADD COLUMN repeat_pattern TEXT", "repeat_pattern"},
+		{"action", "repeat_until", "ALTER TABLE action ADD COLUMN repeat_until DATE", "repeat_until"},
+		{"action", "parent_action_id", "ALTER TABLE action ADD COLUMN parent_action_id INTEGER", "parent_action_id"},
+		{"action", "repeat_mode", "ALTER TABLE action ADD COLUMN repeat_mode TEXT", "repeat_mode"},
+		{"action", "uid", "ALTER TABLE action ADD COLUMN uid TEXT", "uid"},
+		{"action", "updated_at", "ALTER TABLE action ADD COLUMN updated_at TEXT", "updated_at"},
+		{"action", "changed_at", "ALTER TABLE action ADD COLUMN changed_at TEXT", "changed_at"},
+		{"project", "uid", "ALTER TABLE project ADD COLUMN uid TEXT", "uid"},
+		{"project", "updated_at", "ALTER TABLE project ADD COLUMN updated_at TEXT", "updated_at"},
+		{"project", "changed_at", "ALTER TABLE project ADD COLUMN changed_at TEXT", "changed_at"},
+		{"action", "owner_id", "ALTER TABLE action ADD COLUMN owner_id INTEGER", "owner_id"},
+		{"project", "owner_id", "ALTER TABLE project ADD COLUMN owner_id INTEGER", "owner_id"},
+		{"action", "assignee_id", "ALTER TABLE action ADD COLUMN assignee_id INTEGER", "assignee_id"},
+		{"user", "is_admin", "ALTER TABLE user ADD COLUMN is_admin INTEGER NOT NULL DEFAULT 0", "is_admin"},
+		{"user", "disabled", "ALTER TABLE user ADD COLUMN disabled INTEGER NOT NULL DEFAULT 0", "disabled"},
+		{"project", "archived_at", "ALTER TABLE project ADD COLUMN archived_at TEXT", "archived_at"},
+		{"project", "repeat_interval", "ALTER TABLE project ADD COLUMN repeat_interval TEXT", "repeat_interval"},
+		{"project", "parent_project_id", "ALTER TABLE project ADD COLUMN parent_project_id INTEGER", "parent_project_id"},
+		{"project", "review_interval", "ALTER TABLE project ADD COLUMN review_interval TEXT", "review_interval"},
+		{"project", "last_reviewed_at", "ALTER TABLE project ADD COLUMN last_reviewed_at TEXT", "last_reviewed_at"},
+		{"action", "flagged", "ALTER TABLE action ADD COLUMN flagged INTEGER NOT NULL DEFAULT 0", "flagged"},
+		{"status", "color", "ALTER TABLE status ADD COLUMN color TEXT", "color"},
+		{"action", "location", "ALTER TABLE action ADD COLUMN location TEXT", "location"},
+		{"action", "latitude", "ALTER TABLE action ADD COLUMN latitude REAL", "latitude"},
+		{"action", "longitude", "ALTER TABLE action ADD COLUMN longitude REAL", "longitude"},
+		{"action", "energy", "ALTER TABLE action ADD COLUMN energy TEXT", "energy"},
+		{"action", "waiting_on", "ALTER TABLE action ADD COLUMN waiting_on TEXT", "waiting_on"},
+		{"action", "follow_up", "ALTER TABLE action ADD COLUMN follow_up DATE", "follow_up"},
+		{"action", "start_date", "ALTER TABLE action ADD COLUMN start_date DATE", "start_date"},
+		{"action", "version", "ALTER TABLE action ADD COLUMN version INTEGER NOT NULL DEFAULT 1", "version"},
+		{"project", "version", "ALTER TABLE project ADD COLUMN version INTEGER NOT NULL DEFAULT 1", "version"},
+		{"status", "icon", "ALTER TABLE status ADD COLUMN icon TEXT", "icon"},
+		{"action_history", "skipped_date", "ALTER TABLE action_history ADD COLUMN skipped_date TEXT", "skipped_date"},
 	}
 
 	// Add missing columns
 	for _, column := range columns {
 		// Check if column already exists
 		var columnExists int
-		err = db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('action') WHERE name='%s'", column.name)).Scan(&columnExists)
+		err = db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM pragma_table_info('%s') WHERE name='%s'", column.table, column.name)).Scan(&columnExists)
 		if err != nil {
-			fmt.Printf("⚠️ Could not check if column '%s' exists: %v\n", column.name, err)
+			fmt.Println(i18n.T("MigrationColumnCheckFailed", i18n.Data{"Name": column.name, "Error": err}))
+			failed = true
 			continue
 		}
 
 		if columnExists == 0 {
 			if verbose {
-				fmt.Printf("📝 Adding %s column to action table...\n", column.display)
+				fmt.Println(i18n.T("MigrationAddingColumn", i18n.Data{"Column": column.display, "Table": column.table}))
 			}
 			_, err = db.Exec(column.sql)
 			if err != nil {
-				fmt.Printf("❌ Failed to add %s column: %v\n", column.display, err)
+				fmt.Println(i18n.T("MigrationAddColumnFailed", i18n.Data{"Column": column.display, "Error": err}))
+				failed = true
 				continue
 			}
 			if verbose {
-				fmt.Printf("✅ Successfully added %s column\n", column.display)
+				fmt.Println(i18n.T("MigrationColumnAdded", i18n.Data{"Column": column.display}))
 			}
 		} else {
 			if verbose {
-				fmt.Printf("✅ %s column already exists\n", column.display)
+				fmt.Println(i18n.T("MigrationColumnExists", i18n.Data{"Column": column.display}))
 			}
 		}
 	}
 
+	// Empty dates were stored as '' by earlier versions, which reads back as the zero time
+	for _, statement := range []string{
+		"UPDATE action SET due_date = NULL WHERE due_date = ''",
+		"UPDATE action SET repeat_until = NULL WHERE repeat_until = ''",
+		"UPDATE project SET due_date = NULL WHERE due_date = ''",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			fmt.Println(i18n.T("MigrationClearEmptyDatesFailed", i18n.Data{"Error": err}))
+			failed = true
+		}
+	}
+
+	// Rows created before remote sync existed need a uid, updated_at and triggers
+	if err := database.CreateSyncSchema(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationSyncFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Indexes for the frequent queries, see `projector db explain`
+	if err := database.CreateIndexes(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationIndexesFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Change counters for the ETags of the API list endpoints
+	if err := database.CreateChangeCounters(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationChangeCountersFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Versions that detect concurrent edits through the API
+	if err := database.CreateVersionTriggers(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationVersionTriggersFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Statuses introduced by the workflow in the config file
+	if err := database.CreateWorkflowStatuses(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationWorkflowStatusesFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Status history of the actions for the burndown endpoint
+	if err := database.CreateHistory(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationActionHistoryFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	// Event log of the changes for the changefeed endpoint
+	if err := database.CreateEventLog(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationEventLogFailed", i18n.Data{"Error": err}))
+		failed = true
+	}
+
+	if failed {
+		fmt.Println(i18n.T("MigrationIncomplete"))
+		return false
+	}
+
+	if err := database.SetSchemaVersion(database.GetDatabasePath()); err != nil {
+		fmt.Println(i18n.T("MigrationSchemaVersionFailed", i18n.Data{"Error": err}))
+		return false
+	}
+
 	if verbose {
-		fmt.Println("🔄 Migration completed successfully!")
+		fmt.Println(i18n.T("MigrationCompleted"))
 	}
+	return true
 }
 
-func startAPIServer(verbose bool) {
+func startAPIServer(verbose bool, grpcPort int, readOnly bool) {
 	fmt.Println("Projector - Project and Action Management")
 	fmt.Println("======================================")
 	fmt.Println()
 
 	// Check if database exists
 	if !database.DatabaseExists(database.GetDatabasePath()) {
-		fmt.Println("❌ Database not found. Please run 'projector init' first.")
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	// Log messages of the server, such as the stack traces of requests that
+	// panicked, go to stderr unless serve sent them to a log file
+	if log.Writer() == io.Discard {
+		log.SetOutput(os.Stderr)
+	}
+
+	// Start API server in a goroutine. It serves /healthz right away, but
+	// /readyz only passes once the migration below has completed.
+	server := api.NewServer(8080, database.GetDatabasePath())
+
+	// Send notifications when any are configured
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+		cfg = &config.Config{}
+	}
+	server.SetTrashRetention(cfg.Trash.Retention())
+	server.SetReadOnly(readOnly)
+	server.SetCrashDir(cfg.Server.CrashReportDir)
+	server.SetRequestTimeout(cfg.Server.RequestTimeout())
+	server.SetMaxBodySize(cfg.Server.MaxBodySize())
+	var dispatcher *notify.Dispatcher
+	if len(cfg.Notifications.Rules) > 0 || len(cfg.Escalations) > 0 {
+		dispatcher, err = notify.NewDispatcher(cfg.Notifications)
+		if err != nil {
+			fmt.Println(i18n.T("NotificationsDisabled", i18n.Data{"Error": err}))
+		} else {
+			server.SetDispatcher(dispatcher)
+		}
+	}
+	if err := server.Listen(); err != nil {
+		fmt.Println(i18n.T("APIServerError", i18n.Data{"Error": err}))
 		return
 	}
+	go func() {
+		if err := server.Start(); err != nil {
+			fmt.Println(i18n.T("APIServerError", i18n.Data{"Error": err}))
+		}
+	}()
+
+	if grpcPort > 0 {
+		grpcServer := grpcapi.NewServer(grpcPort, database.GetDatabasePath())
+		grpcServer.SetReadOnly(readOnly)
+		if err := grpcServer.Listen(); err != nil {
+			fmt.Println(i18n.T("GRPCServerError", i18n.Data{"Error": err}))
+			return
+		}
+		go func() {
+			if err := grpcServer.Start(); err != nil {
+				fmt.Println(i18n.T("GRPCServerError", i18n.Data{"Error": err}))
+			}
+		}()
+	}
 
 	// Run migration to ensure database schema is up to date
 	if verbose {
-		fmt.Println("🔄 Checking database schema...")
+		fmt.Println(i18n.T("CheckingDatabaseSchema"))
 	}
-	runMigration(verbose)
+	migrated := runMigration(verbose)
 
 	// Display initial actions
 	displayActions()
 
-	// Start API server in a goroutine
-	server := api.NewServer(8080, database.GetDatabasePath())
-	go func() {
-		if err := server.Start(); err != nil {
-			fmt.Printf("❌ API server error: %v\n", err)
+	// Start sending notifications once the schema is up to date
+	stopBackground := make(chan struct{})
+	defer close(stopBackground)
+	if len(cfg.Notifications.Rules) > 0 && dispatcher != nil {
+		go notify.Run(database.GetDatabasePath(), dispatcher, cfg.Notifications.DigestTime, stopBackground, func(err error) {
+			log.Printf("Notification error: %v", err)
+		})
+		if verbose {
+			fmt.Println(i18n.T("NotificationsEnabled", i18n.Data{"Count": len(cfg.Notifications.Rules)}))
 		}
-	}()
+	}
+
+	// Escalate overdue actions every hour
+	if len(cfg.Escalations) > 0 {
+		if err := escalate.Validate(cfg.Escalations, cfg.Notifications.Channels); err != nil {
+			fmt.Println(i18n.T("EscalationsDisabled", i18n.Data{"Error": err}))
+		} else {
+			go escalate.Run(database.GetDatabasePath(), cfg.Escalations, dispatcher, stopBackground, func(err error) {
+				log.Printf("Escalation error: %v", err)
+			})
+			if verbose {
+				fmt.Println(i18n.T("EscalationsEnabled", i18n.Data{"Count": len(cfg.Escalations)}))
+			}
+		}
+	}
+
+	// Turn unread emails into actions when a mailbox is configured
+	if cfg.Mail.Enabled() && migrated {
+		go mailin.Run(database.GetDatabasePath(), cfg.Mail, stopBackground, func(err error) {
+			log.Printf("Mail import error: %v", err)
+		})
+		if verbose {
+			fmt.Println(i18n.T("MailCheckEnabled", i18n.Data{"Host": cfg.Mail.Host, "Interval": cfg.Mail.Interval()}))
+		}
+	}
+
+	// Permanently remove deleted items once their retention period is over
+	if migrated {
+		go database.RunTrashPurge(database.GetDatabasePath(), cfg.Trash.Retention(), stopBackground, func(err error) {
+			log.Printf("Trash purge error: %v", err)
+		})
+	}
+
+	// Archive done actions once they are older than the configured period
+	if cfg.Archive.DoneAfterDays > 0 && migrated {
+		go database.RunArchive(database.GetDatabasePath(), cfg.Archive.DoneAfterDays, stopBackground, func(archived int) {
+			log.Printf("Archived %d done action(s)", archived)
+		}, func(err error) {
+			log.Printf("Archive error: %v", err)
+		})
+		if verbose {
+			fmt.Println(i18n.T("ArchivingEnabled", i18n.Data{"Count": cfg.Archive.DoneAfterDays}))
+		}
+	}
+
+	// Vacuum and analyze the database once a week when enabled
+	if cfg.Maintenance.Weekly && migrated {
+		go database.RunMaintenance(database.GetDatabasePath(), stopBackground, func(result *database.MaintenanceResult) {
+			log.Printf("Database maintained: %d bytes before, %d bytes after", result.Before.SizeBytes, result.After.SizeBytes)
+		}, func(err error) {
+			log.Printf("Database maintenance error: %v", err)
+		})
+		if verbose {
+			fmt.Println(i18n.T("WeeklyMaintenanceEnabled"))
+		}
+	}
+
+	// An incomplete migration keeps the server running but not ready
+	server.SetReady(migrated)
 
 	// Wait for quit signal
-	fmt.Println("🔄 API server is running. Press 'q' to quit...")
+	fmt.Println(i18n.T("APIServerRunning"))
 
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// Tell systemd the servers accept connections when running as a notify service
+	if err := daemon.Notify("READY=1"); err != nil {
+		log.Printf("systemd notification error: %v", err)
+	}
+
 	// Wait for either 'q' key or signal
 	go func() {
 		var input string
@@ -275,6 +754,7 @@ func startAPIServer(verbose bool) {
 	}()
 
 	<-sigChan
+	daemon.Notify("STOPPING=1")
 	fmt.Println("\n👋 Shutting down Projector...")
 }
 
@@ -282,50 +762,16 @@ func displayActions() {
 	// Get all actions
 	actions, err := database.GetAllActions(database.GetDatabasePath())
 	if err != nil {
-		fmt.Printf("❌ Error retrieving actions: %v\n", err)
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
 		return
 	}
 
 	if len(actions) == 0 {
-		fmt.Println("📝 No actions found. Create some actions to get started!")
+		fmt.Println(i18n.T("NoActionsGetStarted"))
 		return
 	}
 
-	fmt.Printf("📋 Found %d action(s):\n\n", len(actions))
-
-	// Display actions in a nice format
-	for _, action := range actions {
-		fmt.Printf("  %d. %s\n", action.ID, action.Name)
-
-		// Show note if available
-		if action.Note.Valid && action.Note.String != "" {
-			fmt.Printf("     📝 Note: %s\n", action.Note.String)
-		}
-
-		// Show project if available
-		if action.ProjectName.Valid {
-			fmt.Printf("     📁 Project: %s\n", action.ProjectName.String)
-		}
-
-		// Show due date if available
-		if action.DueDate.Valid {
-			fmt.Printf("     📅 Due: %s\n", action.DueDate.String)
-		}
-
-		// Show repeat information if available
-		if action.RepeatCount > 0 && action.RepeatInterval.Valid {
-			fmt.Printf("     🔄 Repeat: %d times every %s", action.RepeatCount, action.RepeatInterval.String)
-			if action.RepeatPattern.Valid && action.RepeatPattern.String != "" {
-				fmt.Printf(" on %s", action.RepeatPattern.String)
-			}
-			if action.RepeatUntil.Valid {
-				fmt.Printf(" until %s", action.RepeatUntil.String)
-			}
-			fmt.Println()
-		}
-
-		// Show status
-		fmt.Printf("     🏷️  Status: %s\n", action.StatusName)
-		fmt.Println()
-	}
+	fmt.Println(i18n.T("FoundActions", i18n.Data{"Count": len(actions)}))
+	fmt.Println()
+	printActions(actions, false, false, false)
 }
@@ -0,0 +1,204 @@
+// Package markdown renders the Markdown of action notes for the terminal:
+// headings, paragraphs, lists with checkboxes, block quotes, code blocks,
+// rules, links, and bold, italic and code text. It covers the Markdown that
+// notes are written in rather than all of CommonMark, and shows what it does
+// not know as it is written.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/joelgrimberg/projector/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var (
+	headingStyle = theme.Foreground(theme.Highlight).Bold(true)
+	linkStyle    = theme.Foreground(theme.Accent).Underline(true)
+	codeStyle    = theme.Foreground(theme.Online)
+	mutedStyle   = theme.Foreground(theme.Muted)
+	boldStyle    = lipgloss.NewStyle().Bold(true)
+	italicStyle  = lipgloss.NewStyle().Italic(true)
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listPattern    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	checkPattern   = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	rulePattern    = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	fencePattern   = regexp.MustCompile("^\\s*(```|~~~)")
+	quotePattern   = regexp.MustCompile(`^\s*>\s?(.*)$`)
+
+	// inlinePattern matches, in order of the groups: code, a link, an
+	// autolink, bold and italic text
+	inlinePattern = regexp.MustCompile("`([^`]+)`" +
+		`|\[([^\]]+)\]\(([^)\s]+)\)` +
+		`|<(https?://[^>\s]+)>` +
+		`|\*\*([^*]+)\*\*|__([^_]+)__` +
+		`|\*([^*\s][^*]*)\*|\b_([^_]+)_\b`)
+)
+
+// Render renders Markdown as text for the terminal, wrapping paragraphs,
+// list items and quotes at width. A width of 0 does not wrap.
+func Render(source string, width int) string {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	var out []string
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			out = append(out, wrap(inline(strings.Join(paragraph, " ")), width, "", ""))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fencePattern.MatchString(line):
+			flush()
+			fence := fencePattern.FindStringSubmatch(line)[1]
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				out = append(out, "  "+codeStyle.Render(lines[i]))
+			}
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+		case headingPattern.MatchString(trimmed):
+			flush()
+			out = append(out, headingStyle.Render(headingPattern.FindStringSubmatch(trimmed)[2]))
+		case rulePattern.MatchString(line) && len(paragraph) == 0:
+			ruleWidth := 40
+			if width > 0 {
+				ruleWidth = min(width, ruleWidth)
+			}
+			out = append(out, mutedStyle.Render(strings.Repeat("─", ruleWidth)))
+		case listPattern.MatchString(line):
+			flush()
+			out = append(out, listItem(listPattern.FindStringSubmatch(line), width))
+		case quotePattern.MatchString(line):
+			flush()
+			bar := mutedStyle.Render("│ ")
+			out = append(out, wrap(inline(quotePattern.FindStringSubmatch(line)[1]), width, bar, bar))
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
+
+// listItem renders a list item with its bullet, number or checkbox, and the
+// lines after the first lined up with its text
+func listItem(match []string, width int) string {
+	indent := strings.Repeat("  ", len(strings.ReplaceAll(match[1], "\t", "    "))/2)
+	marker, text := match[2], inline(match[3])
+
+	switch {
+	case checkPattern.MatchString(match[3]):
+		check := checkPattern.FindStringSubmatch(match[3])
+		done := check[1] != " "
+		marker, text = checkbox(done), inline(check[2])
+		if done {
+			text = mutedStyle.Render(text)
+		}
+	case strings.ContainsAny(marker, ".)"):
+		// Numbers are kept, so a list reads as it was written
+	default:
+		marker = "•"
+	}
+
+	prefix := indent + marker + " "
+	return wrap(text, width, prefix, strings.Repeat(" ", ansi.StringWidth(prefix)))
+}
+
+// checkbox returns the mark of a task list item. Without colors it is kept
+// as text, which reads the same on every terminal and screen reader.
+func checkbox(done bool) string {
+	switch {
+	case !theme.Enabled() && done:
+		return "[x]"
+	case !theme.Enabled():
+		return "[ ]"
+	case done:
+		return "☑"
+	default:
+		return "☐"
+	}
+}
+
+// inline renders code, links, and bold and italic text within a line
+func inline(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range inlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:match[0]])
+		last = match[1]
+
+		group := func(n int) string {
+			if match[2*n] < 0 {
+				return ""
+			}
+			return text[match[2*n]:match[2*n+1]]
+		}
+		switch {
+		case group(1) != "":
+			b.WriteString(codeStyle.Render(group(1)))
+		case group(2) != "":
+			b.WriteString(linkStyle.Render(group(2)))
+			if group(2) != group(3) {
+				b.WriteString(" " + mutedStyle.Render("("+group(3)+")"))
+			}
+		case group(4) != "":
+			b.WriteString(linkStyle.Render(group(4)))
+		case group(5) != "" || group(6) != "":
+			b.WriteString(boldStyle.Render(group(5) + group(6)))
+		default:
+			b.WriteString(italicStyle.Render(group(7) + group(8)))
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// wrap wraps text at width, starting the first line with first and the
+// other lines with rest
+func wrap(text string, width int, first, rest string) string {
+	if width > 0 {
+		text = ansi.Wrap(text, max(width-ansi.StringWidth(first), 10), "")
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		if i == 0 {
+			lines[i] = first + lines[i]
+		} else {
+			lines[i] = rest + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// urlPattern matches http and https URLs, which end before whitespace, the
+// brackets and quotes around them, and the punctuation after them
+var urlPattern = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]*[^\s<>()\[\]"'` + "`" + `.,;:!?]`)
+
+// Links returns the http and https URLs in Markdown, in links, autolinks and
+// plain text, in the order they appear and without duplicates
+func Links(source string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range urlPattern.FindAllString(source, -1) {
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
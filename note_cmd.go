@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func noteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "note <id>",
+		Short: "Edit the note of an action in your editor",
+		Long: `Edit the note of an action in your editor. The note is opened as a Markdown
+file in $VISUAL or $EDITOR (vi when neither is set) and saved when the
+editor exits. Saving an empty file removes the note.
+
+The editor command may have arguments, such as
+
+  EDITOR="code --wait" projector note 12`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+				return
+			}
+			if action == nil {
+				fmt.Println(i18n.T("ActionNotFound", i18n.Data{"ID": actionID}))
+				return
+			}
+
+			file, err := os.CreateTemp("", fmt.Sprintf("projector-note-%d-*.md", actionID))
+			if err != nil {
+				fmt.Printf("❌ Failed to create note file: %v\n", err)
+				return
+			}
+			path := file.Name()
+			_, err = file.WriteString(action.Note.String)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(path)
+				fmt.Printf("❌ Failed to write note file: %v\n", err)
+				return
+			}
+
+			if err := runEditor(path); err != nil {
+				os.Remove(path)
+				fmt.Printf("❌ Editor failed, the note was not changed: %v\n", err)
+				return
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				os.Remove(path)
+				fmt.Printf("❌ Failed to read note file: %v\n", err)
+				return
+			}
+			// Editors end the last line with a newline, which is not part of the note
+			note := strings.TrimRight(string(data), "\r\n")
+			if note == strings.TrimRight(action.Note.String, "\r\n") {
+				os.Remove(path)
+				fmt.Printf("📝 Note of action %d unchanged\n", actionID)
+				return
+			}
+
+			// The note may have been changed elsewhere while it was being
+			// edited, such as through the API; the edit is kept in its file then
+			current, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+				fmt.Printf("📝 Your note is kept in %s\n", path)
+				return
+			}
+			if current == nil || current.Note != action.Note {
+				fmt.Printf("❌ The note of action %d was changed or the action deleted while you were editing\n", actionID)
+				fmt.Printf("📝 Your note is kept in %s\n", path)
+				return
+			}
+
+			if err := database.UpdateAction(database.GetDatabasePath(), actionID, database.ActionUpdate{Note: &note}); err != nil {
+				fmt.Printf("❌ Failed to update action: %v\n", err)
+				fmt.Printf("📝 Your note is kept in %s\n", path)
+				return
+			}
+			os.Remove(path)
+
+			if note == "" {
+				fmt.Printf("✅ Removed the note of action %d\n", actionID)
+			} else {
+				fmt.Printf("✅ Updated the note of action %d\n", actionID)
+			}
+			applyRules(action, actionID)
+			fireActionHook(hooks.ActionUpdated, actionID)
+		},
+	}
+}
+
+// editorCommand returns the editor of the user: $VISUAL, then $EDITOR,
+// then vi
+func editorCommand() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(name)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// runEditor opens a file in the editor of the user on the terminal and waits
+// for it to exit. The editor is run by the shell, so it may have arguments.
+func runEditor(path string) error {
+	editor := exec.Command("sh", "-c", editorCommand()+` "$1"`, "sh", path)
+	editor.Stdin = os.Stdin
+	editor.Stdout, editor.Stderr = output.Terminal()
+	return editor.Run()
+}
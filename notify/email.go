@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+)
+
+// EmailSender sends notifications as plain text emails over SMTP
+type EmailSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewEmailSender creates a sender for an email channel. The port defaults to
+// 587 with STARTTLS, port 465 uses implicit TLS.
+func NewEmailSender(channel config.Channel) *EmailSender {
+	port := channel.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	return &EmailSender{
+		host:     channel.SMTPHost,
+		port:     port,
+		username: channel.Username,
+		password: channel.Password,
+		from:     channel.From,
+		to:       channel.To,
+	}
+}
+
+// Send emails the notification to all recipients
+func (s *EmailSender) Send(n Notification) error {
+	message := s.buildMessage(n)
+	addr := net.JoinHostPort(s.host, fmt.Sprint(s.port))
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	if s.port != 465 {
+		// SendMail upgrades to STARTTLS when the server supports it
+		if err := smtp.SendMail(addr, auth, s.from, s.to, message); err != nil {
+			return fmt.Errorf("failed to send email: %v", err)
+		}
+		return nil
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: s.host})
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %v", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to SMTP server: %v", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %v", err)
+		}
+	}
+	if err := client.Mail(s.from); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	for _, recipient := range s.to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to send email to %s: %v", recipient, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return client.Quit()
+}
+
+// buildMessage renders the notification as an RFC 5322 message
+func (s *EmailSender) buildMessage(n Notification) []byte {
+	headers := []string{
+		"From: " + s.from,
+		"To: " + strings.Join(s.to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", n.Title),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+
+	body := strings.ReplaceAll(n.Message, "\n", "\r\n")
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body + "\r\n")
+}
@@ -0,0 +1,566 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+)
+
+// SendOverdue notifies about actions that became overdue. Every action is
+// reported once per channel. It returns the number of notifications sent.
+func SendOverdue(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventOverdue)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	actions, err := database.GetOverdueActions(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving overdue actions: %v", err)
+	}
+
+	sent := 0
+	for _, action := range actions {
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			key := fmt.Sprint(action.ID)
+			already, err := database.NotificationSent(dbPath, EventOverdue, rule.Channel, key)
+			if err != nil {
+				return sent, err
+			}
+			if already {
+				continue
+			}
+
+			n := Notification{
+				Event:   EventOverdue,
+				Title:   "Action overdue",
+				Message: fmt.Sprintf("%s was due on %s", describeAction(action), datefmt.Date(action.DueDate.String)),
+			}
+			if err := d.SendTo(rule.Channel, n); err != nil {
+				return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+			}
+			if err := database.RecordNotification(dbPath, EventOverdue, rule.Channel, key); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// UpcomingRepeatDays is the number of days ahead the digest lists repeating actions
+const UpcomingRepeatDays = 7
+
+// Digest is the morning summary of the actions
+type Digest struct {
+	Date               string // YYYY-MM-DD
+	DueToday           []database.Action
+	Overdue            []database.Action
+	CompletedYesterday []database.Action
+	UpcomingRepeats    []database.Action // Repeating actions due in the next UpcomingRepeatDays days
+}
+
+// BuildDigest collects the digest for the day of now
+func BuildDigest(dbPath string, now time.Time) (*Digest, error) {
+	digest := &Digest{Date: now.Format("2006-01-02")}
+
+	var err error
+	digest.DueToday, err = database.GetActionsDueOn(dbPath, digest.Date)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving today's actions: %v", err)
+	}
+
+	digest.Overdue, err = database.GetOverdueActions(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving overdue actions: %v", err)
+	}
+
+	digest.CompletedYesterday, err = database.GetActionsCompletedOn(dbPath, now.AddDate(0, 0, -1).Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving completed actions: %v", err)
+	}
+
+	upcoming, err := database.GetActionsDueBetween(dbPath, now.AddDate(0, 0, 1).Format("2006-01-02"), now.AddDate(0, 0, UpcomingRepeatDays).Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving upcoming actions: %v", err)
+	}
+	for _, action := range upcoming {
+		if action.IsRepeating() {
+			digest.UpcomingRepeats = append(digest.UpcomingRepeats, action)
+		}
+	}
+
+	return digest, nil
+}
+
+// filter returns the part of the digest a rule applies to
+func (digest *Digest) filter(rule config.Rule) *Digest {
+	return &Digest{
+		Date:               digest.Date,
+		DueToday:           filterRule(digest.DueToday, rule),
+		Overdue:            filterRule(digest.Overdue, rule),
+		CompletedYesterday: filterRule(digest.CompletedYesterday, rule),
+		UpcomingRepeats:    filterRule(digest.UpcomingRepeats, rule),
+	}
+}
+
+// IsEmpty reports whether the digest lists no actions
+func (digest *Digest) IsEmpty() bool {
+	return len(digest.DueToday) == 0 && len(digest.Overdue) == 0 &&
+		len(digest.CompletedYesterday) == 0 && len(digest.UpcomingRepeats) == 0
+}
+
+// SendDigest sends the digest of today's, overdue, yesterday's completed and
+// upcoming repeating actions. Each channel receives at most one digest per
+// day. It returns the number of digests sent.
+func SendDigest(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventDigest)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	digest, err := BuildDigest(dbPath, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	today := digest.Date
+
+	sent := 0
+	for _, rule := range rules {
+		key := today + "/" + rule.Project
+		if rule.User != "" {
+			key += "/" + rule.User
+		}
+		already, err := database.NotificationSent(dbPath, EventDigest, rule.Channel, key)
+		if err != nil {
+			return sent, err
+		}
+		if already {
+			continue
+		}
+
+		n := Notification{
+			Event:   EventDigest,
+			Title:   "Projector digest for " + datefmt.Absolute(today),
+			Message: FormatDigest(digest.filter(rule)),
+		}
+		if rule.Project != "" {
+			n.Title += " (" + rule.Project + ")"
+		}
+		if rule.User != "" {
+			n.Title += " for " + rule.User
+		}
+
+		if err := d.SendTo(rule.Channel, n); err != nil {
+			return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+		}
+		if err := database.RecordNotification(dbPath, EventDigest, rule.Channel, key); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// SendReminders sends a reminder for every action due today that has no
+// reminders of its own, see SendActionReminders. Each action is reminded once
+// per channel. It returns the number of reminders sent.
+func SendReminders(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventReminder)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	today := time.Now().Format("2006-01-02")
+
+	actions, err := database.GetActionsDueOn(dbPath, today)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving today's actions: %v", err)
+	}
+
+	sent := 0
+	for _, action := range actions {
+		if len(action.Reminders) > 0 {
+			continue
+		}
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			key := reminderKey(action.ID, today)
+			already, err := database.NotificationSent(dbPath, EventReminder, rule.Channel, key)
+			if err != nil {
+				return sent, err
+			}
+			if already {
+				continue
+			}
+
+			message := describeAction(action) + " is due today"
+			if action.Note.Valid && action.Note.String != "" {
+				message += "\n\n" + action.Note.String
+			}
+
+			n := Notification{
+				Event:   EventReminder,
+				Title:   "Reminder: " + action.Name,
+				Message: message,
+			}
+			if err := d.SendTo(rule.Channel, n); err != nil {
+				return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+			}
+			if err := database.RecordNotification(dbPath, EventReminder, rule.Channel, key); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendActionReminders sends the reminders of actions with reminders of their
+// own, such as a day or two hours before the action is due, once their moment
+// has passed. Each reminder is sent once per due date and channel. It returns
+// the number of reminders sent.
+func SendActionReminders(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventReminder)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	at, err := d.dueTime()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	actions, err := database.GetActionsWithReminders(dbPath, now.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving actions with reminders: %v", err)
+	}
+
+	sent := 0
+	for _, action := range actions {
+		reminders := offsetReminders(action, at, now)
+		if len(reminders) == 0 || reminders[0].fireAt.After(now) {
+			continue
+		}
+		reminder := reminders[0]
+
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			key := offsetReminderKey(action.ID, database.StoredDate(action.DueDate.String), reminder.offset)
+			already, err := database.NotificationSent(dbPath, EventReminder, rule.Channel, key)
+			if err != nil {
+				return sent, err
+			}
+			if already {
+				continue
+			}
+
+			message := describeAction(action) + " is due " + datefmt.Date(reminder.due.Format("2006-01-02")) + " at " + datefmt.Time(reminder.due)
+			if action.Note.Valid && action.Note.String != "" {
+				message += "\n\n" + action.Note.String
+			}
+
+			n := Notification{
+				Event:   EventReminder,
+				Title:   "Reminder: " + action.Name,
+				Message: message,
+			}
+			if err := d.SendTo(rule.Channel, n); err != nil {
+				return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+			}
+			if err := database.RecordNotification(dbPath, EventReminder, rule.Channel, key); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendStarted notifies about the deferred actions whose start date is today,
+// so they show up once they become active. Every action is reported once per
+// start date and channel.
+func SendStarted(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventStarted)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	today := time.Now().Format("2006-01-02")
+
+	actions, err := database.GetActionsStartingOn(dbPath, today)
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving started actions: %v", err)
+	}
+
+	sent := 0
+	for _, action := range actions {
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			key := reminderKey(action.ID, today)
+			already, err := database.NotificationSent(dbPath, EventStarted, rule.Channel, key)
+			if err != nil {
+				return sent, err
+			}
+			if already {
+				continue
+			}
+
+			message := describeAction(action) + " starts today"
+			if action.DueDate.Valid {
+				message += ", due " + datefmt.Date(action.DueDate.String)
+			}
+
+			n := Notification{
+				Event:   EventStarted,
+				Title:   "Action started: " + action.Name,
+				Message: message,
+			}
+			if err := d.SendTo(rule.Channel, n); err != nil {
+				return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+			}
+			if err := database.RecordNotification(dbPath, EventStarted, rule.Channel, key); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendFollowUps nudges about the actions waiting on someone whose follow-up
+// date has arrived, once per follow-up date and channel
+func SendFollowUps(dbPath string, d *Dispatcher) (int, error) {
+	rules := d.Rules(EventFollowUp)
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	actions, err := database.GetFollowUpsDue(dbPath, time.Now().Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving follow-ups: %v", err)
+	}
+
+	sent := 0
+	for _, action := range actions {
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			key := reminderKey(action.ID, database.StoredDate(action.FollowUp.String))
+			already, err := database.NotificationSent(dbPath, EventFollowUp, rule.Channel, key)
+			if err != nil {
+				return sent, err
+			}
+			if already {
+				continue
+			}
+
+			n := Notification{
+				Event:   EventFollowUp,
+				Title:   "Follow up: " + action.Name,
+				Message: fmt.Sprintf("%s is waiting on %s, follow up since %s", describeAction(action), action.WaitingOn.String, datefmt.Date(action.FollowUp.String)),
+			}
+			if err := d.SendTo(rule.Channel, n); err != nil {
+				return sent, fmt.Errorf("channel %s: %v", rule.Channel, err)
+			}
+			if err := database.RecordNotification(dbPath, EventFollowUp, rule.Channel, key); err != nil {
+				return sent, err
+			}
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendActionCreated notifies the channels following the project of a newly created action
+func SendActionCreated(dbPath string, d *Dispatcher, actionID uint) error {
+	action, err := database.GetActionByID(dbPath, actionID)
+	if err != nil || action == nil || !action.ProjectName.Valid {
+		return err
+	}
+
+	n := Notification{
+		Event:   EventProjectAction,
+		Title:   "New action in " + action.ProjectName.String,
+		Message: describeAction(*action),
+	}
+
+	return sendToRules(d, *action, n)
+}
+
+// SendAssigned notifies the assignee of an action that it was assigned to them
+func SendAssigned(dbPath string, d *Dispatcher, actionID uint) error {
+	action, err := database.GetActionByID(dbPath, actionID)
+	if err != nil || action == nil || !action.AssigneeName.Valid {
+		return err
+	}
+
+	message := describeAction(*action)
+	if action.DueDate.Valid {
+		message += ", due " + datefmt.Date(action.DueDate.String)
+	}
+
+	n := Notification{
+		Event:   EventAssigned,
+		Title:   "Action assigned to " + action.AssigneeName.String,
+		Message: message,
+	}
+
+	return sendToRules(d, *action, n)
+}
+
+// SendDueChanged notifies the assignee of an action that its due date changed
+func SendDueChanged(dbPath string, d *Dispatcher, actionID uint) error {
+	action, err := database.GetActionByID(dbPath, actionID)
+	if err != nil || action == nil || !action.AssigneeName.Valid {
+		return err
+	}
+
+	message := describeAction(*action) + " no longer has a due date"
+	if action.DueDate.Valid {
+		message = describeAction(*action) + " is now due " + datefmt.Date(action.DueDate.String)
+	}
+
+	n := Notification{
+		Event:   EventDueChanged,
+		Title:   "Due date changed",
+		Message: message,
+	}
+
+	return sendToRules(d, *action, n)
+}
+
+// sendToRules delivers a notification about an action to the channels of all
+// rules for the event that match the action
+func sendToRules(d *Dispatcher, action database.Action, n Notification) error {
+	var errs []string
+	for _, rule := range d.Rules(n.Event) {
+		if !Matches(rule, action) {
+			continue
+		}
+		if err := d.SendTo(rule.Channel, n); err != nil {
+			errs = append(errs, fmt.Sprintf("channel %s: %v", rule.Channel, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// FormatDigest renders the digest as a plain text message body
+func FormatDigest(digest *Digest) string {
+	if digest.IsEmpty() {
+		return "Nothing due today, enjoy!"
+	}
+
+	var b strings.Builder
+	section := func(title string, actions []database.Action, describe func(database.Action) string) {
+		if len(actions) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%d):\n", title, len(actions))
+		for _, action := range actions {
+			fmt.Fprintf(&b, "• %s\n", describe(action))
+		}
+	}
+
+	withDueDate := func(action database.Action) string {
+		return fmt.Sprintf("%s (due %s)", describeAction(action), datefmt.Date(action.DueDate.String))
+	}
+	section("Due today", digest.DueToday, describeAction)
+	section("Overdue", digest.Overdue, withDueDate)
+	section("Completed yesterday", digest.CompletedYesterday, describeAction)
+	section("Upcoming repeats", digest.UpcomingRepeats, func(action database.Action) string {
+		return fmt.Sprintf("%s (due %s, %s)", describeAction(action), datefmt.Date(action.DueDate.String), action.RepeatDescription())
+	})
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Run sends overdue and started notifications and the reminders of actions
+// every minute, and the digest, reminders on the due date and follow-ups once
+// a day after digestTime (HH:MM, local time) until stop is closed
+func Run(dbPath string, d *Dispatcher, digestTime string, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		if _, err := SendOverdue(dbPath, d); err != nil {
+			onError(err)
+		}
+		if _, err := SendStarted(dbPath, d); err != nil {
+			onError(err)
+		}
+		if _, err := SendActionReminders(dbPath, d); err != nil {
+			onError(err)
+		}
+		if digestTime != "" && time.Now().Format("15:04") >= digestTime {
+			if _, err := SendDigest(dbPath, d); err != nil {
+				onError(err)
+			}
+			if _, err := SendReminders(dbPath, d); err != nil {
+				onError(err)
+			}
+			if _, err := SendFollowUps(dbPath, d); err != nil {
+				onError(err)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// describeAction returns a one line description of an action
+func describeAction(action database.Action) string {
+	description := fmt.Sprintf("#%d %s", action.ID, action.Name)
+	if action.ProjectName.Valid {
+		description += " [" + action.ProjectName.String + "]"
+	}
+	return description
+}
+
+// filterRule returns the actions a rule applies to
+func filterRule(actions []database.Action, rule config.Rule) []database.Action {
+	var filtered []database.Action
+	for _, action := range actions {
+		if Matches(rule, action) {
+			filtered = append(filtered, action)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+)
+
+// Notification events that rules can subscribe to
+const (
+	EventOverdue       = "overdue"        // An action became overdue
+	EventProjectAction = "project_action" // An action was added to a project
+	EventDigest        = "digest"         // Daily digest of today's and overdue actions
+	EventReminder      = "reminder"       // Reminder for each action due today
+	EventAssigned      = "assigned"       // An action was assigned to a user
+	EventDueChanged    = "due_changed"    // The due date of an assigned action changed
+	EventEscalated     = "escalated"      // An overdue action was escalated, sent to the channel of the escalation
+	EventFollowUp      = "follow_up"      // The follow-up date of an action waiting on someone arrived
+	EventStarted       = "started"        // The start date of a deferred action arrived
+)
+
+// Notification is a message delivered to a channel
+type Notification struct {
+	Event   string
+	Title   string
+	Message string
+}
+
+// Sender delivers notifications to a single channel
+type Sender interface {
+	Send(n Notification) error
+}
+
+// Dispatcher routes notifications to channels according to the configured rules
+type Dispatcher struct {
+	senders     map[string]Sender
+	rules       []config.Rule
+	digestTime  string
+	disabled    map[string]bool
+	preferences preferences
+	users       map[string]preferences
+}
+
+// NewSender creates the sender for a configured channel
+func NewSender(channel config.Channel) (Sender, error) {
+	switch channel.Type {
+	case "slack":
+		if channel.WebhookURL == "" {
+			return nil, fmt.Errorf("slack channel requires a webhook_url")
+		}
+		return NewSlackSender(channel.WebhookURL), nil
+	case "email":
+		if channel.SMTPHost == "" || channel.From == "" || len(channel.To) == 0 {
+			return nil, fmt.Errorf("email channel requires smtp_host, from and to")
+		}
+		return NewEmailSender(channel), nil
+	case "ntfy":
+		if channel.Topic == "" {
+			return nil, fmt.Errorf("ntfy channel requires a topic")
+		}
+		return NewNtfySender(channel.Server, channel.Topic, channel.Token), nil
+	case "pushover":
+		if channel.Token == "" || channel.UserKey == "" {
+			return nil, fmt.Errorf("pushover channel requires a token and user_key")
+		}
+		return NewPushoverSender(channel.Token, channel.UserKey), nil
+	default:
+		return nil, fmt.Errorf("unknown channel type: %s", channel.Type)
+	}
+}
+
+// NewDispatcher creates a dispatcher for the notification config
+func NewDispatcher(cfg config.Notifications) (*Dispatcher, error) {
+	d := &Dispatcher{
+		senders:    make(map[string]Sender),
+		rules:      cfg.Rules,
+		digestTime: cfg.DigestTime,
+		disabled:   make(map[string]bool),
+		users:      make(map[string]preferences),
+	}
+
+	var err error
+	d.preferences, err = newPreferences(cfg.Preferences)
+	if err != nil {
+		return nil, err
+	}
+	for username, configured := range cfg.Users {
+		prefs, err := newPreferences(configured)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %v", username, err)
+		}
+		d.users[username] = prefs
+	}
+
+	for name, channel := range cfg.Channels {
+		sender, err := NewSender(channel)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %v", name, err)
+		}
+		d.senders[name] = sender
+		d.disabled[name] = channel.Disabled
+	}
+
+	for _, rule := range cfg.Rules {
+		if _, ok := d.senders[rule.Channel]; !ok {
+			return nil, fmt.Errorf("rule for %s uses unknown channel: %s", rule.Event, rule.Channel)
+		}
+	}
+
+	return d, nil
+}
+
+// Rules returns the rules subscribed to an event that may send now. Rules to
+// a disabled channel, rules left out by digest_only and rules in their quiet
+// hours are skipped; the scheduled notifications they skip are sent once the
+// quiet hours end, as they are only recorded as sent when they were sent.
+func (d *Dispatcher) Rules(event string) []config.Rule {
+	now := time.Now()
+	var rules []config.Rule
+	for _, rule := range d.subscribedRules(event) {
+		if !d.preferencesFor(rule).quiet.contains(now) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// subscribedRules returns the rules subscribed to an event, regardless of
+// their quiet hours
+func (d *Dispatcher) subscribedRules(event string) []config.Rule {
+	var rules []config.Rule
+	for _, rule := range d.rules {
+		if rule.Event == event && d.subscribed(rule) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Matches reports whether a rule applies to an action, based on the project
+// and assignee the rule is limited to
+func Matches(rule config.Rule, action database.Action) bool {
+	if rule.Project != "" && rule.Project != action.ProjectName.String {
+		return false
+	}
+	if rule.User != "" && rule.User != action.AssigneeName.String {
+		return false
+	}
+	return true
+}
+
+// SendTo delivers a notification to a single channel
+func (d *Dispatcher) SendTo(channel string, n Notification) error {
+	sender, ok := d.senders[channel]
+	if !ok {
+		return fmt.Errorf("unknown channel: %s", channel)
+	}
+	return sender.Send(n)
+}
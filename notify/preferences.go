@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+)
+
+// quietHours is a validated config.QuietHours, with both times as HH:MM
+type quietHours struct {
+	start string
+	end   string
+}
+
+// preferences is a validated config.Preferences
+type preferences struct {
+	quiet      *quietHours
+	digestOnly bool
+}
+
+// newPreferences validates the preferences of the config
+func newPreferences(configured config.Preferences) (preferences, error) {
+	prefs := preferences{digestOnly: configured.DigestOnly}
+	if configured.QuietHours == nil {
+		return prefs, nil
+	}
+
+	start, err := time.Parse("15:04", configured.QuietHours.Start)
+	if err != nil {
+		return prefs, fmt.Errorf("invalid quiet hours start %q, expected HH:MM", configured.QuietHours.Start)
+	}
+	end, err := time.Parse("15:04", configured.QuietHours.End)
+	if err != nil {
+		return prefs, fmt.Errorf("invalid quiet hours end %q, expected HH:MM", configured.QuietHours.End)
+	}
+	if start.Equal(end) {
+		return prefs, fmt.Errorf("quiet hours start and end are both %s", configured.QuietHours.Start)
+	}
+
+	prefs.quiet = &quietHours{start: start.Format("15:04"), end: end.Format("15:04")}
+	return prefs, nil
+}
+
+// contains reports whether a moment falls in the quiet hours
+func (q *quietHours) contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	clock := t.Format("15:04")
+	if q.start < q.end {
+		return clock >= q.start && clock < q.end
+	}
+	return clock >= q.start || clock < q.end
+}
+
+// after returns the first moment from t on outside the quiet hours
+func (q *quietHours) after(t time.Time) time.Time {
+	if !q.contains(t) {
+		return t
+	}
+	end, _ := time.Parse("15:04", q.end)
+	next := time.Date(t.Year(), t.Month(), t.Day(), end.Hour(), end.Minute(), 0, 0, t.Location())
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// preferencesFor returns the preferences of a rule: those of the user it is
+// limited to when the user has preferences of their own, or the global ones
+func (d *Dispatcher) preferencesFor(rule config.Rule) preferences {
+	if prefs, ok := d.users[rule.User]; ok && rule.User != "" {
+		return prefs
+	}
+	return d.preferences
+}
+
+// subscribed reports whether a rule sends notifications at all: its channel
+// is enabled, and the event is the digest when only digests are wanted
+func (d *Dispatcher) subscribed(rule config.Rule) bool {
+	if d.disabled[rule.Channel] {
+		return false
+	}
+	return !d.preferencesFor(rule).digestOnly || rule.Event == EventDigest
+}
+
+// Allows reports whether a notification of an event may be sent to a channel
+// now, outside of the rules, such as for escalations. The global preferences
+// apply.
+func (d *Dispatcher) Allows(channel, event string, now time.Time) bool {
+	if d.disabled[channel] {
+		return false
+	}
+	if d.preferences.digestOnly && event != EventDigest {
+		return false
+	}
+	return !d.preferences.quiet.contains(now)
+}
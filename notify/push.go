@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultNtfyServer = "https://ntfy.sh"
+	pushoverAPIURL    = "https://api.pushover.net/1/messages.json"
+)
+
+// NtfySender publishes notifications to an ntfy topic
+type NtfySender struct {
+	server     string
+	topic      string
+	token      string
+	httpClient *http.Client
+}
+
+// NewNtfySender creates a sender for an ntfy topic. An empty server uses ntfy.sh,
+// the token is only needed for protected topics.
+func NewNtfySender(server, topic, token string) *NtfySender {
+	if server == "" {
+		server = defaultNtfyServer
+	}
+
+	return &NtfySender{
+		server:     strings.TrimSuffix(server, "/"),
+		topic:      topic,
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send publishes the notification to the topic
+func (s *NtfySender) Send(n Notification) error {
+	req, err := http.NewRequest("POST", s.server+"/"+url.PathEscape(s.topic), strings.NewReader(n.Message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", n.Title))
+	req.Header.Set("Tags", n.Event)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to ntfy: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from ntfy: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// PushoverSender sends notifications through the Pushover API
+type PushoverSender struct {
+	apiURL     string
+	token      string
+	userKey    string
+	httpClient *http.Client
+}
+
+// NewPushoverSender creates a sender for a Pushover application token and user key
+func NewPushoverSender(token, userKey string) *PushoverSender {
+	return &PushoverSender{
+		apiURL:     pushoverAPIURL,
+		token:      token,
+		userKey:    userKey,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts the notification to Pushover
+func (s *PushoverSender) Send(n Notification) error {
+	resp, err := s.httpClient.PostForm(s.apiURL, url.Values{
+		"token":   {s.token},
+		"user":    {s.userKey},
+		"title":   {n.Title},
+		"message": {n.Message},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to post to Pushover: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from Pushover: %s", resp.Status)
+	}
+
+	return nil
+}
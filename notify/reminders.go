@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// UpcomingReminderDays is how many days ahead UpcomingReminders looks
+const UpcomingReminderDays = 7
+
+// DefaultDueTime is the time of day (HH:MM) actions are due when no digest
+// time is configured. The reminders of an action count back from its due date
+// at the digest time, or at this time.
+const DefaultDueTime = "09:00"
+
+// ScheduledReminder is a reminder Run will send to a channel
+type ScheduledReminder struct {
+	Action  database.Action
+	Channel string
+	Offset  string // Reminder of the action such as -1d, empty for the reminder on the due date
+	FireAt  time.Time
+}
+
+// key identifies the reminder in the notification log
+func (r *ScheduledReminder) key() string {
+	date := database.StoredDate(r.Action.DueDate.String)
+	if r.Offset == "" {
+		return reminderKey(r.Action.ID, date)
+	}
+	return offsetReminderKey(r.Action.ID, date, r.Offset)
+}
+
+// reminderKey identifies the reminder of an action for a due date in the
+// notification log
+func reminderKey(actionID uint, date string) string {
+	return fmt.Sprintf("%d/%s", actionID, date)
+}
+
+// offsetReminderKey identifies a reminder of an action with an offset before
+// a due date in the notification log, so a new due date reminds again
+func offsetReminderKey(actionID uint, date, offset string) string {
+	return fmt.Sprintf("%d/%s/%s", actionID, date, offset)
+}
+
+// offsetReminder is a reminder of an action with the moment it fires and the
+// moment the action is due
+type offsetReminder struct {
+	offset string
+	fireAt time.Time
+	due    time.Time
+}
+
+// dueTime returns the time of day actions are due
+func (d *Dispatcher) dueTime() (time.Time, error) {
+	dueTime := d.digestTime
+	if dueTime == "" {
+		dueTime = DefaultDueTime
+	}
+	at, err := time.Parse("15:04", dueTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid digest time %s: %v", dueTime, err)
+	}
+	return at, nil
+}
+
+// offsetReminders returns the reminders of an action at the moments they
+// fire, counting back from its due date at the due time. When the moments of
+// several reminders have passed, only the latest of them is returned, so an
+// action due soon after it was created does not send all of them at once.
+func offsetReminders(action database.Action, at, now time.Time) []offsetReminder {
+	day, err := time.ParseInLocation("2006-01-02", database.StoredDate(action.DueDate.String), now.Location())
+	if err != nil {
+		return nil
+	}
+	due := time.Date(day.Year(), day.Month(), day.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+
+	var reminders []offsetReminder
+	for _, offset := range action.Reminders {
+		before, err := database.ParseReminderOffset(offset)
+		if err != nil {
+			continue
+		}
+		reminders = append(reminders, offsetReminder{offset: offset, fireAt: due.Add(-before), due: due})
+	}
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].fireAt.Before(reminders[j].fireAt)
+	})
+
+	latest := 0
+	for i, reminder := range reminders {
+		if !reminder.fireAt.After(now) {
+			latest = i
+		}
+	}
+	return reminders[latest:]
+}
+
+// UpcomingReminders returns the reminders Run sends in the next
+// UpcomingReminderDays days, in the order they fire. An action without
+// reminders of its own is reminded at the digest time of the day it is due,
+// or right away when the digest time has passed and the reminder was not sent
+// yet; without a digest time Run sends no such reminders. An action with
+// reminders is reminded at each of their offsets before it is due instead. A
+// reminder in the quiet hours of its rule fires when they end.
+func UpcomingReminders(dbPath string, d *Dispatcher, now time.Time) ([]ScheduledReminder, error) {
+	rules := d.subscribedRules(EventReminder)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	at, err := d.dueTime()
+	if err != nil {
+		return nil, err
+	}
+
+	// schedule adds the reminder on every channel it was not sent to yet
+	var reminders []ScheduledReminder
+	schedule := func(action database.Action, offset string, fireAt time.Time) error {
+		if fireAt.Before(now) {
+			fireAt = now
+		}
+		for _, rule := range rules {
+			if !Matches(rule, action) {
+				continue
+			}
+
+			// Quiet hours hold the reminder back until they end
+			reminder := ScheduledReminder{Action: action, Channel: rule.Channel, Offset: offset, FireAt: d.preferencesFor(rule).quiet.after(fireAt)}
+			sent, err := database.NotificationSent(dbPath, EventReminder, rule.Channel, reminder.key())
+			if err != nil {
+				return err
+			}
+			if !sent {
+				reminders = append(reminders, reminder)
+			}
+		}
+		return nil
+	}
+
+	today := now.Format("2006-01-02")
+	until := now.AddDate(0, 0, UpcomingReminderDays)
+	if d.digestTime != "" {
+		actions, err := database.GetActionsDueBetween(dbPath, today, until.Format("2006-01-02"))
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving upcoming actions: %v", err)
+		}
+
+		for _, action := range actions {
+			if len(action.Reminders) > 0 {
+				continue
+			}
+			day, err := time.ParseInLocation("2006-01-02", database.StoredDate(action.DueDate.String), now.Location())
+			if err != nil {
+				continue
+			}
+			fireAt := time.Date(day.Year(), day.Month(), day.Day(), at.Hour(), at.Minute(), 0, 0, now.Location())
+			if err := schedule(action, "", fireAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	actions, err := database.GetActionsWithReminders(dbPath, today)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving actions with reminders: %v", err)
+	}
+	for _, action := range actions {
+		for _, reminder := range offsetReminders(action, at, now) {
+			if reminder.fireAt.After(until) {
+				break
+			}
+			if err := schedule(action, reminder.offset, reminder.fireAt); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].FireAt.Before(reminders[j].FireAt)
+	})
+	return reminders, nil
+}
+
+// CancelReminder cancels the upcoming reminders of an action on a channel, or
+// on every channel when channel is empty. The reminder is recorded in the
+// notification log as if it was sent, so Run skips it; a new due date
+// schedules a new reminder. It returns the number of reminders cancelled.
+func CancelReminder(dbPath string, d *Dispatcher, actionID uint, channel string, now time.Time) (int, error) {
+	reminders, err := UpcomingReminders(dbPath, d, now)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for _, reminder := range reminders {
+		if reminder.Action.ID != actionID || (channel != "" && reminder.Channel != channel) {
+			continue
+		}
+
+		if err := database.RecordNotification(dbPath, EventReminder, reminder.Channel, reminder.key()); err != nil {
+			return cancelled, err
+		}
+		cancelled++
+	}
+
+	return cancelled, nil
+}
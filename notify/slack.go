@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackSender posts notifications to a Slack incoming webhook
+type SlackSender struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewSlackSender creates a sender for a Slack incoming webhook URL
+func NewSlackSender(webhookURL string) *SlackSender {
+	return &SlackSender{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts the notification to the webhook
+func (s *SlackSender) Send(n Notification) error {
+	payload, err := json.Marshal(map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", n.Title, n.Message),
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient.Post(s.webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from Slack: %s", resp.Status)
+	}
+
+	return nil
+}
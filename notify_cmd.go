@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/notify"
+
+	"github.com/spf13/cobra"
+)
+
+func notifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Send notifications to the channels in the config file",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "overdue",
+		Short: "Notify about actions that became overdue",
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			sent, err := notify.SendOverdue(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendOverdue", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("SentOverdue", i18n.Data{"Count": sent}))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "digest",
+		Short: "Send the daily digest of today's and overdue actions",
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			sent, err := notify.SendDigest(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendDigest", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("SentDigests", i18n.Data{"Count": sent}))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "reminders",
+		Short: "Send a reminder for every action due today, and the reminders of actions whose time has come",
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			sent, err := notify.SendReminders(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendReminders", i18n.Data{"Error": err}))
+				return
+			}
+			offsetSent, err := notify.SendActionReminders(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendReminders", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("SentReminders", i18n.Data{"Count": sent + offsetSent}))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "started",
+		Short: "Notify about deferred actions whose start date is today",
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			sent, err := notify.SendStarted(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendStarted", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("SentStarted", i18n.Data{"Count": sent}))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "follow-ups",
+		Short: "Nudge about waiting actions whose follow-up date has arrived",
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			sent, err := notify.SendFollowUps(database.GetDatabasePath(), dispatcher)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendFollowUps", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("SentFollowUps", i18n.Data{"Count": sent}))
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "test <channel>",
+		Short: "Send a test notification to a channel",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dispatcher, ok := loadDispatcher()
+			if !ok {
+				return
+			}
+
+			err := dispatcher.SendTo(args[0], notify.Notification{
+				Event:   "test",
+				Title:   "Projector test notification",
+				Message: "Notifications are working!",
+			})
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSendTestNotification", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("TestNotificationSent", i18n.Data{"Channel": args[0]}))
+		},
+	})
+
+	return cmd
+}
+
+// loadDispatcher loads the notification config, printing an error when it is unusable
+func loadDispatcher() (*notify.Dispatcher, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return nil, false
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+
+	dispatcher, err := notify.NewDispatcher(cfg.Notifications)
+	if err != nil {
+		fmt.Println(i18n.T("InvalidNotificationConfig", i18n.Data{"Error": err}))
+		return nil, false
+	}
+
+	return dispatcher, true
+}
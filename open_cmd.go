@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/joelgrimberg/projector/browser"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/markdown"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// linkRecord is the JSON form of a link of an action
+type linkRecord struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Title  string `json:"title,omitempty"`
+}
+
+func openCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open <id> [number]",
+		Short: "Open a link of an action in the browser",
+		Long: `Open a link of an action in the browser: the first one, or the link with the
+given number. The links of an action are its attachments followed by the
+http and https URLs in its note; --list lists them with their numbers.
+
+The browser is $BROWSER when it is set, otherwise the browser of the system.`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+				return
+			}
+			if action == nil {
+				fmt.Println(i18n.T("ActionNotFound", i18n.Data{"ID": actionID}))
+				return
+			}
+
+			attachments, err := database.GetAttachments(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Printf("❌ Error retrieving attachments: %v\n", err)
+				return
+			}
+			links := actionLinks(action.Note.String, attachments)
+
+			if list, _ := cmd.Flags().GetBool("list"); list {
+				table := output.Table{Headers: []string{"#", "URL", "TITLE"}}
+				for _, link := range links {
+					table.AddRow(strconv.Itoa(link.Number), link.URL, link.Title)
+				}
+				if err := output.Print(links, table); err != nil {
+					fmt.Printf("❌ Failed to print links: %v\n", err)
+				}
+				return
+			}
+
+			if len(links) == 0 {
+				fmt.Printf("❌ Action %d has no links\n", actionID)
+				return
+			}
+
+			link := links[0]
+			if len(args) == 2 {
+				number, err := strconv.Atoi(args[1])
+				if err != nil || number < 1 || number > len(links) {
+					fmt.Printf("❌ Invalid link number: %s. Action %d has %d link(s)\n", args[1], actionID, len(links))
+					return
+				}
+				link = links[number-1]
+			}
+
+			if err := browser.Open(link.URL); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			fmt.Printf("✅ Opened %s\n", link.URL)
+		},
+	}
+
+	cmd.Flags().Bool("list", false, "List the links of the action with their numbers")
+	return cmd
+}
+
+// actionLinks returns the links of an action, numbered from 1: its
+// attachments followed by the URLs in its note that are not attached
+func actionLinks(note string, attachments []database.Attachment) []linkRecord {
+	links := []linkRecord{}
+	seen := make(map[string]bool)
+	add := func(url, title string) {
+		if !seen[url] {
+			seen[url] = true
+			links = append(links, linkRecord{Number: len(links) + 1, URL: url, Title: title})
+		}
+	}
+
+	for _, attachment := range attachments {
+		add(attachment.URL, attachment.Title.String)
+	}
+	for _, url := range markdown.Links(note) {
+		add(url, "")
+	}
+	return links
+}
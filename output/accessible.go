@@ -0,0 +1,156 @@
+package output
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/joelgrimberg/projector/i18n"
+)
+
+// accessible is whether plain, screen-reader friendly output is selected
+var accessible bool
+
+// Accessible reports whether plain, screen-reader friendly output is
+// selected, in which case commands print lines rather than full-screen
+// prompts, spinners, progress bars and graphs
+func Accessible() bool {
+	return accessible
+}
+
+// markerLabels are the text labels of the emoji that mark the kind of a
+// message, by i18n message ID. Other emoji that start a word are decoration
+// and left out.
+var markerLabels = map[string]string{
+	"❌": "LabelError",
+	"⚠": "LabelWarning",
+	"✅": "LabelSuccess",
+	"📝": "LabelNote",
+}
+
+// stopAccessible waits for the rewritten output to be written
+var stopAccessible func()
+
+// terminal is standard output and standard error before they were filtered
+var terminal = [2]*os.File{os.Stdout, os.Stderr}
+
+// Terminal returns standard output and standard error as they were before
+// accessible output filtered them, for programs such as an editor that draw
+// on the terminal themselves
+func Terminal() (stdout, stderr *os.File) {
+	return terminal[0], terminal[1]
+}
+
+// SetAccessible selects plain, screen-reader friendly output. Standard output
+// and standard error are passed through a filter that replaces emoji markers
+// with text labels, so every message is covered. Close
+// must be called before the program exits to write the last output.
+func SetAccessible() error {
+	if accessible {
+		return nil
+	}
+
+	terminal = [2]*os.File{os.Stdout, os.Stderr}
+	var done []chan struct{}
+	var writers []*os.File
+	for _, file := range []**os.File{&os.Stdout, &os.Stderr} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		finished := make(chan struct{})
+		go func(dst *os.File) {
+			defer close(finished)
+			rewriteMarkers(dst, r)
+		}(*file)
+		*file = w
+		writers = append(writers, w)
+		done = append(done, finished)
+	}
+
+	accessible = true
+	stopAccessible = func() {
+		for i, w := range writers {
+			w.Close()
+			<-done[i]
+		}
+	}
+	return nil
+}
+
+// Close writes the output that is still being rewritten for accessible
+// output. It does nothing otherwise.
+func Close() {
+	if stopAccessible != nil {
+		stopAccessible()
+		stopAccessible = nil
+	}
+}
+
+// rewriteMarkers copies r to w, replacing emoji that start a line or follow
+// a space, such as the result after a confirmation prompt. Output is passed
+// on as it arrives rather than per line, so prompts show before their answer
+// is read.
+func rewriteMarkers(w io.Writer, r io.Reader) {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	wordStart := true // Whether a line or a space precedes
+	skipSpaces := false
+	for {
+		n, err := r.Read(buf)
+		data := append(pending, buf[:n]...)
+		pending = nil
+
+		var out strings.Builder
+		for len(data) > 0 {
+			// A character split between reads waits for its remaining bytes
+			if !utf8.FullRune(data) && err == nil {
+				pending = append([]byte(nil), data...)
+				break
+			}
+			char, size := utf8.DecodeRune(data)
+			data = data[size:]
+
+			switch {
+			case char == '\n':
+				wordStart, skipSpaces = true, false
+				out.WriteRune(char)
+			case skipSpaces && char == ' ':
+			case skipSpaces && (char == '\uFE0F' || char == '\u200D'):
+				// Variation selectors and joiners belong to the emoji before
+			case wordStart && isEmoji(char):
+				if id, ok := markerLabels[string(char)]; ok {
+					out.WriteString(i18n.T(id) + " ")
+				}
+				skipSpaces = true
+			default:
+				skipSpaces = false
+				wordStart = char == ' ' || char == '\t'
+				out.WriteRune(char)
+			}
+		}
+		if out.Len() > 0 {
+			io.WriteString(w, out.String())
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// isEmoji reports whether a character is a pictograph or symbol used as an
+// emoji, as opposed to letters, punctuation and box drawing
+func isEmoji(char rune) bool {
+	switch {
+	case char >= 0x1F000 && char <= 0x1FAFF:
+		return true
+	case char >= 0x2600 && char <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case char >= 0x2B00 && char <= 0x2BFF: // Arrows such as ⬆ and ⬇
+		return true
+	case char >= 0x23E9 && char <= 0x23FF: // Media controls such as ⏫ and ⏹
+		return true
+	}
+	return false
+}
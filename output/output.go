@@ -0,0 +1,215 @@
+// Package output prints command results as a table, plain text or JSON, as
+// selected with the global --output flag
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/joelgrimberg/projector/theme"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// Output formats
+const (
+	FormatTable = "table" // Aligned columns with a header, for people
+	FormatPlain = "plain" // Tab-separated rows without a header, for cut and awk
+	FormatJSON  = "json"  // JSON documents, for jq
+)
+
+// format is the selected output format
+var format = FormatTable
+
+// SetFormat selects the output format
+func SetFormat(name string) error {
+	switch name {
+	case FormatTable, FormatPlain, FormatJSON:
+		format = name
+		return nil
+	default:
+		return fmt.Errorf("unknown output format: %s (expected table, plain or json)", name)
+	}
+}
+
+// Format returns the selected output format
+func Format() string {
+	return format
+}
+
+// IsJSON reports whether JSON output is selected, so commands can leave out
+// decorations that would break the JSON document
+func IsJSON() bool {
+	return format == FormatJSON
+}
+
+// minColumnWidth is the width below which columns are not truncated to fit
+// the terminal
+const minColumnWidth = 8
+
+// wide is whether all columns are shown at full width
+var wide bool
+
+// SetWide selects whether tables show their wide columns and keep their
+// cells at full width, rather than fitting the terminal
+func SetWide(enabled bool) {
+	wide = enabled
+}
+
+// Table is the tabular form of a command result
+type Table struct {
+	Headers []string
+	Rows    [][]string
+	// Wide are the headers of columns only shown with --wide, which are
+	// left out of table and plain output otherwise
+	Wide []string
+}
+
+// AddRow appends a row with the given cells
+func (t *Table) AddRow(cells ...string) {
+	t.Rows = append(t.Rows, cells)
+}
+
+// Print writes a command result in the selected format: value is encoded for
+// JSON output, the table is printed otherwise
+func Print(value interface{}, table Table) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case FormatPlain:
+		table = table.narrow()
+		for _, row := range table.Rows {
+			if _, err := fmt.Fprintln(os.Stdout, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		table = table.narrow()
+		return printAligned(append([][]string{table.Headers}, table.Rows...))
+	}
+}
+
+// narrow leaves out the wide columns, unless --wide is given
+func (t Table) narrow() Table {
+	if wide || len(t.Wide) == 0 {
+		return t
+	}
+
+	var keep []int
+	for i, header := range t.Headers {
+		if !slices.Contains(t.Wide, header) {
+			keep = append(keep, i)
+		}
+	}
+	pick := func(cells []string) []string {
+		picked := make([]string, 0, len(keep))
+		for _, i := range keep {
+			if i < len(cells) {
+				picked = append(picked, cells[i])
+			}
+		}
+		return picked
+	}
+
+	narrowed := Table{Headers: pick(t.Headers)}
+	for _, row := range t.Rows {
+		narrowed.Rows = append(narrowed.Rows, pick(row))
+	}
+	return narrowed
+}
+
+// printAligned prints rows in columns separated by two spaces. Widths are
+// measured in terminal cells, so colored cells and emoji line up, which
+// text/tabwriter does not do. The last cell of a row is not padded. Cells
+// that do not fit the terminal are cut off with an ellipsis.
+func printAligned(rows [][]string) error {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	if !wide {
+		fitWidths(widths, TerminalWidth())
+	}
+
+	for _, row := range rows {
+		var line strings.Builder
+		for i, cell := range row {
+			if lipgloss.Width(cell) > widths[i] {
+				cell = ansi.Truncate(cell, widths[i], "…")
+			}
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(cell)+2))
+			}
+		}
+		if _, err := fmt.Fprintln(os.Stdout, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fitWidths narrows the widest columns until the rows fit the terminal,
+// down to the minimum column width. Narrow columns such as IDs and dates
+// keep their width. Nothing is narrowed when the width is not known.
+func fitWidths(widths []int, available int) {
+	if available <= 0 {
+		return
+	}
+	total := 2 * (len(widths) - 1)
+	for _, width := range widths {
+		total += width
+	}
+
+	for total > available {
+		widest := -1
+		for i, width := range widths {
+			if width > minColumnWidth && (widest == -1 || width > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// TerminalWidth returns the width of the terminal, or 0 when the output is
+// not a terminal, such as a pipe to another command
+func TerminalWidth() int {
+	if !term.IsTerminal(os.Stdout.Fd()) {
+		return 0
+	}
+	width, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// Colorize returns text in a color for table output. The text is returned as
+// is for the other formats, without a color, or when colors are off or the
+// output is not a terminal.
+func Colorize(text, color string) string {
+	if format != FormatTable {
+		return text
+	}
+	return theme.Colorize(text, color)
+}
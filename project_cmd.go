@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// projectRecord is the JSON form of a project in command output
+type projectRecord struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	DueDate     string `json:"due_date,omitempty"`
+	OpenActions int    `json:"open_actions"`
+	Actions     int    `json:"actions"`
+	ArchivedAt  string `json:"archived_at,omitempty"`
+	Repeat      string `json:"repeat_interval,omitempty"`
+	Review      string `json:"review_interval,omitempty"`
+}
+
+func projectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Manage projects",
+	}
+
+	cmd.AddCommand(projectListCmd())
+	cmd.AddCommand(projectCloneCmd())
+	cmd.AddCommand(projectMergeCmd())
+	cmd.AddCommand(projectRepeatCmd())
+	cmd.AddCommand(projectReviewEveryCmd())
+	return cmd
+}
+
+func projectListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List projects with their number of actions",
+		Run: func(cmd *cobra.Command, args []string) {
+			all, _ := cmd.Flags().GetBool("all")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			projects, err := database.GetAllProjects(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingProjects", i18n.Data{"Error": err}))
+				return
+			}
+
+			actions, err := database.GetAllActions(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []projectRecord{}
+			table := output.Table{Headers: []string{"ID", "NAME", "DUE", "OPEN", "ACTIONS"}}
+			for _, project := range projects {
+				if project.IsArchived() && !all {
+					continue
+				}
+
+				record := projectRecord{
+					ID:         project.ID,
+					Name:       project.Name,
+					DueDate:    database.StoredDate(project.DueDate.String),
+					ArchivedAt: project.ArchivedAt.String,
+					Repeat:     project.RepeatInterval.String,
+					Review:     project.ReviewInterval.String,
+				}
+				for _, action := range actions {
+					if uint(action.ProjectID.Int64) != project.ID {
+						continue
+					}
+					record.Actions++
+					if action.StatusName != "done" {
+						record.OpenActions++
+					}
+				}
+
+				records = append(records, record)
+				name := record.Name
+				if project.IsArchived() {
+					name += " (archived)"
+				}
+				table.AddRow(fmt.Sprint(record.ID), name, displayDate(record.DueDate), fmt.Sprint(record.OpenActions), fmt.Sprint(record.Actions))
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoProjectsFound"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintProjects", i18n.Data{"Error": err}))
+			}
+		},
+	}
+
+	cmd.Flags().Bool("all", false, "Include archived projects")
+	return cmd
+}
+
+func projectCloneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "clone <project>",
+		Short:             "Copy a project (name or ID) with its actions",
+		Long:              "Copy a project with its actions as new todo actions. Repeating actions are\ncopied once, as the first occurrence of their series.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			noActions, _ := cmd.Flags().GetBool("no-actions")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			project, ok := lookupProject(args[0])
+			if !ok {
+				return
+			}
+
+			options := cloneOptions(cmd)
+			options.Actions = !noActions
+			cloneID, err := database.CloneProject(database.GetDatabasePath(), project.ID, options)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToCloneProject", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("ClonedProject", i18n.Data{"Name": project.Name, "CloneID": cloneID}))
+		},
+	}
+
+	addCloneFlags(cmd)
+	cmd.Flags().Bool("no-actions", false, "Only copy the project, not its actions")
+	return cmd
+}
+
+func projectMergeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "merge <source> <target>",
+		Short:             "Move all actions of a project to another project and archive it",
+		Long:              "Move all actions of the source project, with their tags, to the target project\nand archive the source project. Projects are given by name or ID.",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			dryRun := isDryRun(cmd)
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			source, ok := lookupProject(args[0])
+			if !ok {
+				return
+			}
+			target, ok := lookupProject(args[1])
+			if !ok {
+				return
+			}
+
+			if !dryRun && !confirm(cmd, i18n.T("MergeProjectsConfirm", i18n.Data{"Source": source.Name, "Target": target.Name})) {
+				return
+			}
+
+			result, err := database.MergeProjects(database.GetDatabasePath(), source.ID, target.ID, dryRun)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToMergeProjects", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []actionRecord{}
+			table := output.Table{Headers: []string{"ID", "NAME", "DUE", "STATUS", "TAGS"}}
+			for _, action := range result.Actions {
+				record := newActionRecord(action)
+				records = append(records, record)
+				table.AddRow(fmt.Sprint(record.ID), record.Name, displayDate(record.DueDate), statusLabel(record.Status), strings.Join(record.Tags, ","))
+			}
+
+			if len(records) > 0 || output.IsJSON() {
+				if err := output.Print(records, table); err != nil {
+					fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+					return
+				}
+			}
+			if output.IsJSON() {
+				return
+			}
+
+			if dryRun {
+				fmt.Println(i18n.T("WouldMergeProjects", i18n.Data{"Actions": len(result.Actions), "Tags": len(result.Tags), "Source": source.Name, "Target": target.Name}))
+				return
+			}
+			fmt.Println(i18n.T("MergedProjects", i18n.Data{"Count": len(result.Actions), "Source": source.Name, "Target": target.Name}))
+		},
+	}
+
+	return cmd
+}
+
+func projectRepeatCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "repeat <project> <week|month|quarter|year|none>",
+		Short: "Make a project recur, starting the next period when all actions are done",
+		Long: `Make a project recur. When the last open action of the project is marked as
+done, the project is created again with the same actions for the next period,
+with the due dates moved by the interval. The project needs a due date, the
+deadline of the current period. Use none to stop the project from recurring.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			project, ok := lookupProject(args[0])
+			if !ok {
+				return
+			}
+
+			interval := args[1]
+			if interval == "none" {
+				interval = ""
+			}
+			if err := database.SetProjectRepeat(database.GetDatabasePath(), project.ID, interval); err != nil {
+				fmt.Println(i18n.T("FailedToSetProjectRepeat", i18n.Data{"Error": err}))
+				return
+			}
+
+			if interval == "" {
+				fmt.Println(i18n.T("ProjectRepeatRemoved", i18n.Data{"Name": project.Name}))
+				return
+			}
+			fmt.Println(i18n.T("ProjectRepeatSet", i18n.Data{"Name": project.Name, "Interval": interval}))
+		},
+	}
+}
+
+func projectReviewEveryCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "review-every <project> <interval|none>",
+		Short: "Set how often a project is due for a review, such as 1w or 2m",
+		Long: `Set how often a project is due for a review with 'projector review': a number
+of days, weeks or months such as 10d, 1w or 2m, counted from the last review.
+A project that was never reviewed is due right away. Use none to stop
+reviewing the project.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			project, ok := lookupProject(args[0])
+			if !ok {
+				return
+			}
+
+			interval := strings.ToLower(args[1])
+			if interval == "none" {
+				interval = ""
+			}
+			if err := database.SetProjectReviewInterval(database.GetDatabasePath(), project.ID, interval); err != nil {
+				fmt.Println(i18n.T("FailedToSetReviewInterval", i18n.Data{"Error": err}))
+				return
+			}
+
+			if interval == "" {
+				fmt.Println(i18n.T("ProjectReviewRemoved", i18n.Data{"Name": project.Name}))
+				return
+			}
+			fmt.Println(i18n.T("ProjectReviewSet", i18n.Data{"Name": project.Name, "Interval": interval}))
+		},
+	}
+}
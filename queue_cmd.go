@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/remote"
+
+	"github.com/spf13/cobra"
+)
+
+// queueRecord is the JSON form of a queued change in command output
+type queueRecord struct {
+	Remote      string `json:"remote"`
+	Description string `json:"description"`
+	QueuedAt    string `json:"queued_at"`
+}
+
+func queueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "List and send the changes queued while the remote server was unreachable",
+		Long: `When add, done or delete cannot reach the API server given by --remote or
+remote.url in the config, the change is queued and sent again with the next
+command that reaches the server. Every change carries an idempotency key, so a
+change the server received before the connection failed is not made twice.
+Changes the server refuses, such as marking done an action that was deleted
+in the meantime, are reported and dropped.`,
+	}
+
+	cmd.AddCommand(queueListCmd())
+	cmd.AddCommand(queuePushCmd())
+	cmd.AddCommand(queueClearCmd())
+	return cmd
+}
+
+func queueListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the queued changes",
+		Run: func(cmd *cobra.Command, args []string) {
+			queued, err := remote.ReadQueue(remoteQueuePath())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+
+			records := []queueRecord{}
+			table := output.Table{Headers: []string{"REMOTE", "CHANGE", "QUEUED"}}
+			for _, mutation := range queued {
+				record := queueRecord{Remote: mutation.Remote, Description: mutation.Description, QueuedAt: mutation.QueuedAt}
+				records = append(records, record)
+				table.AddRow(record.Remote, record.Description, record.QueuedAt)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println("📋 No changes are queued.")
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Printf("❌ Failed to print queue: %v\n", err)
+			}
+		},
+	}
+}
+
+func queuePushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Send the queued changes to the remote server now",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, ok := configuredRemote(cmd)
+			if !ok {
+				return
+			}
+			if client == nil {
+				fmt.Println("❌ No remote server configured. Pass --remote or set remote.url in the config.")
+				return
+			}
+			flushRemoteQueue(client, true)
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
+
+func queueClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Drop the queued changes without sending them",
+		Run: func(cmd *cobra.Command, args []string) {
+			queued, err := remote.ReadQueue(remoteQueuePath())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			if len(queued) == 0 {
+				fmt.Println("📋 No changes are queued.")
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Printf("📝 Would drop %d queued change(s)\n", len(queued))
+				return
+			}
+			if !confirm(cmd, fmt.Sprintf("Drop %d queued change(s)?", len(queued))) {
+				return
+			}
+
+			if err := remote.WriteQueue(remoteQueuePath(), nil); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			fmt.Printf("✅ Dropped %d queued change(s)\n", len(queued))
+		},
+	}
+}
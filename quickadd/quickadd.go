@@ -0,0 +1,259 @@
+// Package quickadd parses actions written on a single line with inline
+// tokens, such as "Buy milk @errands #home due:fri".
+package quickadd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// PriorityTagPrefix marks the tag that holds a priority, since actions have
+// no priority of their own. The todo.txt import uses the same tag.
+const PriorityTagPrefix = "priority:"
+
+// Entry is an action parsed from a quick-add line
+type Entry struct {
+	Name           string
+	DueDate        string // YYYY-MM-DD
+	StartDate      string // YYYY-MM-DD
+	Tags           []string
+	Priority       string
+	Project        string // Project name
+	RepeatInterval string
+	RepeatPattern  string   // Weekly pattern such as mon,wed
+	Reminders      []string // Offsets before the due date such as -1d
+}
+
+// weekdays maps the day names accepted by due: to their weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// intervals maps the values accepted by every: to repeat intervals
+var intervals = map[string]string{
+	"minute": "minute",
+	"hour":   "hour", "hourly": "hour",
+	"day": "day", "daily": "day",
+	"week": "week", "weekly": "week",
+	"month": "month", "monthly": "month",
+	"year": "year", "yearly": "year",
+}
+
+// Parse parses a quick-add line. The remaining words form the name:
+//
+//	#tag and @context  add a tag (both are stored as tags)
+//	!priority          sets the priority, such as !high or !1
+//	due:<date>         sets the due date, see ParseDate
+//	start:<date>       defers the action until the start date, see ParseDate
+//	every:<interval>   repeats the action forever: day, week, month, year,
+//	                   or days of the week such as every:mon,thu
+//	remind:<offsets>   reminds before the due date, such as remind:-1d or
+//	                   remind:-1d,-2h, see database.ParseReminderOffset
+//	+ProjectName       puts the action in a project, see findProject
+//
+// Relative dates are resolved against now.
+func Parse(line string, now time.Time) (Entry, error) {
+	var entry Entry
+	var words []string
+	for _, field := range strings.Fields(line) {
+		switch {
+		case len(field) > 1 && (field[0] == '#' || field[0] == '@'):
+			entry.Tags = appendUnique(entry.Tags, field[1:])
+		case len(field) > 1 && field[0] == '!':
+			entry.Priority = field[1:]
+		case len(field) > 1 && field[0] == '+':
+			entry.Project = field[1:]
+		case strings.HasPrefix(field, "due:"):
+			date, err := ParseDate(field[4:], now)
+			if err != nil {
+				return Entry{}, err
+			}
+			entry.DueDate = date
+		case strings.HasPrefix(field, "start:"):
+			date, err := ParseDate(field[6:], now)
+			if err != nil {
+				return Entry{}, fmt.Errorf("start:%s: %v", field[6:], err)
+			}
+			entry.StartDate = date
+		case strings.HasPrefix(field, "every:"):
+			interval, pattern, err := parseEvery(field[6:])
+			if err != nil {
+				return Entry{}, err
+			}
+			entry.RepeatInterval, entry.RepeatPattern = interval, pattern
+		case strings.HasPrefix(field, "remind:"):
+			offsets := strings.Split(field[7:], ",")
+			if err := database.ValidateReminders(offsets); err != nil {
+				return Entry{}, err
+			}
+			for _, offset := range offsets {
+				entry.Reminders = appendUnique(entry.Reminders, strings.ToLower(offset))
+			}
+		default:
+			words = append(words, field)
+		}
+	}
+
+	entry.Name = strings.Join(words, " ")
+	if entry.Name == "" {
+		return Entry{}, fmt.Errorf("action name is required")
+	}
+
+	// Repeating needs a first due date to count from
+	if entry.RepeatInterval != "" && entry.DueDate == "" {
+		entry.DueDate, _ = ParseDate("today", now)
+	}
+	return entry, nil
+}
+
+// ParseDate parses a due date: YYYY-MM-DD, today, tomorrow, or a day name
+// such as fri or friday for the next such day (today when it is that day)
+func ParseDate(value string, now time.Time) (string, error) {
+	value = strings.ToLower(value)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "today":
+		return today.Format("2006-01-02"), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	}
+
+	if weekday, ok := weekdays[value]; ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		return today.AddDate(0, 0, days).Format("2006-01-02"), nil
+	}
+
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("invalid due date: %s. Expected YYYY-MM-DD, today, tomorrow or a day name", value)
+	}
+	return value, nil
+}
+
+// ParseRelativeDate parses a date in the syntax of ParseDate, an offset from
+// today such as +3d, +2w or +1m, or end-of-week or end-of-month. Weeks end on
+// Sunday.
+func ParseRelativeDate(value string, now time.Time) (string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch strings.ToLower(value) {
+	case "end-of-week":
+		return today.AddDate(0, 0, (7-int(today.Weekday()))%7).Format("2006-01-02"), nil
+	case "end-of-month":
+		return today.AddDate(0, 1, -today.Day()).Format("2006-01-02"), nil
+	}
+
+	if strings.HasPrefix(value, "+") {
+		date, err := database.ResolveDateOffset("start"+strings.ToLower(value), today)
+		if err != nil {
+			return "", fmt.Errorf("invalid date offset: %s. Expected a number of days, weeks or months such as +3d, +2w or +1m", value)
+		}
+		return date, nil
+	}
+
+	date, err := ParseDate(value, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid date: %s. Expected YYYY-MM-DD, today, tomorrow, a day name, an offset such as +3d, end-of-week or end-of-month", value)
+	}
+	return date, nil
+}
+
+// parseEvery parses the value of every: into a repeat interval and pattern
+func parseEvery(value string) (string, string, error) {
+	value = strings.ToLower(value)
+	if interval, ok := intervals[value]; ok {
+		return interval, "", nil
+	}
+
+	for _, day := range strings.Split(value, ",") {
+		if _, ok := weekdays[day]; !ok {
+			return "", "", fmt.Errorf("invalid repeat: every:%s. Expected day, week, month, year or days such as mon,thu", value)
+		}
+	}
+	return "week", value, nil
+}
+
+// Resolve converts the entry to an action to create, looking up the project
+// by name. The priority becomes a tag.
+func (e Entry) Resolve(dbPath string) (database.NewAction, error) {
+	action := database.NewAction{
+		Name:           e.Name,
+		DueDate:        e.DueDate,
+		StartDate:      e.StartDate,
+		Tags:           e.Tags,
+		RepeatInterval: e.RepeatInterval,
+		RepeatPattern:  e.RepeatPattern,
+		Reminders:      e.Reminders,
+	}
+
+	if e.Priority != "" {
+		action.Tags = appendUnique(action.Tags, PriorityTagPrefix+e.Priority)
+	}
+	if e.RepeatInterval != "" {
+		action.RepeatMode = database.RepeatModeForever
+	}
+
+	if e.Project != "" {
+		projectID, err := findProject(dbPath, e.Project)
+		if err != nil {
+			return database.NewAction{}, err
+		}
+		action.ProjectID = &projectID
+	}
+
+	return action, nil
+}
+
+// findProject returns the ID of the project with the name. As a +Project
+// token cannot contain spaces, names are compared ignoring case, spaces,
+// dashes and underscores, so +learn-go finds "Learn Go". Archived projects
+// are left out.
+func findProject(dbPath, name string) (uint, error) {
+	all, err := database.GetAllProjects(dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up project %s: %v", name, err)
+	}
+
+	var projects []database.Project
+	for _, project := range all {
+		if !project.IsArchived() {
+			projects = append(projects, project)
+		}
+	}
+
+	for _, project := range projects {
+		if project.Name == name {
+			return project.ID, nil
+		}
+	}
+	for _, project := range projects {
+		if projectKey(project.Name) == projectKey(name) {
+			return project.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("project %s not found", name)
+}
+
+// projectKey normalizes a project name for findProject
+func projectKey(name string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "_", "").Replace(name))
+}
+
+// appendUnique appends a value unless the list already holds it
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/client"
+)
+
+// NewAction is an action to create on the remote
+type NewAction = client.NewAction
+
+// DuplicateError is returned when the remote refuses an action because an
+// open action with the same name exists in the project
+type DuplicateError = client.DuplicateError
+
+// CreateAction creates an action on the remote and returns it as created
+func (c *Client) CreateAction(action NewAction) (*client.Action, error) {
+	mutation, err := c.newMutation("PUT", "/api/v1/actions", action, "add "+action.QuickAdd)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Action client.Action `json:"action"`
+	}
+	if err := c.apply(mutation, &response); err != nil {
+		return nil, err
+	}
+	return &response.Action, nil
+}
+
+// CompleteAction marks an action on the remote as done
+func (c *Client) CompleteAction(actionID uint) error {
+	body := map[string]string{"action": "done"}
+	mutation, err := c.newMutation("PUT", fmt.Sprintf("/api/v1/actions/%d", actionID), body, fmt.Sprintf("done %d", actionID))
+	if err != nil {
+		return err
+	}
+	return c.apply(mutation, &struct{}{})
+}
+
+// DeleteAction moves an action on the remote to its trash
+func (c *Client) DeleteAction(actionID uint) error {
+	mutation, err := c.newMutation("DELETE", fmt.Sprintf("/api/v1/actions/%d", actionID), nil, fmt.Sprintf("delete %d", actionID))
+	if err != nil {
+		return err
+	}
+	return c.apply(mutation, &struct{}{})
+}
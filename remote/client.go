@@ -0,0 +1,20 @@
+package remote
+
+import (
+	"github.com/joelgrimberg/projector/client"
+)
+
+// Client talks to the API of another projector instance. It is a
+// client.Client whose changes to actions can be queued while the remote
+// cannot be reached.
+type Client struct {
+	*client.Client
+	baseURL string
+}
+
+// NewClient creates a client for the projector API at baseURL, e.g.
+// http://host:8080. The token is only needed when the remote has user accounts.
+func NewClient(baseURL, token string) *Client {
+	api := client.NewClient(baseURL, token)
+	return &Client{Client: api, baseURL: api.BaseURL()}
+}
@@ -0,0 +1,200 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/joelgrimberg/projector/client"
+)
+
+// Mutation is a request that changes data on a remote. It carries an
+// idempotency key, so that it can be sent again after a failure without being
+// applied twice.
+type Mutation struct {
+	Remote      string          `json:"remote"`
+	Key         string          `json:"key"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Description string          `json:"description"`
+	QueuedAt    string          `json:"queued_at,omitempty"`
+}
+
+// OfflineError is returned when a mutation could not reach the remote. The
+// mutation may have been applied or not: it can be queued and sent again.
+type OfflineError struct {
+	Mutation Mutation
+	Err      error
+}
+
+func (e *OfflineError) Error() string {
+	return e.Err.Error()
+}
+
+// Conflict is a queued mutation the remote refused when it was sent again,
+// such as marking done an action that was deleted in the meantime
+type Conflict struct {
+	Mutation Mutation
+	Err      error
+}
+
+// FlushResult summarizes sending the queued mutations of a remote
+type FlushResult struct {
+	Sent      int
+	Conflicts []Conflict
+	Remaining int // Still queued because the remote is unreachable
+}
+
+// newMutation prepares a request to the remote with a new idempotency key
+func (c *Client) newMutation(method, path string, body interface{}, description string) (Mutation, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return Mutation{}, err
+	}
+
+	mutation := Mutation{Remote: c.baseURL, Key: hex.EncodeToString(key), Method: method, Path: path, Description: description}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return Mutation{}, err
+		}
+		mutation.Body = data
+	}
+	return mutation, nil
+}
+
+// apply sends a mutation and decodes the response into v. It returns an
+// OfflineError when the remote cannot be reached.
+func (c *Client) apply(mutation Mutation, v interface{}) error {
+	req, err := http.NewRequest(mutation.Method, c.baseURL+mutation.Path, bytes.NewReader(mutation.Body))
+	if err != nil {
+		return err
+	}
+	if mutation.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Idempotency-Key", mutation.Key)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return &OfflineError{Mutation: mutation, Err: fmt.Errorf("failed to reach %s: %v", c.baseURL, err)}
+	}
+	defer resp.Body.Close()
+
+	// A proxy in front of a server that is down
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return &OfflineError{Mutation: mutation, Err: fmt.Errorf("%s is unavailable: %s", c.baseURL, resp.Status)}
+	}
+
+	return client.DecodeResponse(resp, v)
+}
+
+// Flush sends the mutations queued for the remote at path in the order they
+// were queued. Mutations the remote refuses are dropped and returned as
+// conflicts. Sending stops when the remote is unreachable, leaving the rest
+// queued.
+func (c *Client) Flush(path string) (*FlushResult, error) {
+	queued, err := ReadQueue(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FlushResult{}
+	var remaining []Mutation
+	offline := false
+	for _, mutation := range queued {
+		if mutation.Remote != c.baseURL || offline {
+			remaining = append(remaining, mutation)
+			continue
+		}
+
+		err := c.apply(mutation, &struct{}{})
+		var offlineErr *OfflineError
+		switch {
+		case errors.As(err, &offlineErr):
+			offline = true
+			remaining = append(remaining, mutation)
+		case err != nil:
+			result.Conflicts = append(result.Conflicts, Conflict{Mutation: mutation, Err: err})
+		default:
+			result.Sent++
+		}
+	}
+
+	for _, mutation := range remaining {
+		if mutation.Remote == c.baseURL {
+			result.Remaining++
+		}
+	}
+	if result.Sent > 0 || len(result.Conflicts) > 0 {
+		if err := WriteQueue(path, remaining); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// Enqueue adds a mutation that could not reach its remote to the queue file
+func Enqueue(path string, mutation Mutation) error {
+	queued, err := ReadQueue(path)
+	if err != nil {
+		return err
+	}
+	mutation.QueuedAt = time.Now().UTC().Format(time.RFC3339)
+	return WriteQueue(path, append(queued, mutation))
+}
+
+// ReadQueue reads the queued mutations. A missing file is an empty queue.
+func ReadQueue(path string) ([]Mutation, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue: %v", err)
+	}
+
+	var queued []Mutation
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return nil, fmt.Errorf("invalid queue file %s: %v", path, err)
+	}
+	return queued, nil
+}
+
+// WriteQueue replaces the queued mutations, removing the file when the queue
+// is empty
+func WriteQueue(path string, queued []Mutation) error {
+	if len(queued) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear queue: %v", err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(queued, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create queue directory: %v", err)
+	}
+
+	// Written to a temporary file first, so that a crash cannot lose the queue
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0600); err != nil {
+		return fmt.Errorf("failed to write queue: %v", err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("failed to write queue: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package remote
+
+import (
+	"net/http"
+)
+
+// Status is the state of the connection to a remote after sending the
+// mutations queued for it
+type Status struct {
+	Connected bool
+	Sent      int // Queued mutations the remote accepted
+	Refused   int // Queued mutations the remote refused, which were dropped
+	Pending   int // Mutations still queued for the remote
+}
+
+// Check sends the mutations queued for the remote at path, then reports
+// whether the remote answers and how many mutations remain queued
+func (c *Client) Check(path string) (*Status, error) {
+	result, err := c.Flush(path)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Sent: result.Sent, Refused: len(result.Conflicts), Pending: result.Remaining}
+	if result.Remaining > 0 {
+		// Sending stopped because the remote could not be reached
+		return status, nil
+	}
+	status.Connected = c.reachable()
+	return status, nil
+}
+
+// reachable reports whether the remote process answers. The liveness
+// endpoint does not touch the database, so a busy remote still counts.
+func (c *Client) reachable() bool {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
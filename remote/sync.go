@@ -0,0 +1,67 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// Result summarizes a sync with another instance
+type Result struct {
+	Pulled    database.ApplyResult // Remote changes applied locally
+	Pushed    database.ApplyResult // Local changes applied on the remote
+	Conflicts int                  // Actions changed on both sides, see database.RecordConflicts
+}
+
+// Sync exchanges changes with another projector instance. Changes are pulled
+// first and then local changes are pushed, so both databases converge. Only
+// changes since the previous sync with the same remote are exchanged.
+// Actions changed on both sides since then are recorded as conflicts.
+func Sync(dbPath string, client *Client) (*Result, error) {
+	pulledAt, pushedAt, err := database.GetSyncPeer(dbPath, client.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sync state: %v", err)
+	}
+
+	result := &Result{}
+
+	remoteChanges, err := client.GetChanges(pulledAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compare before applying, while the local versions are still there
+	conflicts, err := database.RecordConflicts(dbPath, client.baseURL, pushedAt, remoteChanges.Actions)
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = conflicts
+
+	applied, err := database.ApplyChanges(dbPath, remoteChanges, 0)
+	if err != nil {
+		return nil, err
+	}
+	result.Pulled = *applied
+
+	// Save the pull cursor now, so a failing push does not pull everything again
+	if err := database.SaveSyncPeer(dbPath, client.baseURL, remoteChanges.Until, pushedAt); err != nil {
+		return nil, err
+	}
+
+	localChanges, err := database.GetChangesSince(dbPath, pushedAt, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pushed, err := client.PushChanges(localChanges)
+	if err != nil {
+		return nil, err
+	}
+	result.Pushed = *pushed
+
+	if err := database.SaveSyncPeer(dbPath, client.baseURL, remoteChanges.Until, localChanges.Until); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/api"
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/quickadd"
+	"github.com/joelgrimberg/projector/remote"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// remoteQueueName is the file next to the database that holds the changes
+// made while the API server was unreachable
+const remoteQueueName = "remote-queue.json"
+
+// addRemoteFlags adds the flags that make a command talk to an API server
+// instead of the local database
+func addRemoteFlags(cmd *cobra.Command) {
+	cmd.Flags().String("remote", "", "URL of the projector API server to use instead of the local database (or remote.url in the config)")
+	cmd.Flags().String("token", "", "API token for a server with user accounts (or remote.token in the config, or PROJECTOR_REMOTE_TOKEN)")
+}
+
+// remoteClient returns a client for the API server given by --remote or the
+// remote section of the config, or nil when the command works on the local
+// database. The changes queued while the server was unreachable are sent
+// first, and the TUI shows the state of the connection. It returns false
+// when the config cannot be read.
+func remoteClient(cmd *cobra.Command) (*remote.Client, bool) {
+	client, ok := configuredRemote(cmd)
+	if client != nil {
+		flushRemoteQueue(client, false)
+		ui.SetSync(remoteSyncStatus(client))
+	}
+	return client, ok
+}
+
+// remoteSyncStatus returns the sync function of the TUI for an API server:
+// it sends the queued changes and checks the connection
+func remoteSyncStatus(client *remote.Client) ui.SyncFunc {
+	return func() ui.SyncStatus {
+		status, err := client.Check(remoteQueuePath())
+		if err != nil {
+			return ui.SyncStatus{Remote: client.BaseURL(), Err: err}
+		}
+		return ui.SyncStatus{Remote: client.BaseURL(), Connected: status.Connected, Pending: status.Pending, Refused: status.Refused}
+	}
+}
+
+// configuredRemote returns a client for the API server given by --remote or
+// the remote section of the config, or nil when there is none
+func configuredRemote(cmd *cobra.Command) (*remote.Client, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+
+	remoteURL, _ := cmd.Flags().GetString("remote")
+	if remoteURL == "" {
+		remoteURL = cfg.Remote.URL
+	}
+	if remoteURL == "" {
+		return nil, true
+	}
+
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.Remote.Token
+	}
+	if token == "" {
+		token = os.Getenv("PROJECTOR_REMOTE_TOKEN")
+	}
+
+	return remote.NewClient(remoteURL, token), true
+}
+
+// remoteQueuePath returns the path of the queue of changes for API servers
+func remoteQueuePath() string {
+	return filepath.Join(database.GetDataDir(database.GetDatabasePath()), remoteQueueName)
+}
+
+// flushRemoteQueue sends the changes queued while the API server was
+// unreachable, reporting those it refused. It is quiet when nothing was
+// queued or the server is still unreachable, unless verbose is set.
+func flushRemoteQueue(client *remote.Client, verbose bool) bool {
+	result, err := client.Flush(remoteQueuePath())
+	if err != nil {
+		fmt.Println(i18n.T("FailedToSendQueuedChanges", i18n.Data{"Error": err}))
+		return false
+	}
+
+	if result.Sent > 0 || verbose {
+		fmt.Println(i18n.T("SentQueuedChanges", i18n.Data{"Count": result.Sent}))
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Println(i18n.T("QueuedChangeRefused", i18n.Data{"Change": conflict.Mutation.Description, "Error": conflict.Err}))
+	}
+	if result.Remaining > 0 && verbose {
+		fmt.Println(i18n.T("ChangesStillQueued", i18n.Data{"Count": result.Remaining}))
+	}
+	return len(result.Conflicts) == 0 && result.Remaining == 0
+}
+
+// queueOffline queues the change of an error returned because the API server
+// was unreachable, to send it with a later command. It reports whether the
+// change was queued.
+func queueOffline(err error) bool {
+	var offline *remote.OfflineError
+	if !errors.As(err, &offline) {
+		return false
+	}
+
+	if err := remote.Enqueue(remoteQueuePath(), offline.Mutation); err != nil {
+		fmt.Println(i18n.T("QueueingFailed", i18n.Data{"Offline": offline, "Error": err}))
+		return true
+	}
+	fmt.Println(i18n.T("QueuedOffline", i18n.Data{"Offline": offline, "Change": offline.Mutation.Description}))
+	return true
+}
+
+// runRemoteList prints the matching actions of the API server, with the same
+// filters as runList
+func runRemoteList(client *remote.Client, project, tag, query, saved, near string, radius float64, energy string, all, deferred bool) bool {
+	// The query is parsed here as well, to decide on done and deferred
+	// actions and to report mistakes before reaching the server
+	var match *filter.Filter
+	if query != "" {
+		parsed, err := filter.Parse(query, time.Now())
+		if err != nil {
+			fmt.Println(i18n.T("InvalidQuery", i18n.Data{"Error": err}))
+			return false
+		}
+		match = parsed
+	}
+	showDeferred := all || deferred || match != nil && match.Uses("start")
+	if match != nil && match.Uses("status") {
+		all = true
+	}
+
+	params := url.Values{}
+	if query != "" {
+		params.Set("q", query)
+	}
+	if saved != "" {
+		params.Set("saved", saved)
+	}
+	if near != "" {
+		params.Set("near", near)
+		params.Set("radius", strconv.FormatFloat(radius, 'f', -1, 64))
+	}
+	if energy != "" {
+		params.Set("energy", energy)
+	}
+	if showDeferred {
+		params.Set("include_deferred", "true")
+	}
+
+	var projectID uint
+	if project != "" {
+		found, ok := lookupRemoteProject(client, project)
+		if !ok {
+			return false
+		}
+		projectID = found.ID
+	}
+
+	remoteActions, err := client.ListActions(params)
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return false
+	}
+
+	today := time.Now().Format("2006-01-02")
+	var listed []database.Action
+	for _, remoteAction := range remoteActions {
+		action := fromRemoteAction(remoteAction)
+		if !all && action.StatusName == "done" {
+			continue
+		}
+		if deferred && !action.IsDeferred(today) {
+			continue
+		}
+		if projectID != 0 && uint(action.ProjectID.Int64) != projectID {
+			continue
+		}
+		if tag != "" && !slices.Contains(action.Tags, tag) {
+			continue
+		}
+		listed = append(listed, action)
+	}
+
+	// The statuses of the server may differ from the local ones, so they are
+	// shown without their local color and icon
+	statusStyles = make(map[string]database.Status)
+	return printActions(listed, deferred, near != "", energy != "")
+}
+
+// runRemoteAdd creates an action on the API server for each non-empty line.
+// Unlike runAdd the actions are created one by one: when a line is refused,
+// the lines before it have been added.
+func runRemoteAdd(client *remote.Client, lines []string, project, due, location, energy string, tags, reminders []string, allowDuplicate bool) {
+	var projectID *uint
+	if project != "" {
+		found, ok := lookupRemoteProject(client, project)
+		if !ok {
+			return
+		}
+		projectID = &found.ID
+	}
+
+	now := time.Now()
+	if due != "" {
+		date, err := quickadd.ParseDate(due, now)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		due = date
+	}
+
+	records := []addedRecord{}
+	queued := 0
+	defer func() {
+		if len(records) > 0 {
+			printAdded(records)
+		}
+	}()
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// The server parses the line, it is parsed here to apply the flags
+		// only to what the line does not set itself
+		entry, err := quickadd.Parse(line, now)
+		if err != nil {
+			fmt.Println(i18n.T("LineError", i18n.Data{"Line": i + 1, "Error": err}))
+			return
+		}
+		for _, tag := range tags {
+			if !slices.Contains(entry.Tags, tag) {
+				line += " #" + tag
+			}
+		}
+
+		action := remote.NewAction{QuickAdd: line, Location: location, Energy: energy, AllowDuplicate: allowDuplicate}
+		if entry.Project == "" {
+			action.ProjectID = projectID
+		}
+		if entry.DueDate == "" {
+			action.DueDate = due
+		}
+		if len(entry.Reminders) == 0 {
+			action.Reminders = reminders
+		}
+
+		created, err := client.CreateAction(action)
+		if queueOffline(err) {
+			queued++
+			continue
+		}
+		var duplicate *remote.DuplicateError
+		if errors.As(err, &duplicate) {
+			fmt.Println(i18n.T("DuplicateExists", i18n.Data{"Line": i + 1, "Name": entry.Name, "ID": duplicate.ActionID}))
+			return
+		}
+		if err != nil {
+			fmt.Println(i18n.T("LineAddFailed", i18n.Data{"Line": i + 1, "Error": err}))
+			return
+		}
+
+		record := addedRecord{ID: created.ID, Name: created.Name, Tags: created.Tags}
+		if created.DueDate != nil {
+			record.DueDate = *created.DueDate
+		}
+		records = append(records, record)
+	}
+
+	if len(records) == 0 && queued == 0 {
+		fmt.Println(i18n.T("NoActionsToAdd"))
+	}
+}
+
+// pickRemoteAction opens a fuzzy picker over the open actions of the API
+// server and returns the ID of the chosen one
+func pickRemoteAction(client *remote.Client, title string) (uint, bool) {
+	remoteActions, err := client.ListActions(url.Values{})
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+		return 0, false
+	}
+
+	var actions []database.Action
+	for _, action := range remoteActions {
+		actions = append(actions, fromRemoteAction(action))
+	}
+	return pickAction(title, actions)
+}
+
+// lookupRemoteProject finds a project of the API server by ID or name,
+// printing an error when it does not exist
+func lookupRemoteProject(client *remote.Client, nameOrID string) (*api.Project, bool) {
+	projects, err := client.ListProjects(url.Values{})
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingProject", i18n.Data{"Error": err}))
+		return nil, false
+	}
+
+	id, parseErr := strconv.ParseUint(nameOrID, 10, 32)
+	for _, project := range projects {
+		if parseErr == nil && project.ID == uint(id) || parseErr != nil && project.Name == nameOrID {
+			return &project, true
+		}
+	}
+
+	fmt.Println(i18n.T("ProjectNotFound", i18n.Data{"Name": nameOrID}))
+	return nil, false
+}
+
+// fromRemoteAction converts an action of the API server, so that it is
+// filtered and printed like a local one
+func fromRemoteAction(a api.Action) database.Action {
+	action := database.Action{
+		ID:             a.ID,
+		Name:           a.Name,
+		Note:           nullString(a.Note),
+		DueDate:        nullString(a.DueDate),
+		StatusID:       a.StatusID,
+		RepeatCount:    a.RepeatCount,
+		RepeatInterval: nullString(a.RepeatInterval),
+		RepeatPattern:  nullString(a.RepeatPattern),
+		RepeatUntil:    nullString(a.RepeatUntil),
+		ParentActionID: nullInt64(a.ParentActionID),
+		RepeatMode:     nullString(a.RepeatMode),
+		OwnerID:        nullInt64(a.OwnerID),
+		AssigneeID:     nullInt64(a.AssigneeID),
+		Flagged:        a.Flagged,
+		Location:       nullString(a.Location),
+		Energy:         nullString(a.Energy),
+		WaitingOn:      nullString(a.WaitingOn),
+		FollowUp:       nullString(a.FollowUp),
+		StartDate:      nullString(a.StartDate),
+		AssigneeName:   nullString(a.AssigneeName),
+		ProjectID:      nullInt64(a.ProjectID),
+		ProjectName:    nullString(a.ProjectName),
+		StatusName:     a.StatusName,
+		Tags:           a.Tags,
+		Reminders:      a.Reminders,
+		Version:        a.Version,
+	}
+	if a.Latitude != nil && a.Longitude != nil {
+		action.Latitude = sql.NullFloat64{Float64: *a.Latitude, Valid: true}
+		action.Longitude = sql.NullFloat64{Float64: *a.Longitude, Valid: true}
+	}
+	return action
+}
+
+// nullString converts an optional API field
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+// nullInt64 converts an optional API ID
+func nullInt64(id *uint) sql.NullInt64 {
+	if id == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*id), Valid: true}
+}
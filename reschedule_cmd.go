@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/quickadd"
+
+	"github.com/spf13/cobra"
+)
+
+func rescheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reschedule",
+		Short: "Move the due date of all overdue actions, or those matching a filter query",
+		Long: `Move the due date of all overdue actions, or of the open actions matching a
+filter query, to a single date in one transaction. The affected actions are
+listed before anything changes; use --dry-run to only list them.
+
+  projector reschedule --overdue --to tomorrow
+  projector reschedule --filter 'tag:errands AND due<=today' --to sat
+
+--to takes YYYY-MM-DD, today, tomorrow, a day name, an offset such as +3d,
+end-of-week or end-of-month.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			overdue, _ := cmd.Flags().GetBool("overdue")
+			query, _ := cmd.Flags().GetString("filter")
+			to, _ := cmd.Flags().GetString("to")
+
+			if !overdue && query == "" {
+				fmt.Println(i18n.T("NothingSelected"))
+				return
+			}
+			dueDate, err := quickadd.ParseRelativeDate(to, time.Now())
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+
+			actions, ok := rescheduleActions(overdue, query)
+			if !ok {
+				return
+			}
+			if !previewBulk(cmd, actions, i18n.T("BulkReschedule", i18n.Data{"Count": len(actions), "Date": dueDate})) {
+				return
+			}
+
+			if err := database.RescheduleActions(database.GetDatabasePath(), actionIDs(actions), dueDate); err != nil {
+				fmt.Println(i18n.T("FailedToRescheduleActions", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("RescheduledActions", i18n.Data{"Count": len(actions), "Date": dueDate}))
+		},
+	}
+
+	cmd.Flags().Bool("overdue", false, "Select the open actions due before today")
+	cmd.Flags().StringP("filter", "f", "", "Filter query selecting the actions, combined with --overdue when both are given")
+	cmd.Flags().String("to", "tomorrow", "New due date")
+	return cmd
+}
+
+// rescheduleActions returns the open actions to reschedule: the overdue
+// ones, those matching the query, or the overdue ones matching the query
+func rescheduleActions(overdue bool, query string) ([]database.Action, bool) {
+	if !overdue {
+		actions, ok := bulkActions(query)
+		if !ok {
+			return nil, false
+		}
+		var open []database.Action
+		for _, action := range actions {
+			if action.StatusName != "done" {
+				open = append(open, action)
+			}
+		}
+		return open, true
+	}
+
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return nil, false
+	}
+	actions, err := database.GetOverdueActions(database.GetDatabasePath())
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingOverdueActions", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if query == "" {
+		return actions, true
+	}
+
+	match, err := filter.Parse(query, time.Now())
+	if err != nil {
+		fmt.Println(i18n.T("InvalidQuery", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	return match.Apply(actions), true
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/ui"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+// reviewRecord is the JSON form of a project due for review in command output
+type reviewRecord struct {
+	ID             uint   `json:"id"`
+	Name           string `json:"name"`
+	ReviewInterval string `json:"review_interval"`
+	LastReviewedAt string `json:"last_reviewed_at,omitempty"`
+	NextReview     string `json:"next_review,omitempty"`
+	OpenActions    int    `json:"open_actions"`
+}
+
+func reviewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review [project]",
+		Short: "Review the projects that are due for a review, one at a time",
+		Long: `Walk through the projects due for a review, showing the open actions of each.
+Mark a project reviewed to start its next review interval, or skip it to
+review it later. Set how often a project is reviewed with
+'projector project review-every <project> <interval>'.
+
+With a project, mark it reviewed without opening the review. --list, JSON
+output and output that is not a terminal list the projects due for a review
+instead.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			if len(args) == 1 {
+				project, ok := lookupProject(args[0])
+				if !ok {
+					return
+				}
+				if err := database.MarkProjectReviewed(database.GetDatabasePath(), project.ID); err != nil {
+					fmt.Println(i18n.T("FailedToMarkProjectReviewed", i18n.Data{"Error": err}))
+					return
+				}
+				fmt.Println(i18n.T("ReviewedProject", i18n.Data{"Name": project.Name}))
+				return
+			}
+
+			projects, err := database.GetProjectsDueForReview(database.GetDatabasePath(), time.Now().Format("2006-01-02"))
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingProjects", i18n.Data{"Error": err}))
+				return
+			}
+
+			actions, err := database.GetAllActions(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+				return
+			}
+			openActions := make(map[uint][]database.Action)
+			for _, action := range actions {
+				if action.ProjectID.Valid && action.StatusName != "done" {
+					openActions[uint(action.ProjectID.Int64)] = append(openActions[uint(action.ProjectID.Int64)], action)
+				}
+			}
+
+			list, _ := cmd.Flags().GetBool("list")
+			if list || output.IsJSON() || output.Accessible() || !term.IsTerminal(os.Stdout.Fd()) {
+				printReviews(projects, openActions)
+				return
+			}
+
+			if len(projects) == 0 {
+				fmt.Println(i18n.T("NoProjectsDueForReview"))
+				return
+			}
+
+			var items []ui.ReviewItem
+			for _, project := range projects {
+				item := ui.ReviewItem{ID: project.ID, Name: project.Name, Detail: reviewDetail(project)}
+				for _, action := range openActions[project.ID] {
+					description := action.Name
+					if action.DueDate.Valid {
+						description += " (due " + datefmt.Date(action.DueDate.String) + ")"
+					}
+					item.Actions = append(item.Actions, description)
+				}
+				items = append(items, item)
+			}
+
+			reviewed, err := ui.Review(items, func(projectID uint) error {
+				return database.MarkProjectReviewed(database.GetDatabasePath(), projectID)
+			})
+			if err != nil {
+				fmt.Println(i18n.T("ReviewFailed", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("ReviewedProjects", i18n.Data{"Reviewed": reviewed, "Count": len(items)}))
+		},
+	}
+
+	cmd.Flags().Bool("list", false, "List the projects due for a review instead of reviewing them")
+	return cmd
+}
+
+// printReviews prints the projects due for a review
+func printReviews(projects []database.Project, openActions map[uint][]database.Action) {
+	if len(projects) == 0 && !output.IsJSON() {
+		fmt.Println(i18n.T("NoProjectsDueForReview"))
+		return
+	}
+
+	records := []reviewRecord{}
+	table := output.Table{Headers: []string{"ID", "NAME", "EVERY", "LAST REVIEWED", "DUE", "OPEN"}}
+	for _, project := range projects {
+		record := reviewRecord{
+			ID:             project.ID,
+			Name:           project.Name,
+			ReviewInterval: project.ReviewInterval.String,
+			LastReviewedAt: project.LastReviewedAt.String,
+			NextReview:     project.NextReview(),
+			OpenActions:    len(openActions[project.ID]),
+		}
+		records = append(records, record)
+
+		lastReviewed, due := "never", displayDate(record.NextReview)
+		if project.LastReviewedAt.Valid {
+			lastReviewed = displayDate(reviewedDate(project))
+		} else {
+			due = "now"
+		}
+		table.AddRow(fmt.Sprint(record.ID), record.Name, record.ReviewInterval, lastReviewed, due, fmt.Sprint(record.OpenActions))
+	}
+
+	if err := output.Print(records, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintProjects", i18n.Data{"Error": err}))
+	}
+}
+
+// reviewDetail describes when a project was last reviewed and how often
+func reviewDetail(project database.Project) string {
+	if !project.LastReviewedAt.Valid {
+		return i18n.T("ReviewedNever", i18n.Data{"Interval": project.ReviewInterval.String})
+	}
+	return i18n.T("ReviewedLast", i18n.Data{"Interval": project.ReviewInterval.String, "Last": datefmt.Date(reviewedDate(project)), "Due": datefmt.Date(project.NextReview())})
+}
+
+// reviewedDate returns the local date a project was last reviewed
+func reviewedDate(project database.Project) string {
+	reviewed, err := time.Parse(database.ChangeTimeFormat, project.LastReviewedAt.String)
+	if err != nil {
+		return project.LastReviewedAt.String
+	}
+	return reviewed.Local().Format("2006-01-02")
+}
@@ -0,0 +1,234 @@
+// Package rules applies the action rules of the config file as actions are
+// created or updated, such as "when an action tagged bill is created, set its
+// due date to the end of the month and its priority to high".
+package rules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/filter"
+	"github.com/joelgrimberg/projector/quickadd"
+)
+
+// Events the rules run on
+const (
+	Created = "created"
+	Updated = "updated"
+)
+
+// Change is a field a rule changes, with its value before and after
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Result lists the changes a single rule makes to an action
+type Result struct {
+	Rule    string   `json:"rule"`
+	Changes []Change `json:"changes"`
+}
+
+var rules []config.ActionRule
+
+// SetRules validates the rules and sets them for the rest of the process.
+// Invalid rules are not set, so that no rule runs.
+func SetRules(configured []config.ActionRule) error {
+	if err := Validate(configured); err != nil {
+		rules = nil
+		return err
+	}
+	rules = configured
+	return nil
+}
+
+// Enabled reports whether any rule is set
+func Enabled() bool {
+	return len(rules) > 0
+}
+
+// Validate checks the rules for names, filter expressions and changes
+func Validate(configured []config.ActionRule) error {
+	names := map[string]bool{}
+	for i, rule := range configured {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d requires a name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.On != "" && rule.On != Created && rule.On != Updated {
+			return fmt.Errorf("rule %s: on must be created or updated, not %s", rule.Name, rule.On)
+		}
+		if _, err := filter.Parse(rule.If, time.Now()); err != nil {
+			return fmt.Errorf("rule %s: invalid if: %v", rule.Name, err)
+		}
+		if rule.Due == "" && rule.Priority == "" && rule.Tag == "" && rule.Project == "" && !rule.Flag {
+			return fmt.Errorf("rule %s requires a due, priority, tag, project or flag", rule.Name)
+		}
+		if rule.Due != "" {
+			if _, err := quickadd.ParseRelativeDate(rule.Due, time.Now()); err != nil {
+				return fmt.Errorf("rule %s: due: %v", rule.Name, err)
+			}
+		}
+		if strings.ContainsAny(rule.Priority+rule.Tag, " ,") {
+			return fmt.Errorf("rule %s: priority and tag cannot contain spaces or commas", rule.Name)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the changes the rules make to an action, without applying
+// them. Previous is the action before an update, or nil for a created
+// action. Each rule sees the changes of the rules before it.
+func Evaluate(dbPath string, configured []config.ActionRule, previous, action *database.Action, now time.Time) ([]Result, error) {
+	results, _, err := evaluate(dbPath, configured, previous, action, now)
+	return results, err
+}
+
+// evaluate returns the changes of the rules and the action as they leave it
+func evaluate(dbPath string, configured []config.ActionRule, previous, action *database.Action, now time.Time) ([]Result, *database.Action, error) {
+	event := Created
+	if previous != nil {
+		event = Updated
+	}
+
+	current := *action
+	current.Tags = slices.Clone(action.Tags)
+
+	var results []Result
+	for _, rule := range configured {
+		if rule.On != "" && rule.On != event {
+			continue
+		}
+
+		condition, err := filter.Parse(rule.If, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %s: invalid if: %v", rule.Name, err)
+		}
+		if !condition.Match(&current) {
+			continue
+		}
+		// An update runs the rule only when the action starts to match, so
+		// that the rule does not undo later changes by hand
+		if previous != nil && condition.Match(previous) {
+			continue
+		}
+
+		changes, err := change(dbPath, rule, &current, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule %s: %v", rule.Name, err)
+		}
+		if len(changes) > 0 {
+			results = append(results, Result{Rule: rule.Name, Changes: changes})
+		}
+	}
+	return results, &current, nil
+}
+
+// change applies a rule to an action in memory and returns what it changed
+func change(dbPath string, rule config.ActionRule, action *database.Action, now time.Time) ([]Change, error) {
+	var changes []Change
+
+	if rule.Due != "" {
+		due, err := quickadd.ParseRelativeDate(rule.Due, now)
+		if err != nil {
+			return nil, err
+		}
+		if current := database.StoredDate(action.DueDate.String); current != due {
+			changes = append(changes, Change{Field: "due", From: current, To: due})
+			action.DueDate.String, action.DueDate.Valid = due, true
+		}
+	}
+
+	tags := slices.Clone(action.Tags)
+	if rule.Priority != "" {
+		// An action has one priority, the rule replaces it
+		tags = slices.DeleteFunc(tags, func(tag string) bool {
+			return strings.HasPrefix(tag, quickadd.PriorityTagPrefix)
+		})
+		tags = append(tags, quickadd.PriorityTagPrefix+rule.Priority)
+	}
+	if rule.Tag != "" && !slices.Contains(tags, rule.Tag) {
+		tags = append(tags, rule.Tag)
+	}
+	if !slices.Equal(tags, action.Tags) {
+		changes = append(changes, Change{Field: "tags", From: strings.Join(action.Tags, ","), To: strings.Join(tags, ",")})
+		action.Tags = tags
+	}
+
+	if rule.Project != "" && rule.Project != action.ProjectName.String {
+		project, err := database.GetProjectByName(dbPath, rule.Project)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up project %s: %v", rule.Project, err)
+		}
+		if project == nil {
+			return nil, fmt.Errorf("project %s not found", rule.Project)
+		}
+		changes = append(changes, Change{Field: "project", From: action.ProjectName.String, To: project.Name})
+		action.ProjectID.Int64, action.ProjectID.Valid = int64(project.ID), true
+		action.ProjectName.String, action.ProjectName.Valid = project.Name, true
+	}
+
+	if rule.Flag && !action.Flagged {
+		changes = append(changes, Change{Field: "flagged", From: "false", To: "true"})
+		action.Flagged = true
+	}
+
+	return changes, nil
+}
+
+// Apply runs the rules set with SetRules on an action that was just created,
+// or updated from previous, and stores their changes
+func Apply(dbPath string, previous *database.Action, actionID uint) ([]Result, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+
+	action, err := database.GetActionByID(dbPath, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving action: %v", err)
+	}
+	if action == nil {
+		return nil, fmt.Errorf("action not found")
+	}
+
+	results, changed, err := evaluate(dbPath, rules, previous, action, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	update := database.ActionUpdate{}
+	if changed.DueDate.String != action.DueDate.String {
+		update.DueDate = &changed.DueDate.String
+	}
+	if changed.ProjectID != action.ProjectID {
+		projectID := uint(changed.ProjectID.Int64)
+		update.ProjectID = &projectID
+	}
+	if changed.Flagged != action.Flagged {
+		update.Flagged = &changed.Flagged
+	}
+	if update != (database.ActionUpdate{}) {
+		if err := database.UpdateAction(dbPath, actionID, update); err != nil {
+			return nil, fmt.Errorf("error updating action: %v", err)
+		}
+	}
+	if !slices.Equal(changed.Tags, action.Tags) {
+		if err := database.SetActionTags(dbPath, actionID, changed.Tags); err != nil {
+			return nil, fmt.Errorf("error tagging action: %v", err)
+		}
+	}
+
+	return results, nil
+}
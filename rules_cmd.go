@@ -0,0 +1,253 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/quickadd"
+	"github.com/joelgrimberg/projector/rules"
+
+	"github.com/spf13/cobra"
+)
+
+func rulesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "List and test the action rules of the config file",
+	}
+
+	cmd.AddCommand(rulesListCmd())
+	cmd.AddCommand(rulesTestCmd())
+	return cmd
+}
+
+func rulesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the action rules of the config file",
+		Run: func(cmd *cobra.Command, args []string) {
+			configured, ok := loadRules()
+			if !ok {
+				return
+			}
+
+			table := output.Table{Headers: []string{"NAME", "ON", "IF", "THEN"}}
+			for _, rule := range configured {
+				on := rule.On
+				if on == "" {
+					on = "created,updated"
+				}
+				table.AddRow(rule.Name, on, rule.If, describeRule(rule))
+			}
+			if err := output.Print(configured, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintRules", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func rulesTestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "test [id | line]",
+		Short: "Show what the action rules would change, without changing anything",
+		Long: `Show what the action rules would change when an action is created, without
+changing anything. Pass the ID of an existing action, or a line in the syntax of
+projector add to test an action that does not exist yet:
+
+  projector rules test 'Pay rent #bill'
+
+Without an argument every open action is tested.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+			configured, ok := loadRules()
+			if !ok {
+				return
+			}
+
+			actions, ok := ruleTestActions(args)
+			if !ok {
+				return
+			}
+
+			type testRecord struct {
+				ActionID uint           `json:"action_id,omitempty"`
+				Action   string         `json:"action"`
+				Results  []rules.Result `json:"results"`
+			}
+			records := []testRecord{}
+			table := output.Table{Headers: []string{"ID", "ACTION", "RULE", "FIELD", "FROM", "TO"}}
+			now := time.Now()
+			for i := range actions {
+				action := &actions[i]
+				results, err := rules.Evaluate(database.GetDatabasePath(), configured, nil, action, now)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToTestRules", i18n.Data{"Name": action.Name, "Error": err}))
+					return
+				}
+				if len(results) == 0 {
+					continue
+				}
+
+				id := ""
+				if action.ID != 0 {
+					id = strconv.FormatUint(uint64(action.ID), 10)
+				}
+				records = append(records, testRecord{ActionID: action.ID, Action: action.Name, Results: results})
+				for _, result := range results {
+					for _, change := range result.Changes {
+						table.AddRow(id, action.Name, result.Rule, change.Field, orNone(change.From), change.To)
+					}
+				}
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoRuleMatches"))
+				return
+			}
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintRuleTest", i18n.Data{"Error": err}))
+			}
+		},
+	}
+	return cmd
+}
+
+// loadRules reads and validates the action rules of the config file
+func loadRules() ([]config.ActionRule, bool) {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+	if len(cfg.Rules) == 0 {
+		fmt.Println(i18n.T("NoRules"))
+		return nil, false
+	}
+	if err := rules.Validate(cfg.Rules); err != nil {
+		fmt.Println(i18n.T("InvalidRuleConfig", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	return cfg.Rules, true
+}
+
+// ruleTestActions returns the actions to test the rules on: the action with
+// the ID, an action parsed from a quick-add line, or every open action
+func ruleTestActions(args []string) ([]database.Action, bool) {
+	dbPath := database.GetDatabasePath()
+
+	if len(args) == 0 {
+		all, err := database.GetAllActions(dbPath)
+		if err != nil {
+			fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+			return nil, false
+		}
+		var open []database.Action
+		for _, action := range all {
+			if action.StatusName != "done" {
+				open = append(open, action)
+			}
+		}
+		return open, true
+	}
+
+	if actionID, err := strconv.ParseUint(args[0], 10, 32); err == nil {
+		action, err := database.GetActionByID(dbPath, uint(actionID))
+		if err != nil {
+			fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+			return nil, false
+		}
+		if action == nil {
+			fmt.Println(i18n.T("ActionNotFound", i18n.Data{"ID": actionID}))
+			return nil, false
+		}
+		return []database.Action{*action}, true
+	}
+
+	entry, err := quickadd.Parse(args[0], time.Now())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+	resolved, err := entry.Resolve(dbPath)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return nil, false
+	}
+
+	action := database.Action{
+		Name:       resolved.Name,
+		DueDate:    sql.NullString{String: resolved.DueDate, Valid: resolved.DueDate != ""},
+		StartDate:  sql.NullString{String: resolved.StartDate, Valid: resolved.StartDate != ""},
+		Tags:       resolved.Tags,
+		StatusName: "todo",
+	}
+	if entry.Project != "" {
+		action.ProjectID = sql.NullInt64{Int64: int64(*resolved.ProjectID), Valid: true}
+		project, err := database.GetProjectByID(dbPath, *resolved.ProjectID)
+		if err == nil && project != nil {
+			action.ProjectName = sql.NullString{String: project.Name, Valid: true}
+		}
+	}
+	if statusID, err := database.DefaultStatusID(dbPath); err == nil {
+		if status, err := database.GetStatus(dbPath, strconv.FormatUint(uint64(statusID), 10)); err == nil && status != nil {
+			action.StatusID, action.StatusName = status.ID, status.Name
+		}
+	}
+	return []database.Action{action}, true
+}
+
+// describeRule summarizes the changes a rule makes
+func describeRule(rule config.ActionRule) string {
+	var parts []string
+	if rule.Due != "" {
+		parts = append(parts, "due "+rule.Due)
+	}
+	if rule.Priority != "" {
+		parts = append(parts, "priority "+rule.Priority)
+	}
+	if rule.Tag != "" {
+		parts = append(parts, "tag "+rule.Tag)
+	}
+	if rule.Project != "" {
+		parts = append(parts, "project "+rule.Project)
+	}
+	if rule.Flag {
+		parts = append(parts, "flag")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// orNone returns the value, or "none" when it is empty
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// applyRules runs the action rules on an action of the local database that
+// was just created, or updated from previous, and reports what they changed
+func applyRules(previous *database.Action, actionID uint) {
+	results, err := rules.Apply(database.GetDatabasePath(), previous, actionID)
+	if err != nil {
+		fmt.Println(i18n.T("FailedToApplyRules", i18n.Data{"ID": actionID, "Error": err}))
+		return
+	}
+	if output.IsJSON() {
+		return
+	}
+	for _, result := range results {
+		fmt.Println(i18n.T("RuleChangedAction", i18n.Data{"Rule": result.Rule, "ID": actionID}))
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+func seedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Fill the database with sample data",
+		Run: func(cmd *cobra.Command, args []string) {
+			demo, _ := cmd.Flags().GetBool("demo")
+			force, _ := cmd.Flags().GetBool("force")
+
+			if !demo {
+				fmt.Println(i18n.T("SeedWhat"))
+				return
+			}
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			// Demo data mixed into real data is hard to clean up again
+			stats, err := database.GetStats(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorReadingDatabase", i18n.Data{"Error": err}))
+				return
+			}
+			if (stats.Projects > 0 || stats.Actions > 0) && !force {
+				fmt.Println(i18n.T("SeedDatabaseNotEmpty", i18n.Data{"Projects": stats.Projects, "Actions": stats.Actions}))
+				return
+			}
+
+			result, err := database.SeedDemoData(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("FailedToSeed", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("SeededDemoData", i18n.Data{"Projects": result.Projects, "Actions": result.Actions, "Tags": result.Tags}))
+			fmt.Println(i18n.T("SeedExplore"))
+		},
+	}
+
+	cmd.Flags().Bool("demo", false, "Add demo projects, actions and tags")
+	cmd.Flags().Bool("force", false, "Seed even when the database is not empty")
+	return cmd
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/joelgrimberg/projector/daemon"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+func serveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the API server, in the background with --daemon",
+		Run: func(cmd *cobra.Command, args []string) {
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			grpcPort, _ := cmd.Flags().GetInt("grpc-port")
+			background, _ := cmd.Flags().GetBool("daemon")
+			pidFile, _ := cmd.Flags().GetString("pid-file")
+			logFile, _ := cmd.Flags().GetString("log-file")
+			readOnly, _ := cmd.Flags().GetBool("read-only")
+
+			if pidFile == "" {
+				pidFile = daemon.GetPIDFilePath(database.GetDataDir(database.GetDatabasePath()))
+			}
+
+			if background {
+				if logFile == "" {
+					logFile = daemon.GetLogFilePath(database.GetDataDir(database.GetDatabasePath()))
+				}
+				runDetached(verbose, grpcPort, readOnly, pidFile, logFile)
+				return
+			}
+
+			// Send the server output and log messages to the log file.
+			// Without one the log messages go to stderr, which is the
+			// journal when systemd runs the server.
+			if logFile != "" {
+				file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToOpenLogFile", i18n.Data{"Error": err}))
+					return
+				}
+				defer file.Close()
+				os.Stdout = file
+				os.Stderr = file
+				log.SetOutput(file)
+			}
+
+			if err := daemon.WritePIDFile(pidFile); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			defer daemon.RemovePIDFile(pidFile)
+
+			startAPIServer(verbose, grpcPort, readOnly)
+		},
+	}
+
+	cmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	cmd.Flags().Int("grpc-port", 0, "Also serve the gRPC API on this port")
+	cmd.Flags().Bool("read-only", false, "Only serve requests that read data, rejecting all changes with 403 Forbidden")
+	cmd.Flags().BoolP("daemon", "d", false, "Run the server in the background")
+	cmd.Flags().String("pid-file", "", "PID file path (default: projector.pid next to the database)")
+	cmd.Flags().String("log-file", "", "Write output to this file (default with --daemon: projector.log next to the database)")
+	return cmd
+}
+
+// runDetached starts `projector serve` in the background with the same options
+func runDetached(verbose bool, grpcPort int, readOnly bool, pidFile, logFile string) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	pid, err := daemon.ReadPIDFile(pidFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if pid != 0 && daemon.Running(pid) {
+		fmt.Println(i18n.T("ServerAlreadyRunning", i18n.Data{"PID": pid}))
+		return
+	}
+
+	args := []string{"serve", "--pid-file", pidFile, "--log-file", logFile}
+	if verbose {
+		args = append(args, "--verbose")
+	}
+	if grpcPort > 0 {
+		args = append(args, "--grpc-port", strconv.Itoa(grpcPort))
+	}
+	if readOnly {
+		args = append(args, "--read-only")
+	}
+
+	pid, err = daemon.Detach(args, pidFile, logFile, 10*time.Second)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	fmt.Println(i18n.T("ServerStartedInBackground", i18n.Data{"PID": pid}))
+	fmt.Println(i18n.T("LoggingTo", i18n.Data{"Path": logFile}))
+}
+
+func statusCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether a background server is running",
+		Run: func(cmd *cobra.Command, args []string) {
+			pidFile, _ := cmd.Flags().GetString("pid-file")
+			if pidFile == "" {
+				pidFile = daemon.GetPIDFilePath(database.GetDataDir(database.GetDatabasePath()))
+			}
+
+			pid, ok := runningPID(pidFile)
+			if !ok {
+				return
+			}
+
+			fmt.Println(i18n.T("ServerRunning", i18n.Data{"PID": pid}))
+
+			client := &http.Client{Timeout: 2 * time.Second}
+			response, err := client.Get("http://localhost:8080/health")
+			if err != nil {
+				fmt.Println(i18n.T("APIServerNotResponding", i18n.Data{"Error": err}))
+				return
+			}
+			response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				fmt.Println(i18n.T("APIServerHealthCheckFailed", i18n.Data{"Status": response.Status}))
+				return
+			}
+			fmt.Println(i18n.T("APIServerResponding"))
+		},
+	}
+
+	cmd.Flags().String("pid-file", "", "PID file path (default: projector.pid next to the database)")
+	return cmd
+}
+
+func stopCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a background server",
+		Run: func(cmd *cobra.Command, args []string) {
+			pidFile, _ := cmd.Flags().GetString("pid-file")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			if pidFile == "" {
+				pidFile = daemon.GetPIDFilePath(database.GetDataDir(database.GetDatabasePath()))
+			}
+
+			pid, ok := runningPID(pidFile)
+			if !ok {
+				return
+			}
+
+			if err := daemon.Stop(pid, timeout); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				return
+			}
+			fmt.Println(i18n.T("ServerStopped", i18n.Data{"PID": pid}))
+		},
+	}
+
+	cmd.Flags().String("pid-file", "", "PID file path (default: projector.pid next to the database)")
+	cmd.Flags().Duration("timeout", 10*time.Second, "How long to wait for the server to exit")
+	return cmd
+}
+
+// runningPID returns the PID of the running server, printing a message when
+// there is none. A PID file left behind by a server that died is removed.
+func runningPID(pidFile string) (int, bool) {
+	pid, err := daemon.ReadPIDFile(pidFile)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return 0, false
+	}
+	if pid == 0 {
+		fmt.Println(i18n.T("ServerNotRunning"))
+		return 0, false
+	}
+	if !daemon.Running(pid) {
+		os.Remove(pidFile)
+		fmt.Println(i18n.T("ServerNotRunningStalePID", i18n.Data{"PID": pid}))
+		return 0, false
+	}
+
+	return pid, true
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/markdown"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// actionDetailRecord is the JSON form of everything about an action
+type actionDetailRecord struct {
+	actionRecord
+	Reminders   []string           `json:"reminders"`
+	Series      []uint             `json:"series"`
+	Attachments []attachmentRecord `json:"attachments"`
+	History     []historyRecord    `json:"history"`
+}
+
+// attachmentRecord is the JSON form of a link attached to an action
+type attachmentRecord struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// historyRecord is the JSON form of a recorded state of an action
+type historyRecord struct {
+	ChangedAt   string `json:"changed_at"`
+	Status      string `json:"status,omitempty"`
+	Project     string `json:"project,omitempty"`
+	SkippedDate string `json:"skipped_date,omitempty"`
+	Deleted     bool   `json:"deleted,omitempty"`
+}
+
+func showCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show everything about an action",
+		Long: `Show everything about an action: its note, project, dates, repeat schedule,
+tags, reminders, attachments, the occurrences of its series and the history
+of its status and project. Use -o json for scripts.
+
+The note is rendered as Markdown, with headings, lists, checkboxes, links
+and code blocks; --raw shows it as written.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			actionID, ok := parseActionID(args[0])
+			if !ok {
+				return
+			}
+
+			action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+				return
+			}
+			if action == nil {
+				fmt.Println(i18n.T("ActionNotFound", i18n.Data{"ID": actionID}))
+				return
+			}
+
+			record, err := newActionDetailRecord(action)
+			if err != nil {
+				fmt.Printf("❌ Error retrieving action details: %v\n", err)
+				return
+			}
+
+			if output.Format() == output.FormatTable {
+				raw, _ := cmd.Flags().GetBool("raw")
+				printActionDetail(record, raw)
+				return
+			}
+
+			// Plain output has a field and its value per line
+			table := output.Table{Headers: []string{"FIELD", "VALUE"}}
+			for _, field := range actionDetailFields(record) {
+				table.AddRow(field[0], field[1])
+			}
+			if err := output.Print(record, table); err != nil {
+				fmt.Printf("❌ Failed to print action: %v\n", err)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("raw", false, "Show the note as written rather than rendered as Markdown")
+	return cmd
+}
+
+// newActionDetailRecord collects everything about an action
+func newActionDetailRecord(action *database.Action) (actionDetailRecord, error) {
+	record := actionDetailRecord{
+		actionRecord: newActionRecord(*action),
+		Reminders:    action.Reminders,
+		Attachments:  []attachmentRecord{},
+		History:      []historyRecord{},
+	}
+	if record.Reminders == nil {
+		record.Reminders = []string{}
+	}
+
+	series, err := database.GetSeriesOccurrenceIDs(database.GetDatabasePath(), action.ID)
+	if err != nil {
+		return record, err
+	}
+	record.Series = series
+
+	attachments, err := database.GetAttachments(database.GetDatabasePath(), action.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, attachment := range attachments {
+		record.Attachments = append(record.Attachments, attachmentRecord{URL: attachment.URL, Title: attachment.Title.String})
+	}
+
+	history, err := database.GetActionHistory(database.GetDatabasePath(), action.ID)
+	if err != nil {
+		return record, err
+	}
+	for _, entry := range history {
+		record.History = append(record.History, historyRecord{
+			ChangedAt:   entry.ChangedAt,
+			Status:      entry.Status.String,
+			Project:     entry.ProjectName.String,
+			SkippedDate: entry.SkippedDate.String,
+			Deleted:     !entry.Status.Valid,
+		})
+	}
+	return record, nil
+}
+
+// actionDetailFields returns the fields of an action that are set, as label
+// and value pairs. The note, attachments and history are printed apart.
+func actionDetailFields(record actionDetailRecord) [][2]string {
+	var fields [][2]string
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{label, value})
+		}
+	}
+
+	add("name", record.Name)
+	add("status", statusLabel(record.Status))
+	if record.ProjectID != 0 {
+		add("project", fmt.Sprintf("%s (%d)", record.Project, record.ProjectID))
+	}
+	add("due", displayDate(record.DueDate))
+	add("start", displayDate(record.StartDate))
+	add("repeat", record.Repeat)
+	add("tags", strings.Join(record.Tags, ", "))
+	add("reminders", strings.Join(record.Reminders, ", "))
+	add("assignee", record.Assignee)
+	if record.Flagged {
+		add("flagged", "today")
+	}
+	add("location", record.Location)
+	add("energy", record.Energy)
+	add("waiting on", record.WaitingOn)
+	add("follow up", displayDate(record.FollowUp))
+
+	// The series lists the occurrences that followed, the action itself first
+	if len(record.Series) > 1 {
+		var ids []string
+		for _, id := range record.Series[1:] {
+			ids = append(ids, fmt.Sprintf("#%d", id))
+		}
+		add("next", strings.Join(ids, ", "))
+	}
+	return fields
+}
+
+// printActionDetail prints an action as a title, its fields, and its note,
+// attachments and history in sections below. The note is rendered as
+// Markdown unless raw is set.
+func printActionDetail(record actionDetailRecord, raw bool) {
+	fmt.Printf("#%d %s\n\n", record.ID, record.Name)
+
+	fields := actionDetailFields(record)[1:] // The name is the title
+	width := 0
+	for _, field := range fields {
+		width = max(width, len(field[0]))
+	}
+	for _, field := range fields {
+		fmt.Printf("  %-*s  %s\n", width+1, field[0]+":", field[1])
+	}
+
+	if note := strings.TrimSpace(record.Note); note != "" {
+		if !raw {
+			note = markdown.Render(note, max(output.TerminalWidth()-2, 0))
+		}
+		fmt.Println("\nNote")
+		for _, line := range strings.Split(note, "\n") {
+			fmt.Println("  " + line)
+		}
+	}
+
+	if len(record.Attachments) > 0 {
+		fmt.Println("\nAttachments")
+		for _, attachment := range record.Attachments {
+			if attachment.Title != "" {
+				fmt.Printf("  %s <%s>\n", attachment.Title, attachment.URL)
+			} else {
+				fmt.Println("  " + attachment.URL)
+			}
+		}
+	}
+
+	if len(record.History) > 0 {
+		fmt.Println("\nHistory")
+		for i, entry := range record.History {
+			fmt.Printf("  %s  %s\n", displayTimestamp(entry.ChangedAt), describeHistory(entry, i == 0))
+		}
+	}
+}
+
+// describeHistory describes a recorded state of an action, the first one
+// being its creation, or the earliest state known
+func describeHistory(entry historyRecord, first bool) string {
+	var description string
+	switch {
+	case entry.Deleted:
+		return "deleted"
+	case entry.SkippedDate != "":
+		return "skipped the occurrence due " + displayDate(entry.SkippedDate)
+	case first:
+		description = "created as " + entry.Status
+	default:
+		description = "status " + entry.Status
+	}
+	if entry.Project != "" {
+		description += " in " + entry.Project
+	}
+	return description
+}
+
+// displayTimestamp formats a stored UTC timestamp as a local date and time
+// in the configured formats
+func displayTimestamp(value string) string {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.Local()
+			return datefmt.Absolute(t.Format("2006-01-02")) + " " + datefmt.Time(t)
+		}
+	}
+	return value
+}
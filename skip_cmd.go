@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/hooks"
+
+	"github.com/spf13/cobra"
+)
+
+func skipCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "skip [id]",
+		Short: "Skip the next occurrence of a repeating action without completing it",
+		Long: `Skip the open occurrence of a repeating action without completing it: the
+action moves on to the next due date of its schedule and the skipped date is
+recorded in its history. In a series with a number of repetitions, the skipped
+occurrence counts as one of them.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, ok := remoteClient(cmd)
+			if !ok {
+				return
+			}
+
+			actionID, ok := argOrPickedAction(client, args, "Which occurrence do you skip?")
+			if !ok {
+				return
+			}
+
+			var skippedDate, nextDueDate string
+			if client != nil {
+				skipped, err := client.SkipAction(actionID)
+				if err != nil {
+					fmt.Printf("❌ Failed to skip action %d: %v\n", actionID, err)
+					return
+				}
+				skippedDate, nextDueDate = skipped.SkippedDate, skipped.NextDueDate
+			} else {
+				result, err := database.SkipOccurrence(database.GetDatabasePath(), actionID)
+				if err != nil {
+					fmt.Printf("❌ Failed to skip action %d: %v\n", actionID, err)
+					return
+				}
+				skippedDate, nextDueDate = result.SkippedDate, result.NextDueDate
+			}
+			fmt.Printf("⏭️ Skipped action %d on %s, next due %s\n", actionID, skippedDate, nextDueDate)
+			if client == nil {
+				fireActionHook(hooks.ActionUpdated, actionID)
+			}
+		},
+	}
+
+	addRemoteFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func statsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show the number of projects, actions and tags",
+		Long: `Show the number of projects, actions and tags.
+
+With --format csv the totals per project are exported for spreadsheets
+instead: the actions, open, done and overdue actions, and the actions
+completed since --since. With --daily the export has the number of actions
+completed per day.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			since, _ := cmd.Flags().GetString("since")
+			daily, _ := cmd.Flags().GetBool("daily")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			switch format {
+			case "":
+				if since != "" || daily {
+					fmt.Println(i18n.T("StatsCSVOnly"))
+					return
+				}
+			case "csv":
+				if _, err := time.Parse("2006-01-02", since); since != "" && err != nil {
+					fmt.Println(i18n.T("InvalidSinceDate", i18n.Data{"Date": since}))
+					return
+				}
+				if err := exportStatsCSV(since, daily); err != nil {
+					fmt.Fprintln(os.Stderr, i18n.T("FailedToExportStats", i18n.Data{"Error": err}))
+				}
+				return
+			default:
+				fmt.Println(i18n.T("UnknownStatsFormat", i18n.Data{"Format": format}))
+				return
+			}
+
+			stats, err := database.GetStats(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingStats", i18n.Data{"Error": err}))
+				return
+			}
+
+			printStats(stats)
+		},
+	}
+
+	cmd.Flags().String("format", "", "Export format: csv")
+	cmd.Flags().String("since", "", "Count the actions completed on or after this date (YYYY-MM-DD, default all time)")
+	cmd.Flags().Bool("daily", false, "Export the number of actions completed per day instead of the project totals")
+	return cmd
+}
+
+// exportStatsCSV writes the totals per project, or the completions per day,
+// as CSV with a header row to stdout
+func exportStatsCSV(since string, daily bool) error {
+	w := csv.NewWriter(os.Stdout)
+
+	if daily {
+		counts, err := database.GetCompletionCounts(database.GetDatabasePath(), since)
+		if err != nil {
+			return err
+		}
+		w.Write([]string{"date", "completed"})
+		for _, count := range counts {
+			w.Write([]string{count.Date, fmt.Sprint(count.Completed)})
+		}
+	} else {
+		projects, err := database.GetProjectStats(database.GetDatabasePath(), since)
+		if err != nil {
+			return err
+		}
+		w.Write([]string{"project_id", "project", "actions", "open", "done", "overdue", "completed"})
+		for _, project := range projects {
+			w.Write([]string{
+				fmt.Sprint(project.ProjectID), project.Project, fmt.Sprint(project.Actions), fmt.Sprint(project.Open),
+				fmt.Sprint(project.Done), fmt.Sprint(project.Overdue), fmt.Sprint(project.Completed),
+			})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// printStats prints database statistics in the selected output format
+func printStats(stats *database.Stats) {
+	table := output.Table{Headers: []string{"METRIC", "VALUE"}}
+	table.AddRow("users", fmt.Sprint(stats.Users))
+	table.AddRow("projects", fmt.Sprint(stats.Projects))
+	table.AddRow("actions", fmt.Sprint(stats.Actions))
+	table.AddRow("open_actions", fmt.Sprint(stats.OpenActions))
+	table.AddRow("done_actions", fmt.Sprint(stats.DoneActions))
+	table.AddRow("overdue_actions", fmt.Sprint(stats.OverdueActions))
+	table.AddRow("tags", fmt.Sprint(stats.Tags))
+	table.AddRow("size_bytes", fmt.Sprint(stats.SizeBytes))
+
+	if err := output.Print(stats, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintStats", i18n.Data{"Error": err}))
+	}
+}
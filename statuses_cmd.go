@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// statusRecord is the JSON form of a status in command output
+type statusRecord struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Color   string `json:"color,omitempty"`
+	Icon    string `json:"icon,omitempty"`
+	Actions int    `json:"actions"`
+	Limit   int    `json:"limit,omitempty"` // Work-in-progress limit
+}
+
+// statusStyles holds the statuses by name once statusLabel has loaded them
+var statusStyles map[string]database.Status
+
+// statusLabel returns a status name as shown in tables: after its icon and
+// in its color. Plain and JSON output get the bare name, so scripts can keep
+// matching on it.
+func statusLabel(name string) string {
+	if output.Format() != output.FormatTable {
+		return name
+	}
+
+	if statusStyles == nil {
+		statusStyles = make(map[string]database.Status)
+		statuses, err := database.GetAllStatuses(database.GetDatabasePath())
+		if err != nil {
+			return name
+		}
+		for _, status := range statuses {
+			statusStyles[status.Name] = status
+		}
+	}
+
+	status := statusStyles[name]
+	label := output.Colorize(name, status.Color.String)
+	if status.Icon.String != "" {
+		label = status.Icon.String + " " + label
+	}
+	return label
+}
+
+func statusesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "statuses",
+		Short: "List action statuses with their color, icon and number of actions",
+		Long: `List action statuses with their color, icon and number of actions. Statuses
+with a work-in-progress limit in the workflow section of the config show
+their number of actions out of the limit, such as 2/3. With --project the
+actions and limits are those of a single project.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			var projectID uint
+			var projectName string
+			if nameOrID, _ := cmd.Flags().GetString("project"); nameOrID != "" {
+				project, ok := lookupProject(nameOrID)
+				if !ok {
+					return
+				}
+				projectID, projectName = project.ID, project.Name
+			}
+
+			statuses, err := database.GetAllStatuses(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingStatuses", i18n.Data{"Error": err}))
+				return
+			}
+			counts, err := database.CountActionsByStatus(database.GetDatabasePath(), projectID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorCountingActions", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []statusRecord{}
+			table := output.Table{Headers: []string{"ID", "STATUS", "COLOR", "ICON", "ACTIONS"}}
+			for _, status := range statuses {
+				record := statusRecord{
+					ID:      status.ID,
+					Name:    status.Name,
+					Color:   status.Color.String,
+					Icon:    status.Icon.String,
+					Actions: counts[status.ID],
+					Limit:   database.WIPLimit(status.Name, projectName),
+				}
+				records = append(records, record)
+				table.AddRow(fmt.Sprint(record.ID), statusLabel(record.Name), record.Color, record.Icon, wipUsage(record.Actions, record.Limit))
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintStatuses", i18n.Data{"Error": err}))
+			}
+		},
+	}
+
+	cmd.Flags().StringP("project", "p", "", "Count the actions and show the limits of a project (name or ID)")
+	cmd.RegisterFlagCompletionFunc("project", completeProjectIDs)
+	cmd.AddCommand(statusesEditCmd())
+	return cmd
+}
+
+// wipUsage shows the number of actions in a status, out of its
+// work-in-progress limit when it has one: yellow when the limit is reached
+// and red when it is exceeded
+func wipUsage(count, limit int) string {
+	if limit == 0 {
+		return fmt.Sprint(count)
+	}
+	usage := fmt.Sprintf("%d/%d", count, limit)
+	switch {
+	case count > limit:
+		return output.Colorize(usage, "red")
+	case count == limit:
+		return output.Colorize(usage, "yellow")
+	}
+	return usage
+}
+
+func statusesEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <status>",
+		Short: "Set the color and icon of a status",
+		Long: fmt.Sprintf(`Set the color and icon a status is shown with in tables and the web interface.
+
+The color is one of %s, or a #rrggbb color. Pass an empty
+value to remove the color or icon.
+
+Examples:
+  projector statuses edit done --color green --icon ✅
+  projector statuses edit todo --icon ""`, strings.Join(database.StatusColors, ", ")),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			var color, icon *string
+			if cmd.Flags().Changed("color") {
+				value, _ := cmd.Flags().GetString("color")
+				color = &value
+			}
+			if cmd.Flags().Changed("icon") {
+				value, _ := cmd.Flags().GetString("icon")
+				icon = &value
+			}
+			if color == nil && icon == nil {
+				fmt.Println(i18n.T("NothingToChangeStatus"))
+				return
+			}
+
+			status, err := database.GetStatus(database.GetDatabasePath(), args[0])
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingStatus", i18n.Data{"Error": err}))
+				return
+			}
+			if status == nil {
+				fmt.Println(i18n.T("StatusNotFound", i18n.Data{"Name": args[0]}))
+				return
+			}
+
+			if err := database.UpdateStatusStyle(database.GetDatabasePath(), status.ID, color, icon); err != nil {
+				fmt.Println(i18n.T("FailedToUpdateStatus", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("UpdatedStatus", i18n.Data{"Name": status.Name, "Label": statusLabel(status.Name)}))
+		},
+	}
+
+	cmd.Flags().String("color", "", "Color of the status")
+	cmd.Flags().String("icon", "", "Icon shown before the status, such as an emoji")
+	return cmd
+}
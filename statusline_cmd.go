@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultStatuslineFormat is the status line printed without --format
+const defaultStatuslineFormat = "{due} due · {overdue} overdue"
+
+// statuslineCacheName is the file next to the database that caches the counts
+// of the status line
+const statuslineCacheName = "statusline.cache"
+
+// statuslineToken matches a token of the status line format, such as {due}
+var statuslineToken = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// statuslineTokens are the counts the status line format can show
+var statuslineTokens = map[string]func(*database.Summary) int{
+	"open":       func(s *database.Summary) int { return s.Open },
+	"due":        func(s *database.Summary) int { return s.Due },
+	"overdue":    func(s *database.Summary) int { return s.Overdue },
+	"today":      func(s *database.Summary) int { return s.Today },
+	"flagged":    func(s *database.Summary) int { return s.Flagged },
+	"follow_ups": func(s *database.Summary) int { return s.FollowUps },
+}
+
+// statuslineCache is the cached summary, valid as long as the key matches
+type statuslineCache struct {
+	Key     string           `json:"key"`
+	Summary database.Summary `json:"summary"`
+}
+
+func statuslineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "statusline",
+		Short: "Print a one-line summary of the actions due, for shell prompts and tmux",
+		Long: `Print a one-line summary of the open actions, such as "3 due · 1 overdue",
+for shell prompts (starship, powerlevel10k) and the tmux status bar.
+
+--format sets the line, with these tokens replaced by their count:
+  {due}         actions due today
+  {overdue}     actions due before today
+  {today}       actions flagged, due or overdue, as listed by 'projector today'
+  {flagged}     actions flagged for today
+  {follow_ups}  waiting actions whose follow-up date has arrived
+  {open}        all open actions
+Actions deferred to a later start date are not counted. Nothing is printed
+when every count in the line is zero, unless --always is given.
+
+The counts are cached next to the database until it changes or the day
+changes, so the command returns quickly enough to run on every prompt.`,
+		Example: `  # starship.toml
+  [custom.projector]
+  command = "projector statusline --format '✅ {today}'"
+  when = true
+
+  # .tmux.conf
+  set -g status-right '#(projector statusline)'`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, _ := cmd.Flags().GetString("format")
+			always, _ := cmd.Flags().GetBool("always")
+			noCache, _ := cmd.Flags().GetBool("no-cache")
+
+			// Errors go to stderr, so that they do not end up in the prompt
+			dbPath := database.GetDatabasePath()
+			if !database.DatabaseExists(dbPath) {
+				fmt.Fprintln(os.Stderr, i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			summary, err := statuslineSummary(dbPath, time.Now().Format("2006-01-02"), !noCache)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				return
+			}
+
+			if output.IsJSON() {
+				if err := output.Print(summary, output.Table{}); err != nil {
+					fmt.Fprintln(os.Stderr, i18n.T("FailedToPrintSummary", i18n.Data{"Error": err}))
+				}
+				return
+			}
+
+			line, empty, err := formatStatusline(format, summary)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+				return
+			}
+			if !empty || always {
+				fmt.Println(line)
+			}
+		},
+	}
+
+	cmd.Flags().String("format", defaultStatuslineFormat, "Line to print, with tokens such as {due}, {overdue} and {today}")
+	cmd.Flags().Bool("always", false, "Print the line even when every count is zero")
+	cmd.Flags().Bool("no-cache", false, "Count the actions without using or updating the cache")
+	return cmd
+}
+
+// formatStatusline replaces the tokens of the format by their counts. It
+// reports whether every count in the line is zero.
+func formatStatusline(format string, summary *database.Summary) (string, bool, error) {
+	empty := true
+	var unknown string
+	line := statuslineToken.ReplaceAllStringFunc(format, func(token string) string {
+		count, ok := statuslineTokens[strings.Trim(token, "{}")]
+		if !ok {
+			unknown = token
+			return token
+		}
+		if count(summary) > 0 {
+			empty = false
+		}
+		return strconv.Itoa(count(summary))
+	})
+	if unknown != "" {
+		return "", false, fmt.Errorf("unknown statusline token %s", unknown)
+	}
+	return line, empty, nil
+}
+
+// statuslineSummary returns the summary of the date, from the cache when the
+// database did not change since it was cached on the same date
+func statuslineSummary(dbPath, date string, useCache bool) (*database.Summary, error) {
+	if !useCache || database.IsMemoryPath(dbPath) {
+		return database.GetSummary(dbPath, date)
+	}
+
+	// Writes may only reach the write-ahead log, so it is part of the key
+	key := date
+	for _, path := range []string{dbPath, dbPath + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			key += fmt.Sprintf(" %d/%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+
+	cachePath := filepath.Join(database.GetDataDir(dbPath), statuslineCacheName)
+	var cache statuslineCache
+	if data, err := os.ReadFile(cachePath); err == nil && json.Unmarshal(data, &cache) == nil && cache.Key == key {
+		return &cache.Summary, nil
+	}
+
+	summary, err := database.GetSummary(dbPath, date)
+	if err != nil {
+		return nil, err
+	}
+
+	// A cache that cannot be written only makes the next call slower
+	if data, err := json.Marshal(statuslineCache{Key: key, Summary: *summary}); err == nil {
+		temporary := cachePath + ".tmp"
+		if os.WriteFile(temporary, data, 0644) == nil {
+			os.Rename(temporary, cachePath)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joelgrimberg/projector/caldav"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/remote"
+
+	"github.com/spf13/cobra"
+)
+
+func syncCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize actions with external services",
+		Long:  "Synchronize actions with external services, or with another projector instance using --remote",
+		Run: func(cmd *cobra.Command, args []string) {
+			remoteURL, _ := cmd.Flags().GetString("remote")
+			if remoteURL == "" {
+				remoteURL = os.Getenv("PROJECTOR_REMOTE_URL")
+			}
+			if remoteURL == "" {
+				cmd.Help()
+				return
+			}
+			token, _ := cmd.Flags().GetString("token")
+			if token == "" {
+				token = os.Getenv("PROJECTOR_REMOTE_TOKEN")
+			}
+			runRemoteSync(remoteURL, token)
+		},
+	}
+
+	cmd.Flags().String("remote", "", "URL of another projector API server to sync with (or PROJECTOR_REMOTE_URL)")
+	cmd.Flags().String("token", "", "API token for a remote with user accounts (or PROJECTOR_REMOTE_TOKEN)")
+
+	cmd.AddCommand(syncCalDAVCmd())
+	cmd.AddCommand(syncTodoistCmd())
+	return cmd
+}
+
+func syncCalDAVCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "caldav",
+		Short: "Two-way sync of actions with a CalDAV task list",
+		Run: func(cmd *cobra.Command, args []string) {
+			url, _ := cmd.Flags().GetString("url")
+			username, _ := cmd.Flags().GetString("username")
+			password, _ := cmd.Flags().GetString("password")
+			prefer, _ := cmd.Flags().GetString("prefer")
+
+			// Fall back to environment variables so credentials stay out of the shell history
+			if url == "" {
+				url = os.Getenv("PROJECTOR_CALDAV_URL")
+			}
+			if username == "" {
+				username = os.Getenv("PROJECTOR_CALDAV_USERNAME")
+			}
+			if password == "" {
+				password = os.Getenv("PROJECTOR_CALDAV_PASSWORD")
+			}
+
+			runCalDAVSync(url, username, password, prefer)
+		},
+	}
+
+	cmd.Flags().String("url", "", "CalDAV task collection URL (or PROJECTOR_CALDAV_URL)")
+	cmd.Flags().String("username", "", "CalDAV username (or PROJECTOR_CALDAV_USERNAME)")
+	cmd.Flags().String("password", "", "CalDAV password (or PROJECTOR_CALDAV_PASSWORD)")
+	cmd.Flags().String("prefer", caldav.PreferRemote, "Side that wins when an action changed on both sides: remote or local")
+	return cmd
+}
+
+func runCalDAVSync(url, username, password, prefer string) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	if url == "" {
+		fmt.Println(i18n.T("CalDAVURLRequired"))
+		return
+	}
+
+	fmt.Println(i18n.T("SyncingCalDAV"))
+
+	client := caldav.NewClient(url, username, password)
+	result, err := caldav.Sync(database.GetDatabasePath(), client, prefer)
+	if err != nil {
+		fmt.Println(i18n.T("SyncFailed", i18n.Data{"Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("PushedCount", i18n.Data{"Count": result.Pushed}))
+	fmt.Println(i18n.T("PulledCount", i18n.Data{"Count": result.Pulled}))
+	fmt.Println(i18n.T("DeletedCount", i18n.Data{"Count": result.Deleted}))
+	if result.Conflicts > 0 {
+		fmt.Println(i18n.T("CalDAVConflictsResolved", i18n.Data{"Prefer": prefer, "Count": result.Conflicts}))
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("⚠️ %s\n", warning)
+	}
+	for _, message := range result.Errors {
+		fmt.Printf("❌ %s\n", message)
+	}
+
+	if len(result.Errors) == 0 {
+		fmt.Println(i18n.T("SyncCompleted"))
+	}
+}
+
+func runRemoteSync(remoteURL, token string) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return
+	}
+
+	fmt.Println(i18n.T("SyncingRemote", i18n.Data{"URL": remoteURL}))
+
+	client := remote.NewClient(remoteURL, token)
+	result, err := remote.Sync(database.GetDatabasePath(), client)
+	if err != nil {
+		fmt.Println(i18n.T("SyncFailed", i18n.Data{"Error": err}))
+		return
+	}
+
+	fmt.Println(i18n.T("PulledSummary", i18n.Data{"Applied": result.Pulled.Applied, "Deleted": result.Pulled.Deleted, "Skipped": result.Pulled.Skipped}))
+	fmt.Println(i18n.T("PushedSummary", i18n.Data{"Applied": result.Pushed.Applied, "Deleted": result.Pushed.Deleted, "Skipped": result.Pushed.Skipped}))
+	if result.Conflicts > 0 {
+		fmt.Println(i18n.T("SyncConflicts", i18n.Data{"Count": result.Conflicts}))
+	}
+	fmt.Println(i18n.T("SyncCompleted"))
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// tagUsageRecord is the JSON form of a tag in `tag list` output, with the
+// action counts when --counts is given
+type tagUsageRecord struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Open *int   `json:"open,omitempty"`
+	Done *int   `json:"done,omitempty"`
+}
+
+// tagsCmd is `tag list` under the name of the top-level command that lists
+// tags, so both print the same
+func tagsCmd() *cobra.Command {
+	cmd := tagListCmd()
+	cmd.Use = "tags"
+	cmd.Short = "List tags, the same as 'tag list'"
+	return cmd
+}
+
+func tagCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Rename and merge tags",
+	}
+
+	cmd.AddCommand(tagListCmd())
+	cmd.AddCommand(tagRenameCmd())
+	cmd.AddCommand(tagMergeCmd())
+	cmd.AddCommand(tagPruneCmd())
+	return cmd
+}
+
+func tagListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tags",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			counts, _ := cmd.Flags().GetBool("counts")
+
+			tags, err := database.GetTagUsage(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTags", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []tagUsageRecord{}
+			table := output.Table{Headers: []string{"ID", "TAG"}}
+			if counts {
+				table.Headers = append(table.Headers, "OPEN", "DONE")
+			}
+			for _, tag := range tags {
+				record := tagUsageRecord{ID: tag.ID, Name: tag.Name}
+				row := []string{fmt.Sprint(record.ID), record.Name}
+				if counts {
+					record.Open, record.Done = &tag.Open, &tag.Done
+					row = append(row, fmt.Sprint(tag.Open), fmt.Sprint(tag.Done))
+				}
+				records = append(records, record)
+				table.AddRow(row...)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoTagsFound"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintTags", i18n.Data{"Error": err}))
+			}
+		},
+	}
+
+	cmd.Flags().Bool("counts", false, "Show the number of open and done actions per tag")
+	return cmd
+}
+
+func tagRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename a tag on all its actions",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			actions, err := database.RenameTag(database.GetDatabasePath(), args[0], args[1])
+			if err != nil {
+				fmt.Println(i18n.T("FailedToRenameTag", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("RenamedTag", i18n.Data{"Tag": args[0], "NewName": args[1], "Count": actions}))
+		},
+	}
+}
+
+func tagMergeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <source> <target>",
+		Short: "Move a tag to the actions of another tag and delete it",
+		Long: `Move a tag to the actions of another tag and delete it, for cleaning up
+tags that mean the same thing. The target tag is created when it does not
+exist yet.
+
+Examples:
+  projector tag merge shopping groceries`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			source, err := database.GetTagByName(database.GetDatabasePath(), args[0])
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTag", i18n.Data{"Error": err}))
+				return
+			}
+			if source == nil {
+				fmt.Println(i18n.T("TagNotFound", i18n.Data{"Name": args[0]}))
+				return
+			}
+
+			actionTags, err := database.GetAllActionTags(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTags", i18n.Data{"Error": err}))
+				return
+			}
+			count := 0
+			for _, names := range actionTags {
+				if slices.Contains(names, source.Name) {
+					count++
+				}
+			}
+
+			description := i18n.T("BulkMergeTag", i18n.Data{"Tag": args[0], "Target": args[1], "Count": count})
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldChange", i18n.Data{"Change": description}))
+				return
+			}
+			if !confirm(cmd, i18n.T("MergeTagConfirm", i18n.Data{"Tag": args[0], "Target": args[1], "Count": count})) {
+				return
+			}
+
+			actions, err := database.MergeTags(database.GetDatabasePath(), args[0], args[1])
+			if err != nil {
+				fmt.Println(i18n.T("FailedToMergeTags", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("MergedTag", i18n.Data{"Tag": args[0], "Target": args[1], "Count": actions}))
+		},
+	}
+}
+
+func tagPruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the tags no action carries",
+		Long: `Delete the tags no action carries. Tags of actions in the trash are kept,
+so restoring the actions restores their tags.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			tags, err := database.GetOrphanTags(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTags", i18n.Data{"Error": err}))
+				return
+			}
+			if len(tags) == 0 {
+				fmt.Println(i18n.T("NoUnusedTags"))
+				return
+			}
+
+			names := make([]string, len(tags))
+			for i, tag := range tags {
+				names[i] = tag.Name
+			}
+			fmt.Println(i18n.T("UnusedTags", i18n.Data{"Tags": strings.Join(names, ", ")}))
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldDeleteTags", i18n.Data{"Count": len(tags)}))
+				return
+			}
+			if !confirm(cmd, i18n.T("DeleteTagsConfirm", i18n.Data{"Count": len(tags)})) {
+				return
+			}
+
+			deleted, err := database.DeleteOrphanTags(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("FailedToDeleteTags", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("DeletedTags", i18n.Data{"Count": deleted}))
+		},
+	}
+}
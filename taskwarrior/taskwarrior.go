@@ -0,0 +1,295 @@
+package taskwarrior
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// timeFormat is the timestamp format used in Taskwarrior JSON
+const timeFormat = "20060102T150405Z"
+
+// SyncSource is the sync_state source name recording the UUIDs of imported
+// tasks
+const SyncSource = "taskwarrior"
+
+// Annotation is a timestamped note attached to a task
+type Annotation struct {
+	Entry       string `json:"entry"`
+	Description string `json:"description"`
+}
+
+// Task is a task in the Taskwarrior JSON format
+type Task struct {
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Entry       string       `json:"entry"`
+	Due         string       `json:"due,omitempty"`
+	Until       string       `json:"until,omitempty"`
+	Recur       string       `json:"recur,omitempty"`
+	Project     string       `json:"project,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+}
+
+// Result summarizes the changes made by an import
+type Result struct {
+	Imported int                      `json:"imported"`
+	Skipped  int                      `json:"skipped"`  // Deleted tasks, recurrence templates and tasks imported before
+	Failed   []database.ImportFailure `json:"failed"`   // Tasks that were not imported
+	Warnings []string                 `json:"warnings"` // Tasks imported with changes
+}
+
+// recurrences maps repeat intervals to Taskwarrior recurrence periods
+var recurrences = map[string]string{
+	"hour":  "hourly",
+	"day":   "daily",
+	"week":  "weekly",
+	"month": "monthly",
+	"year":  "yearly",
+}
+
+// Export writes the actions as a Taskwarrior compatible JSON array
+func Export(w io.Writer, actions []database.Action) error {
+	entry := time.Now().UTC().Format(timeFormat)
+
+	tasks := []Task{}
+	for _, action := range actions {
+		task := Task{
+			UUID:        actionUUID(action.ID),
+			Description: action.Name,
+			Status:      "pending",
+			Entry:       entry,
+			Tags:        action.Tags,
+		}
+
+		if action.StatusName == "done" {
+			task.Status = "completed"
+		}
+		if action.ProjectName.Valid {
+			task.Project = action.ProjectName.String
+		}
+		if action.Note.Valid && action.Note.String != "" {
+			task.Annotations = []Annotation{{Entry: entry, Description: action.Note.String}}
+		}
+		if action.DueDate.Valid && action.DueDate.String != "" {
+			if due, err := database.ParseStoredDate(action.DueDate.String); err == nil {
+				task.Due = toTaskTime(due)
+			}
+		}
+
+		// Only the pending occurrence carries the recurrence, which Taskwarrior
+		// only accepts on a task with a due date
+		if recur, ok := recurrences[action.RepeatInterval.String]; ok && task.Status == "pending" && task.Due != "" && action.IsRepeating() {
+			task.Recur = recur
+			if action.EffectiveRepeatMode() == database.RepeatModeUntil {
+				if until, err := database.ParseStoredDate(action.RepeatUntil.String); err == nil {
+					task.Until = toTaskTime(until)
+				}
+			}
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tasks)
+}
+
+// Import reads the output of `task export` and creates an action for every
+// pending or completed task. Recurrence templates, deleted tasks and tasks
+// whose UUID was imported before are skipped, so importing a file again does
+// not duplicate its tasks. Progress, when not nil, is called after every task.
+func Import(dbPath string, r io.Reader, progress database.ImportProgress) (*Result, error) {
+	tasks, err := decodeTasks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := database.GetSyncStates(dbPath, SyncSource)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving imported tasks: %v", err)
+	}
+	imported := make(map[string]uint)
+	for _, state := range states {
+		imported[state.RemoteID] = state.ActionID
+	}
+
+	result := &Result{Failed: []database.ImportFailure{}, Warnings: []string{}}
+	for i, task := range tasks {
+		if err := importTask(dbPath, task, imported, result); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i+1, len(tasks))
+		}
+	}
+
+	return result, nil
+}
+
+// importTask creates the action of a task, recording a task that cannot be
+// imported as a failure and the UUID of an imported task in imported. It
+// returns an error when the import cannot go on.
+func importTask(dbPath string, task Task, imported map[string]uint, result *Result) error {
+	if task.Status != "pending" && task.Status != "completed" && task.Status != "waiting" {
+		result.Skipped++
+		return nil
+	}
+
+	// A task imported before is skipped while its action exists
+	if actionID, ok := imported[task.UUID]; ok && task.UUID != "" {
+		action, err := database.GetActionByID(dbPath, actionID)
+		if err != nil {
+			return fmt.Errorf("error retrieving action: %v", err)
+		}
+		if action != nil {
+			result.Skipped++
+			return nil
+		}
+	}
+
+	var projectID *uint
+	if task.Project != "" {
+		id, err := database.GetOrCreateProject(dbPath, task.Project)
+		if err != nil {
+			return fmt.Errorf("failed to create project %s: %v", task.Project, err)
+		}
+		projectID = &id
+	}
+
+	var notes []string
+	for _, annotation := range task.Annotations {
+		notes = append(notes, annotation.Description)
+	}
+
+	dueDate := fromTaskTime(task.Due)
+	if _, err := database.ValidateDate(dueDate); err != nil {
+		// Past due dates can't be stored, only warn about them for open tasks
+		if task.Status != "completed" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: dropped due date: %v", task.Description, err))
+		}
+		dueDate = ""
+	}
+
+	repeatMode, repeatInterval, repeatUntil := "", "", ""
+	if task.Recur != "" && task.Status != "completed" {
+		repeatInterval = intervalFromRecur(task.Recur)
+		if repeatInterval == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: unsupported recurrence %s", task.Description, task.Recur))
+		} else if until := fromTaskTime(task.Until); until != "" {
+			repeatMode, repeatUntil = database.RepeatModeUntil, until
+		} else {
+			repeatMode = database.RepeatModeForever
+		}
+	}
+
+	statusID := uint(2)
+	if task.Status != "completed" {
+		var err error
+		if statusID, err = database.DefaultStatusID(dbPath); err != nil {
+			return err
+		}
+	}
+
+	actionID, err := database.CreateAction(dbPath, task.Description, strings.Join(notes, "\n"), projectID, dueDate, statusID, repeatMode, 0, repeatInterval, "", repeatUntil, nil)
+	if err != nil {
+		result.Failed = append(result.Failed, database.ImportFailure{Row: fmt.Sprintf("task %q", task.Description), Reason: err.Error()})
+		return nil
+	}
+
+	if len(task.Tags) > 0 {
+		if err := database.SetActionTags(dbPath, actionID, task.Tags); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Description, err))
+		}
+	}
+	if task.UUID != "" {
+		err := database.SaveSyncState(dbPath, database.SyncState{Source: SyncSource, ActionID: actionID, RemoteID: task.UUID})
+		if err != nil {
+			return fmt.Errorf("failed to record task %s: %v", task.UUID, err)
+		}
+		imported[task.UUID] = actionID
+	}
+	result.Imported++
+	return nil
+}
+
+// decodeTasks accepts both a JSON array and the one-task-per-line format of
+// older Taskwarrior versions
+func decodeTasks(r io.Reader) ([]Task, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &tasks); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON: %v", err)
+		}
+		return tasks, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var task Task
+		if err := decoder.Decode(&task); err != nil {
+			return nil, fmt.Errorf("invalid Taskwarrior JSON: %v", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// intervalFromRecur maps a Taskwarrior recurrence period to a repeat interval
+func intervalFromRecur(recur string) string {
+	switch strings.ToLower(recur) {
+	case "hourly", "1h":
+		return "hour"
+	case "daily", "day", "1d":
+		return "day"
+	case "weekly", "week", "1w", "7d":
+		return "week"
+	case "monthly", "month", "1mo":
+		return "month"
+	case "yearly", "annual", "year", "1y":
+		return "year"
+	default:
+		return ""
+	}
+}
+
+// toTaskTime formats a local date as a Taskwarrior UTC timestamp
+func toTaskTime(date time.Time) string {
+	local := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local)
+	return local.UTC().Format(timeFormat)
+}
+
+// fromTaskTime converts a Taskwarrior UTC timestamp to a local YYYY-MM-DD date
+func fromTaskTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	t, err := time.Parse(timeFormat, value)
+	if err != nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// actionUUID derives a stable name based UUID from an action ID so repeated
+// exports keep referring to the same Taskwarrior task
+func actionUUID(actionID uint) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("projector-action-%d", actionID)))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
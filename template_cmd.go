@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/quickadd"
+
+	"github.com/spf13/cobra"
+)
+
+// templateRecord is the JSON form of a template in command output
+type templateRecord struct {
+	Name       string `json:"name"`
+	ProjectDue string `json:"project_due,omitempty"`
+	Actions    int    `json:"actions"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func templateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Save projects as templates and create projects from them",
+		Long: `Save a project with its actions and tags as a template, and create new
+projects from it. Due dates are stored as offsets from a start date, such as
+start+3d, and are resolved against the start date of the new project.`,
+	}
+
+	cmd.AddCommand(templateSaveCmd())
+	cmd.AddCommand(templateListCmd())
+	cmd.AddCommand(templateUseCmd())
+	cmd.AddCommand(templateDeleteCmd())
+	return cmd
+}
+
+func templateSaveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "save <project> <template>",
+		Short:             "Save a project (name or ID) as a template",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeProjectNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			start, _ := cmd.Flags().GetString("start")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			project, ok := lookupProject(args[0])
+			if !ok {
+				return
+			}
+
+			template, err := database.NewProjectTemplate(database.GetDatabasePath(), project.ID, args[1], start)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToBuildTemplate", i18n.Data{"Error": err}))
+				return
+			}
+			if _, err := database.SaveTemplate(database.GetDatabasePath(), template); err != nil {
+				fmt.Println(i18n.T("FailedToSaveTemplate", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("SavedTemplate", i18n.Data{"Project": project.Name, "Name": template.Name, "Count": len(template.Actions)}))
+		},
+	}
+
+	cmd.Flags().String("start", "", "Date the offsets are relative to (YYYY-MM-DD, default the earliest due date)")
+	return cmd
+}
+
+func templateListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List templates",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			templates, err := database.GetAllTemplates(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTemplates", i18n.Data{"Error": err}))
+				return
+			}
+
+			records := []templateRecord{}
+			table := output.Table{Headers: []string{"NAME", "PROJECT DUE", "ACTIONS", "CREATED"}}
+			for _, template := range templates {
+				record := templateRecord{
+					Name:       template.Name,
+					ProjectDue: template.ProjectDue,
+					Actions:    len(template.Actions),
+					CreatedAt:  template.CreatedAt,
+				}
+				records = append(records, record)
+				table.AddRow(record.Name, record.ProjectDue, fmt.Sprint(record.Actions), record.CreatedAt)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NoTemplatesFound"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintTemplates", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func templateUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "use <template> <project name>",
+		Short:             "Create a project from a template",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeTemplateNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			start, _ := cmd.Flags().GetString("start")
+
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			startDate, err := quickadd.ParseDate(start, time.Now())
+			if err != nil {
+				fmt.Println(i18n.T("InvalidStartDate", i18n.Data{"Error": err}))
+				return
+			}
+
+			template, ok := lookupTemplate(args[0])
+			if !ok {
+				return
+			}
+
+			projectID, err := database.CreateProjectFromTemplate(database.GetDatabasePath(), template, args[1], startDate)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToCreateProject", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("CreatedProjectFromTemplate", i18n.Data{"Name": args[1], "ID": projectID, "Count": len(template.Actions), "StartDate": startDate}))
+		},
+	}
+
+	cmd.Flags().String("start", "today", "Start date of the project: YYYY-MM-DD, today, tomorrow or a day name")
+	return cmd
+}
+
+func templateDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <template>",
+		Short:             "Delete a template",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			template, ok := lookupTemplate(args[0])
+			if !ok {
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldDeleteTemplate", i18n.Data{"Name": template.Name}))
+				return
+			}
+			if !confirm(cmd, i18n.T("DeleteTemplateConfirm", i18n.Data{"Name": template.Name})) {
+				return
+			}
+
+			if err := database.DeleteTemplate(database.GetDatabasePath(), template.ID); err != nil {
+				fmt.Println(i18n.T("FailedToDeleteTemplate", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("DeletedTemplate", i18n.Data{"Name": template.Name}))
+		},
+	}
+}
+
+// lookupTemplate retrieves a template by name, printing an error when it
+// cannot be found
+func lookupTemplate(name string) (*database.Template, bool) {
+	template, err := database.GetTemplateByName(database.GetDatabasePath(), name)
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingTemplate", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if template == nil {
+		fmt.Println(i18n.T("TemplateNotFound", i18n.Data{"Name": name}))
+		return nil, false
+	}
+	return template, true
+}
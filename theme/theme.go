@@ -0,0 +1,77 @@
+// Package theme holds the colors of the CLI and the TUI. All styling goes
+// through it, so that colors can be turned off as a whole with the NO_COLOR
+// environment variable or the --no-color flag.
+package theme
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Palette of the CLI and the TUI, as ANSI 256 colors
+const (
+	Accent    = lipgloss.Color("206") // Cursors and spinners
+	Highlight = lipgloss.Color("212") // Titles, matches and chosen values
+	Muted     = lipgloss.Color("241") // Help and details
+	Error     = lipgloss.Color("196")
+	Online    = lipgloss.Color("42")
+)
+
+// HeatmapLevels are the colors of days without completions up to the
+// busiest days
+var HeatmapLevels = []lipgloss.Color{"237", "22", "28", "34", "46"}
+
+// statusColors maps the color names of statuses to ANSI colors, #rrggbb
+// colors are used as is
+var statusColors = map[string]string{
+	"red":     "1",
+	"green":   "2",
+	"yellow":  "3",
+	"blue":    "4",
+	"magenta": "5",
+	"cyan":    "6",
+	"white":   "7",
+	"gray":    "8",
+}
+
+// enabled is whether colors are shown
+var enabled = true
+
+// Configure turns colors off when noColor is set or the NO_COLOR environment
+// variable is set to any value that is not empty, see https://no-color.org
+func Configure(noColor bool) {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		Disable()
+	}
+}
+
+// Disable turns colors off. Styles keep their other attributes, such as bold.
+func Disable() {
+	enabled = false
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// Enabled reports whether colors are shown, so views can stand in for
+// colors that carry meaning, such as the levels of the heatmap
+func Enabled() bool {
+	return enabled
+}
+
+// Foreground returns a style with a foreground color of the palette
+func Foreground(color lipgloss.Color) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(color)
+}
+
+// Colorize returns text in a color name of a status, such as red, or a
+// #rrggbb color
+func Colorize(text, color string) string {
+	if color == "" || !enabled {
+		return text
+	}
+	if ansi, ok := statusColors[color]; ok {
+		color = ansi
+	}
+	return Foreground(lipgloss.Color(color)).Render(text)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func todayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "today",
+		Short: "List the actions for today: flagged, due today and overdue",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			energy, _ := cmd.Flags().GetString("energy")
+			if energy != "" {
+				validated, err := database.ValidateEnergy(energy)
+				if err != nil {
+					fmt.Printf("❌ %v\n", err)
+					return
+				}
+				energy = validated
+			}
+
+			actions, err := database.GetAllActions(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+				return
+			}
+
+			today := time.Now().Format("2006-01-02")
+			records := []actionRecord{}
+			table := output.Table{Headers: []string{"ID", "NAME", "PROJECT", "DUE", "TODAY", "TAGS"}}
+			for _, action := range actions {
+				if !action.IsToday(today) {
+					continue
+				}
+				if energy != "" && !action.NeedsAtMost(energy) {
+					continue
+				}
+
+				var reasons []string
+				if action.Flagged {
+					reasons = append(reasons, "flagged")
+				}
+				switch dueDate := database.StoredDate(action.DueDate.String); {
+				case dueDate == today:
+					reasons = append(reasons, "due")
+				case dueDate != "" && dueDate < today:
+					reasons = append(reasons, "overdue")
+				}
+
+				record := newActionRecord(action)
+				records = append(records, record)
+				table.AddRow(fmt.Sprint(record.ID), record.Name, record.Project, displayDate(record.DueDate), strings.Join(reasons, ","), strings.Join(record.Tags, ","))
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("NothingForToday"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+			}
+		},
+	}
+
+	cmd.Flags().String("energy", "", "Only list the actions that take at most this energy level: low, medium or high")
+	return cmd
+}
+
+func flagCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "flag [id]",
+		Short:             "Flag an action for today or remove its flag, picking it interactively when no ID is given",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeActionIDs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var actionID uint
+			if len(args) == 1 {
+				id, ok := parseActionID(args[0])
+				if !ok {
+					return
+				}
+				actionID = id
+			} else {
+				id, ok := pickOpenAction("Which action do you want to flag or unflag?")
+				if !ok {
+					return
+				}
+				actionID = id
+			}
+
+			action, err := database.GetActionByID(database.GetDatabasePath(), actionID)
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingAction", i18n.Data{"Error": err}))
+				return
+			}
+			if action == nil {
+				fmt.Println(i18n.T("ActionNotFound", i18n.Data{"ID": actionID}))
+				return
+			}
+
+			flagged := !action.Flagged
+			if err := database.UpdateAction(database.GetDatabasePath(), actionID, database.ActionUpdate{Flagged: &flagged}); err != nil {
+				fmt.Println(i18n.T("FailedToUpdateAction", i18n.Data{"Error": err}))
+				return
+			}
+
+			if flagged {
+				fmt.Println(i18n.T("FlaggedForToday", i18n.Data{"Name": action.Name}))
+			} else {
+				fmt.Println(i18n.T("FlagRemoved", i18n.Data{"Name": action.Name}))
+			}
+		},
+	}
+}
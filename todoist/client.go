@@ -0,0 +1,148 @@
+package todoist
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultBaseURL is the Todoist API endpoint
+const DefaultBaseURL = "https://api.todoist.com/api/v1"
+
+// Client talks to the Todoist API using a personal API token
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// Project is a Todoist project
+type Project struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	InboxProject bool   `json:"inbox_project"`
+}
+
+// Section is a Todoist section within a project
+type Section struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+}
+
+// Task is an active Todoist task
+type Task struct {
+	ID          string   `json:"id"`
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"project_id"`
+	SectionID   string   `json:"section_id"`
+	Labels      []string `json:"labels"`
+	Due         *struct {
+		Date string `json:"date"`
+	} `json:"due"`
+}
+
+// NewClient creates a new Todoist client
+func NewClient(token string) *Client {
+	return &Client{
+		baseURL:    DefaultBaseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// GetProjects retrieves all projects
+func (c *Client) GetProjects() ([]Project, error) {
+	var projects []Project
+	err := c.getAll("/projects", &projects)
+	return projects, err
+}
+
+// GetSections retrieves all sections
+func (c *Client) GetSections() ([]Section, error) {
+	var sections []Section
+	err := c.getAll("/sections", &sections)
+	return sections, err
+}
+
+// GetTasks retrieves all active tasks
+func (c *Client) GetTasks() ([]Task, error) {
+	var tasks []Task
+	err := c.getAll("/tasks", &tasks)
+	return tasks, err
+}
+
+// CloseTask marks a task as completed
+func (c *Client) CloseTask(taskID string) error {
+	req, err := http.NewRequest("POST", c.baseURL+"/tasks/"+url.PathEscape(taskID)+"/close", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to close task: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected response from Todoist: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// getAll retrieves every page of a paginated list endpoint into out, which
+// must point to a slice
+func (c *Client) getAll(path string, out interface{}) error {
+	var all []json.RawMessage
+	cursor := ""
+
+	for {
+		target := c.baseURL + path
+		if cursor != "" {
+			target += "?cursor=" + url.QueryEscape(cursor)
+		}
+
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to query Todoist: %v", err)
+		}
+
+		var page struct {
+			Results    []json.RawMessage `json:"results"`
+			NextCursor *string           `json:"next_cursor"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected response from Todoist: %s", resp.Status)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("invalid response from Todoist: %v", err)
+		}
+
+		all = append(all, page.Results...)
+		if page.NextCursor == nil || *page.NextCursor == "" {
+			break
+		}
+		cursor = *page.NextCursor
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,191 @@
+package todoist
+
+import (
+	"fmt"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// SyncSource is the sync_state source name used for Todoist
+const SyncSource = "todoist"
+
+// Result summarizes the changes made by an import or sync run
+type Result struct {
+	Imported  int
+	Updated   int
+	Completed int
+	Closed    int
+	Warnings  []string
+}
+
+// Import copies the active Todoist tasks into the database. Todoist projects
+// become projects and labels and sections become tags. Tasks imported before
+// are updated instead of duplicated. With sync enabled, tasks completed on
+// either side are also completed on the other side.
+func Import(dbPath string, client *Client, sync bool) (*Result, error) {
+	projects, err := client.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := client.GetSections()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := client.GetTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := database.GetSyncStates(dbPath, SyncSource)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sync state: %v", err)
+	}
+
+	statusID, err := database.DefaultStatusID(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+
+	// Map Todoist projects to local projects, the inbox maps to no project
+	projectIDs := make(map[string]uint)
+	for _, project := range projects {
+		if project.InboxProject {
+			continue
+		}
+		projectID, err := database.GetOrCreateProject(dbPath, project.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create project %s: %v", project.Name, err)
+		}
+		projectIDs[project.ID] = projectID
+	}
+
+	sectionNames := make(map[string]string)
+	for _, section := range sections {
+		sectionNames[section.ID] = section.Name
+	}
+
+	stateByTask := make(map[string]database.SyncState)
+	for _, state := range states {
+		stateByTask[state.RemoteID] = state
+	}
+
+	activeTasks := make(map[string]bool)
+	for _, task := range tasks {
+		activeTasks[task.ID] = true
+
+		var projectID *uint
+		if id, ok := projectIDs[task.ProjectID]; ok {
+			projectID = &id
+		}
+
+		tags := append([]string{}, task.Labels...)
+		if name, ok := sectionNames[task.SectionID]; ok && name != "" {
+			tags = append(tags, name)
+		}
+
+		dueDate := ""
+		if task.Due != nil && len(task.Due.Date) >= 10 {
+			dueDate = task.Due.Date[:10]
+		}
+		// A dropped due date, such as one in the past, leaves the due date
+		// of an action that was imported before as it is
+		dueDropped := false
+		if _, err := database.ValidateDate(dueDate); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: dropped due date: %v", task.Content, err))
+			dueDate = ""
+			dueDropped = true
+		}
+
+		state, known := stateByTask[task.ID]
+		var action *database.Action
+		if known {
+			action, err = database.GetActionByID(dbPath, state.ActionID)
+			if err != nil {
+				return nil, fmt.Errorf("error retrieving action: %v", err)
+			}
+		}
+
+		if action != nil {
+			// Completed locally, close the task in Todoist
+			if sync && action.StatusName == "done" {
+				if err := client.CloseTask(task.ID); err != nil {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Content, err))
+					continue
+				}
+				database.DeleteSyncState(dbPath, SyncSource, action.ID)
+				result.Closed++
+				continue
+			}
+
+			noProject := uint(0)
+			if projectID == nil {
+				projectID = &noProject
+			}
+			update := database.ActionUpdate{
+				Name:      &task.Content,
+				Note:      &task.Description,
+				ProjectID: projectID,
+			}
+			if !dueDropped && dueDate != database.StoredDate(action.DueDate.String) {
+				update.DueDate = &dueDate
+			}
+			if err := database.UpdateAction(dbPath, action.ID, update); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Content, err))
+				continue
+			}
+			if err := database.SetActionTags(dbPath, action.ID, tags); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Content, err))
+			}
+			result.Updated++
+			continue
+		}
+
+		actionID, err := database.CreateAction(dbPath, task.Content, task.Description, projectID, dueDate, statusID, "", 0, "", "", "", nil)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Content, err))
+			continue
+		}
+		if err := database.SetActionTags(dbPath, actionID, tags); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("task %q: %v", task.Content, err))
+		}
+		err = database.SaveSyncState(dbPath, database.SyncState{
+			Source:   SyncSource,
+			ActionID: actionID,
+			RemoteID: task.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Imported++
+	}
+
+	if !sync {
+		return result, nil
+	}
+
+	// Tasks that are no longer active were completed or deleted in Todoist
+	for _, state := range states {
+		if activeTasks[state.RemoteID] {
+			continue
+		}
+
+		action, err := database.GetActionByID(dbPath, state.ActionID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving action: %v", err)
+		}
+		if action != nil && action.StatusName != "done" {
+			if err := database.MarkActionAsDone(dbPath, action.ID); err != nil {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("action %d: %v", action.ID, err))
+				continue
+			}
+			result.Completed++
+		}
+		database.DeleteSyncState(dbPath, SyncSource, state.ActionID)
+	}
+
+	return result, nil
+}
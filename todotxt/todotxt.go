@@ -0,0 +1,283 @@
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+)
+
+// priorityTagPrefix marks the tag that holds a todo.txt priority, since
+// actions have no priority of their own
+const priorityTagPrefix = "priority:"
+
+// Result summarizes the changes made by an import
+type Result struct {
+	Imported int                      `json:"imported"`
+	Failed   []database.ImportFailure `json:"failed"`   // Lines that were not imported
+	Warnings []string                 `json:"warnings"` // Lines imported with changes
+}
+
+var (
+	priorityPattern = regexp.MustCompile(`^\(([A-Z])\) `)
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// recurrences maps repeat intervals to the rec: extension used by todo.txt clients
+var recurrences = map[string]string{
+	"day":   "1d",
+	"week":  "1w",
+	"month": "1m",
+	"year":  "1y",
+}
+
+// Export writes one todo.txt line per action. Tags become @contexts, the
+// project becomes a +project and the due date and repeat interval are written
+// as due: and rec: extensions.
+func Export(w io.Writer, actions []database.Action) error {
+	for _, action := range actions {
+		var parts []string
+
+		priority := ""
+		var contexts []string
+		for _, tag := range action.Tags {
+			if strings.HasPrefix(tag, priorityTagPrefix) {
+				priority = strings.TrimPrefix(tag, priorityTagPrefix)
+				continue
+			}
+			contexts = append(contexts, "@"+sanitizeWord(tag))
+		}
+
+		if action.StatusName == "done" {
+			parts = append(parts, "x")
+		} else if priority != "" {
+			parts = append(parts, "("+priority+")")
+		}
+
+		parts = append(parts, strings.Join(strings.Fields(action.Name), " "))
+
+		if action.ProjectName.Valid && action.ProjectName.String != "" {
+			parts = append(parts, "+"+sanitizeWord(action.ProjectName.String))
+		}
+		parts = append(parts, contexts...)
+
+		if action.DueDate.Valid && action.DueDate.String != "" {
+			parts = append(parts, "due:"+database.StoredDate(action.DueDate.String))
+		}
+		if rec, ok := recurrences[action.RepeatInterval.String]; ok && action.IsRepeating() {
+			parts = append(parts, "rec:"+rec)
+		}
+		if action.StatusName == "done" && priority != "" {
+			// Completed tasks lose their priority prefix, keep it as an extension
+			parts = append(parts, "pri:"+priority)
+		}
+
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Import creates an action for every task line in a todo.txt file. The
+// lines are read first, so that progress, when not nil, is called with the
+// total after every line.
+func Import(dbPath string, r io.Reader, progress database.ImportProgress) (*Result, error) {
+	type numberedLine struct {
+		number int
+		text   string
+	}
+
+	var lines []numberedLine
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, numberedLine{number: lineNumber, text: line})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Projects are exported as +words, so they are matched by that word
+	projects, err := database.GetAllProjects(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve projects: %v", err)
+	}
+	projectIDs := make(map[string]uint)
+	for _, project := range projects {
+		word := sanitizeWord(project.Name)
+		if _, ok := projectIDs[word]; !ok || project.Name == word {
+			projectIDs[word] = project.ID
+		}
+	}
+
+	result := &Result{Failed: []database.ImportFailure{}, Warnings: []string{}}
+	for i, line := range lines {
+		if err := importLine(dbPath, line.number, line.text, projectIDs, result); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i+1, len(lines))
+		}
+	}
+
+	return result, nil
+}
+
+// importLine creates the action of a todo.txt line, recording a line that
+// cannot be imported as a failure. The project is looked up in projectIDs,
+// by the word it is written as, and added to it when it is created. It
+// returns an error when the import cannot go on.
+func importLine(dbPath string, lineNumber int, line string, projectIDs map[string]uint, result *Result) error {
+	row := fmt.Sprintf("line %d", lineNumber)
+
+	task := parseLine(line)
+	if task.name == "" {
+		result.Failed = append(result.Failed, database.ImportFailure{Row: row, Reason: "no description"})
+		return nil
+	}
+
+	var projectID *uint
+	if task.project != "" {
+		id, ok := projectIDs[task.project]
+		if !ok {
+			var err error
+			id, err = database.GetOrCreateProject(dbPath, task.project)
+			if err != nil {
+				return fmt.Errorf("failed to create project %s: %v", task.project, err)
+			}
+			projectIDs[task.project] = id
+		}
+		projectID = &id
+	}
+
+	dueDate := task.due
+	if _, err := database.ValidateDate(dueDate); err != nil {
+		// Past due dates can't be stored, only warn about them for open tasks
+		if !task.done {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: dropped due date: %v", row, err))
+		}
+		dueDate = ""
+	}
+
+	interval := ""
+	if task.rec != "" && !task.done {
+		interval = intervalFromRec(task.rec)
+		if interval == "" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: unsupported recurrence rec:%s", row, task.rec))
+		}
+	}
+	repeatMode := ""
+	if interval != "" {
+		repeatMode = database.RepeatModeForever
+	}
+
+	statusID := uint(2)
+	if !task.done {
+		var err error
+		if statusID, err = database.DefaultStatusID(dbPath); err != nil {
+			return err
+		}
+	}
+
+	actionID, err := database.CreateAction(dbPath, task.name, "", projectID, dueDate, statusID, repeatMode, 0, interval, "", "", nil)
+	if err != nil {
+		result.Failed = append(result.Failed, database.ImportFailure{Row: row, Reason: err.Error()})
+		return nil
+	}
+
+	if len(task.tags) > 0 {
+		if err := database.SetActionTags(dbPath, actionID, task.tags); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", row, err))
+		}
+	}
+	result.Imported++
+	return nil
+}
+
+// task holds the parts of a todo.txt line that map to an action
+type task struct {
+	name    string
+	done    bool
+	project string
+	due     string
+	rec     string // The rec: extension, such as 1w or +1m
+	tags    []string
+}
+
+// parseLine parses a single todo.txt line
+func parseLine(line string) task {
+	var t task
+
+	if strings.HasPrefix(line, "x ") {
+		t.done = true
+		line = strings.TrimPrefix(line, "x ")
+	} else if match := priorityPattern.FindStringSubmatch(line); match != nil {
+		t.tags = append(t.tags, priorityTagPrefix+match[1])
+		line = line[len(match[0]):]
+	}
+
+	fields := strings.Fields(line)
+
+	// Skip the completion and creation dates
+	for i := 0; i < 2 && len(fields) > 0 && datePattern.MatchString(fields[0]); i++ {
+		fields = fields[1:]
+	}
+
+	var words []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			if t.project == "" {
+				t.project = field[1:]
+			} else {
+				words = append(words, field)
+			}
+		case len(field) > 1 && field[0] == '@':
+			t.tags = append(t.tags, field[1:])
+		case strings.HasPrefix(field, "due:") && datePattern.MatchString(field[4:]):
+			t.due = field[4:]
+		case strings.HasPrefix(field, "rec:"):
+			t.rec = field[4:]
+		case strings.HasPrefix(field, "pri:") && len(field) == 5:
+			t.tags = append(t.tags, priorityTagPrefix+field[4:])
+		default:
+			words = append(words, field)
+		}
+	}
+
+	t.name = strings.Join(words, " ")
+	return t
+}
+
+// intervalFromRec maps a rec: value such as 1w or +1m to a repeat interval.
+// Actions repeat every single interval, so values such as 2w or +3d, and
+// business days, have no repeat interval and return "".
+func intervalFromRec(rec string) string {
+	rec = strings.TrimPrefix(rec, "+")
+	if count := strings.TrimRight(rec, "dwmyb"); count != "" && count != "1" {
+		return ""
+	}
+	switch strings.TrimLeft(rec, "1") {
+	case "d":
+		return "day"
+	case "w":
+		return "week"
+	case "m":
+		return "month"
+	case "y":
+		return "year"
+	default:
+		return ""
+	}
+}
+
+// sanitizeWord replaces whitespace so a name can be used as a +project or @context
+func sanitizeWord(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/joelgrimberg/projector/config"
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+// trashRecord is the JSON form of a trash item in command output
+type trashRecord struct {
+	ID        uint   `json:"id"`
+	Entity    string `json:"entity"`
+	EntityID  uint   `json:"entity_id"`
+	Name      string `json:"name"`
+	DeletedAt string `json:"deleted_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+func trashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List, restore and empty deleted actions and projects",
+		Long: `Deleted actions and projects are kept in the trash, from where they
+can be restored. The server permanently removes items that have been in the
+trash for longer than trash.retention_days in the config (30 by default).`,
+	}
+
+	cmd.AddCommand(trashListCmd())
+	cmd.AddCommand(trashRestoreCmd())
+	cmd.AddCommand(trashEmptyCmd())
+	return cmd
+}
+
+// trashRetention returns the configured trash retention in days
+func trashRetention() int {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+		return config.DefaultTrashRetentionDays
+	}
+	return cfg.Trash.Retention()
+}
+
+func trashListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List deleted actions and projects",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			items, err := database.GetTrash(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTrash", i18n.Data{"Error": err}))
+				return
+			}
+
+			retention := trashRetention()
+			records := []trashRecord{}
+			table := output.Table{Headers: []string{"ID", "TYPE", "NAME", "DELETED", "EXPIRES"}}
+			for _, item := range items {
+				record := trashRecord{
+					ID:        item.ID,
+					Entity:    item.Entity,
+					EntityID:  item.EntityID,
+					Name:      item.Name,
+					DeletedAt: item.DeletedAt,
+					ExpiresAt: item.ExpiresAt(retention).Format(time.RFC3339),
+				}
+				records = append(records, record)
+				table.AddRow(fmt.Sprintf("%d", record.ID), record.Entity, record.Name, displayDate(record.DeletedAt), displayDate(item.ExpiresAt(retention).Local().Format("2006-01-02")))
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				fmt.Println(i18n.T("TrashEmpty"))
+				return
+			}
+
+			if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintTrash", i18n.Data{"Error": err}))
+			}
+		},
+	}
+}
+
+func trashRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <trash-id>",
+		Short: "Restore a deleted action or project",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			trashID, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				fmt.Println(i18n.T("InvalidTrashID", i18n.Data{"ID": args[0]}))
+				return
+			}
+
+			item, err := database.GetTrashItem(database.GetDatabasePath(), uint(trashID))
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTrash", i18n.Data{"Error": err}))
+				return
+			}
+			if item == nil {
+				fmt.Println(i18n.T("TrashItemNotFound", i18n.Data{"ID": trashID}))
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldRestoreTrash", i18n.Data{"Entity": item.Entity, "ID": item.EntityID, "Name": item.Name}))
+				return
+			}
+			if !confirm(cmd, i18n.T("RestoreTrashConfirm", i18n.Data{"Entity": item.Entity, "ID": item.EntityID, "Name": item.Name})) {
+				return
+			}
+
+			if err := database.RestoreTrash(database.GetDatabasePath(), item.ID); err != nil {
+				fmt.Println(i18n.T("FailedToRestoreTrash", i18n.Data{"Entity": item.Entity, "Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("RestoredTrash", i18n.Data{"Entity": item.Entity, "ID": item.EntityID, "Name": item.Name}))
+		},
+	}
+}
+
+func trashEmptyCmd() *cobra.Command {
+	var expired bool
+
+	cmd := &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently remove the items in the trash",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			items, err := database.GetTrash(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingTrash", i18n.Data{"Error": err}))
+				return
+			}
+
+			now := time.Now()
+			retention := trashRetention()
+			var before time.Time
+			if expired {
+				before = now.AddDate(0, 0, -retention)
+			}
+
+			var affected []database.TrashItem
+			for _, item := range items {
+				if !expired || !item.ExpiresAt(retention).After(now) {
+					affected = append(affected, item)
+				}
+			}
+			if len(affected) == 0 {
+				fmt.Println(i18n.T("NothingToRemoveFromTrash"))
+				return
+			}
+
+			if isDryRun(cmd) {
+				for _, item := range affected {
+					fmt.Printf("   %s %d: %s\n", item.Entity, item.EntityID, item.Name)
+				}
+				fmt.Println(i18n.T("WouldEmptyTrash", i18n.Data{"Count": len(affected)}))
+				return
+			}
+			if !confirm(cmd, i18n.T("EmptyTrashConfirm", i18n.Data{"Count": len(affected)})) {
+				return
+			}
+
+			removed, err := database.EmptyTrash(database.GetDatabasePath(), before)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToEmptyTrash", i18n.Data{"Error": err}))
+				return
+			}
+
+			fmt.Println(i18n.T("EmptiedTrash", i18n.Data{"Count": int(removed)}))
+		},
+	}
+
+	cmd.Flags().BoolVar(&expired, "expired", false, "Only remove the items older than the retention period")
+	return cmd
+}
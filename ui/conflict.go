@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const conflictColumnWidth = 32 // Width of the mine and theirs columns
+
+var conflictChosenStyle = theme.Foreground(theme.Highlight).Bold(true)
+
+// ConflictField is a field of an action changed on both sides of a sync
+type ConflictField struct {
+	Name   string
+	Mine   string
+	Theirs string
+}
+
+// conflictModel is the state of the conflict resolution
+type conflictModel struct {
+	title    string
+	fields   []ConflictField
+	theirs   []bool // Whether the field is taken from theirs, by index
+	cursor   int
+	resolved bool
+	quitting bool
+}
+
+// ResolveConflict shows my version and theirs side by side and lets the user
+// choose a side for every field that differs. It returns the names of the
+// fields taken from theirs, and false when the resolution was cancelled.
+// Fields start on the side that was kept.
+func ResolveConflict(title string, fields []ConflictField, keptTheirs bool) ([]string, bool, error) {
+	if output.Accessible() {
+		return resolveConflictLines(title, fields, keptTheirs)
+	}
+
+	m := conflictModel{title: title, fields: fields, theirs: make([]bool, len(fields))}
+	for i := range m.theirs {
+		m.theirs[i] = keptTheirs
+	}
+
+	result, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, false, err
+	}
+
+	final := result.(conflictModel)
+	if !final.resolved {
+		return nil, false, nil
+	}
+	var theirs []string
+	for i, field := range final.fields {
+		if final.theirs[i] {
+			theirs = append(theirs, field.Name)
+		}
+	}
+	return theirs, true, nil
+}
+
+// Init initializes the conflict resolution
+func (m conflictModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update moves between fields and chooses their side
+func (m conflictModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "esc", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		m.resolved = true
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.fields)-1 {
+			m.cursor++
+		}
+	case "left", "h":
+		m.theirs[m.cursor] = false
+	case "right", "l":
+		m.theirs[m.cursor] = true
+	case "tab", " ":
+		m.theirs[m.cursor] = !m.theirs[m.cursor]
+	case "m":
+		for i := range m.theirs {
+			m.theirs[i] = false
+		}
+	case "t":
+		for i := range m.theirs {
+			m.theirs[i] = true
+		}
+	}
+	return m, nil
+}
+
+// View renders the fields with both versions side by side, the chosen side
+// highlighted
+func (m conflictModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(reviewTitleStyle.Render(m.title) + "\n\n")
+	b.WriteString(pickerDetailStyle.Render(fmt.Sprintf("  %-10s %s %s", "FIELD", padCell("MINE"), padCell("THEIRS"))) + "\n")
+
+	for i, field := range m.fields {
+		prefix := "  "
+		if i == m.cursor {
+			prefix = pickerCursorStyle.Render("> ")
+		}
+
+		mine, theirs := padCell(field.Mine), padCell(field.Theirs)
+		if m.theirs[i] {
+			theirs = conflictChosenStyle.Render(theirs)
+			mine = pickerDetailStyle.Render(mine)
+		} else {
+			mine = conflictChosenStyle.Render(mine)
+			theirs = pickerDetailStyle.Render(theirs)
+		}
+		row := fmt.Sprintf("%s%-10s %s %s", prefix, field.Name, mine, theirs)
+		// Without colors the chosen side is named instead
+		if !theme.Enabled() {
+			if m.theirs[i] {
+				row += " theirs"
+			} else {
+				row += " mine"
+			}
+		}
+		b.WriteString(row + "\n")
+	}
+
+	b.WriteString("\n" + helpStyle(i18n.T("ConflictHelp")) + "\n")
+	return mainStyle.Render(b.String())
+}
+
+// padCell fits a value on a single line of the column width
+func padCell(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		value = "–"
+	}
+	runes := []rune(value)
+	if len(runes) > conflictColumnWidth {
+		runes = append(runes[:conflictColumnWidth-1], '…')
+	}
+	return string(runes) + strings.Repeat(" ", conflictColumnWidth-len(runes))
+}
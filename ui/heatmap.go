@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const heatmapWeeks = 53 // Weeks shown, a year and the current week
+
+// heatmapLevels are the styles of days without completions up to the busiest days
+var heatmapLevels = heatmapStyles()
+
+func heatmapStyles() []lipgloss.Style {
+	var styles []lipgloss.Style
+	for _, color := range theme.HeatmapLevels {
+		styles = append(styles, theme.Foreground(color))
+	}
+	return styles
+}
+
+// heatmapGlyphs stand in for the colors of the levels when the heatmap is
+// rendered without a terminal or without colors
+var heatmapGlyphs = []string{"·", "░", "▒", "▓", "█"}
+
+var heatmapCursorStyle = theme.Foreground(theme.Accent).Bold(true)
+
+// heatmapModel is the state of the completion heatmap
+type heatmapModel struct {
+	counts map[string]int // Completions per day (YYYY-MM-DD)
+	start  time.Time      // Sunday of the first week shown
+	today  time.Time
+	cursor time.Time // Selected day
+	max    int
+	total  int
+}
+
+// Heatmap shows the number of actions completed per day over the last year
+// as a grid of weeks, like the contribution graph of GitHub. The arrow keys
+// move between days to show their counts.
+func Heatmap(counts map[string]int, now time.Time) error {
+	_, err := tea.NewProgram(newHeatmapModel(counts, now)).Run()
+	return err
+}
+
+// RenderHeatmap renders the heatmap once, for output that is not a terminal
+func RenderHeatmap(counts map[string]int, now time.Time) string {
+	m := newHeatmapModel(counts, now)
+	return m.render(false)
+}
+
+// DescribeHeatmap describes the completions of the heatmap as lines rather
+// than a grid, for accessible output: the total, the busiest day and the
+// completions of every month
+func DescribeHeatmap(counts map[string]int, now time.Time) string {
+	m := newHeatmapModel(counts, now)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d action(s) completed in the last year\n", m.total)
+
+	var busiest string
+	months := make([]int, 13) // Completions per month, the current month last
+	for day := m.start; !day.After(m.today); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		count := m.counts[date]
+		if count > 0 && count == m.max && busiest == "" {
+			busiest = date
+		}
+		month := (m.today.Year()-day.Year())*12 + int(m.today.Month()-day.Month())
+		if month < len(months) {
+			months[len(months)-1-month] += count
+		}
+	}
+	if busiest != "" {
+		fmt.Fprintf(&b, "Busiest day: %s, %d action(s) completed\n", datefmt.Date(busiest), m.max)
+	}
+	for i, count := range months {
+		month := time.Date(m.today.Year(), m.today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, i-len(months)+1, 0)
+		fmt.Fprintf(&b, "%s: %d completed\n", month.Format("January 2006"), count)
+	}
+	return b.String()
+}
+
+func newHeatmapModel(counts map[string]int, now time.Time) heatmapModel {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -int(today.Weekday())-7*(heatmapWeeks-1))
+
+	m := heatmapModel{counts: counts, start: start, today: today, cursor: today}
+	for date, count := range counts {
+		if date < start.Format("2006-01-02") {
+			continue
+		}
+		m.total += count
+		if count > m.max {
+			m.max = count
+		}
+	}
+	return m
+}
+
+// Init initializes the heatmap
+func (m heatmapModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update moves the selected day with the arrow keys, a day up or down and a
+// week left or right
+func (m heatmapModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	var cursor time.Time
+	switch key.String() {
+	case "ctrl+c", "esc", "q":
+		return m, tea.Quit
+	case "left", "h":
+		cursor = m.cursor.AddDate(0, 0, -7)
+	case "right", "l":
+		cursor = m.cursor.AddDate(0, 0, 7)
+	case "up", "k":
+		cursor = m.cursor.AddDate(0, 0, -1)
+	case "down", "j":
+		cursor = m.cursor.AddDate(0, 0, 1)
+	default:
+		return m, nil
+	}
+
+	if !cursor.Before(m.start) && !cursor.After(m.today) {
+		m.cursor = cursor
+	}
+	return m, nil
+}
+
+// View renders the heatmap with the selected day
+func (m heatmapModel) View() string {
+	return mainStyle.Render(m.render(true))
+}
+
+// render draws the month labels, a row per weekday and a column per week.
+// Interactively the levels are colors and the selected day is shown,
+// otherwise the levels are glyphs.
+func (m heatmapModel) render(interactive bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d action(s) completed in the last year\n\n", m.total)
+
+	// Month labels above the first week that starts in the month
+	labels := []rune(strings.Repeat(" ", 4+heatmapWeeks*2))
+	for week := 0; week < heatmapWeeks; week++ {
+		day := m.start.AddDate(0, 0, 7*week)
+		if day.Day() <= 7 {
+			copy(labels[4+week*2:], []rune(day.Format("Jan")))
+		}
+	}
+	b.WriteString(strings.TrimRight(string(labels), " ") + "\n")
+
+	for weekday := 0; weekday < 7; weekday++ {
+		switch weekday {
+		case 1, 3, 5:
+			b.WriteString(time.Weekday(weekday).String()[:3] + " ")
+		default:
+			b.WriteString("    ")
+		}
+
+		for week := 0; week < heatmapWeeks; week++ {
+			day := m.start.AddDate(0, 0, 7*week+weekday)
+			if day.After(m.today) {
+				break
+			}
+			b.WriteString(m.cell(m.level(m.counts[day.Format("2006-01-02")]), interactive && day.Equal(m.cursor), interactive) + " ")
+		}
+		b.WriteString("\n")
+	}
+
+	// Legend from no completions to the busiest days
+	b.WriteString("\n    Less ")
+	for level := range heatmapLevels {
+		b.WriteString(m.cell(level, false, interactive) + " ")
+	}
+	b.WriteString("More\n")
+
+	if interactive {
+		count := m.counts[m.cursor.Format("2006-01-02")]
+		fmt.Fprintf(&b, "\n%d action(s) completed on %s\n", count, datefmt.Date(m.cursor.Format("2006-01-02")))
+		b.WriteString(helpStyle("\n"+i18n.T("HeatmapHelp")) + "\n")
+	}
+	return b.String()
+}
+
+// cell renders a day of the given level
+func (m heatmapModel) cell(level int, selected, interactive bool) string {
+	switch {
+	case selected:
+		return heatmapCursorStyle.Render("□")
+	case !interactive || !theme.Enabled():
+		return heatmapGlyphs[level]
+	}
+	return heatmapLevels[level].Render("■")
+}
+
+// level returns the color level of a count, relative to the busiest day
+func (m heatmapModel) level(count int) int {
+	if count == 0 || m.max == 0 {
+		return 0
+	}
+	last := len(heatmapLevels) - 1
+	level := (count*last + m.max - 1) / m.max
+	return min(max(level, 1), last)
+}
@@ -0,0 +1,295 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/joelgrimberg/projector/browser"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/markdown"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	pickerHeight     = 10 // Maximum number of items to display
+	pickerNoteHeight = 8  // Maximum number of note lines to display
+)
+
+var (
+	pickerCursorStyle = theme.Foreground(theme.Accent).Bold(true)
+	pickerMatchStyle  = theme.Foreground(theme.Highlight).Bold(true)
+	pickerDetailStyle = theme.Foreground(theme.Muted)
+)
+
+// PickerItem is an entry of the fuzzy picker
+type PickerItem struct {
+	ID     uint
+	Label  string   // Text the query is matched against
+	Detail string   // Dimmed text shown after the label
+	Note   string   // Markdown shown below the items while the item is under the cursor
+	Links  []string // URLs of the item, the first of which ctrl+o opens
+}
+
+// pickerMatch is an item matching the query, with the label positions that matched
+type pickerMatch struct {
+	item      PickerItem
+	score     int
+	positions []int
+}
+
+// pickerModel is the state of the fuzzy picker
+type pickerModel struct {
+	title    string
+	input    textinput.Model
+	items    []PickerItem
+	matches  []pickerMatch
+	cursor   int
+	raw      bool // Whether the note is shown as written rather than rendered
+	width    int
+	status   string // Result of opening a link
+	chosen   *PickerItem
+	quitting bool
+	sync     syncWidget
+}
+
+// Pick opens a fuzzy-search picker over the items and returns the chosen
+// item, or nil when the picker was cancelled
+func Pick(title string, items []PickerItem) (*PickerItem, error) {
+	input := textinput.New()
+	input.Placeholder = i18n.T("PickerFilter")
+	input.Prompt = "> "
+	input.Focus()
+
+	m := pickerModel{title: title, input: input, items: items}
+	m.filter()
+	if output.Accessible() {
+		return pickLines(m)
+	}
+
+	result, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return nil, err
+	}
+	return result.(pickerModel).chosen, nil
+}
+
+// Init initializes the picker
+func (m pickerModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.sync.init())
+}
+
+// Update handles key presses, moving the cursor or editing the query
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.sync.update(msg); ok {
+		return m, cmd
+	}
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = msg.Width
+		return m, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "tab":
+			m.raw = !m.raw
+			return m, nil
+		case "ctrl+o":
+			m.status = m.openLink()
+			return m, nil
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "enter":
+			if len(m.matches) > 0 {
+				chosen := m.matches[m.cursor].item
+				m.chosen = &chosen
+			}
+			m.quitting = true
+			return m, tea.Quit
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+				m.status = ""
+			}
+			return m, nil
+		case "down", "ctrl+n", "ctrl+j":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+				m.status = ""
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	query := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// View renders the query and the best matches
+func (m pickerModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.title + "\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	if len(m.matches) == 0 {
+		b.WriteString(pickerDetailStyle.Render("  "+i18n.T("PickerNoMatches")) + "\n")
+	}
+
+	// Scroll so the cursor stays visible
+	start := 0
+	if m.cursor >= pickerHeight {
+		start = m.cursor - pickerHeight + 1
+	}
+	for i := start; i < len(m.matches) && i < start+pickerHeight; i++ {
+		match := m.matches[i]
+		prefix := "  "
+		if i == m.cursor {
+			prefix = pickerCursorStyle.Render("> ")
+		}
+		b.WriteString(prefix + highlight(match.item.Label, match.positions))
+		if match.item.Detail != "" {
+			b.WriteString("  " + pickerDetailStyle.Render(match.item.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	help := i18n.T("PickerHelp", i18n.Data{"Matches": len(m.matches), "Total": len(m.items)})
+	if len(m.matches) > 0 && len(m.matches[m.cursor].item.Links) > 0 {
+		help += " • " + i18n.T("PickerOpenLink")
+	}
+	if note := m.note(); note != "" {
+		b.WriteString("\n" + note + "\n")
+		if m.raw {
+			help += " • " + i18n.T("PickerShowRendered")
+		} else {
+			help += " • " + i18n.T("PickerShowRaw")
+		}
+	}
+
+	if m.status != "" {
+		b.WriteString("\n" + pickerDetailStyle.Render(m.status) + "\n")
+	}
+	b.WriteString("\n" + helpStyle(help) + "\n")
+	b.WriteString(m.sync.view())
+	return mainStyle.Render(b.String())
+}
+
+// openLink opens the first link of the item under the cursor in the browser
+// and describes the result
+func (m pickerModel) openLink() string {
+	if len(m.matches) == 0 || len(m.matches[m.cursor].item.Links) == 0 {
+		return ""
+	}
+	link := m.matches[m.cursor].item.Links[0]
+	if err := browser.Open(link); err != nil {
+		return err.Error()
+	}
+	return i18n.T("PickerOpened", i18n.Data{"URL": link})
+}
+
+// note renders the note of the item under the cursor, cut off after a few
+// lines, or returns an empty string when it has none
+func (m pickerModel) note() string {
+	if len(m.matches) == 0 || strings.TrimSpace(m.matches[m.cursor].item.Note) == "" {
+		return ""
+	}
+
+	note := strings.TrimSpace(m.matches[m.cursor].item.Note)
+	if !m.raw {
+		width := 0
+		if m.width > 0 {
+			width = m.width - 3 // The margin and the indent
+		}
+		note = markdown.Render(note, width)
+	}
+	lines := strings.Split(note, "\n")
+	if len(lines) > pickerNoteHeight {
+		lines = append(lines[:pickerNoteHeight], pickerDetailStyle.Render("…"))
+	}
+	return "  " + strings.Join(lines, "\n  ")
+}
+
+// filter matches the items against the query, best matches first
+func (m *pickerModel) filter() {
+	query := m.input.Value()
+	m.matches = m.matches[:0]
+	for _, item := range m.items {
+		if score, positions, ok := fuzzyMatch(query, item.Label); ok {
+			m.matches = append(m.matches, pickerMatch{item: item, score: score, positions: positions})
+		}
+	}
+	sort.SliceStable(m.matches, func(i, j int) bool {
+		return m.matches[i].score > m.matches[j].score
+	})
+	m.cursor = 0
+}
+
+// fuzzyMatch reports whether all characters of the query appear in order in
+// the text, ignoring case. Consecutive characters and characters at the start
+// of a word score higher, so "fs" prefers "Fix sink" over "offset".
+func fuzzyMatch(query, text string) (int, []int, bool) {
+	queryRunes := []rune(strings.ToLower(strings.TrimSpace(query)))
+	textRunes := []rune(text)
+	if len(queryRunes) == 0 {
+		return 0, nil, true
+	}
+
+	score := 0
+	positions := make([]int, 0, len(queryRunes))
+	q := 0
+	for i := 0; i < len(textRunes) && q < len(queryRunes); i++ {
+		if unicode.ToLower(textRunes[i]) != queryRunes[q] {
+			continue
+		}
+
+		score++
+		if len(positions) > 0 && positions[len(positions)-1] == i-1 {
+			score += 3 // Consecutive characters
+		}
+		if i == 0 || !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]) {
+			score += 2 // Start of a word
+		}
+		positions = append(positions, i)
+		q++
+	}
+
+	if q < len(queryRunes) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+// highlight renders the matched positions of a label in the match style
+func highlight(label string, positions []int) string {
+	if len(positions) == 0 {
+		return label
+	}
+
+	matched := make(map[int]bool, len(positions))
+	for _, position := range positions {
+		matched[position] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(label) {
+		if matched[i] {
+			b.WriteString(pickerMatchStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
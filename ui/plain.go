@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/quickadd"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The prompts below stand in for the full-screen models with accessible
+// output: they print lines and read answers as lines, which screen readers
+// and dumb terminals follow.
+
+// readLine prompts for a line of stdin, returning false at the end of input
+func readLine(in *bufio.Reader, prompt string) (string, bool) {
+	fmt.Print(prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// pickLines lists the best matches numbered and reads a number to choose
+// one, or text to filter the items by
+func pickLines(m pickerModel) (*PickerItem, error) {
+	in := bufio.NewReader(os.Stdin)
+	fmt.Println(m.title)
+	for {
+		if len(m.matches) == 0 {
+			fmt.Println(i18n.T("PickerNoMatches"))
+		}
+		for i, match := range m.matches {
+			if i == pickerHeight {
+				fmt.Println(i18n.T("PlainMoreMatches", i18n.Data{"Count": len(m.matches) - pickerHeight}))
+				break
+			}
+			line := fmt.Sprintf("%d. %s", i+1, match.item.Label)
+			if match.item.Detail != "" {
+				line += ", " + match.item.Detail
+			}
+			fmt.Println(line)
+		}
+
+		answer, ok := readLine(in, i18n.T("PlainPickPrompt")+" ")
+		if !ok || answer == "" {
+			return nil, nil
+		}
+		if number, err := strconv.Atoi(answer); err == nil && number >= 1 && number <= min(len(m.matches), pickerHeight) {
+			chosen := m.matches[number-1].item
+			return &chosen, nil
+		}
+		m.input.SetValue(answer)
+		m.filter()
+	}
+}
+
+// quickEntryLines reads a line in quick-add syntax, asking again while it
+// does not parse or breaks a validation rule
+func quickEntryLines(title string, validate func(line string) error) (string, error) {
+	in := bufio.NewReader(os.Stdin)
+	fmt.Println(title)
+	fmt.Println(i18n.T("PlainQuickEntryHelp"))
+	for {
+		line, ok := readLine(in, "> ")
+		if !ok || line == "" {
+			return "", nil
+		}
+
+		entry, err := quickadd.Parse(line, time.Now())
+		if err == nil && validate != nil {
+			err = validate(line)
+		}
+		if err == nil {
+			if details := entryDetails(entry); len(details) > 0 {
+				fmt.Println(entry.Name + ", " + strings.Join(details, ", "))
+			}
+			return line, nil
+		}
+
+		var invalid *database.ValidationError
+		if errors.As(err, &invalid) {
+			for _, field := range invalid.Fields {
+				fmt.Println(field.Field + ": " + field.Message)
+			}
+		} else {
+			fmt.Println(err)
+		}
+	}
+}
+
+// resolveConflictLines asks for every field whether to keep mine or theirs,
+// the side that was kept being the default
+func resolveConflictLines(title string, fields []ConflictField, keptTheirs bool) ([]string, bool, error) {
+	in := bufio.NewReader(os.Stdin)
+	fmt.Println(title)
+
+	prompt := i18n.T("PlainConflictMine")
+	if keptTheirs {
+		prompt = i18n.T("PlainConflictTheirs")
+	}
+
+	var theirs []string
+	for _, field := range fields {
+		fmt.Println()
+		fmt.Println(field.Name)
+		fmt.Println(i18n.T("PlainConflictMineValue", i18n.Data{"Value": plainValue(field.Mine)}))
+		fmt.Println(i18n.T("PlainConflictTheirsValue", i18n.Data{"Value": plainValue(field.Theirs)}))
+		for {
+			answer, ok := readLine(in, prompt+" ")
+			if !ok {
+				return nil, false, nil
+			}
+			answer = strings.ToLower(answer)
+			if answer == "t" || answer == "theirs" || answer == "" && keptTheirs {
+				theirs = append(theirs, field.Name)
+				break
+			}
+			if answer == "m" || answer == "mine" || answer == "" {
+				break
+			}
+		}
+	}
+	return theirs, true, nil
+}
+
+// plainValue shows a value on a single line, and names an empty value
+func plainValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return i18n.T("PlainEmpty")
+	}
+	return value
+}
+
+// RunInit initializes the database, showing the steps below a spinner, or as
+// a line per step with accessible output
+func RunInit() error {
+	if !output.Accessible() {
+		_, err := tea.NewProgram(NewModel()).Run()
+		return err
+	}
+
+	result, err := tea.NewProgram(NewModel(), tea.WithoutRenderer(), tea.WithInput(nil), tea.WithOutput(io.Discard)).Run()
+	if err != nil {
+		return err
+	}
+	if outcome := result.(Model).outcome(); outcome != "" {
+		fmt.Println(outcome)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/quickadd"
+	"github.com/joelgrimberg/projector/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var quickEntryErrorStyle = theme.Foreground(theme.Error)
+
+// quickEntryModel is the state of the quick-entry bar
+type quickEntryModel struct {
+	title    string
+	input    textinput.Model
+	line     string
+	quitting bool
+	sync     syncWidget
+	validate func(line string) error
+	invalid  error // Result of validate for the current line
+}
+
+// QuickEntry opens a single-line entry bar for an action in quick-add syntax,
+// with a preview of how the line is parsed. When validate is not nil, the
+// line is checked with it as it is typed: the fields that break a rule are
+// shown below the preview and the line cannot be submitted. It returns the
+// entered line, or an empty string when the entry was cancelled.
+func QuickEntry(title string, validate func(line string) error) (string, error) {
+	if output.Accessible() {
+		return quickEntryLines(title, validate)
+	}
+
+	input := textinput.New()
+	input.Placeholder = "Buy milk #errands !high due:fri every:week +Home"
+	input.Prompt = "> "
+	input.CharLimit = 255
+	input.Focus()
+
+	result, err := tea.NewProgram(quickEntryModel{title: title, input: input, validate: validate}).Run()
+	if err != nil {
+		return "", err
+	}
+	return result.(quickEntryModel).line, nil
+}
+
+// Init initializes the quick-entry bar
+func (m quickEntryModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, m.sync.init())
+}
+
+// Update handles key presses, submitting the line on enter when it parses
+func (m quickEntryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if cmd, ok := m.sync.update(msg); ok {
+		return m, cmd
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			m.quitting = true
+			return m, tea.Quit
+		case "enter":
+			if _, err := quickadd.Parse(m.input.Value(), time.Now()); err != nil || m.invalid != nil {
+				return m, nil
+			}
+			m.line = m.input.Value()
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	previous := m.input.Value()
+	m.input, cmd = m.input.Update(msg)
+	if m.validate != nil && m.input.Value() != previous {
+		m.invalid = nil
+		if strings.TrimSpace(m.input.Value()) != "" {
+			m.invalid = m.validate(m.input.Value())
+		}
+	}
+	return m, cmd
+}
+
+// View renders the entry bar and the parsed fields
+func (m quickEntryModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.title + "\n")
+	b.WriteString(m.input.View() + "\n\n")
+
+	if strings.TrimSpace(m.input.Value()) != "" {
+		entry, err := quickadd.Parse(m.input.Value(), time.Now())
+		if err != nil {
+			b.WriteString("  " + quickEntryErrorStyle.Render(err.Error()) + "\n")
+		} else {
+			b.WriteString("  " + entry.Name)
+			if details := entryDetails(entry); len(details) > 0 {
+				b.WriteString("  " + pickerDetailStyle.Render(strings.Join(details, " · ")))
+			}
+			b.WriteString("\n")
+			b.WriteString(invalidFields(m.invalid))
+		}
+	}
+
+	b.WriteString("\n" + helpStyle(i18n.T("QuickEntryHelp")) + "\n")
+	b.WriteString(m.sync.view())
+	return mainStyle.Render(b.String())
+}
+
+// invalidFields renders the fields of a line that break a validation rule,
+// one per line, or the error when it is not a validation error
+func invalidFields(err error) string {
+	if err == nil {
+		return ""
+	}
+	var invalid *database.ValidationError
+	if !errors.As(err, &invalid) {
+		return "  " + quickEntryErrorStyle.Render(err.Error()) + "\n"
+	}
+
+	var b strings.Builder
+	for _, field := range invalid.Fields {
+		b.WriteString("  " + quickEntryErrorStyle.Render(field.Field+": "+field.Message) + "\n")
+	}
+	return b.String()
+}
+
+// entryDetails describes the parsed fields of an entry besides its name
+func entryDetails(entry quickadd.Entry) []string {
+	var details []string
+	if entry.Project != "" {
+		details = append(details, "+"+entry.Project)
+	}
+	if entry.StartDate != "" {
+		details = append(details, "starts "+entry.StartDate)
+	}
+	if entry.DueDate != "" {
+		details = append(details, "due "+entry.DueDate)
+	}
+	if entry.RepeatInterval != "" {
+		repeat := "every " + entry.RepeatInterval
+		if entry.RepeatPattern != "" {
+			repeat += " on " + entry.RepeatPattern
+		}
+		details = append(details, repeat)
+	}
+	if len(entry.Reminders) > 0 {
+		details = append(details, "remind "+strings.Join(entry.Reminders, ","))
+	}
+	if entry.Priority != "" {
+		details = append(details, "!"+entry.Priority)
+	}
+	for _, tag := range entry.Tags {
+		details = append(details, "#"+tag)
+	}
+	return details
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const reviewActionsShown = 10 // Maximum number of open actions listed per project
+
+var (
+	reviewTitleStyle = theme.Foreground(theme.Highlight).Bold(true)
+	reviewErrorStyle = theme.Foreground(theme.Error)
+)
+
+// ReviewItem is a project to review
+type ReviewItem struct {
+	ID      uint
+	Name    string
+	Detail  string   // Dimmed text shown under the name, such as when the review was due
+	Actions []string // Open actions of the project
+}
+
+// reviewModel is the state of the project review
+type reviewModel struct {
+	items        []ReviewItem
+	current      int
+	reviewed     int
+	markReviewed func(projectID uint) error
+	err          error
+	quitting     bool
+}
+
+// Review walks through the projects one at a time, showing their open
+// actions. Marking a project reviewed calls markReviewed and moves on to the
+// next one, as does skipping it. It returns the number of projects marked
+// reviewed.
+func Review(items []ReviewItem, markReviewed func(projectID uint) error) (int, error) {
+	result, err := tea.NewProgram(reviewModel{items: items, markReviewed: markReviewed}).Run()
+	if err != nil {
+		return 0, err
+	}
+	return result.(reviewModel).reviewed, nil
+}
+
+// Init initializes the review
+func (m reviewModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update marks the current project reviewed or skips it
+func (m reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c", "esc", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter", "r":
+		if err := m.markReviewed(m.items[m.current].ID); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.reviewed++
+		return m.next()
+	case "s", "n", "right":
+		return m.next()
+	case "p", "left":
+		if m.current > 0 {
+			m.current--
+			m.err = nil
+		}
+	}
+	return m, nil
+}
+
+// next moves on to the next project, ending the review after the last one
+func (m reviewModel) next() (tea.Model, tea.Cmd) {
+	m.err = nil
+	if m.current == len(m.items)-1 {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	m.current++
+	return m, nil
+}
+
+// View renders the current project and its open actions
+func (m reviewModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	item := m.items[m.current]
+
+	var b strings.Builder
+	b.WriteString(i18n.T("ReviewProgress", i18n.Data{"Current": m.current + 1, "Total": len(m.items)}) + "\n\n")
+	b.WriteString(reviewTitleStyle.Render(item.Name) + "\n")
+	if item.Detail != "" {
+		b.WriteString(pickerDetailStyle.Render(item.Detail) + "\n")
+	}
+	b.WriteString("\n")
+
+	if len(item.Actions) == 0 {
+		b.WriteString(pickerDetailStyle.Render("  No open actions, is the project finished or stuck?") + "\n")
+	}
+	for i, action := range item.Actions {
+		if i == reviewActionsShown {
+			b.WriteString(pickerDetailStyle.Render(fmt.Sprintf("  … and %d more", len(item.Actions)-reviewActionsShown)) + "\n")
+			break
+		}
+		b.WriteString("  • " + action + "\n")
+	}
+
+	if m.err != nil {
+		b.WriteString("\n  " + reviewErrorStyle.Render(m.err.Error()) + "\n")
+	}
+
+	b.WriteString("\n" + helpStyle(i18n.T("ReviewHelp")) + "\n")
+	return mainStyle.Render(b.String())
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/joelgrimberg/projector/datefmt"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/theme"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// syncInterval is how often the sync widget sends queued changes and checks
+// the connection by itself
+const syncInterval = 30 * time.Second
+
+var (
+	syncOnlineStyle  = theme.Foreground(theme.Online)
+	syncOfflineStyle = theme.Foreground(theme.Error)
+)
+
+// SyncStatus is the state of the connection to a remote API server, as
+// shown in the footer of the TUI
+type SyncStatus struct {
+	Remote    string
+	Connected bool
+	Pending   int // Changes queued while the remote was unreachable
+	Refused   int // Queued changes the remote refused, which were dropped
+	Err       error
+}
+
+// SyncFunc sends the queued changes to the remote API server and returns the
+// state of the connection. It may block, the TUI calls it in the background.
+type SyncFunc func() SyncStatus
+
+var syncFunc SyncFunc
+
+// SetSync makes the TUI show the state of the connection to a remote API
+// server in its footer, syncing every 30 seconds and on ctrl+s. Without it no
+// sync widget is shown.
+func SetSync(fn SyncFunc) {
+	syncFunc = fn
+}
+
+// syncStartMsg starts a sync of the given generation
+type syncStartMsg struct{ generation int }
+
+// syncResultMsg is the result of a sync
+type syncResultMsg SyncStatus
+
+// syncWidget is the sync state shown in the footer of the TUI models
+type syncWidget struct {
+	status     *SyncStatus
+	lastSync   time.Time // Last time the remote answered
+	refused    int       // Queued changes refused since the TUI started
+	syncing    bool
+	generation int // Only the latest scheduled sync runs, so forced syncs do not add up
+}
+
+// init schedules the first sync, when a sync function is set
+func (w syncWidget) init() tea.Cmd {
+	if syncFunc == nil {
+		return nil
+	}
+	generation := w.generation
+	return func() tea.Msg {
+		return syncStartMsg{generation: generation}
+	}
+}
+
+// start runs the sync function in the background
+func (w *syncWidget) start() tea.Cmd {
+	w.syncing = true
+	fn := syncFunc
+	return func() tea.Msg {
+		return syncResultMsg(fn())
+	}
+}
+
+// update handles the sync messages and the key that forces a sync. It
+// reports whether the message was meant for the widget.
+func (w *syncWidget) update(msg tea.Msg) (tea.Cmd, bool) {
+	if syncFunc == nil {
+		return nil, false
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() != "ctrl+s" {
+			return nil, false
+		}
+		if w.syncing {
+			return nil, true
+		}
+		return w.start(), true
+	case syncStartMsg:
+		if msg.generation != w.generation || w.syncing {
+			return nil, true
+		}
+		return w.start(), true
+	case syncResultMsg:
+		status := SyncStatus(msg)
+		w.status = &status
+		w.syncing = false
+		w.refused += status.Refused
+		if status.Connected {
+			w.lastSync = time.Now()
+		}
+
+		w.generation++
+		generation := w.generation
+		return tea.Tick(syncInterval, func(time.Time) tea.Msg {
+			return syncStartMsg{generation: generation}
+		}), true
+	}
+	return nil, false
+}
+
+// view renders the sync state as a single footer line, or nothing when no
+// sync function is set
+func (w syncWidget) view() string {
+	if syncFunc == nil {
+		return ""
+	}
+
+	var indicator string
+	var parts []string
+	switch {
+	case w.status == nil:
+		indicator = helpStyle("○")
+		parts = append(parts, i18n.T("SyncConnecting"))
+	case w.status.Err != nil:
+		indicator = syncOfflineStyle.Render("●")
+		parts = append(parts, w.status.Err.Error())
+	case w.status.Connected:
+		indicator = syncOnlineStyle.Render("●")
+		parts = append(parts, w.status.Remote)
+	default:
+		indicator = syncOfflineStyle.Render("●")
+		parts = append(parts, i18n.T("SyncUnreachable", i18n.Data{"Remote": w.status.Remote}))
+	}
+
+	if w.lastSync.IsZero() {
+		parts = append(parts, i18n.T("SyncNever"))
+	} else {
+		parts = append(parts, i18n.T("SyncedAt", i18n.Data{"Time": datefmt.Time(w.lastSync)}))
+	}
+	if w.status != nil && w.status.Pending > 0 {
+		parts = append(parts, i18n.T("SyncQueued", i18n.Data{"Count": w.status.Pending}))
+	}
+	if w.refused > 0 {
+		parts = append(parts, i18n.T("SyncRefused", i18n.Data{"Count": w.refused}))
+	}
+	if w.syncing {
+		parts = append(parts, i18n.T("Syncing"))
+	} else {
+		parts = append(parts, i18n.T("SyncKey"))
+	}
+
+	return indicator + " " + helpStyle(strings.Join(parts, " • ")) + "\n"
+}
@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
 	"github.com/joelgrimberg/projector/models"
+	"github.com/joelgrimberg/projector/output"
+	"github.com/joelgrimberg/projector/theme"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,8 +18,11 @@ import (
 
 const maxResults = 5 // Maximum number of rows to display
 
+// tables lists the tables created or checked during initialization, in order
+var tables = database.Tables
+
 var (
-	helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render
+	helpStyle = theme.Foreground(theme.Muted).Render
 	mainStyle = lipgloss.NewStyle().MarginLeft(1)
 )
 
@@ -33,7 +39,7 @@ type Model struct {
 // NewModel creates a new UI model
 func NewModel() Model {
 	sp := spinner.New()
-	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("206"))
+	sp.Style = theme.Foreground(theme.Accent)
 
 	// Prefill the results slice with dots
 	prefilledResults := make([]models.Result, maxResults)
@@ -71,6 +77,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, cmd
 
 	case models.Result:
+		// Accessible output has no view, every step is printed as a line
+		if output.Accessible() {
+			fmt.Printf("%s %s\n", msg.Emoji, msg.Message)
+		}
+
 		// Add the new result and shift existing messages up
 		m.results = append(m.results[1:], msg)
 		m.step++
@@ -96,14 +107,14 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				return m, createTableStep(m.tableIndex)
 			}
-		case 2, 3, 4, 5, 6, 7: // Continue processing tables (6 steps total due to status seeding/verification)
+		default: // Continue processing tables (one extra step for status seeding/verification)
 			if m.step == 3 && m.tableIndex == 1 { // Special case: status table seeding or verification
 				if m.schemaMode {
 					return m, verifyStatusTableStep()
 				} else {
 					return m, seedStatusTableStep()
 				}
-			} else if m.tableIndex < 4 { // 5 tables total (0-4)
+			} else if m.tableIndex < len(tables)-1 {
 				m.tableIndex++
 				if m.schemaMode {
 					return m, checkTableSchemaStep(m.tableIndex)
@@ -113,8 +124,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				return m, tea.Quit
 			}
-		default:
-			return m, nil
 		}
 
 	default:
@@ -131,24 +140,11 @@ func (m Model) View() string {
 		s += fmt.Sprintf("%s %s\n", res.Emoji, res.Message)
 	}
 
-	// Check if initialization was aborted due to schema differences
-	abortedDueToSchema := false
-	for _, res := range m.results {
-		if strings.Contains(res.Message, "schema differs") {
-			abortedDueToSchema = true
-			break
-		}
-	}
-
-	if abortedDueToSchema {
-		// Show abort message when schema validation failed
-		s += "\n❌ Initialization aborted due to schema differences!\n"
-	} else if m.step >= 7 && m.tableIndex >= 4 {
-		// Show success message when all tables are processed (6 steps total due to status seeding)
-		s += "\n🎉 Initialization complete!\n"
+	if outcome := m.outcome(); outcome != "" {
+		s += "\n" + outcome + "\n"
 	} else {
 		// Only show "Press any key to exit" when initialization is still in progress
-		s += helpStyle("\nPress any key to exit\n")
+		s += helpStyle("\n" + i18n.T("PressAnyKey") + "\n")
 	}
 
 	if m.quitting {
@@ -158,6 +154,23 @@ func (m Model) View() string {
 	return mainStyle.Render(s)
 }
 
+// outcome describes how initialization ended, or is empty while it is still
+// in progress
+func (m Model) outcome() string {
+	// Check if initialization was aborted due to schema differences
+	for _, res := range m.results {
+		if strings.Contains(res.Message, "schema differs") {
+			return "❌ Initialization aborted due to schema differences!"
+		}
+	}
+
+	// All tables are processed after one extra step for status seeding
+	if m.step >= len(tables)+2 && m.tableIndex >= len(tables)-1 {
+		return "🎉 Initialization complete!"
+	}
+	return ""
+}
+
 // runInitStep handles the initial database check/creation
 func runInitStep() tea.Cmd {
 	return func() tea.Msg {
@@ -183,7 +196,6 @@ func createTableStep(tableIndex int) tea.Cmd {
 	return func() tea.Msg {
 		time.Sleep(1 * time.Second)
 
-		tables := []string{"project", "status", "action", "tag", "action_tag"}
 		table := tables[tableIndex]
 
 		err := database.CreateTable(database.GetDatabasePath(), table)
@@ -216,7 +228,6 @@ func checkTableSchemaStep(tableIndex int) tea.Cmd {
 	return func() tea.Msg {
 		time.Sleep(1 * time.Second)
 
-		tables := []string{"project", "status", "action", "tag", "action_tag"}
 		table := tables[tableIndex]
 
 		err := database.CheckTableSchema(database.GetDatabasePath(), table)
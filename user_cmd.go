@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+)
+
+func userCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "Manage user accounts for the API server",
+		Long:  "Manage user accounts for the API server. Once an account exists, API requests need a token and only see the projects and actions of their user.",
+	}
+
+	cmd.AddCommand(userAddCmd())
+	cmd.AddCommand(userListCmd())
+	cmd.AddCommand(userPasswordCmd())
+	cmd.AddCommand(userDeleteCmd())
+	cmd.AddCommand(userTokenCmd())
+	cmd.AddCommand(userAdminCmd())
+	return cmd
+}
+
+func userAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <username>",
+		Short: "Create a user account",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			password, ok := readPassword(cmd)
+			if !ok {
+				return
+			}
+
+			isAdmin, _ := cmd.Flags().GetBool("admin")
+			userID, err := database.CreateUser(database.GetDatabasePath(), args[0], password, isAdmin)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToCreateUser", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("CreatedUser", i18n.Data{"Username": args[0]}))
+
+			claim, _ := cmd.Flags().GetBool("claim")
+			if claim {
+				claimed, err := database.ClaimUnowned(database.GetDatabasePath(), userID)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToClaimItems", i18n.Data{"Error": err}))
+					return
+				}
+				fmt.Println(i18n.T("ClaimedItems", i18n.Data{"Username": args[0], "Count": claimed}))
+			}
+		},
+	}
+
+	cmd.Flags().String("password", "", "Password (prompted for when omitted)")
+	cmd.Flags().Bool("claim", false, "Give the user all projects and actions that have no owner yet")
+	cmd.Flags().Bool("admin", false, "Make the user an admin (the first user always is)")
+	return cmd
+}
+
+func userListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List user accounts",
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			users, err := database.GetAllUsers(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingUsers", i18n.Data{"Error": err}))
+				return
+			}
+
+			printUsers(users)
+		},
+	}
+}
+
+func userPasswordCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "passwd <username>",
+		Short:             "Change the password of a user and revoke its tokens",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeUsernames,
+		Run: func(cmd *cobra.Command, args []string) {
+			user, ok := lookupUser(args[0])
+			if !ok {
+				return
+			}
+
+			password, ok := readPassword(cmd)
+			if !ok {
+				return
+			}
+
+			if err := database.SetUserPassword(database.GetDatabasePath(), user.ID, password); err != nil {
+				fmt.Println(i18n.T("FailedToChangePassword", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("PasswordChanged", i18n.Data{"Username": user.Username}))
+		},
+	}
+
+	cmd.Flags().String("password", "", "New password (prompted for when omitted)")
+	return cmd
+}
+
+func userDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <username>",
+		Short:             "Delete a user account, keeping its projects and actions without an owner",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeUsernames,
+		Run: func(cmd *cobra.Command, args []string) {
+			user, ok := lookupUser(args[0])
+			if !ok {
+				return
+			}
+
+			if isDryRun(cmd) {
+				fmt.Println(i18n.T("WouldDeleteUser", i18n.Data{"Username": user.Username}))
+				return
+			}
+			if !confirm(cmd, i18n.T("DeleteUserConfirm", i18n.Data{"Username": user.Username})) {
+				return
+			}
+
+			if err := database.DeleteUser(database.GetDatabasePath(), user.ID); err != nil {
+				fmt.Println(i18n.T("FailedToDeleteUser", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(i18n.T("DeletedUser", i18n.Data{"Username": user.Username}))
+		},
+	}
+}
+
+func userTokenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "token <username>",
+		Short:             "Issue an API token for a user",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeUsernames,
+		Run: func(cmd *cobra.Command, args []string) {
+			user, ok := lookupUser(args[0])
+			if !ok {
+				return
+			}
+
+			token, err := database.CreateToken(database.GetDatabasePath(), user.ID)
+			if err != nil {
+				fmt.Println(i18n.T("FailedToCreateToken", i18n.Data{"Error": err}))
+				return
+			}
+			fmt.Println(token)
+		},
+	}
+}
+
+func userAdminCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "admin <username>",
+		Short:             "Grant a user the admin role, or revoke it with --revoke",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeUsernames,
+		Run: func(cmd *cobra.Command, args []string) {
+			user, ok := lookupUser(args[0])
+			if !ok {
+				return
+			}
+
+			revoke, _ := cmd.Flags().GetBool("revoke")
+			if err := database.SetUserAdmin(database.GetDatabasePath(), user.ID, !revoke); err != nil {
+				fmt.Println(i18n.T("FailedToUpdateUser", i18n.Data{"Error": err}))
+				return
+			}
+
+			if revoke {
+				fmt.Println(i18n.T("AdminRevoked", i18n.Data{"Username": user.Username}))
+			} else {
+				fmt.Println(i18n.T("AdminGranted", i18n.Data{"Username": user.Username}))
+			}
+		},
+	}
+
+	cmd.Flags().Bool("revoke", false, "Revoke the admin role")
+	return cmd
+}
+
+// userRecord is the JSON form of a user in command output
+type userRecord struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+	IsAdmin   bool   `json:"is_admin"`
+	Disabled  bool   `json:"disabled"`
+}
+
+// printUsers prints user accounts in the selected output format
+func printUsers(users []database.User) {
+	if len(users) == 0 && !output.IsJSON() {
+		fmt.Println("No users, the API server runs in single-user mode.")
+		return
+	}
+
+	records := []userRecord{}
+	table := output.Table{Headers: []string{"ID", "USERNAME", "CREATED", "ADMIN", "DISABLED"}}
+	for _, user := range users {
+		record := userRecord{
+			ID:        user.ID,
+			Username:  user.Username,
+			CreatedAt: database.StoredDate(user.CreatedAt),
+			IsAdmin:   user.IsAdmin,
+			Disabled:  user.Disabled,
+		}
+		records = append(records, record)
+		table.AddRow(fmt.Sprint(record.ID), record.Username, record.CreatedAt, yesNo(record.IsAdmin), yesNo(record.Disabled))
+	}
+
+	if err := output.Print(records, table); err != nil {
+		fmt.Println(i18n.T("FailedToPrintUsers", i18n.Data{"Error": err}))
+	}
+}
+
+// yesNo formats a boolean for table output
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+// lookupUser finds a user by username, printing an error when it does not exist
+func lookupUser(username string) (*database.User, bool) {
+	if !database.DatabaseExists(database.GetDatabasePath()) {
+		fmt.Println(i18n.T("DatabaseNotFound"))
+		return nil, false
+	}
+
+	user, err := database.GetUserByUsername(database.GetDatabasePath(), username)
+	if err != nil {
+		fmt.Println(i18n.T("ErrorRetrievingUser", i18n.Data{"Error": err}))
+		return nil, false
+	}
+	if user == nil {
+		fmt.Println(i18n.T("UserNotFound", i18n.Data{"Username": username}))
+		return nil, false
+	}
+
+	return user, true
+}
+
+// readPassword returns the --password flag, or prompts for the password.
+// Input that is not a terminal is read as a single line.
+func readPassword(cmd *cobra.Command) (string, bool) {
+	if cmd.Flags().Changed("password") {
+		password, _ := cmd.Flags().GetString("password")
+		return password, true
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Println(i18n.T("FailedToReadPassword", i18n.Data{"Error": err}))
+			return "", false
+		}
+		return strings.TrimRight(line, "\r\n"), true
+	}
+
+	fmt.Print("Password: ")
+	password, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	if err != nil {
+		fmt.Println(i18n.T("FailedToReadPassword", i18n.Data{"Error": err}))
+		return "", false
+	}
+
+	fmt.Print("Repeat password: ")
+	repeated, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Println()
+	if err != nil {
+		fmt.Println(i18n.T("FailedToReadPassword", i18n.Data{"Error": err}))
+		return "", false
+	}
+
+	if string(password) != string(repeated) {
+		fmt.Println(i18n.T("PasswordsDoNotMatch"))
+		return "", false
+	}
+
+	return string(password), true
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joelgrimberg/projector/database"
+	"github.com/joelgrimberg/projector/i18n"
+	"github.com/joelgrimberg/projector/notify"
+	"github.com/joelgrimberg/projector/output"
+
+	"github.com/spf13/cobra"
+)
+
+func waitingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "waiting",
+		Short: "List the actions waiting on someone or something, earliest follow-up first",
+		Long: `List the open actions waiting on a person or system, set with
+'projector action update <id> --waiting-on <who> --follow-up <date>'.
+
+Actions whose follow-up date has arrived are marked in the FOLLOW UP column.
+--due lists only those, and --notify also nudges the channels subscribed to
+the follow_up event, once per follow-up date.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !database.DatabaseExists(database.GetDatabasePath()) {
+				fmt.Println(i18n.T("DatabaseNotFound"))
+				return
+			}
+
+			due, _ := cmd.Flags().GetBool("due")
+			notifyChannels, _ := cmd.Flags().GetBool("notify")
+
+			actions, err := database.GetWaitingActions(database.GetDatabasePath())
+			if err != nil {
+				fmt.Println(i18n.T("ErrorRetrievingActions", i18n.Data{"Error": err}))
+				return
+			}
+
+			today := time.Now().Format("2006-01-02")
+			records := []actionRecord{}
+			table := output.Table{Headers: []string{"ID", "NAME", "PROJECT", "WAITING ON", "FOLLOW UP"}}
+			for _, action := range actions {
+				followUpDue := action.FollowUpDue(today)
+				if due && !followUpDue {
+					continue
+				}
+
+				record := newActionRecord(action)
+				records = append(records, record)
+
+				followUp := displayDate(record.FollowUp)
+				if followUpDue {
+					followUp = output.Colorize(followUp+" (due)", "red")
+				}
+				table.AddRow(fmt.Sprint(record.ID), record.Name, record.Project, record.WaitingOn, followUp)
+			}
+
+			if len(records) == 0 && !output.IsJSON() {
+				if due {
+					fmt.Println(i18n.T("NoFollowUpsDue"))
+				} else {
+					fmt.Println(i18n.T("NothingWaiting"))
+				}
+			} else if err := output.Print(records, table); err != nil {
+				fmt.Println(i18n.T("FailedToPrintActions", i18n.Data{"Error": err}))
+				return
+			}
+
+			if notifyChannels {
+				dispatcher, ok := loadDispatcher()
+				if !ok {
+					return
+				}
+
+				sent, err := notify.SendFollowUps(database.GetDatabasePath(), dispatcher)
+				if err != nil {
+					fmt.Println(i18n.T("FailedToSendFollowUps", i18n.Data{"Error": err}))
+					return
+				}
+				fmt.Println(i18n.T("SentFollowUps", i18n.Data{"Count": sent}))
+			}
+		},
+	}
+
+	cmd.Flags().Bool("due", false, "Only list the actions whose follow-up date has arrived")
+	cmd.Flags().Bool("notify", false, "Send a follow-up notification for every action whose follow-up date has arrived")
+	return cmd
+}
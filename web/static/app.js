@@ -0,0 +1,249 @@
+// Built-in web interface for the projector API. The API token of servers with
+// user accounts is kept in localStorage.
+"use strict";
+
+const $ = (selector) => document.querySelector(selector);
+
+let projects = [];
+let actions = [];
+let statuses = {};
+
+// api calls an API endpoint and returns the decoded JSON response. A 401
+// response to anything but a login shows the login form.
+async function api(method, path, body) {
+  const headers = {};
+  const token = localStorage.getItem("projector-token");
+  if (token) {
+    headers["Authorization"] = "Bearer " + token;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+
+  const response = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+
+  if (response.status === 401 && path !== "/api/v1/login") {
+    localStorage.removeItem("projector-token");
+    showLogin();
+    throw new Error("Please log in");
+  }
+  if (!response.ok) {
+    // Most errors are plain text, a duplicate action is JSON with a message
+    const text = (await response.text()).trim();
+    let message = text;
+    try {
+      message = JSON.parse(text).message || text;
+    } catch (e) {}
+    throw new Error(message || response.statusText);
+  }
+  return response.json();
+}
+
+function showError(error) {
+  $("#error").textContent = error ? error.message : "";
+  $("#error").hidden = !error;
+}
+
+function showLogin() {
+  $("#login").hidden = false;
+  $("#app").hidden = true;
+  $("#logout").hidden = true;
+}
+
+function showApp() {
+  $("#login").hidden = true;
+  $("#app").hidden = false;
+  $("#logout").hidden = !localStorage.getItem("projector-token");
+}
+
+function today() {
+  const now = new Date();
+  now.setMinutes(now.getMinutes() - now.getTimezoneOffset());
+  return now.toISOString().slice(0, 10);
+}
+
+async function load() {
+  try {
+    const [projectResponse, actionResponse, statusResponse] = await Promise.all([
+      api("GET", "/api/v1/projects"),
+      api("GET", "/api/v1/actions"),
+      api("GET", "/api/v1/statuses"),
+    ]);
+    projects = projectResponse.projects || [];
+    actions = actionResponse.actions || [];
+    statuses = {};
+    for (const status of statusResponse.statuses || []) {
+      statuses[status.name] = status;
+    }
+    showApp();
+    showError(null);
+    renderProjects();
+    renderActions();
+  } catch (error) {
+    if (!$("#login").hidden) {
+      return;
+    }
+    showError(error);
+  }
+}
+
+function renderProjects() {
+  for (const select of [$("#create-project"), $("#filter-project")]) {
+    const selected = select.value;
+    select.querySelectorAll("option[data-project]").forEach((option) => option.remove());
+    for (const project of projects) {
+      const option = document.createElement("option");
+      option.value = project.id;
+      option.textContent = project.name;
+      option.dataset.project = "true";
+      select.append(option);
+    }
+    select.value = selected;
+    if (select.value !== selected) {
+      select.selectedIndex = 0;
+    }
+  }
+}
+
+function renderActions() {
+  const filter = $("#filter-project").value;
+  const showDone = $("#filter-done").checked;
+
+  const visible = actions.filter((action) => {
+    if (!showDone && action.status_name === "done") {
+      return false;
+    }
+    if (filter === "none") {
+      return action.project_id === undefined;
+    }
+    if (filter !== "all") {
+      return String(action.project_id) === filter;
+    }
+    return true;
+  });
+
+  const list = $("#actions");
+  list.replaceChildren();
+  for (const action of visible) {
+    list.append(renderAction(action));
+  }
+  $("#empty").hidden = visible.length > 0;
+}
+
+function renderAction(action) {
+  const item = document.createElement("li");
+  const done = action.status_name === "done";
+  item.classList.toggle("done", done);
+
+  item.append(renderStatus(action.status_name));
+
+  const name = document.createElement("span");
+  name.className = "name";
+  name.textContent = action.name;
+  item.append(name);
+
+  const meta = document.createElement("span");
+  meta.className = "meta";
+  const due = action.due_date || "";
+  meta.textContent = [action.project_name, due].filter(Boolean).join(" · ");
+  if (!done && due && due < today()) {
+    meta.classList.add("overdue");
+  }
+  item.append(meta);
+
+  if (!done) {
+    const doneButton = document.createElement("button");
+    doneButton.type = "button";
+    doneButton.textContent = "Done";
+    doneButton.addEventListener("click", () => update(() => api("PUT", "/api/v1/actions/" + action.id, { action: "done" })));
+    item.append(doneButton);
+  }
+
+  const deleteButton = document.createElement("button");
+  deleteButton.type = "button";
+  deleteButton.textContent = "Delete";
+  deleteButton.addEventListener("click", () => {
+    if (confirm("Delete \"" + action.name + "\"?")) {
+      update(() => api("DELETE", "/api/v1/actions/" + action.id));
+    }
+  });
+  item.append(deleteButton);
+
+  return item;
+}
+
+// renderStatus shows a status with the icon and color set with
+// `projector statuses edit`, like the CLI tables do
+function renderStatus(statusName) {
+  const status = statuses[statusName] || { name: statusName };
+  const badge = document.createElement("span");
+  badge.className = "status";
+  badge.textContent = [status.icon, status.name].filter(Boolean).join(" ");
+  if (status.color) {
+    badge.style.color = status.color;
+  }
+  return badge;
+}
+
+// update runs a change against the API and reloads the list
+async function update(change) {
+  try {
+    await change();
+    await load();
+  } catch (error) {
+    showError(error);
+  }
+}
+
+$("#create").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const fields = event.target.elements;
+  const body = { name: fields["name"].value.trim() };
+  if (fields["project_id"].value) {
+    body.project_id = Number(fields["project_id"].value);
+  }
+  if (fields["due_date"].value) {
+    body.due_date = fields["due_date"].value;
+  }
+
+  update(async () => {
+    await api("PUT", "/api/v1/actions", body);
+    fields["name"].value = "";
+    fields["due_date"].value = "";
+  });
+});
+
+$("#login").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const fields = event.target.elements;
+  try {
+    const response = await api("POST", "/api/v1/login", {
+      username: fields["username"].value,
+      password: fields["password"].value,
+    });
+    localStorage.setItem("projector-token", response.token);
+    fields["password"].value = "";
+    await load();
+  } catch (error) {
+    showError(error);
+  }
+});
+
+$("#logout").addEventListener("click", async () => {
+  try {
+    await api("POST", "/api/v1/logout");
+  } catch (error) {
+    // The token is dropped locally either way
+  }
+  localStorage.removeItem("projector-token");
+  showLogin();
+});
+
+$("#filter-project").addEventListener("change", renderActions);
+$("#filter-done").addEventListener("change", renderActions);
+
+load();
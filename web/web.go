@@ -0,0 +1,21 @@
+// Package web serves the built-in browser interface of the API server
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the static files of the web interface
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded directory is fixed at build time
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
@@ -0,0 +1,21 @@
+package wire
+
+// ImportFailure is a row of an import file that was not imported
+type ImportFailure struct {
+	Row    string `json:"row"` // Where the row is in the file, such as line 12
+	Reason string `json:"reason"`
+}
+
+// ImportResult summarizes an import, with the rows that failed and why
+type ImportResult struct {
+	Imported int             `json:"imported"`
+	Skipped  int             `json:"skipped"`
+	Failed   []ImportFailure `json:"failed"`
+	Warnings []string        `json:"warnings"`
+}
+
+// ImportProgress is the progress event of a streamed import
+type ImportProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
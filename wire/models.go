@@ -0,0 +1,75 @@
+// Package wire holds the JSON forms exchanged between the server and its
+// clients. It has no dependencies, so the client package can use them without
+// pulling in the server and the database driver.
+package wire
+
+// Action is the JSON form of an action in API responses. Optional fields are
+// pointers, left out of the response when they are not set.
+type Action struct {
+	ID             uint     `json:"id"`
+	ProjectID      *uint    `json:"project_id,omitempty"`
+	Name           string   `json:"name"`
+	Note           *string  `json:"note,omitempty"`
+	DueDate        *string  `json:"due_date,omitempty"`   // YYYY-MM-DD
+	StartDate      *string  `json:"start_date,omitempty"` // YYYY-MM-DD, hidden from active lists until then
+	StatusID       uint     `json:"status_id"`
+	RepeatCount    uint     `json:"repeat_count"`
+	RepeatInterval *string  `json:"repeat_interval,omitempty"`
+	RepeatPattern  *string  `json:"repeat_pattern,omitempty"`
+	RepeatUntil    *string  `json:"repeat_until,omitempty"` // YYYY-MM-DD
+	ParentActionID *uint    `json:"parent_action_id,omitempty"`
+	RepeatMode     *string  `json:"repeat_mode,omitempty"`
+	OwnerID        *uint    `json:"owner_id,omitempty"`
+	AssigneeID     *uint    `json:"assignee_id,omitempty"`
+	Flagged        bool     `json:"flagged"` // Picked for today
+	Location       *string  `json:"location,omitempty"`
+	Latitude       *float64 `json:"latitude,omitempty"`
+	Longitude      *float64 `json:"longitude,omitempty"`
+	Energy         *string  `json:"energy,omitempty"` // low, medium or high
+	WaitingOn      *string  `json:"waiting_on,omitempty"`
+	FollowUp       *string  `json:"follow_up,omitempty"`
+	AssigneeName   *string  `json:"assignee_name,omitempty"`
+	ProjectName    *string  `json:"project_name,omitempty"`
+	StatusName     string   `json:"status_name"`
+	Tags           []string `json:"tags"`
+	Reminders      []string `json:"reminders"` // Offsets before the due date such as -1d or -2h
+	Version        uint     `json:"version"`   // Send back with PATCH to detect concurrent edits
+}
+
+// Project is the JSON form of a project in API responses
+type Project struct {
+	ID         uint    `json:"id"`
+	Name       string  `json:"name"`
+	DueDate    *string `json:"due_date,omitempty"` // YYYY-MM-DD
+	OwnerID    *uint   `json:"owner_id,omitempty"`
+	ArchivedAt *string `json:"archived_at,omitempty"`
+
+	RepeatInterval  *string `json:"repeat_interval,omitempty"`
+	ParentProjectID *uint   `json:"parent_project_id,omitempty"` // Previous period
+
+	ReviewInterval *string `json:"review_interval,omitempty"` // Such as 1w or 2m
+	LastReviewedAt *string `json:"last_reviewed_at,omitempty"`
+
+	Version uint `json:"version"` // Send back with PATCH to detect concurrent edits
+}
+
+// User is the JSON form of a user account in API responses
+type User struct {
+	ID        uint   `json:"id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+	IsAdmin   bool   `json:"is_admin"`
+	Disabled  bool   `json:"disabled"`
+}
+
+// Stats summarizes the contents of the whole database
+type Stats struct {
+	Users          int   `json:"users"`
+	Projects       int   `json:"projects"`
+	Actions        int   `json:"actions"`
+	OpenActions    int   `json:"open_actions"`
+	DoneActions    int   `json:"done_actions"`
+	OverdueActions int   `json:"overdue_actions"`
+	Tags           int   `json:"tags"`
+	SizeBytes      int64 `json:"size_bytes"`
+}
@@ -0,0 +1,64 @@
+package wire
+
+// ProjectChange is a project as exchanged during remote sync
+type ProjectChange struct {
+	UID            string `json:"uid"`
+	Name           string `json:"name"`
+	DueDate        string `json:"due_date,omitempty"`
+	RepeatInterval string `json:"repeat_interval,omitempty"`
+	ReviewInterval string `json:"review_interval,omitempty"`
+	LastReviewedAt string `json:"last_reviewed_at,omitempty"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+// ActionChange is an action as exchanged during remote sync. Projects, parent
+// actions, statuses and tags are referenced by uid or name, since row IDs
+// differ between instances.
+type ActionChange struct {
+	UID            string   `json:"uid"`
+	ProjectUID     string   `json:"project_uid,omitempty"`
+	Name           string   `json:"name"`
+	Note           string   `json:"note,omitempty"`
+	DueDate        string   `json:"due_date,omitempty"`
+	Status         string   `json:"status"`
+	RepeatMode     string   `json:"repeat_mode,omitempty"`
+	RepeatCount    uint     `json:"repeat_count,omitempty"`
+	RepeatInterval string   `json:"repeat_interval,omitempty"`
+	RepeatPattern  string   `json:"repeat_pattern,omitempty"`
+	RepeatUntil    string   `json:"repeat_until,omitempty"`
+	ParentUID      string   `json:"parent_uid,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Flagged        bool     `json:"flagged,omitempty"`
+	Location       string   `json:"location,omitempty"`
+	Latitude       *float64 `json:"latitude,omitempty"`
+	Longitude      *float64 `json:"longitude,omitempty"`
+	Energy         string   `json:"energy,omitempty"`
+	WaitingOn      string   `json:"waiting_on,omitempty"`
+	FollowUp       string   `json:"follow_up,omitempty"`
+	StartDate      string   `json:"start_date,omitempty"`
+	Reminders      []string `json:"reminders,omitempty"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// Tombstone records the deletion of a project or action
+type Tombstone struct {
+	Entity    string `json:"entity"`
+	UID       string `json:"uid"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+// Changes holds everything that changed since a point in time. Until is the
+// time the changes were read, to be used as the next since.
+type Changes struct {
+	Projects   []ProjectChange `json:"projects"`
+	Actions    []ActionChange  `json:"actions"`
+	Tombstones []Tombstone     `json:"tombstones"`
+	Until      string          `json:"until"`
+}
+
+// ApplyResult summarizes the outcome of applying Changes
+type ApplyResult struct {
+	Applied int `json:"applied"` // Rows created or updated
+	Deleted int `json:"deleted"` // Rows removed by a tombstone
+	Skipped int `json:"skipped"` // Changes older than the local data
+}